@@ -6,8 +6,11 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/sbilibin2017/go-yandex-practicum/internal/apps"
+	"github.com/sbilibin2017/go-yandex-practicum/internal/collectors"
 	"github.com/spf13/pflag"
 )
 
@@ -53,18 +56,77 @@ var (
 	flagRestore        bool   // whether to restore data from backup
 	flagLogLevel       string // application log level
 	flagBatchSize      int    // batch size for metrics reporting
+	flagKey            string // key for HMAC SHA256 hash
+	flagCryptoKey      string // path to public key file for encryption
+	flagHashHeader     string // metadata key for SHA256 hash
+	flagTLSCA          string // path to the CA bundle for the server certificate
+	flagTLSCert        string // path to the client TLS certificate
+	flagTLSKey         string // path to the client TLS certificate key
+	flagOutboxDir      string // directory of the on-disk outbox
+	flagOutboxMaxBytes int64  // maximum size of the outbox in bytes
+	flagOutboxMaxAge   int    // maximum age of queued batches in seconds
+	flagOutboxDrop     string // outbox drop policy
+
+	flagRetryAttempts       int           // attempts per batch including the first
+	flagRetryInitialBackoff time.Duration // delay before the first retry
+	flagRetryMaxBackoff     time.Duration // cap on the delay between retries
+	flagBreakerThreshold    int           // consecutive failures that open the circuit breaker
+	flagBreakerCooldown     time.Duration // how long the circuit breaker stays open
+
+	flagGaugeAggregation string // how gauges are consolidated between reports
+
+	flagCollectors         string        // comma-separated collectors to poll
+	flagCollectorIntervals string        // per-collector poll intervals, name=seconds pairs
+	flagCollectorTimeout   time.Duration // limit on a single poll of a collector
+	flagCgroupPath         string        // cgroup v2 directory to report
+	flagProcesses          string        // processes reported by the process collector
+	flagExecPlugins        []string      // exec plugins as name=command pairs
+	flagExecTimeout        time.Duration // limit on a single run of an exec plugin
+
+	flagPushAddress   string // local HTTP endpoint for pushed metrics
+	flagStatsDAddress string // local StatsD UDP listener
 )
 
 // parseFlags parses command-line flags and stores their values in the global config variables.
 func parseFlags() error {
-	pflag.StringVarP(&flagServerAddress, "address", "a", "http://localhost:8080", "Metrics server address")
+	pflag.StringVarP(&flagServerAddress, "address", "a", "http://localhost:8080", "Metrics server address, or unix:///path.sock")
 	pflag.IntVarP(&flagPollInterval, "poll-interval", "p", 2, "Poll interval in seconds")
 	pflag.IntVarP(&flagReportInterval, "report-interval", "r", 10, "Report interval in seconds")
 	pflag.IntVarP(&flagRateLimit, "rate-limit", "l", 0, "Max number of concurrent outgoing requests")
-	pflag.BoolVar(&flagRestore, "restore", false, "Whether to restore data from backup")
+	pflag.BoolVar(&flagRestore, "restore", false, "Whether to replay batches left in the outbox by a previous run")
 	pflag.StringVarP(&flagConfigPath, "config", "c", "", "Path to config file")
 	pflag.StringVarP(&flagLogLevel, "log-level", "L", "info", "Log level for the application")
 	pflag.IntVarP(&flagBatchSize, "batch-size", "b", 100, "Batch size for metrics reporting")
+	pflag.StringVarP(&flagKey, "key", "k", "", "Key for HMAC SHA256 hash")
+	pflag.StringVar(&flagCryptoKey, "crypto-key", "", "Path to public key file for encryption")
+	pflag.StringVar(&flagHashHeader, "hash-header", "H", "Metadata key for SHA256 hash")
+
+	pflag.StringVar(&flagTLSCA, "tls-ca", "", "Path to the CA bundle the server certificate is verified against; enables TLS")
+	pflag.StringVar(&flagTLSCert, "tls-cert", "", "Path to the client certificate for mutual TLS")
+	pflag.StringVar(&flagTLSKey, "tls-key", "", "Path to the client certificate key")
+
+	pflag.StringVar(&flagOutboxDir, "outbox-dir", "", "Directory for batches the server did not accept; empty disables the outbox")
+	pflag.Int64Var(&flagOutboxMaxBytes, "outbox-max-bytes", 64<<20, "Maximum size of the outbox in bytes, 0 for unlimited")
+	pflag.IntVar(&flagOutboxMaxAge, "outbox-max-age", 3600, "Seconds after which queued batches are discarded, 0 keeps them")
+	pflag.StringVar(&flagOutboxDrop, "outbox-drop", "oldest", "What to drop when the outbox is full: oldest or newest")
+
+	pflag.IntVar(&flagRetryAttempts, "retry-attempts", 4, "Attempts per batch including the first")
+	pflag.DurationVar(&flagRetryInitialBackoff, "retry-initial-backoff", time.Second, "Delay before the first retry; later delays grow exponentially with jitter")
+	pflag.DurationVar(&flagRetryMaxBackoff, "retry-max-backoff", 5*time.Second, "Cap on the delay between retries")
+	pflag.IntVar(&flagBreakerThreshold, "breaker-threshold", 5, "Consecutive failures that stop sending to the server, 0 disables the circuit breaker")
+	pflag.DurationVar(&flagBreakerCooldown, "breaker-cooldown", 30*time.Second, "How long to wait before probing a server the circuit breaker stopped")
+
+	pflag.StringVar(&flagGaugeAggregation, "gauge-aggregation", "last", "Gauge value reported when polled several times per report: last, min, max or avg")
+
+	pflag.StringVar(&flagCollectors, "collectors", "", "Comma-separated collectors to poll (runtime, memory, cpu, disk, network, load, filesystem, swap, processes, cgroup, process); empty polls all of them")
+	pflag.StringVar(&flagCollectorIntervals, "collector-intervals", "", "Per-collector poll intervals in seconds overriding the poll interval, e.g. cpu=5,memory=10")
+	pflag.DurationVar(&flagCollectorTimeout, "collector-timeout", 0, "Limit on a single poll of a collector, 0 for its poll interval")
+	pflag.StringVar(&flagCgroupPath, "cgroup-path", "", "cgroup v2 directory reported by the cgroup collector; empty for the agent's own cgroup")
+	pflag.StringVar(&flagProcesses, "processes", "", "Comma-separated processes to report: name:<glob>, pidfile:<path> or pid:<number>")
+	pflag.StringArrayVar(&flagExecPlugins, "exec", nil, "Exec plugin as name=command [args], run on every poll; its output lines are \"name type value\" or JSON metrics. Repeatable")
+	pflag.DurationVar(&flagExecTimeout, "exec-timeout", 10*time.Second, "Limit on a single run of an exec plugin")
+	pflag.StringVar(&flagPushAddress, "push-address", "", "Local address accepting metrics on the server's /update/ and /updates/ routes, e.g. 127.0.0.1:8081; empty disables it")
+	pflag.StringVar(&flagStatsDAddress, "statsd-address", "", "Local UDP address of the StatsD listener, e.g. 127.0.0.1:8125; empty disables it")
 
 	pflag.Parse()
 	return nil
@@ -95,6 +157,35 @@ func parseConfigFile() error {
 		Restore        *bool   `json:"restore,omitempty"`
 		LogLevel       *string `json:"log_level,omitempty"`
 		BatchSize      *int    `json:"batch_size,omitempty"`
+		Key            *string `json:"key,omitempty"`
+		CryptoKey      *string `json:"crypto_key,omitempty"`
+		HashHeader     *string `json:"hash_header,omitempty"`
+		TLSCA          *string `json:"tls_ca,omitempty"`
+		TLSCert        *string `json:"tls_cert,omitempty"`
+		TLSKey         *string `json:"tls_key,omitempty"`
+		OutboxDir      *string `json:"outbox_dir,omitempty"`
+		OutboxMaxBytes *int64  `json:"outbox_max_bytes,omitempty"`
+		OutboxMaxAge   *int    `json:"outbox_max_age,omitempty"`
+		OutboxDrop     *string `json:"outbox_drop,omitempty"`
+
+		RetryAttempts       *int    `json:"retry_attempts,omitempty"`
+		RetryInitialBackoff *string `json:"retry_initial_backoff,omitempty"`
+		RetryMaxBackoff     *string `json:"retry_max_backoff,omitempty"`
+		BreakerThreshold    *int    `json:"breaker_threshold,omitempty"`
+		BreakerCooldown     *string `json:"breaker_cooldown,omitempty"`
+
+		GaugeAggregation *string `json:"gauge_aggregation,omitempty"`
+
+		Collectors         *string  `json:"collectors,omitempty"`
+		CollectorIntervals *string  `json:"collector_intervals,omitempty"`
+		CollectorTimeout   *string  `json:"collector_timeout,omitempty"`
+		CgroupPath         *string  `json:"cgroup_path,omitempty"`
+		Processes          *string  `json:"processes,omitempty"`
+		ExecPlugins        []string `json:"exec_plugins,omitempty"`
+		ExecTimeout        *string  `json:"exec_timeout,omitempty"`
+
+		PushAddress   *string `json:"push_address,omitempty"`
+		StatsDAddress *string `json:"statsd_address,omitempty"`
 	}{}
 
 	if err := json.NewDecoder(file).Decode(cfg); err != nil {
@@ -122,6 +213,91 @@ func parseConfigFile() error {
 	if cfg.BatchSize != nil {
 		flagBatchSize = *cfg.BatchSize
 	}
+	if cfg.Key != nil {
+		flagKey = *cfg.Key
+	}
+	if cfg.CryptoKey != nil {
+		flagCryptoKey = *cfg.CryptoKey
+	}
+	if cfg.HashHeader != nil {
+		flagHashHeader = *cfg.HashHeader
+	}
+	if cfg.TLSCA != nil {
+		flagTLSCA = *cfg.TLSCA
+	}
+	if cfg.TLSCert != nil {
+		flagTLSCert = *cfg.TLSCert
+	}
+	if cfg.TLSKey != nil {
+		flagTLSKey = *cfg.TLSKey
+	}
+	if cfg.OutboxDir != nil {
+		flagOutboxDir = *cfg.OutboxDir
+	}
+	if cfg.OutboxMaxBytes != nil {
+		flagOutboxMaxBytes = *cfg.OutboxMaxBytes
+	}
+	if cfg.OutboxMaxAge != nil {
+		flagOutboxMaxAge = *cfg.OutboxMaxAge
+	}
+	if cfg.OutboxDrop != nil {
+		flagOutboxDrop = *cfg.OutboxDrop
+	}
+	if cfg.RetryAttempts != nil {
+		flagRetryAttempts = *cfg.RetryAttempts
+	}
+	if cfg.RetryInitialBackoff != nil {
+		if flagRetryInitialBackoff, err = time.ParseDuration(*cfg.RetryInitialBackoff); err != nil {
+			return err
+		}
+	}
+	if cfg.RetryMaxBackoff != nil {
+		if flagRetryMaxBackoff, err = time.ParseDuration(*cfg.RetryMaxBackoff); err != nil {
+			return err
+		}
+	}
+	if cfg.BreakerThreshold != nil {
+		flagBreakerThreshold = *cfg.BreakerThreshold
+	}
+	if cfg.BreakerCooldown != nil {
+		if flagBreakerCooldown, err = time.ParseDuration(*cfg.BreakerCooldown); err != nil {
+			return err
+		}
+	}
+	if cfg.GaugeAggregation != nil {
+		flagGaugeAggregation = *cfg.GaugeAggregation
+	}
+	if cfg.Collectors != nil {
+		flagCollectors = *cfg.Collectors
+	}
+	if cfg.CollectorIntervals != nil {
+		flagCollectorIntervals = *cfg.CollectorIntervals
+	}
+	if cfg.CollectorTimeout != nil {
+		if flagCollectorTimeout, err = time.ParseDuration(*cfg.CollectorTimeout); err != nil {
+			return err
+		}
+	}
+	if cfg.CgroupPath != nil {
+		flagCgroupPath = *cfg.CgroupPath
+	}
+	if cfg.Processes != nil {
+		flagProcesses = *cfg.Processes
+	}
+	if cfg.ExecPlugins != nil {
+		flagExecPlugins = cfg.ExecPlugins
+	}
+	if cfg.ExecTimeout != nil {
+		if flagExecTimeout, err = time.ParseDuration(*cfg.ExecTimeout); err != nil {
+			return err
+		}
+	}
+	if cfg.PushAddress != nil {
+		flagPushAddress = *cfg.PushAddress
+	}
+	if cfg.StatsDAddress != nil {
+		flagStatsDAddress = *cfg.StatsDAddress
+	}
 
 	return nil
 }
@@ -160,12 +336,122 @@ func parseEnvs() error {
 			flagBatchSize = val
 		}
 	}
+	if v := os.Getenv("KEY"); v != "" {
+		flagKey = v
+	}
+	if v := os.Getenv("CRYPTO_KEY"); v != "" {
+		flagCryptoKey = v
+	}
+	if v := os.Getenv("HASH_HEADER"); v != "" {
+		flagHashHeader = v
+	}
+	if v := os.Getenv("TLS_CA"); v != "" {
+		flagTLSCA = v
+	}
+	if v := os.Getenv("TLS_CERT"); v != "" {
+		flagTLSCert = v
+	}
+	if v := os.Getenv("TLS_KEY"); v != "" {
+		flagTLSKey = v
+	}
+	if v := os.Getenv("OUTBOX_DIR"); v != "" {
+		flagOutboxDir = v
+	}
+	if v := os.Getenv("OUTBOX_MAX_BYTES"); v != "" {
+		if val, err := strconv.ParseInt(v, 10, 64); err == nil {
+			flagOutboxMaxBytes = val
+		}
+	}
+	if v := os.Getenv("OUTBOX_MAX_AGE"); v != "" {
+		if val, err := strconv.Atoi(v); err == nil {
+			flagOutboxMaxAge = val
+		}
+	}
+	if v := os.Getenv("OUTBOX_DROP"); v != "" {
+		flagOutboxDrop = v
+	}
+	if v := os.Getenv("RETRY_ATTEMPTS"); v != "" {
+		if val, err := strconv.Atoi(v); err == nil {
+			flagRetryAttempts = val
+		}
+	}
+	if v := os.Getenv("RETRY_INITIAL_BACKOFF"); v != "" {
+		if val, err := time.ParseDuration(v); err == nil {
+			flagRetryInitialBackoff = val
+		}
+	}
+	if v := os.Getenv("RETRY_MAX_BACKOFF"); v != "" {
+		if val, err := time.ParseDuration(v); err == nil {
+			flagRetryMaxBackoff = val
+		}
+	}
+	if v := os.Getenv("BREAKER_THRESHOLD"); v != "" {
+		if val, err := strconv.Atoi(v); err == nil {
+			flagBreakerThreshold = val
+		}
+	}
+	if v := os.Getenv("BREAKER_COOLDOWN"); v != "" {
+		if val, err := time.ParseDuration(v); err == nil {
+			flagBreakerCooldown = val
+		}
+	}
+	if v := os.Getenv("GAUGE_AGGREGATION"); v != "" {
+		flagGaugeAggregation = v
+	}
+	if v := os.Getenv("COLLECTORS"); v != "" {
+		flagCollectors = v
+	}
+	if v := os.Getenv("COLLECTOR_INTERVALS"); v != "" {
+		flagCollectorIntervals = v
+	}
+	if v := os.Getenv("COLLECTOR_TIMEOUT"); v != "" {
+		if val, err := time.ParseDuration(v); err == nil {
+			flagCollectorTimeout = val
+		}
+	}
+	if v := os.Getenv("CGROUP_PATH"); v != "" {
+		flagCgroupPath = v
+	}
+	if v := os.Getenv("PROCESSES"); v != "" {
+		flagProcesses = v
+	}
+	if v := os.Getenv("EXEC_PLUGINS"); v != "" {
+		flagExecPlugins = strings.Split(v, ";")
+	}
+	if v := os.Getenv("EXEC_TIMEOUT"); v != "" {
+		if val, err := time.ParseDuration(v); err == nil {
+			flagExecTimeout = val
+		}
+	}
+	if v := os.Getenv("PUSH_ADDRESS"); v != "" {
+		flagPushAddress = v
+	}
+	if v := os.Getenv("STATSD_ADDRESS"); v != "" {
+		flagStatsDAddress = v
+	}
 
 	return nil
 }
 
 // run initializes and runs the agent application using the parsed configuration.
 func run() error {
+	collectorIntervals, err := collectors.ParseIntervals(flagCollectorIntervals)
+	if err != nil {
+		return err
+	}
+	processTargets, err := collectors.ParseProcessTargets(flagProcesses)
+	if err != nil {
+		return err
+	}
+	var execPlugins []collectors.ExecPlugin
+	for _, spec := range flagExecPlugins {
+		plugin, err := collectors.ParseExecPlugin(spec)
+		if err != nil {
+			return err
+		}
+		execPlugins = append(execPlugins, plugin)
+	}
+
 	app, err := apps.NewAgentApp(
 		apps.WithAgentServerAddress(flagServerAddress),
 		apps.WithAgentPollInterval(flagPollInterval),
@@ -173,7 +459,31 @@ func run() error {
 		apps.WithAgentBatchSize(flagBatchSize),
 		apps.WithAgentRateLimit(flagRateLimit),
 		apps.WithAgentLogLevel(flagLogLevel),
+		apps.WithAgentKey(flagKey),
+		apps.WithAgentCryptoKey(flagCryptoKey),
+		apps.WithAgentHashHeader(flagHashHeader),
 		apps.WithGRPC(),
+		apps.WithAgentTLSCA(flagTLSCA),
+		apps.WithAgentTLSCert(flagTLSCert),
+		apps.WithAgentTLSKey(flagTLSKey),
+		apps.WithAgentRestore(flagRestore),
+		apps.WithAgentOutboxDir(flagOutboxDir),
+		apps.WithAgentOutboxMaxBytes(flagOutboxMaxBytes),
+		apps.WithAgentOutboxMaxAge(flagOutboxMaxAge),
+		apps.WithAgentOutboxDropPolicy(flagOutboxDrop),
+		apps.WithAgentRetryMaxAttempts(flagRetryAttempts),
+		apps.WithAgentRetryBackoff(flagRetryInitialBackoff, flagRetryMaxBackoff),
+		apps.WithAgentBreaker(flagBreakerThreshold, flagBreakerCooldown),
+		apps.WithAgentGaugeAggregation(flagGaugeAggregation),
+		apps.WithAgentCollectors(collectors.ParseNames(flagCollectors)),
+		apps.WithAgentCollectorIntervals(collectorIntervals),
+		apps.WithAgentCollectorTimeout(flagCollectorTimeout),
+		apps.WithAgentCgroupPath(flagCgroupPath),
+		apps.WithAgentProcessTargets(processTargets),
+		apps.WithAgentExecPlugins(execPlugins),
+		apps.WithAgentExecTimeout(flagExecTimeout),
+		apps.WithAgentPushAddress(flagPushAddress),
+		apps.WithAgentStatsDAddress(flagStatsDAddress),
 	)
 
 	if err != nil {
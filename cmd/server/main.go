@@ -8,6 +8,7 @@ import (
 	"strconv"
 
 	"github.com/sbilibin2017/go-yandex-practicum/internal/apps"
+	"github.com/sbilibin2017/go-yandex-practicum/internal/types"
 	"github.com/spf13/pflag"
 )
 
@@ -56,11 +57,24 @@ var (
 	flagHashHeader      string // header for SHA256 hash
 	flagLogLevel        string // log level for the application
 	flagMigrationsDir   string // directory containing DB migration files
+
+	flagMetricTTL          int    // default metric TTL in seconds, 0 disables expiry
+	flagMetricTTLPrefixes  string // per-prefix TTL overrides, "prefix=seconds,..."
+	flagStaleMode          string // what to do with stale metrics: "mark" or "expire"
+	flagStaleCheckInterval int    // interval (in seconds) between staleness checks
+
+	flagTransport      string // which APIs to serve: http, grpc or both
+	flagGRPCAddress    string // separate gRPC address for the "both" transport
+	flagGRPCReflection bool   // whether to register the gRPC reflection service
+	flagTLSCert        string // path to the server TLS certificate
+	flagTLSKey         string // path to the server TLS certificate key
+	flagTLSClientCA    string // path to the CA bundle for client certificates
+	flagSocketMode     string // octal permissions of Unix socket files
 )
 
 // parseFlags parses command-line flags and stores their values in package-level variables.
 func parseFlags() error {
-	pflag.StringVarP(&flagServerAddress, "address", "a", ":8080", "address and port to run server")
+	pflag.StringVarP(&flagServerAddress, "address", "a", ":8080", "address and port to run server, or unix:///path.sock")
 	pflag.StringVarP(&flagDatabaseDSN, "dsn", "d", "", "dsn for database connection")
 	pflag.IntVarP(&flagStoreInterval, "interval", "i", 300, "interval (in seconds) to store data")
 	pflag.StringVarP(&flagFileStoragePath, "file", "f", "", "path to store files")
@@ -73,6 +87,21 @@ func parseFlags() error {
 	pflag.StringVarP(&flagLogLevel, "log-level", "l", "info", "log level for the application")
 	pflag.StringVarP(&flagMigrationsDir, "migrations-dir", "m", "../../migrations", "directory containing DB migration files")
 
+	pflag.IntVar(&flagMetricTTL, "metric-ttl", 0, "default metric TTL in seconds, 0 disables expiry")
+	pflag.StringVar(&flagMetricTTLPrefixes, "metric-ttl-prefixes", "", "per-prefix TTL overrides, e.g. CPUutilization=60,Disk=600")
+	pflag.StringVar(&flagStaleMode, "stale-mode", "mark", "what to do with stale metrics: mark or expire")
+	pflag.IntVar(&flagStaleCheckInterval, "stale-check-interval", 60, "interval (in seconds) between staleness checks")
+
+	pflag.StringVar(&flagTransport, "transport", "http", "which APIs to serve: http, grpc or both")
+	pflag.StringVar(&flagGRPCAddress, "grpc-address", "", "separate gRPC address for the both transport, empty to share --address")
+	pflag.BoolVar(&flagGRPCReflection, "grpc-reflection", false, "register the gRPC server reflection service")
+
+	pflag.StringVar(&flagTLSCert, "tls-cert", "", "path to the server TLS certificate; empty serves plaintext")
+	pflag.StringVar(&flagTLSKey, "tls-key", "", "path to the server TLS certificate key")
+	pflag.StringVar(&flagTLSClientCA, "tls-client-ca", "", "path to the CA bundle client certificates must chain to; enables mutual TLS")
+
+	pflag.StringVar(&flagSocketMode, "socket-mode", "", "octal permissions of Unix socket files, e.g. 0660; empty keeps the umask default")
+
 	pflag.Parse()
 
 	return nil
@@ -107,6 +136,19 @@ func parseConfigFile() error {
 		HashHeader      *string `json:"hash_header,omitempty"`
 		LogLevel        *string `json:"log_level,omitempty"`
 		MigrationsDir   *string `json:"migrations_dir,omitempty"`
+
+		MetricTTL          *int    `json:"metric_ttl,omitempty"`
+		MetricTTLPrefixes  *string `json:"metric_ttl_prefixes,omitempty"`
+		StaleMode          *string `json:"stale_mode,omitempty"`
+		StaleCheckInterval *int    `json:"stale_check_interval,omitempty"`
+
+		Transport      *string `json:"transport,omitempty"`
+		GRPCAddress    *string `json:"grpc_address,omitempty"`
+		GRPCReflection *bool   `json:"grpc_reflection,omitempty"`
+		TLSCert        *string `json:"tls_cert,omitempty"`
+		TLSKey         *string `json:"tls_key,omitempty"`
+		TLSClientCA    *string `json:"tls_client_ca,omitempty"`
+		SocketMode     *string `json:"socket_mode,omitempty"`
 	}{}
 
 	if err := json.NewDecoder(file).Decode(cfg); err != nil {
@@ -146,6 +188,39 @@ func parseConfigFile() error {
 	if cfg.MigrationsDir != nil {
 		flagMigrationsDir = *cfg.MigrationsDir
 	}
+	if cfg.MetricTTL != nil {
+		flagMetricTTL = *cfg.MetricTTL
+	}
+	if cfg.MetricTTLPrefixes != nil {
+		flagMetricTTLPrefixes = *cfg.MetricTTLPrefixes
+	}
+	if cfg.StaleMode != nil {
+		flagStaleMode = *cfg.StaleMode
+	}
+	if cfg.StaleCheckInterval != nil {
+		flagStaleCheckInterval = *cfg.StaleCheckInterval
+	}
+	if cfg.Transport != nil {
+		flagTransport = *cfg.Transport
+	}
+	if cfg.GRPCAddress != nil {
+		flagGRPCAddress = *cfg.GRPCAddress
+	}
+	if cfg.GRPCReflection != nil {
+		flagGRPCReflection = *cfg.GRPCReflection
+	}
+	if cfg.TLSCert != nil {
+		flagTLSCert = *cfg.TLSCert
+	}
+	if cfg.TLSKey != nil {
+		flagTLSKey = *cfg.TLSKey
+	}
+	if cfg.TLSClientCA != nil {
+		flagTLSClientCA = *cfg.TLSClientCA
+	}
+	if cfg.SocketMode != nil {
+		flagSocketMode = *cfg.SocketMode
+	}
 
 	return nil
 }
@@ -190,13 +265,62 @@ func parseEnvs() error {
 	if v := os.Getenv("MIGRATIONS_DIR"); v != "" {
 		flagMigrationsDir = v
 	}
+	if v := os.Getenv("METRIC_TTL"); v != "" {
+		if val, err := strconv.Atoi(v); err == nil {
+			flagMetricTTL = val
+		}
+	}
+	if v := os.Getenv("METRIC_TTL_PREFIXES"); v != "" {
+		flagMetricTTLPrefixes = v
+	}
+	if v := os.Getenv("STALE_MODE"); v != "" {
+		flagStaleMode = v
+	}
+	if v := os.Getenv("STALE_CHECK_INTERVAL"); v != "" {
+		if val, err := strconv.Atoi(v); err == nil {
+			flagStaleCheckInterval = val
+		}
+	}
+	if v := os.Getenv("TRANSPORT"); v != "" {
+		flagTransport = v
+	}
+	if v := os.Getenv("GRPC_ADDRESS"); v != "" {
+		flagGRPCAddress = v
+	}
+	if v := os.Getenv("GRPC_REFLECTION"); v != "" {
+		if val, err := strconv.ParseBool(v); err == nil {
+			flagGRPCReflection = val
+		}
+	}
+	if v := os.Getenv("TLS_CERT"); v != "" {
+		flagTLSCert = v
+	}
+	if v := os.Getenv("TLS_KEY"); v != "" {
+		flagTLSKey = v
+	}
+	if v := os.Getenv("TLS_CLIENT_CA"); v != "" {
+		flagTLSClientCA = v
+	}
+	if v := os.Getenv("SOCKET_MODE"); v != "" {
+		flagSocketMode = v
+	}
 
 	return nil
 }
 
 // run initializes the server app with the parsed configuration and starts it.
 func run() error {
-	app, err := apps.NewServerApp(
+	socketMode, err := parseSocketMode(flagSocketMode)
+	if err != nil {
+		return err
+	}
+
+	ttlPrefixes, err := types.ParseTTLPrefixes(flagMetricTTLPrefixes)
+	if err != nil {
+		return err
+	}
+
+	app, err := apps.NewServerCombinedApp(
 		apps.WithServerAddress(flagServerAddress),
 		apps.WithServerDatabaseDSN(flagDatabaseDSN),
 		apps.WithServerStoreInterval(flagStoreInterval),
@@ -209,6 +333,17 @@ func run() error {
 		apps.WithServerHashHeader(flagHashHeader),
 		apps.WithServerLogLevel(flagLogLevel),
 		apps.WithServerMigrationsDir(flagMigrationsDir),
+		apps.WithServerMetricTTL(flagMetricTTL),
+		apps.WithServerMetricTTLPrefixes(ttlPrefixes),
+		apps.WithServerStaleMode(flagStaleMode),
+		apps.WithServerStaleCheckInterval(flagStaleCheckInterval),
+		apps.WithServerTransport(flagTransport),
+		apps.WithServerGRPCAddress(flagGRPCAddress),
+		apps.WithServerGRPCReflection(flagGRPCReflection),
+		apps.WithServerTLSCert(flagTLSCert),
+		apps.WithServerTLSKey(flagTLSKey),
+		apps.WithServerTLSClientCA(flagTLSClientCA),
+		apps.WithServerSocketMode(socketMode),
 	)
 
 	if err != nil {
@@ -218,6 +353,18 @@ func run() error {
 	return app.Run(context.Background())
 }
 
+// parseSocketMode parses octal socket file permissions; an empty string means zero.
+func parseSocketMode(s string) (os.FileMode, error) {
+	if s == "" {
+		return 0, nil
+	}
+	mode, err := strconv.ParseUint(s, 8, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid socket mode %q: %w", s, err)
+	}
+	return os.FileMode(mode), nil
+}
+
 // printBuildInfo prints the build version, date, and commit hash to stdout.
 // If any of these values are empty, it prints "N/A" instead.
 func printBuildInfo() {
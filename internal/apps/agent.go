@@ -4,9 +4,15 @@ import (
 	"context"
 	"os/signal"
 	"syscall"
+	"time"
 
+	"github.com/go-chi/chi/v5"
+	"github.com/sbilibin2017/go-yandex-practicum/internal/collectors"
 	"github.com/sbilibin2017/go-yandex-practicum/internal/facades"
+	"github.com/sbilibin2017/go-yandex-practicum/internal/handlers"
 	"github.com/sbilibin2017/go-yandex-practicum/internal/logger"
+	"github.com/sbilibin2017/go-yandex-practicum/internal/middlewares"
+	"github.com/sbilibin2017/go-yandex-practicum/internal/outbox"
 	"github.com/sbilibin2017/go-yandex-practicum/internal/workers"
 )
 
@@ -25,6 +31,33 @@ type agentAppConfig struct {
 	LogLevel       string // logging verbosity level (e.g., debug, info)
 	BatchSize      int    // number of items processed in a batch
 	IsGRPC         bool
+	TLSCA          string // path to the CA bundle the server certificate is verified against
+	TLSCert        string // path to the client certificate for mutual TLS
+	TLSKey         string // path to the client certificate key
+
+	OutboxDir        string // directory of the on-disk outbox; empty disables it
+	OutboxMaxBytes   int64  // maximum size of undelivered batches kept, 0 for unlimited
+	OutboxMaxAge     int    // seconds after which undelivered batches are discarded, 0 keeps them
+	OutboxDropPolicy string // what to drop when the outbox is full: "oldest" or "newest"
+
+	RetryMaxAttempts    int           // attempts per batch including the first, 0 for the default
+	RetryInitialBackoff time.Duration // delay before the first retry, 0 for the default
+	RetryMaxBackoff     time.Duration // cap on the delay between retries, 0 for the default
+	BreakerThreshold    int           // consecutive failures that open the circuit breaker, 0 disables it
+	BreakerCooldown     time.Duration // how long the breaker stays open before probing the server
+
+	GaugeAggregation string // which gauge sample is reported: "last", "min", "max" or "avg"
+
+	Collectors         []string                   // collectors to poll; empty polls all of them
+	CollectorIntervals map[string]time.Duration   // per-collector poll intervals overriding PollInterval
+	CollectorTimeout   time.Duration              // limit on a single poll of a collector, 0 for its interval
+	CgroupPath         string                     // cgroup v2 directory to report, empty for the agent's own
+	ProcessTargets     []collectors.ProcessTarget // processes reported by the process collector
+	ExecPlugins        []collectors.ExecPlugin    // commands whose output is reported as metrics
+	ExecTimeout        time.Duration              // limit on a single run of an exec plugin, 0 for the default
+
+	PushAddress   string // address of the local HTTP endpoint applications push metrics to; empty disables it
+	StatsDAddress string // UDP address of the local StatsD listener; empty disables it
 }
 
 // AgentAppOpt represents a functional option for configuring the AgentAppConfig.
@@ -123,17 +156,168 @@ func WithAgentBatchSize(batchSize int) AgentAppOpt {
 	}
 }
 
-// WithAgentBatchSize sets the batch size for reporting metrics.
+// WithGRPC makes the agent report metrics over gRPC instead of HTTP.
 func WithGRPC() AgentAppOpt {
 	return func(c *agentAppConfig) {
 		c.IsGRPC = true
 	}
 }
 
+// WithAgentTLSCA sets the CA bundle the server certificate is verified against.
+// Setting it makes the agent connect over TLS.
+func WithAgentTLSCA(path string) AgentAppOpt {
+	return func(c *agentAppConfig) {
+		c.TLSCA = path
+	}
+}
+
+// WithAgentTLSCert sets the path to the client certificate presented for mutual TLS.
+func WithAgentTLSCert(path string) AgentAppOpt {
+	return func(c *agentAppConfig) {
+		c.TLSCert = path
+	}
+}
+
+// WithAgentTLSKey sets the path to the client certificate key.
+func WithAgentTLSKey(path string) AgentAppOpt {
+	return func(c *agentAppConfig) {
+		c.TLSKey = path
+	}
+}
+
+// WithAgentOutboxDir enables the on-disk outbox for batches the server did not accept.
+func WithAgentOutboxDir(dir string) AgentAppOpt {
+	return func(c *agentAppConfig) {
+		c.OutboxDir = dir
+	}
+}
+
+// WithAgentOutboxMaxBytes limits the total size of batches kept in the outbox.
+func WithAgentOutboxMaxBytes(n int64) AgentAppOpt {
+	return func(c *agentAppConfig) {
+		c.OutboxMaxBytes = n
+	}
+}
+
+// WithAgentOutboxMaxAge sets the age (in seconds) after which queued batches are discarded.
+func WithAgentOutboxMaxAge(seconds int) AgentAppOpt {
+	return func(c *agentAppConfig) {
+		c.OutboxMaxAge = seconds
+	}
+}
+
+// WithAgentOutboxDropPolicy sets which batches are dropped when the outbox is full.
+func WithAgentOutboxDropPolicy(policy string) AgentAppOpt {
+	return func(c *agentAppConfig) {
+		c.OutboxDropPolicy = policy
+	}
+}
+
+// WithAgentRetryMaxAttempts sets how many times a batch is sent before giving up.
+func WithAgentRetryMaxAttempts(n int) AgentAppOpt {
+	return func(c *agentAppConfig) {
+		c.RetryMaxAttempts = n
+	}
+}
+
+// WithAgentRetryBackoff sets the delay before the first retry and the cap on the delay.
+func WithAgentRetryBackoff(initial, max time.Duration) AgentAppOpt {
+	return func(c *agentAppConfig) {
+		c.RetryInitialBackoff = initial
+		c.RetryMaxBackoff = max
+	}
+}
+
+// WithAgentBreaker sets the consecutive failures that open the circuit breaker
+// and how long it stays open. A zero threshold disables the breaker.
+func WithAgentBreaker(threshold int, cooldown time.Duration) AgentAppOpt {
+	return func(c *agentAppConfig) {
+		c.BreakerThreshold = threshold
+		c.BreakerCooldown = cooldown
+	}
+}
+
+// WithAgentGaugeAggregation sets how gauges polled several times between reports
+// are consolidated; counters are always summed.
+func WithAgentGaugeAggregation(mode string) AgentAppOpt {
+	return func(c *agentAppConfig) {
+		c.GaugeAggregation = mode
+	}
+}
+
+// WithAgentCollectors sets the names of the collectors to poll. An empty list polls all of them.
+func WithAgentCollectors(names []string) AgentAppOpt {
+	return func(c *agentAppConfig) {
+		c.Collectors = names
+	}
+}
+
+// WithAgentCollectorIntervals sets poll intervals for individual collectors.
+func WithAgentCollectorIntervals(intervals map[string]time.Duration) AgentAppOpt {
+	return func(c *agentAppConfig) {
+		c.CollectorIntervals = intervals
+	}
+}
+
+// WithAgentCollectorTimeout limits how long a single poll of a collector may take.
+func WithAgentCollectorTimeout(timeout time.Duration) AgentAppOpt {
+	return func(c *agentAppConfig) {
+		c.CollectorTimeout = timeout
+	}
+}
+
+// WithAgentCgroupPath sets the cgroup v2 directory the cgroup collector reports.
+// By default it reports the cgroup the agent runs in.
+func WithAgentCgroupPath(path string) AgentAppOpt {
+	return func(c *agentAppConfig) {
+		c.CgroupPath = path
+	}
+}
+
+// WithAgentProcessTargets sets the processes the process collector reports.
+func WithAgentProcessTargets(targets []collectors.ProcessTarget) AgentAppOpt {
+	return func(c *agentAppConfig) {
+		c.ProcessTargets = targets
+	}
+}
+
+// WithAgentExecPlugins sets the commands run on every poll whose output is reported as metrics.
+func WithAgentExecPlugins(plugins []collectors.ExecPlugin) AgentAppOpt {
+	return func(c *agentAppConfig) {
+		c.ExecPlugins = plugins
+	}
+}
+
+// WithAgentExecTimeout limits how long a single run of an exec plugin may take.
+func WithAgentExecTimeout(timeout time.Duration) AgentAppOpt {
+	return func(c *agentAppConfig) {
+		c.ExecTimeout = timeout
+	}
+}
+
+// WithAgentPushAddress sets the address of the local HTTP endpoint that accepts
+// metrics on the server's /update/ and /updates/ routes.
+func WithAgentPushAddress(addr string) AgentAppOpt {
+	return func(c *agentAppConfig) {
+		c.PushAddress = addr
+	}
+}
+
+// WithAgentStatsDAddress sets the UDP address of the local StatsD listener.
+func WithAgentStatsDAddress(addr string) AgentAppOpt {
+	return func(c *agentAppConfig) {
+		c.StatsDAddress = addr
+	}
+}
+
 // AgentApp is the main struct representing the agent application.
 type AgentApp struct {
 	Config              *agentAppConfig
 	MetricContextFacade *facades.MetricFacadeContext
+	Outbox              *outbox.Outbox // nil unless an outbox directory is configured
+	Breaker             *facades.CircuitBreaker
+	Collectors          *collectors.Registry
+	Push                *collectors.PushCollector // nil unless a push endpoint or StatsD listener is configured
 	Workers             []func(ctx context.Context) error
 }
 
@@ -149,30 +333,91 @@ func NewAgentApp(opts ...AgentAppOpt) (*AgentApp, error) {
 		return nil, err
 	}
 
+	if err := workers.ValidateGaugeAggregation(config.GaugeAggregation); err != nil {
+		return nil, err
+	}
+
+	if config.PushAddress != "" || config.StatsDAddress != "" {
+		app.Push = collectors.NewPushCollector()
+	}
+	app.Collectors, err = newCollectorRegistry(config, app.Push)
+	if err != nil {
+		return nil, err
+	}
+
 	app.MetricContextFacade = facades.NewMetricFacadeContext()
 
+	var metricFacade facades.MetricFacade
 	if !config.IsGRPC {
-		metricFacade, err := facades.NewMetricHTTPFacade(
+		httpFacade, err := facades.NewMetricHTTPFacade(
 			facades.WithMetricFacadeServerAddress(config.ServerAddress),
 			facades.WithMetricFacadeHeader(config.Header),
 			facades.WithMetricFacadeKey(config.Key),
 			facades.WithMetricFacadeCryptoKeyPath(config.CryptoKey),
+			facades.WithMetricFacadeTLSCAPath(config.TLSCA),
+			facades.WithMetricFacadeTLSCertificate(config.TLSCert, config.TLSKey),
 		)
 		if err != nil {
 			logger.Log.Error("Failed to create MetricFacade:", err)
 			return nil, err
 		}
-		app.MetricContextFacade.SetContext(metricFacade)
+		metricFacade = httpFacade
 	} else {
-		metricFacade, err := facades.NewMetricGRPCFacade(
+		grpcFacade, err := facades.NewMetricGRPCFacade(
 			facades.WithMetricGRPCServerAddress(config.ServerAddress),
+			facades.WithMetricGRPCHeader(config.HashHeader),
+			facades.WithMetricGRPCKey(config.Key),
+			facades.WithMetricGRPCCryptoKeyPath(config.CryptoKey),
+			facades.WithMetricGRPCTLSCAPath(config.TLSCA),
+			facades.WithMetricGRPCTLSCertificate(config.TLSCert, config.TLSKey),
 		)
 		if err != nil {
 			logger.Log.Error("Failed to create MetricFacade:", err)
 			return nil, err
 		}
-		app.MetricContextFacade.SetContext(metricFacade)
+		metricFacade = grpcFacade
+	}
+
+	breakerOpts := []facades.CircuitBreakerOpt{facades.WithCircuitBreakerThreshold(config.BreakerThreshold)}
+	if config.BreakerCooldown > 0 {
+		breakerOpts = append(breakerOpts, facades.WithCircuitBreakerCooldown(config.BreakerCooldown))
+	}
+	app.Breaker = facades.NewCircuitBreaker(breakerOpts...)
+
+	resilientOpts := []facades.MetricResilientFacadeOpt{
+		facades.WithMetricResilientNext(metricFacade),
+		facades.WithMetricResilientBreaker(app.Breaker),
+	}
+	if config.RetryMaxAttempts > 0 {
+		resilientOpts = append(resilientOpts, facades.WithMetricResilientMaxAttempts(config.RetryMaxAttempts))
+	}
+	if config.RetryInitialBackoff > 0 && config.RetryMaxBackoff > 0 {
+		resilientOpts = append(resilientOpts, facades.WithMetricResilientBackoff(config.RetryInitialBackoff, config.RetryMaxBackoff, 3))
+	}
+	metricFacade = facades.NewMetricResilientFacade(resilientOpts...)
+
+	if config.OutboxDir != "" {
+		dropPolicy := config.OutboxDropPolicy
+		if dropPolicy == "" {
+			dropPolicy = outbox.DropOldest
+		}
+		app.Outbox, err = outbox.Open(
+			outbox.WithDir(config.OutboxDir),
+			outbox.WithMaxBytes(config.OutboxMaxBytes),
+			outbox.WithMaxAge(time.Duration(config.OutboxMaxAge)*time.Second),
+			outbox.WithDropPolicy(dropPolicy),
+			outbox.WithRestore(config.Restore),
+		)
+		if err != nil {
+			logger.Log.Error("Failed to open outbox:", err)
+			return nil, err
+		}
+		metricFacade = facades.NewMetricOutboxFacade(
+			facades.WithMetricOutboxNext(metricFacade),
+			facades.WithMetricOutbox(app.Outbox),
+		)
 	}
+	app.MetricContextFacade.SetContext(metricFacade)
 
 	app.Workers = append(
 		app.Workers,
@@ -182,12 +427,82 @@ func NewAgentApp(opts ...AgentAppOpt) (*AgentApp, error) {
 			workers.WithBatchSize(config.BatchSize),
 			workers.WithRateLimit(config.RateLimit),
 			workers.WithUpdater(app.MetricContextFacade),
+			workers.WithGaugeAggregation(config.GaugeAggregation),
+			workers.WithCollectors(app.Collectors),
 		),
 	)
+	if config.PushAddress != "" {
+		app.Workers = append(app.Workers, workers.NewPushWorker(
+			workers.WithPushAddress(config.PushAddress),
+			workers.WithPushHandler(newPushRouter(app.Push)),
+		))
+	}
+	if config.StatsDAddress != "" {
+		app.Workers = append(app.Workers, workers.NewStatsDWorker(
+			workers.WithStatsDAddress(config.StatsDAddress),
+			workers.WithStatsDPusher(app.Push),
+		))
+	}
 
 	return &app, nil
 }
 
+// newCollectorRegistry registers the built-in collectors and applies the
+// enabled list, intervals and timeout from the config. The push collector,
+// when given, stays enabled whatever the list says.
+func newCollectorRegistry(config *agentAppConfig, push *collectors.PushCollector) (*collectors.Registry, error) {
+	var execOpts []collectors.ExecCollectorOpt
+	if config.ExecTimeout > 0 {
+		execOpts = append(execOpts, collectors.WithExecTimeout(config.ExecTimeout))
+	}
+	registryOpts := []collectors.DefaultRegistryOpt{
+		collectors.WithCgroupCollector(collectors.WithCgroupPath(config.CgroupPath)),
+		collectors.WithProcessCollector(collectors.WithProcessTargets(config.ProcessTargets...)),
+		collectors.WithExecPlugins(config.ExecPlugins, execOpts...),
+	}
+	if push != nil {
+		registryOpts = append(registryOpts, collectors.WithPushCollector(push))
+	}
+	registry, err := collectors.NewDefaultRegistry(time.Duration(config.PollInterval)*time.Second, registryOpts...)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := registry.EnableOnly(config.Collectors); err != nil {
+		return nil, err
+	}
+	if push != nil {
+		if err := registry.Configure(collectors.PushName, collectors.WithEnabled(true)); err != nil {
+			return nil, err
+		}
+	}
+	for name, interval := range config.CollectorIntervals {
+		if err := registry.Configure(name, collectors.WithInterval(interval)); err != nil {
+			return nil, err
+		}
+	}
+	if config.CollectorTimeout > 0 {
+		for _, name := range registry.Names() {
+			if err := registry.Configure(name, collectors.WithTimeout(config.CollectorTimeout)); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return registry, nil
+}
+
+// newPushRouter serves the server's update routes on the agent, buffering the
+// accepted metrics in push until the next poll.
+func newPushRouter(push *collectors.PushCollector) *chi.Mux {
+	router := chi.NewRouter()
+	router.Use(middlewares.GzipMiddleware)
+	handlers.NewMetricUpdatePathHandler(handlers.WithMetricUpdaterPath(push)).RegisterRoute(router)
+	handlers.NewMetricUpdateBodyHandler(handlers.WithMetricUpdaterBody(push)).RegisterRoute(router)
+	handlers.NewMetricUpdatesBodyHandler(handlers.WithMetricUpdaterBatchBody(push)).RegisterRoute(router)
+	return router
+}
+
 // Run starts the AgentApp and waits for shutdown signals.
 // It listens for SIGINT, SIGTERM, or SIGQUIT and gracefully shuts down all workers.
 func (app *AgentApp) Run(ctx context.Context) error {
@@ -3,11 +3,19 @@ package apps
 import (
 	"context"
 	"errors"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sbilibin2017/go-yandex-practicum/internal/collectors"
+	"github.com/sbilibin2017/go-yandex-practicum/internal/facades"
+	"github.com/sbilibin2017/go-yandex-practicum/internal/types"
 )
 
 func TestNewAgentAppConfig_Options(t *testing.T) {
@@ -24,6 +32,21 @@ func TestNewAgentAppConfig_Options(t *testing.T) {
 		WithAgentHashHeader("X-Hash"),
 		WithAgentLogLevel("debug"),
 		WithAgentBatchSize(50),
+		WithAgentOutboxDir("/var/lib/agent/outbox"),
+		WithAgentOutboxMaxBytes(1<<20),
+		WithAgentOutboxMaxAge(3600),
+		WithAgentOutboxDropPolicy("newest"),
+		WithAgentRetryMaxAttempts(6),
+		WithAgentRetryBackoff(time.Second, 10*time.Second),
+		WithAgentBreaker(5, time.Minute),
+		WithAgentGaugeAggregation("max"),
+		WithAgentCollectors([]string{"cpu"}),
+		WithAgentCollectorIntervals(map[string]time.Duration{"cpu": 5 * time.Second}),
+		WithAgentCollectorTimeout(time.Second),
+		WithAgentCgroupPath("/sys/fs/cgroup/agent.slice"),
+		WithAgentProcessTargets([]collectors.ProcessTarget{{Name: "postgres"}}),
+		WithAgentExecPlugins([]collectors.ExecPlugin{{Name: "queue", Command: []string{"queue-stats"}}}),
+		WithAgentExecTimeout(5*time.Second),
 	)
 
 	assert.Equal(t, "localhost:8080", cfg.ServerAddress)
@@ -38,6 +61,23 @@ func TestNewAgentAppConfig_Options(t *testing.T) {
 	assert.Equal(t, "X-Hash", cfg.HashHeader)
 	assert.Equal(t, "debug", cfg.LogLevel)
 	assert.Equal(t, 50, cfg.BatchSize)
+	assert.Equal(t, "/var/lib/agent/outbox", cfg.OutboxDir)
+	assert.Equal(t, int64(1<<20), cfg.OutboxMaxBytes)
+	assert.Equal(t, 3600, cfg.OutboxMaxAge)
+	assert.Equal(t, "newest", cfg.OutboxDropPolicy)
+	assert.Equal(t, 6, cfg.RetryMaxAttempts)
+	assert.Equal(t, time.Second, cfg.RetryInitialBackoff)
+	assert.Equal(t, 10*time.Second, cfg.RetryMaxBackoff)
+	assert.Equal(t, 5, cfg.BreakerThreshold)
+	assert.Equal(t, time.Minute, cfg.BreakerCooldown)
+	assert.Equal(t, "max", cfg.GaugeAggregation)
+	assert.Equal(t, []string{"cpu"}, cfg.Collectors)
+	assert.Equal(t, map[string]time.Duration{"cpu": 5 * time.Second}, cfg.CollectorIntervals)
+	assert.Equal(t, time.Second, cfg.CollectorTimeout)
+	assert.Equal(t, "/sys/fs/cgroup/agent.slice", cfg.CgroupPath)
+	assert.Equal(t, []collectors.ProcessTarget{{Name: "postgres"}}, cfg.ProcessTargets)
+	assert.Equal(t, []collectors.ExecPlugin{{Name: "queue", Command: []string{"queue-stats"}}}, cfg.ExecPlugins)
+	assert.Equal(t, 5*time.Second, cfg.ExecTimeout)
 }
 
 func TestAgentApp_Run_GracefulShutdown(t *testing.T) {
@@ -178,3 +218,152 @@ func TestNewAgentApp_GrpcFacade(t *testing.T) {
 	assert.NotNil(t, app.MetricContextFacade, "MetricContextFacade should be initialized")
 
 }
+
+func TestNewAgentApp_Outbox(t *testing.T) {
+	dir := t.TempDir()
+
+	// Nothing listens on the address, so the batch ends up in the outbox
+	app, err := NewAgentApp(
+		WithAgentServerAddress("http://127.0.0.1:1"),
+		WithAgentOutboxDir(dir),
+		WithAgentRetryMaxAttempts(1),
+	)
+	require.NoError(t, err)
+	require.NotNil(t, app.Outbox)
+
+	value := 1.0
+	err = app.MetricContextFacade.Updates(context.Background(), []*types.Metrics{{ID: "OutboxGauge", Type: types.Gauge, Value: &value}})
+	require.NoError(t, err)
+	assert.Equal(t, 1, app.Outbox.Len())
+	require.NoError(t, app.Outbox.Close())
+
+	restored, err := NewAgentApp(
+		WithAgentServerAddress("http://127.0.0.1:1"),
+		WithAgentOutboxDir(dir),
+		WithAgentRestore(true),
+	)
+	require.NoError(t, err)
+	assert.Equal(t, 1, restored.Outbox.Len())
+	require.NoError(t, restored.Outbox.Close())
+
+	_, err = NewAgentApp(WithAgentOutboxDir(dir), WithAgentOutboxDropPolicy("random"))
+	assert.Error(t, err)
+}
+
+func TestNewAgentApp_RetryAndBreaker(t *testing.T) {
+	app, err := NewAgentApp(
+		WithAgentServerAddress("http://127.0.0.1:1"),
+		WithAgentRetryMaxAttempts(3),
+		WithAgentRetryBackoff(time.Millisecond, time.Millisecond),
+		WithAgentBreaker(2, time.Hour),
+	)
+	require.NoError(t, err)
+	assert.Equal(t, facades.BreakerClosed, app.Breaker.State())
+
+	value := 1.0
+	err = app.MetricContextFacade.Updates(context.Background(), []*types.Metrics{{ID: "BreakerGauge", Type: types.Gauge, Value: &value}})
+	assert.ErrorIs(t, err, facades.ErrCircuitOpen)
+	assert.Equal(t, facades.BreakerOpen, app.Breaker.State())
+}
+
+func TestNewAgentApp_UnknownGaugeAggregation(t *testing.T) {
+	_, err := NewAgentApp(WithAgentGaugeAggregation("median"))
+	assert.Error(t, err)
+}
+
+func TestNewAgentApp_Collectors(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    []AgentAppOpt
+		wantErr bool
+	}{
+		{name: "defaults", opts: nil},
+		{
+			name: "subset with intervals and timeout",
+			opts: []AgentAppOpt{
+				WithAgentCollectors([]string{"runtime", "cpu"}),
+				WithAgentCollectorIntervals(map[string]time.Duration{"cpu": 5 * time.Second}),
+				WithAgentCollectorTimeout(time.Second),
+			},
+		},
+		{name: "unknown collector", opts: []AgentAppOpt{WithAgentCollectors([]string{"gpu"})}, wantErr: true},
+		{name: "unknown interval", opts: []AgentAppOpt{WithAgentCollectorIntervals(map[string]time.Duration{"gpu": time.Second})}, wantErr: true},
+		{
+			name: "exec plugin with its own interval",
+			opts: []AgentAppOpt{
+				WithAgentExecPlugins([]collectors.ExecPlugin{{Name: "queue", Command: []string{"queue-stats"}}}),
+				WithAgentExecTimeout(time.Second),
+				WithAgentCollectorIntervals(map[string]time.Duration{"exec:queue": 30 * time.Second}),
+			},
+		},
+		{
+			name:    "duplicate exec plugin",
+			opts:    []AgentAppOpt{WithAgentExecPlugins([]collectors.ExecPlugin{{Name: "queue"}, {Name: "queue"}})},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app, err := NewAgentApp(append([]AgentAppOpt{WithAgentPollInterval(1)}, tt.opts...)...)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Contains(t, app.Collectors.Names(), "cpu")
+		})
+	}
+}
+
+func TestNewAgentApp_Push(t *testing.T) {
+	app, err := NewAgentApp(
+		WithAgentPollInterval(1),
+		WithAgentCollectors([]string{"runtime"}),
+		WithAgentPushAddress("127.0.0.1:0"),
+		WithAgentStatsDAddress("127.0.0.1:0"),
+	)
+	require.NoError(t, err)
+	require.NotNil(t, app.Push)
+	assert.Contains(t, app.Collectors.Names(), collectors.PushName)
+	assert.Len(t, app.Workers, 3)
+
+	app, err = NewAgentApp(WithAgentPollInterval(1))
+	require.NoError(t, err)
+	assert.Nil(t, app.Push)
+	assert.NotContains(t, app.Collectors.Names(), collectors.PushName)
+	assert.Len(t, app.Workers, 1)
+}
+
+func TestNewPushRouter(t *testing.T) {
+	push := collectors.NewPushCollector()
+	srv := httptest.NewServer(newPushRouter(push))
+	defer srv.Close()
+
+	tests := []struct {
+		name       string
+		path       string
+		body       string
+		wantStatus int
+	}{
+		{name: "path", path: "/update/counter/JobsDone/2", wantStatus: http.StatusOK},
+		{name: "body", path: "/update/", body: `{"id":"QueueDepth","type":"gauge","value":4}`, wantStatus: http.StatusOK},
+		{name: "batch", path: "/updates/", body: `[{"id":"JobsDone","type":"counter","delta":3}]`, wantStatus: http.StatusOK},
+		{name: "invalid", path: "/update/", body: `{"id":"QueueDepth","type":"gauge"}`, wantStatus: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := http.Post(srv.URL+tt.path, "application/json", strings.NewReader(tt.body))
+			require.NoError(t, err)
+			resp.Body.Close()
+			assert.Equal(t, tt.wantStatus, resp.StatusCode)
+		})
+	}
+
+	metrics, err := push.Collect(context.Background())
+	require.NoError(t, err)
+	var ids []string
+	for _, m := range metrics {
+		ids = append(ids, m.ID)
+	}
+	assert.Equal(t, []string{"JobsDone", "QueueDepth", "JobsDone"}, ids)
+}
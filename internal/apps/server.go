@@ -2,6 +2,9 @@ package apps
 
 import (
 	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
 	"net"
 	"net/http"
 	"os"
@@ -13,20 +16,35 @@ import (
 	"github.com/go-chi/chi/v5"
 	"github.com/jmoiron/sqlx"
 	"github.com/pressly/goose"
+	"github.com/sbilibin2017/go-yandex-practicum/internal/certificates"
+	"github.com/sbilibin2017/go-yandex-practicum/internal/codecs"
 	"github.com/sbilibin2017/go-yandex-practicum/internal/contexts"
 	"github.com/sbilibin2017/go-yandex-practicum/internal/handlers"
+	"github.com/sbilibin2017/go-yandex-practicum/internal/interceptors"
 	"github.com/sbilibin2017/go-yandex-practicum/internal/logger"
+	"github.com/sbilibin2017/go-yandex-practicum/internal/middlewares"
 	"github.com/sbilibin2017/go-yandex-practicum/internal/repositories"
 	"github.com/sbilibin2017/go-yandex-practicum/internal/services"
+	"github.com/sbilibin2017/go-yandex-practicum/internal/sockets"
+	"github.com/sbilibin2017/go-yandex-practicum/internal/systemd"
+	"github.com/sbilibin2017/go-yandex-practicum/internal/transcoders"
+	"github.com/sbilibin2017/go-yandex-practicum/internal/types"
 	"github.com/sbilibin2017/go-yandex-practicum/internal/workers"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/encoding"
+	_ "google.golang.org/grpc/encoding/gzip" // registers the gzip decompressor used by agents
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/reflection"
 
 	pb "github.com/sbilibin2017/go-yandex-practicum/protos"
+	pbv2 "github.com/sbilibin2017/go-yandex-practicum/protos/v2"
 )
 
 // ServerAppConfig holds the configuration parameters for the server application.
 type serverAppConfig struct {
-	ServerAddress   string // address and port to run the server on
+	ServerAddress   string // address and port to run the server on, or "unix:///path.sock"
 	DatabaseDSN     string // data source name for database connection
 	StoreInterval   int    // interval in seconds to store data
 	FileStoragePath string // path to store files on disk
@@ -38,6 +56,22 @@ type serverAppConfig struct {
 	HashHeader      string // HTTP header for SHA256 hash
 	LogLevel        string // logging level (e.g., debug, info)
 	MigrationsDir   string // directory containing DB migration files
+
+	MetricTTL          int                      // default metric TTL in seconds, 0 disables expiry
+	MetricTTLPrefixes  map[string]time.Duration // per-prefix TTL overrides
+	StaleMode          string                   // what to do with stale metrics: "mark" or "expire"
+	StaleCheckInterval int                      // interval in seconds between staleness checks
+
+	Transport   string // which APIs ServerCombinedApp exposes: "http", "grpc" or "both"
+	GRPCAddress string // separate gRPC address in "both" mode; empty multiplexes on ServerAddress
+
+	GRPCReflection bool // whether to register the gRPC server reflection service
+
+	TLSCert     string // path to the server certificate; empty serves plaintext
+	TLSKey      string // path to the server certificate key
+	TLSClientCA string // path to the CA bundle client certificates must chain to; enables mutual TLS
+
+	SocketMode os.FileMode // permissions of Unix socket files; zero keeps the umask default
 }
 
 // ServerAppOpt defines a functional option for configuring ServerAppConfig.
@@ -136,6 +170,83 @@ func WithServerMigrationsDir(migrationsDir string) ServerAppOpt {
 	}
 }
 
+// WithServerMetricTTL sets the default time-to-live (in seconds) for metrics without updates.
+func WithServerMetricTTL(ttl int) ServerAppOpt {
+	return func(c *serverAppConfig) {
+		c.MetricTTL = ttl
+	}
+}
+
+// WithServerMetricTTLPrefixes sets per-prefix TTL overrides.
+func WithServerMetricTTLPrefixes(prefixes map[string]time.Duration) ServerAppOpt {
+	return func(c *serverAppConfig) {
+		c.MetricTTLPrefixes = prefixes
+	}
+}
+
+// WithServerStaleMode sets whether stale metrics are marked ("mark") or deleted ("expire").
+func WithServerStaleMode(mode string) ServerAppOpt {
+	return func(c *serverAppConfig) {
+		c.StaleMode = mode
+	}
+}
+
+// WithServerStaleCheckInterval sets the interval in seconds between staleness checks.
+func WithServerStaleCheckInterval(interval int) ServerAppOpt {
+	return func(c *serverAppConfig) {
+		c.StaleCheckInterval = interval
+	}
+}
+
+// WithServerTransport sets which APIs ServerCombinedApp exposes: "http", "grpc" or "both".
+func WithServerTransport(transport string) ServerAppOpt {
+	return func(c *serverAppConfig) {
+		c.Transport = transport
+	}
+}
+
+// WithServerGRPCAddress sets a separate gRPC address for the "both" transport.
+func WithServerGRPCAddress(addr string) ServerAppOpt {
+	return func(c *serverAppConfig) {
+		c.GRPCAddress = addr
+	}
+}
+
+// WithServerGRPCReflection enables the gRPC server reflection service.
+func WithServerGRPCReflection(enabled bool) ServerAppOpt {
+	return func(c *serverAppConfig) {
+		c.GRPCReflection = enabled
+	}
+}
+
+// WithServerTLSCert sets the path to the server certificate.
+func WithServerTLSCert(path string) ServerAppOpt {
+	return func(c *serverAppConfig) {
+		c.TLSCert = path
+	}
+}
+
+// WithServerTLSKey sets the path to the server certificate key.
+func WithServerTLSKey(path string) ServerAppOpt {
+	return func(c *serverAppConfig) {
+		c.TLSKey = path
+	}
+}
+
+// WithServerTLSClientCA sets the CA bundle client certificates are verified against.
+func WithServerTLSClientCA(path string) ServerAppOpt {
+	return func(c *serverAppConfig) {
+		c.TLSClientCA = path
+	}
+}
+
+// WithServerSocketMode sets the permissions of the Unix socket files the servers listen on.
+func WithServerSocketMode(mode os.FileMode) ServerAppOpt {
+	return func(c *serverAppConfig) {
+		c.SocketMode = mode
+	}
+}
+
 // [ServerAppOpt setters omitted for brevity: same as your code]
 
 // ServerApp represents the main application server.
@@ -148,10 +259,20 @@ type ServerApp struct {
 	MetricUpdatesBodyHandler *handlers.MetricUpdatesBodyHandler
 	MetricGetPathHandler     *handlers.MetricGetPathHandler
 	MetricGetBodyHandler     *handlers.MetricGetBodyHandler
+	MetricGetBatchHandler    *handlers.MetricGetBatchBodyHandler
 	MetricListHTMLHandler    *handlers.MetricListHTMLHandler
+	MetricListJSONHandler    *handlers.MetricListJSONHandler
+
+	MetricDeletePathHandler     *handlers.MetricDeletePathHandler
+	MetricDeleteByPrefixHandler *handlers.MetricDeleteByPrefixHandler
+	MetricResetPathHandler      *handlers.MetricResetPathHandler
 
 	PingHandlerHandler *handlers.PingDBHandler
 
+	// Transcoder serves the gRPC services as REST/JSON under /api/, following
+	// the google.api.http annotations of the proto files.
+	Transcoder *transcoders.Transcoder
+
 	Router *chi.Mux
 	Srv    *http.Server
 }
@@ -164,17 +285,28 @@ func NewServerApp(opts ...ServerAppOpt) (*ServerApp, error) {
 		return nil, err
 	}
 
-	app := &ServerApp{
-		Config: cfg,
+	container, err := newContainer(cfg)
+	if err != nil {
+		return nil, err
 	}
 
-	var err error
-	app.Container, err = newContainer(cfg)
+	app, err := newServerApp(cfg, container)
 	if err != nil {
+		container.close()
 		return nil, err
 	}
+	return app, nil
+}
+
+// newServerApp builds the HTTP server on top of an existing container.
+func newServerApp(cfg *serverAppConfig, container *container) (*ServerApp, error) {
+	app := &ServerApp{
+		Config:    cfg,
+		Container: container,
+	}
 
 	app.Router = chi.NewRouter()
+	app.Router.Use(middlewares.AgentIdentityMiddleware)
 
 	// Initialize handlers with services from container
 	app.MetricUpdatePathHandler = handlers.NewMetricUpdatePathHandler(
@@ -202,19 +334,72 @@ func NewServerApp(opts ...ServerAppOpt) (*ServerApp, error) {
 	)
 	app.MetricGetBodyHandler.RegisterRoute(app.Router)
 
+	app.MetricGetBatchHandler = handlers.NewMetricGetBatchBodyHandler(
+		handlers.WithMetricBatchGetterBody(app.Container.MetricGetBatchService),
+	)
+	app.MetricGetBatchHandler.RegisterRoute(app.Router)
+
 	app.MetricListHTMLHandler = handlers.NewMetricListHTMLHandler(
 		handlers.WithMetricLister(app.Container.MetricListService),
 	)
 	app.MetricListHTMLHandler.RegisterRoute(app.Router)
 
+	app.MetricListJSONHandler = handlers.NewMetricListJSONHandler(
+		handlers.WithMetricListerJSON(app.Container.MetricListService),
+	)
+	app.MetricListJSONHandler.RegisterRoute(app.Router)
+
+	// Destructive routes require a signed request when a key is configured.
+	// Their bodies are empty, so the signature covers the method and URI.
+	hashMiddleware, err := middlewares.HashMiddleware(
+		middlewares.WithHashKey(cfg.Key),
+		middlewares.WithHashHeader(cfg.HashHeader),
+		middlewares.WithHashRequired(true),
+		middlewares.WithHashRequestTarget(true),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	app.MetricDeletePathHandler = handlers.NewMetricDeletePathHandler(
+		handlers.WithMetricDeleterPath(app.Container.MetricDeleteService),
+	)
+	app.MetricDeleteByPrefixHandler = handlers.NewMetricDeleteByPrefixHandler(
+		handlers.WithMetricPrefixDeleter(app.Container.MetricDeleteService),
+	)
+	app.MetricResetPathHandler = handlers.NewMetricResetPathHandler(
+		handlers.WithMetricResetterPath(app.Container.MetricResetService),
+	)
+	app.Router.Group(func(r chi.Router) {
+		r.Use(hashMiddleware)
+		app.MetricDeletePathHandler.RegisterRoute(r)
+		app.MetricDeleteByPrefixHandler.RegisterRoute(r)
+		app.MetricResetPathHandler.RegisterRoute(r)
+	})
+
 	app.PingHandlerHandler = handlers.NewPingDBHandler(
 		handlers.WithPingDB(app.Container.DB),
+		handlers.WithPingHealthChecker(app.Container.HealthService),
 	)
 	app.PingHandlerHandler.RegisterRoute(app.Router)
 
+	// REST calls go through the same interceptors as gRPC ones
+	unaryInterceptors, streamInterceptors, err := newGRPCInterceptors(cfg, container)
+	if err != nil {
+		return nil, err
+	}
+	app.Transcoder = transcoders.NewTranscoder(
+		transcoders.WithRouter(app.Router),
+		transcoders.WithUnaryInterceptors(unaryInterceptors...),
+		transcoders.WithStreamInterceptors(streamInterceptors...),
+	)
+	updater, service, v2 := newMetricGRPCHandlers(cfg, container)
+	registerMetricGRPCHandlers(app.Transcoder, updater, service, v2)
+
 	app.Srv = &http.Server{
-		Addr:    cfg.ServerAddress,
-		Handler: app.Router,
+		Addr:      cfg.ServerAddress,
+		Handler:   app.Router,
+		TLSConfig: container.TLSConfig,
 	}
 
 	return app, nil
@@ -235,13 +420,19 @@ func (app *ServerApp) Run(ctx context.Context) error {
 		}(worker)
 	}
 
+	lis, err := app.Container.listen(app.Config, TransportHTTP, app.Config.ServerAddress)
+	if err != nil {
+		return err
+	}
+
 	go func() {
-		logger.Log.Infof("Starting HTTP server on %s", app.Config.ServerAddress)
-		if err := app.Srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		logger.Log.Infof("Starting HTTP server on %s", lis.Addr())
+		if err := serve(app.Srv, lis); err != nil && err != http.ErrServerClosed {
 			logger.Log.Error("HTTP server error: " + err.Error())
 			errCh <- err
 		}
 	}()
+	notifySystemd(systemd.Ready)
 
 	select {
 	case <-ctx.Done():
@@ -253,6 +444,11 @@ func (app *ServerApp) Run(ctx context.Context) error {
 		}
 	}
 
+	notifySystemd(systemd.Stopping)
+
+	// Watch streams served over REST never end on their own, so close them first
+	app.Container.MetricWatchService.Close()
+
 	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
@@ -271,6 +467,9 @@ type ServerGRPCApp struct {
 	Container *container
 
 	MetricGRPCUpdaterHandler *handlers.MetricGRPCUpdaterHandler
+	MetricGRPCServiceHandler *handlers.MetricGRPCServiceHandler
+	MetricGRPCV2Handler      *handlers.MetricGRPCV2Handler
+	HealthGRPCHandler        *handlers.HealthGRPCHandler
 
 	Server   *grpc.Server
 	Listener net.Listener
@@ -291,25 +490,180 @@ func NewServerGRPCApp(opts ...ServerAppOpt) (*ServerGRPCApp, error) {
 		return nil, err
 	}
 
+	lis, err := container.listen(cfg, TransportGRPC, cfg.ServerAddress)
+	if err != nil {
+		container.close()
+		return nil, err
+	}
+
+	app, err := newServerGRPCApp(cfg, container, lis)
+	if err != nil {
+		lis.Close()
+		container.close()
+		return nil, err
+	}
+	return app, nil
+}
+
+// newServerGRPCApp builds the gRPC server on top of an existing container.
+// A nil listener means the server is only reached through ServeHTTP.
+func newServerGRPCApp(cfg *serverAppConfig, container *container, lis net.Listener) (*ServerGRPCApp, error) {
 	app := &ServerGRPCApp{
 		Config:    cfg,
 		Container: container,
+		Listener:  lis,
 	}
 
-	// Create handler with injected MetricUpdatesService
-	app.MetricGRPCUpdaterHandler = handlers.NewMetricGRPCUpdaterHandler(container.MetricUpdatesService)
+	app.MetricGRPCUpdaterHandler, app.MetricGRPCServiceHandler, app.MetricGRPCV2Handler =
+		newMetricGRPCHandlers(cfg, container)
 
-	app.Listener, err = net.Listen("tcp", cfg.ServerAddress)
+	unaryInterceptors, streamInterceptors, err := newGRPCInterceptors(cfg, container)
 	if err != nil {
 		return nil, err
 	}
 
-	app.Server = grpc.NewServer()
-	pb.RegisterMetricUpdaterServer(app.Server, app.MetricGRPCUpdaterHandler)
+	// Encrypted requests are selected by content-subtype, which gRPC resolves
+	// through the process-wide codec registry.
+	if cfg.CryptoKey != "" {
+		privateKey, err := codecs.LoadPrivateKey(cfg.CryptoKey)
+		if err != nil {
+			return nil, fmt.Errorf("error loading private key: %w", err)
+		}
+		encoding.RegisterCodec(codecs.NewDecryptCodec(privateKey))
+	}
+
+	serverOpts := []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(unaryInterceptors...),
+		grpc.ChainStreamInterceptor(streamInterceptors...),
+		// Agents ping every 30s; allow that without tearing down the connection.
+		grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{
+			MinTime:             15 * time.Second,
+			PermitWithoutStream: true,
+		}),
+	}
+	if container.TLSConfig != nil {
+		serverOpts = append(serverOpts, grpc.Creds(credentials.NewTLS(container.TLSConfig)))
+	}
+	app.Server = grpc.NewServer(serverOpts...)
+	registerMetricGRPCHandlers(app.Server, app.MetricGRPCUpdaterHandler, app.MetricGRPCServiceHandler, app.MetricGRPCV2Handler)
+
+	app.HealthGRPCHandler = handlers.NewHealthGRPCHandler(
+		handlers.WithHealthGRPCChecker(container.HealthService),
+		handlers.WithHealthGRPCServices(
+			pb.MetricUpdater_ServiceDesc.ServiceName,
+			pb.MetricService_ServiceDesc.ServiceName,
+			pbv2.MetricService_ServiceDesc.ServiceName,
+		),
+	)
+	healthpb.RegisterHealthServer(app.Server, app.HealthGRPCHandler)
+
+	if cfg.GRPCReflection {
+		reflection.Register(app.Server)
+	}
 
 	return app, nil
 }
 
+// newMetricGRPCHandlers creates the metric gRPC handlers on top of the container services.
+func newMetricGRPCHandlers(
+	cfg *serverAppConfig,
+	container *container,
+) (*handlers.MetricGRPCUpdaterHandler, *handlers.MetricGRPCServiceHandler, *handlers.MetricGRPCV2Handler) {
+	updater := handlers.NewMetricGRPCUpdaterHandler(container.MetricUpdatesService)
+	service := handlers.NewMetricGRPCServiceHandler(
+		handlers.WithMetricGRPCGetter(container.MetricGetService),
+		handlers.WithMetricGRPCLister(container.MetricListService),
+		handlers.WithMetricGRPCUpdater(container.MetricUpdatesService),
+		handlers.WithMetricGRPCWatcher(container.MetricWatchService),
+		handlers.WithMetricGRPCBatchGetter(container.MetricGetBatchService),
+		handlers.WithMetricGRPCDeleter(container.MetricDeleteService),
+		handlers.WithMetricGRPCPrefixDeleter(container.MetricDeleteService),
+		handlers.WithMetricGRPCResetter(container.MetricResetService),
+		handlers.WithMetricGRPCHashKey(cfg.Key, cfg.HashHeader),
+	)
+	v2 := handlers.NewMetricGRPCV2Handler(
+		handlers.WithMetricGRPCV2Updater(container.MetricUpdatesService),
+		handlers.WithMetricGRPCV2Getter(container.MetricGetService),
+		handlers.WithMetricGRPCV2BatchGetter(container.MetricGetBatchService),
+		handlers.WithMetricGRPCV2Lister(container.MetricListService),
+		handlers.WithMetricGRPCV2Deleter(container.MetricDeleteService),
+		handlers.WithMetricGRPCV2HashKey(cfg.Key, cfg.HashHeader),
+	)
+	return updater, service, v2
+}
+
+// registerMetricGRPCHandlers registers the metric services on a gRPC server or transcoder.
+func registerMetricGRPCHandlers(
+	reg grpc.ServiceRegistrar,
+	updater *handlers.MetricGRPCUpdaterHandler,
+	service *handlers.MetricGRPCServiceHandler,
+	v2 *handlers.MetricGRPCV2Handler,
+) {
+	pb.RegisterMetricUpdaterServer(reg, updater)
+	pb.RegisterMetricServiceServer(reg, service)
+	pbv2.RegisterMetricServiceServer(reg, v2)
+}
+
+// newGRPCInterceptors builds the gRPC counterparts of the HTTP middlewares.
+// Recovery is outermost so panics in any later interceptor are caught too.
+func newGRPCInterceptors(
+	cfg *serverAppConfig,
+	container *container,
+) ([]grpc.UnaryServerInterceptor, []grpc.StreamServerInterceptor, error) {
+	subnetUnary, err := interceptors.TrustedSubnetUnaryInterceptor(
+		interceptors.WithTrustedSubnet(cfg.TrustedSubnet),
+	)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid trusted subnet: %w", err)
+	}
+	subnetStream, err := interceptors.TrustedSubnetStreamInterceptor(
+		interceptors.WithTrustedSubnet(cfg.TrustedSubnet),
+	)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid trusted subnet: %w", err)
+	}
+
+	hashUnary, err := interceptors.HashUnaryInterceptor(
+		interceptors.WithHashKey(cfg.Key),
+		interceptors.WithHashHeader(cfg.HashHeader),
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	hashStream, err := interceptors.HashStreamInterceptor(
+		interceptors.WithHashKey(cfg.Key),
+		interceptors.WithHashHeader(cfg.HashHeader),
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	txUnary, err := interceptors.TxUnaryInterceptor(
+		interceptors.WithDB(container.DB),
+		interceptors.WithTxSetter(contexts.SetTxToContext),
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	unary := []grpc.UnaryServerInterceptor{
+		interceptors.RecoveryUnaryInterceptor,
+		interceptors.AgentIdentityUnaryInterceptor,
+		interceptors.LoggingUnaryInterceptor,
+		subnetUnary,
+		hashUnary,
+		txUnary,
+	}
+	stream := []grpc.StreamServerInterceptor{
+		interceptors.RecoveryStreamInterceptor,
+		interceptors.AgentIdentityStreamInterceptor,
+		interceptors.LoggingStreamInterceptor,
+		subnetStream,
+		hashStream,
+	}
+	return unary, stream, nil
+}
+
 // Run starts the gRPC server and workers, handling graceful shutdown.
 func (app *ServerGRPCApp) Run(ctx context.Context) error {
 	ctx, stop := signal.NotifyContext(ctx, syscall.SIGINT, syscall.SIGTERM, syscall.SIGQUIT)
@@ -329,12 +683,13 @@ func (app *ServerGRPCApp) Run(ctx context.Context) error {
 
 	// Start gRPC server
 	go func() {
-		logger.Log.Infof("Starting gRPC server on %s", app.Config.ServerAddress)
+		logger.Log.Infof("Starting gRPC server on %s", app.Listener.Addr())
 		if err := app.Server.Serve(app.Listener); err != nil {
 			logger.Log.Error("gRPC server error: " + err.Error())
 			errCh <- err
 		}
 	}()
+	notifySystemd(systemd.Ready)
 
 	select {
 	case <-ctx.Done():
@@ -346,23 +701,89 @@ func (app *ServerGRPCApp) Run(ctx context.Context) error {
 		}
 	}
 
-	// Graceful shutdown
+	notifySystemd(systemd.Stopping)
+
+	// Graceful shutdown; watch streams never end on their own, so close them first
+	app.HealthGRPCHandler.Shutdown()
+	app.Container.MetricWatchService.Close()
+	stopGRPCServer(app.Server, 10*time.Second)
+
+	logger.Log.Info("Server stopped")
+	return nil
+}
+
+// listen returns the socket-activated listener for the transport named name,
+// or listens on a TCP or "unix://" address, applying the configured socket permissions.
+//
+// Inherited sockets are matched by their FileDescriptorName= ("http" or "grpc");
+// unnamed ones are handed out in the order systemd passed them.
+func (c *container) listen(cfg *serverAppConfig, name, addr string) (net.Listener, error) {
+	match := -1
+	for i, l := range c.Listeners {
+		if l.Name == name {
+			match = i
+			break
+		}
+		if match < 0 && l.Name != TransportHTTP && l.Name != TransportGRPC {
+			match = i
+		}
+	}
+	if match >= 0 {
+		lis := c.Listeners[match]
+		c.Listeners = append(c.Listeners[:match], c.Listeners[match+1:]...)
+		logger.Log.Infow("Using socket-activated listener", "name", lis.Name, "addr", lis.Addr().String())
+		return lis.Listener, nil
+	}
+	return sockets.Listen(addr, sockets.WithMode(cfg.SocketMode))
+}
+
+// close releases a container whose app never ran: it ends the watch
+// subscriptions and closes the inherited listeners nobody took and the DB pool.
+func (c *container) close() error {
+	c.MetricWatchService.Close()
+
+	var errs []error
+	for _, l := range c.Listeners {
+		errs = append(errs, l.Close())
+	}
+	c.Listeners = nil
+	if c.DB != nil {
+		errs = append(errs, c.DB.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// notifySystemd reports a state change to the service manager, if any.
+// Failures are logged only: a notification must never stop the server.
+func notifySystemd(state string) {
+	if _, err := systemd.Notify(state); err != nil {
+		logger.Log.Errorw("systemd notification failed", "state", state, "error", err)
+	}
+}
+
+// serve serves srv on lis, over TLS when it has a TLS configuration.
+func serve(srv *http.Server, lis net.Listener) error {
+	if srv.TLSConfig != nil {
+		return srv.ServeTLS(lis, "", "")
+	}
+	return srv.Serve(lis)
+}
+
+// stopGRPCServer stops srv gracefully, forcing it to stop after timeout.
+func stopGRPCServer(srv *grpc.Server, timeout time.Duration) {
 	done := make(chan struct{})
 	go func() {
-		app.Server.GracefulStop()
+		srv.GracefulStop()
 		close(done)
 	}()
 
 	select {
 	case <-done:
 		logger.Log.Info("gRPC server stopped gracefully")
-	case <-time.After(10 * time.Second):
+	case <-time.After(timeout):
 		logger.Log.Warn("Timeout on gRPC server graceful stop, forcing stop")
-		app.Server.Stop()
+		srv.Stop()
 	}
-
-	logger.Log.Info("Server stopped")
-	return nil
 }
 
 // Container holds dependencies.
@@ -373,21 +794,57 @@ type container struct {
 	MetricDBGetRepository  *repositories.MetricDBGetRepository
 	MetricDBListRepository *repositories.MetricDBListRepository
 
+	MetricDBGetBatchRepository *repositories.MetricDBGetBatchRepository
+	MetricDBDeleteRepository   *repositories.MetricDBDeleteRepository
+	MetricDBResetRepository    *repositories.MetricDBResetRepository
+	MetricDBApplyRepository    *repositories.MetricDBApplyRepository
+	MetricDBExpireRepository   *repositories.MetricDBExpireRepository
+
 	MetricFileSaveRepository *repositories.MetricFileSaveRepository
 	MetricFileGetRepository  *repositories.MetricFileGetRepository
 	MetricFileListRepository *repositories.MetricFileListRepository
 
+	MetricFileGetBatchRepository *repositories.MetricFileGetBatchRepository
+	MetricFileDeleteRepository   *repositories.MetricFileDeleteRepository
+	MetricFileResetRepository    *repositories.MetricFileResetRepository
+	MetricFileApplyRepository    *repositories.MetricFileApplyRepository
+	MetricFileExpireRepository   *repositories.MetricFileExpireRepository
+
 	MetricMemorySaveRepository *repositories.MetricMemorySaveRepository
 	MetricMemoryGetRepository  *repositories.MetricMemoryGetRepository
 	MetricMemoryListRepository *repositories.MetricMemoryListRepository
 
+	MetricMemoryGetBatchRepository *repositories.MetricMemoryGetBatchRepository
+	MetricMemoryDeleteRepository   *repositories.MetricMemoryDeleteRepository
+	MetricMemoryResetRepository    *repositories.MetricMemoryResetRepository
+	MetricMemoryApplyRepository    *repositories.MetricMemoryApplyRepository
+	MetricMemoryExpireRepository   *repositories.MetricMemoryExpireRepository
+
 	MetricContextSaveRepository *repositories.MetricContextSaveRepository
 	MetricContextGetRepository  *repositories.MetricContextGetRepository
 	MetricContextListRepository *repositories.MetricContextListRepository
 
-	MetricUpdatesService *services.MetricUpdatesService
-	MetricGetService     *services.MetricGetService
-	MetricListService    *services.MetricListService
+	MetricContextGetBatchRepository *repositories.MetricContextGetBatchRepository
+	MetricContextDeleteRepository   *repositories.MetricContextDeleteRepository
+	MetricContextResetRepository    *repositories.MetricContextResetRepository
+	MetricContextApplyRepository    *repositories.MetricContextApplyRepository
+	MetricContextExpireRepository   *repositories.MetricContextExpireRepository
+
+	MetricUpdatesService  *services.MetricUpdatesService
+	MetricGetService      *services.MetricGetService
+	MetricGetBatchService *services.MetricGetBatchService
+	MetricListService     *services.MetricListService
+	MetricDeleteService   *services.MetricDeleteService
+	MetricResetService    *services.MetricResetService
+	MetricWatchService    *services.MetricWatchService
+	HealthService         *services.HealthService
+
+	// TLSConfig is shared by the HTTP and gRPC servers; nil means plaintext.
+	TLSConfig *tls.Config
+
+	// Listeners holds the sockets passed by systemd socket activation that
+	// no server has taken yet.
+	Listeners []systemd.Listener
 
 	Workers []func(ctx context.Context) error
 }
@@ -395,6 +852,20 @@ type container struct {
 func newContainer(cfg *serverAppConfig) (*container, error) {
 	c := &container{}
 
+	tlsConfig, err := certificates.NewServerTLSConfig(
+		certificates.WithCertificate(cfg.TLSCert, cfg.TLSKey),
+		certificates.WithCAFile(cfg.TLSClientCA),
+	)
+	if err != nil {
+		return nil, err
+	}
+	c.TLSConfig = tlsConfig
+
+	c.Listeners, err = systemd.Listeners()
+	if err != nil {
+		return nil, err
+	}
+
 	if cfg.DatabaseDSN != "" {
 		db, err := sqlx.Open("pgx", cfg.DatabaseDSN)
 		if err != nil {
@@ -404,14 +875,17 @@ func newContainer(cfg *serverAppConfig) (*container, error) {
 		defer cancel()
 
 		if err := db.PingContext(ctx); err != nil {
+			db.Close()
 			return nil, err
 		}
 
 		if cfg.MigrationsDir != "" {
 			if err := goose.SetDialect("postgres"); err != nil {
+				db.Close()
 				return nil, err
 			}
 			if err := goose.Up(db.DB, cfg.MigrationsDir); err != nil {
+				db.Close()
 				return nil, err
 			}
 		}
@@ -431,6 +905,26 @@ func newContainer(cfg *serverAppConfig) (*container, error) {
 			repositories.WithMetricDBListRepositoryDB(db),
 			repositories.WithMetricDBListRepositoryTxGetter(contexts.GetTxFromContext),
 		)
+		c.MetricDBGetBatchRepository = repositories.NewMetricDBGetBatchRepository(
+			repositories.WithMetricDBGetBatchRepositoryDB(db),
+			repositories.WithMetricDBGetBatchRepositoryTxGetter(contexts.GetTxFromContext),
+		)
+		c.MetricDBDeleteRepository = repositories.NewMetricDBDeleteRepository(
+			repositories.WithMetricDBDeleteRepositoryDB(db),
+			repositories.WithMetricDBDeleteRepositoryTxGetter(contexts.GetTxFromContext),
+		)
+		c.MetricDBResetRepository = repositories.NewMetricDBResetRepository(
+			repositories.WithMetricDBResetRepositoryDB(db),
+			repositories.WithMetricDBResetRepositoryTxGetter(contexts.GetTxFromContext),
+		)
+		c.MetricDBApplyRepository = repositories.NewMetricDBApplyRepository(
+			repositories.WithMetricDBApplyRepositoryDB(db),
+			repositories.WithMetricDBApplyRepositoryTxGetter(contexts.GetTxFromContext),
+		)
+		c.MetricDBExpireRepository = repositories.NewMetricDBExpireRepository(
+			repositories.WithMetricDBExpireRepositoryDB(db),
+			repositories.WithMetricDBExpireRepositoryTxGetter(contexts.GetTxFromContext),
+		)
 	}
 
 	if cfg.DatabaseDSN == "" && cfg.FileStoragePath != "" {
@@ -448,6 +942,21 @@ func newContainer(cfg *serverAppConfig) (*container, error) {
 		c.MetricFileListRepository = repositories.NewMetricFileListRepository(
 			repositories.WithMetricFileListRepositoryPath(cfg.FileStoragePath),
 		)
+		c.MetricFileGetBatchRepository = repositories.NewMetricFileGetBatchRepository(
+			repositories.WithMetricFileGetBatchRepositoryPath(cfg.FileStoragePath),
+		)
+		c.MetricFileDeleteRepository = repositories.NewMetricFileDeleteRepository(
+			repositories.WithMetricFileDeleteRepositoryPath(cfg.FileStoragePath),
+		)
+		c.MetricFileResetRepository = repositories.NewMetricFileResetRepository(
+			repositories.WithMetricFileResetRepositoryPath(cfg.FileStoragePath),
+		)
+		c.MetricFileApplyRepository = repositories.NewMetricFileApplyRepository(
+			repositories.WithMetricFileApplyRepositoryPath(cfg.FileStoragePath),
+		)
+		c.MetricFileExpireRepository = repositories.NewMetricFileExpireRepository(
+			repositories.WithMetricFileExpireRepositoryPath(cfg.FileStoragePath),
+		)
 	}
 
 	if cfg.DatabaseDSN == "" && cfg.FileStoragePath == "" {
@@ -455,39 +964,94 @@ func newContainer(cfg *serverAppConfig) (*container, error) {
 		c.MetricMemorySaveRepository = repositories.NewMetricMemorySaveRepository()
 		c.MetricMemoryGetRepository = repositories.NewMetricMemoryGetRepository()
 		c.MetricMemoryListRepository = repositories.NewMetricMemoryListRepository()
+		c.MetricMemoryGetBatchRepository = repositories.NewMetricMemoryGetBatchRepository()
+		c.MetricMemoryDeleteRepository = repositories.NewMetricMemoryDeleteRepository()
+		c.MetricMemoryResetRepository = repositories.NewMetricMemoryResetRepository()
+		c.MetricMemoryApplyRepository = repositories.NewMetricMemoryApplyRepository()
+		c.MetricMemoryExpireRepository = repositories.NewMetricMemoryExpireRepository()
 	}
 
 	// Context repositories always initialized
 	c.MetricContextSaveRepository = repositories.NewMetricContextSaveRepository()
 	c.MetricContextGetRepository = repositories.NewMetricContextGetRepository()
 	c.MetricContextListRepository = repositories.NewMetricContextListRepository()
+	c.MetricContextGetBatchRepository = repositories.NewMetricContextGetBatchRepository()
+	c.MetricContextDeleteRepository = repositories.NewMetricContextDeleteRepository()
+	c.MetricContextResetRepository = repositories.NewMetricContextResetRepository()
+	c.MetricContextApplyRepository = repositories.NewMetricContextApplyRepository()
+	c.MetricContextExpireRepository = repositories.NewMetricContextExpireRepository()
 
 	switch {
 	case c.MetricDBSaveRepository != nil:
 		c.MetricContextSaveRepository.SetContext(c.MetricDBSaveRepository)
 		c.MetricContextGetRepository.SetContext(c.MetricDBGetRepository)
 		c.MetricContextListRepository.SetContext(c.MetricDBListRepository)
+		c.MetricContextGetBatchRepository.SetContext(c.MetricDBGetBatchRepository)
+		c.MetricContextDeleteRepository.SetContext(c.MetricDBDeleteRepository)
+		c.MetricContextResetRepository.SetContext(c.MetricDBResetRepository)
+		c.MetricContextApplyRepository.SetContext(c.MetricDBApplyRepository)
+		c.MetricContextExpireRepository.SetContext(c.MetricDBExpireRepository)
 
 	case c.MetricFileSaveRepository != nil:
 		c.MetricContextSaveRepository.SetContext(c.MetricFileSaveRepository)
 		c.MetricContextGetRepository.SetContext(c.MetricFileGetRepository)
 		c.MetricContextListRepository.SetContext(c.MetricFileListRepository)
+		c.MetricContextGetBatchRepository.SetContext(c.MetricFileGetBatchRepository)
+		c.MetricContextDeleteRepository.SetContext(c.MetricFileDeleteRepository)
+		c.MetricContextResetRepository.SetContext(c.MetricFileResetRepository)
+		c.MetricContextApplyRepository.SetContext(c.MetricFileApplyRepository)
+		c.MetricContextExpireRepository.SetContext(c.MetricFileExpireRepository)
 
 	default:
 		c.MetricContextSaveRepository.SetContext(c.MetricMemorySaveRepository)
 		c.MetricContextGetRepository.SetContext(c.MetricMemoryGetRepository)
 		c.MetricContextListRepository.SetContext(c.MetricMemoryListRepository)
+		c.MetricContextGetBatchRepository.SetContext(c.MetricMemoryGetBatchRepository)
+		c.MetricContextDeleteRepository.SetContext(c.MetricMemoryDeleteRepository)
+		c.MetricContextResetRepository.SetContext(c.MetricMemoryResetRepository)
+		c.MetricContextApplyRepository.SetContext(c.MetricMemoryApplyRepository)
+		c.MetricContextExpireRepository.SetContext(c.MetricMemoryExpireRepository)
 	}
 
+	var healthOpts []services.HealthServiceOption
+	if c.DB != nil {
+		healthOpts = append(healthOpts, services.WithHealthDB(c.DB))
+	}
+	if cfg.FileStoragePath != "" {
+		healthOpts = append(healthOpts, services.WithHealthFilePath(cfg.FileStoragePath))
+	}
+	c.HealthService = services.NewHealthService(healthOpts...)
+
+	c.MetricWatchService = services.NewMetricWatchService()
 	c.MetricUpdatesService = services.NewMetricUpdatesService(
 		services.WithMetricUpdatesGetter(c.MetricContextGetRepository),
 		services.WithMetricUpdatesSaver(c.MetricContextSaveRepository),
+		services.WithMetricUpdatesApplier(c.MetricContextApplyRepository),
+		services.WithMetricUpdatesClock(time.Now),
+		services.WithMetricUpdatesPublisher(c.MetricWatchService),
 	)
 	c.MetricGetService = services.NewMetricGetService(
 		services.WithMetricGetGetter(c.MetricContextGetRepository),
 	)
+	c.MetricGetBatchService = services.NewMetricGetBatchService(
+		services.WithMetricGetBatchGetter(c.MetricContextGetBatchRepository),
+	)
+	ttlPolicy := types.TTLPolicy{
+		Default:  time.Duration(cfg.MetricTTL) * time.Second,
+		Prefixes: cfg.MetricTTLPrefixes,
+	}
+
 	c.MetricListService = services.NewMetricListService(
 		services.WithMetricListLister(c.MetricContextListRepository),
+		services.WithMetricListTTLPolicy(ttlPolicy),
+	)
+	c.MetricDeleteService = services.NewMetricDeleteService(
+		services.WithMetricDeleteDeleter(c.MetricContextDeleteRepository),
+		services.WithMetricDeletePublisher(c.MetricWatchService),
+	)
+	c.MetricResetService = services.NewMetricResetService(
+		services.WithMetricResetResetter(c.MetricContextResetRepository),
+		services.WithMetricResetPublisher(c.MetricWatchService),
 	)
 
 	if cfg.FileStoragePath != "" {
@@ -504,5 +1068,35 @@ func newContainer(cfg *serverAppConfig) (*container, error) {
 		)
 	}
 
+	switch cfg.StaleMode {
+	case "", workers.ExpiryModeMark, workers.ExpiryModeExpire:
+	default:
+		return nil, fmt.Errorf("unknown stale mode %q", cfg.StaleMode)
+	}
+
+	if ttlPolicy.Enabled() {
+		c.Workers = append(
+			c.Workers,
+			workers.NewExpiryWorker(
+				workers.WithExpiryInterval(cfg.StaleCheckInterval),
+				workers.WithExpiryMode(cfg.StaleMode),
+				workers.WithExpiryPolicy(ttlPolicy),
+				workers.WithExpiryLister(c.MetricContextListRepository),
+				workers.WithExpiryExpirer(c.MetricContextExpireRepository),
+				workers.WithExpiryPublisher(c.MetricWatchService),
+			),
+		)
+	}
+
+	if interval := systemd.WatchdogInterval(); interval > 0 {
+		c.Workers = append(
+			c.Workers,
+			workers.NewWatchdogWorker(
+				workers.WithWatchdogInterval(interval),
+				workers.WithWatchdogNotify(systemd.Notify, systemd.Watchdog),
+			),
+		)
+	}
+
 	return c, nil
 }
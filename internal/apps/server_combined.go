@@ -0,0 +1,237 @@
+package apps
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/sbilibin2017/go-yandex-practicum/internal/logger"
+	"github.com/sbilibin2017/go-yandex-practicum/internal/systemd"
+	"google.golang.org/grpc"
+)
+
+// Transports supported by ServerCombinedApp.
+const (
+	TransportHTTP = "http"
+	TransportGRPC = "grpc"
+	TransportBoth = "both"
+)
+
+// ServerCombinedApp serves the HTTP and/or gRPC APIs from one process, sharing
+// a single container, its workers and storage.
+//
+// With the "both" transport the gRPC server listens on GRPCAddress when it is set;
+// otherwise gRPC requests are multiplexed with HTTP on ServerAddress over
+// HTTP/2, cleartext unless TLS is configured.
+type ServerCombinedApp struct {
+	Config    *serverAppConfig
+	Container *container
+
+	HTTP *ServerApp     // nil for the "grpc" transport
+	GRPC *ServerGRPCApp // nil for the "http" transport
+
+	HTTPListener net.Listener
+}
+
+// NewServerCombinedApp creates a ServerCombinedApp. An empty transport means "http".
+func NewServerCombinedApp(opts ...ServerAppOpt) (*ServerCombinedApp, error) {
+	cfg := newServerAppConfig(opts...)
+
+	if err := logger.Initialize(cfg.LogLevel); err != nil {
+		return nil, err
+	}
+
+	if cfg.Transport == "" {
+		cfg.Transport = TransportHTTP
+	}
+	switch cfg.Transport {
+	case TransportHTTP, TransportGRPC, TransportBoth:
+	default:
+		return nil, fmt.Errorf("unknown transport %q", cfg.Transport)
+	}
+
+	container, err := newContainer(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	app := &ServerCombinedApp{
+		Config:    cfg,
+		Container: container,
+	}
+	if err := app.build(); err != nil {
+		app.closeListeners()
+		container.close()
+		return nil, err
+	}
+	return app, nil
+}
+
+// build creates the servers and listeners for the configured transport.
+func (app *ServerCombinedApp) build() error {
+	cfg := app.Config
+	var err error
+
+	if cfg.Transport != TransportGRPC {
+		app.HTTP, err = newServerApp(cfg, app.Container)
+		if err != nil {
+			return err
+		}
+		app.HTTPListener, err = app.Container.listen(cfg, TransportHTTP, cfg.ServerAddress)
+		if err != nil {
+			return err
+		}
+	}
+
+	if cfg.Transport == TransportHTTP {
+		return nil
+	}
+
+	var grpcListener net.Listener
+	switch {
+	case cfg.Transport == TransportGRPC:
+		grpcListener, err = app.Container.listen(cfg, TransportGRPC, cfg.ServerAddress)
+	case cfg.GRPCAddress != "":
+		grpcListener, err = app.Container.listen(cfg, TransportGRPC, cfg.GRPCAddress)
+	}
+	if err != nil {
+		return err
+	}
+
+	app.GRPC, err = newServerGRPCApp(cfg, app.Container, grpcListener)
+	if err != nil {
+		if grpcListener != nil {
+			grpcListener.Close()
+		}
+		return err
+	}
+
+	if app.multiplexed() {
+		app.HTTP.Srv.Handler = grpcHandlerFunc(app.GRPC.Server, app.HTTP.Router)
+		app.HTTP.Srv.Protocols = new(http.Protocols)
+		app.HTTP.Srv.Protocols.SetHTTP1(true)
+		if app.HTTP.Srv.TLSConfig != nil {
+			app.HTTP.Srv.Protocols.SetHTTP2(true)
+		} else {
+			app.HTTP.Srv.Protocols.SetUnencryptedHTTP2(true)
+		}
+	}
+	return nil
+}
+
+// multiplexed reports whether gRPC is served through the HTTP listener.
+func (app *ServerCombinedApp) multiplexed() bool {
+	return app.GRPC != nil && app.GRPC.Listener == nil
+}
+
+func (app *ServerCombinedApp) closeListeners() {
+	if app.HTTPListener != nil {
+		app.HTTPListener.Close()
+	}
+	if app.GRPC != nil && app.GRPC.Listener != nil {
+		app.GRPC.Listener.Close()
+	}
+}
+
+// grpcHandlerFunc routes HTTP/2 gRPC requests to grpcServer and everything else to httpHandler.
+func grpcHandlerFunc(grpcServer *grpc.Server, httpHandler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.ProtoMajor == 2 && strings.HasPrefix(r.Header.Get("Content-Type"), "application/grpc") {
+			grpcServer.ServeHTTP(w, r)
+			return
+		}
+		httpHandler.ServeHTTP(w, r)
+	})
+}
+
+// Run starts the workers and servers, and shuts all of them down together
+// on a signal, context cancellation or the first server error.
+func (app *ServerCombinedApp) Run(ctx context.Context) error {
+	ctx, stop := signal.NotifyContext(ctx, syscall.SIGINT, syscall.SIGTERM, syscall.SIGQUIT)
+	defer stop()
+
+	defer logger.Sync()
+
+	errCh := make(chan error, len(app.Container.Workers)+2)
+
+	for _, worker := range app.Container.Workers {
+		go func(w func(context.Context) error) {
+			logger.Log.Info("Worker goroutine started")
+			errCh <- w(ctx)
+		}(worker)
+	}
+
+	if app.HTTP != nil {
+		go func() {
+			logger.Log.Infof("Starting HTTP server on %s", app.HTTPListener.Addr())
+			if err := serve(app.HTTP.Srv, app.HTTPListener); err != nil && err != http.ErrServerClosed {
+				logger.Log.Error("HTTP server error: " + err.Error())
+				errCh <- err
+			}
+		}()
+	}
+
+	if app.GRPC != nil && app.GRPC.Listener != nil {
+		go func() {
+			logger.Log.Infof("Starting gRPC server on %s", app.GRPC.Listener.Addr())
+			if err := app.GRPC.Server.Serve(app.GRPC.Listener); err != nil {
+				logger.Log.Error("gRPC server error: " + err.Error())
+				errCh <- err
+			}
+		}()
+	}
+
+	notifySystemd(systemd.Ready)
+
+	var runErr error
+	select {
+	case <-ctx.Done():
+		logger.Log.Info("Shutdown signal received")
+	case runErr = <-errCh:
+		if runErr != nil {
+			logger.Log.Error("Error received: " + runErr.Error())
+		}
+	}
+
+	notifySystemd(systemd.Stopping)
+	return errors.Join(runErr, app.shutdown(10*time.Second))
+}
+
+// shutdown stops both servers within timeout.
+func (app *ServerCombinedApp) shutdown(timeout time.Duration) error {
+	// Watch streams never end on their own, so close them first
+	if app.GRPC != nil {
+		app.GRPC.HealthGRPCHandler.Shutdown()
+	}
+	app.Container.MetricWatchService.Close()
+
+	if app.GRPC != nil && !app.multiplexed() {
+		stopGRPCServer(app.GRPC.Server, timeout)
+	}
+
+	var err error
+	if app.HTTP != nil {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+
+		logger.Log.Info("Shutting down HTTP server gracefully")
+		if err = app.HTTP.Srv.Shutdown(shutdownCtx); err != nil {
+			logger.Log.Error("Error during server shutdown: " + err.Error())
+		}
+	}
+
+	// Multiplexed gRPC streams are drained by the HTTP shutdown above;
+	// GracefulStop does not support ServeHTTP transports, so just release them.
+	if app.multiplexed() {
+		app.GRPC.Server.Stop()
+	}
+
+	logger.Log.Info("Server gracefully stopped")
+	return err
+}
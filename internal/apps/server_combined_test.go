@@ -0,0 +1,350 @@
+package apps
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/sbilibin2017/go-yandex-practicum/internal/facades"
+	"github.com/sbilibin2017/go-yandex-practicum/internal/types"
+	pb "github.com/sbilibin2017/go-yandex-practicum/protos"
+)
+
+func TestNewServerCombinedApp_Transports(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        []ServerAppOpt
+		wantErr     bool
+		wantHTTP    bool
+		wantGRPC    bool
+		multiplexed bool
+	}{
+		{
+			name:     "default is http",
+			opts:     []ServerAppOpt{WithServerAddress("127.0.0.1:0")},
+			wantHTTP: true,
+		},
+		{
+			name:     "grpc only",
+			opts:     []ServerAppOpt{WithServerAddress("127.0.0.1:0"), WithServerTransport(TransportGRPC)},
+			wantGRPC: true,
+		},
+		{
+			name: "both on separate ports",
+			opts: []ServerAppOpt{
+				WithServerAddress("127.0.0.1:0"),
+				WithServerTransport(TransportBoth),
+				WithServerGRPCAddress("127.0.0.1:0"),
+			},
+			wantHTTP: true,
+			wantGRPC: true,
+		},
+		{
+			name:        "both multiplexed",
+			opts:        []ServerAppOpt{WithServerAddress("127.0.0.1:0"), WithServerTransport(TransportBoth)},
+			wantHTTP:    true,
+			wantGRPC:    true,
+			multiplexed: true,
+		},
+		{
+			name:    "unknown transport",
+			opts:    []ServerAppOpt{WithServerAddress("127.0.0.1:0"), WithServerTransport("smtp")},
+			wantErr: true,
+		},
+		{
+			name: "invalid grpc address",
+			opts: []ServerAppOpt{
+				WithServerAddress("127.0.0.1:0"),
+				WithServerTransport(TransportBoth),
+				WithServerGRPCAddress("invalid:address"),
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app, err := NewServerCombinedApp(tt.opts...)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			defer app.closeListeners()
+
+			assert.Equal(t, tt.wantHTTP, app.HTTP != nil)
+			assert.Equal(t, tt.wantGRPC, app.GRPC != nil)
+			assert.Equal(t, tt.multiplexed, app.multiplexed())
+			if tt.wantHTTP && tt.wantGRPC {
+				assert.Same(t, app.HTTP.Container, app.GRPC.Container)
+			}
+		})
+	}
+}
+
+func TestServerCombinedApp_Multiplexed(t *testing.T) {
+	app, err := NewServerCombinedApp(
+		WithServerAddress("127.0.0.1:0"),
+		WithServerTransport(TransportBoth),
+	)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	runErr := make(chan error, 1)
+	go func() { runErr <- app.Run(ctx) }()
+
+	addr := app.HTTPListener.Addr().String()
+
+	resp, err := http.Post("http://"+addr+"/update/gauge/CombinedMuxGauge/3", "text/plain", nil)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+	client := pb.NewMetricServiceClient(conn)
+
+	// The gRPC API sees what was written through HTTP.
+	got, err := client.Get(context.Background(), &pb.GetMetricRequest{
+		Id: &pb.MetricID{Id: "CombinedMuxGauge", Type: "gauge"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 3.0, got.GetMetric().GetValue())
+
+	// An open watch stream does not hold up shutdown.
+	watch, err := client.Watch(context.Background(), &pb.WatchRequest{Prefix: "CombinedMux"})
+	require.NoError(t, err)
+	_, err = watch.Header()
+	require.NoError(t, err)
+
+	cancel()
+	select {
+	case err := <-runErr:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("server did not stop")
+	}
+}
+
+func TestServerCombinedApp_SeparatePorts(t *testing.T) {
+	app, err := NewServerCombinedApp(
+		WithServerAddress("127.0.0.1:0"),
+		WithServerTransport(TransportBoth),
+		WithServerGRPCAddress("127.0.0.1:0"),
+	)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	runErr := make(chan error, 1)
+	go func() { runErr <- app.Run(ctx) }()
+
+	conn, err := grpc.NewClient(app.GRPC.Listener.Addr().String(),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = pb.NewMetricUpdaterClient(conn).Updates(context.Background(), &pb.UpdateMetricsRequest{
+		Metrics: []*pb.Metric{{Id: "CombinedSplitGauge", Type: "gauge", Value: 2.5}},
+	})
+	require.NoError(t, err)
+
+	resp, err := http.Get("http://" + app.HTTPListener.Addr().String() + "/value/gauge/CombinedSplitGauge")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	cancel()
+	select {
+	case err := <-runErr:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("server did not stop")
+	}
+}
+
+// writeTestPKI writes a CA with a server certificate for 127.0.0.1 and an
+// agent client certificate, returning the CA bundle path and the pair paths.
+func writeTestPKI(t *testing.T) (caFile, serverCert, serverKey, agentCert, agentKey string) {
+	t.Helper()
+	dir := t.TempDir()
+
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	caTmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTmpl, caTmpl, &caKey.PublicKey, caKey)
+	require.NoError(t, err)
+	caCert, err := x509.ParseCertificate(caDER)
+	require.NoError(t, err)
+	caFile = filepath.Join(dir, "ca.pem")
+	require.NoError(t, os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caDER}), 0o600))
+
+	issue := func(name string, serial int64, usage x509.ExtKeyUsage) (string, string) {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+		tmpl := &x509.Certificate{
+			SerialNumber: big.NewInt(serial),
+			Subject:      pkix.Name{CommonName: name},
+			NotBefore:    time.Now().Add(-time.Hour),
+			NotAfter:     time.Now().Add(time.Hour),
+			KeyUsage:     x509.KeyUsageDigitalSignature,
+			ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+			IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		}
+		der, err := x509.CreateCertificate(rand.Reader, tmpl, caCert, &key.PublicKey, caKey)
+		require.NoError(t, err)
+		keyDER, err := x509.MarshalECPrivateKey(key)
+		require.NoError(t, err)
+
+		certFile := filepath.Join(dir, name+".crt")
+		keyFile := filepath.Join(dir, name+".key")
+		require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+		require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+		return certFile, keyFile
+	}
+
+	serverCert, serverKey = issue("server", 2, x509.ExtKeyUsageServerAuth)
+	agentCert, agentKey = issue("agent-1", 3, x509.ExtKeyUsageClientAuth)
+	return caFile, serverCert, serverKey, agentCert, agentKey
+}
+
+func TestServerCombinedApp_MutualTLS(t *testing.T) {
+	caFile, serverCert, serverKey, agentCert, agentKey := writeTestPKI(t)
+
+	app, err := NewServerCombinedApp(
+		WithServerAddress("127.0.0.1:0"),
+		WithServerTransport(TransportBoth),
+		WithServerTLSCert(serverCert),
+		WithServerTLSKey(serverKey),
+		WithServerTLSClientCA(caFile),
+	)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	runErr := make(chan error, 1)
+	go func() { runErr <- app.Run(ctx) }()
+
+	addr := app.HTTPListener.Addr().String()
+	value := 7.5
+	metrics := []*types.Metrics{{ID: "MutualTLSGauge", Type: types.Gauge, Value: &value}}
+
+	httpFacade, err := facades.NewMetricHTTPFacade(
+		facades.WithMetricFacadeServerAddress(addr),
+		facades.WithMetricFacadeTLSCAPath(caFile),
+		facades.WithMetricFacadeTLSCertificate(agentCert, agentKey),
+	)
+	require.NoError(t, err)
+	require.NoError(t, httpFacade.Updates(context.Background(), metrics))
+
+	grpcFacade, err := facades.NewMetricGRPCFacade(
+		facades.WithMetricGRPCServerAddress(addr),
+		facades.WithMetricGRPCTLSCAPath(caFile),
+		facades.WithMetricGRPCTLSCertificate(agentCert, agentKey),
+	)
+	require.NoError(t, err)
+	defer grpcFacade.Close()
+	require.NoError(t, grpcFacade.Updates(context.Background(), metrics))
+
+	// Without a client certificate the handshake is rejected.
+	anonymous, err := facades.NewMetricHTTPFacade(
+		facades.WithMetricFacadeServerAddress(addr),
+		facades.WithMetricFacadeTLSCAPath(caFile),
+	)
+	require.NoError(t, err)
+	assert.Error(t, anonymous.Updates(context.Background(), metrics))
+
+	// Plaintext requests are not served on the TLS port.
+	resp, err := http.Get("http://" + addr + "/value/gauge/MutualTLSGauge")
+	if err == nil {
+		resp.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	}
+
+	cancel()
+	select {
+	case err := <-runErr:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("server did not stop")
+	}
+}
+
+func TestServerCombinedApp_UnixSockets(t *testing.T) {
+	dir := t.TempDir()
+	httpAddr := "unix://" + filepath.Join(dir, "http.sock")
+	grpcAddr := "unix://" + filepath.Join(dir, "grpc.sock")
+
+	app, err := NewServerCombinedApp(
+		WithServerAddress(httpAddr),
+		WithServerTransport(TransportBoth),
+		WithServerGRPCAddress(grpcAddr),
+		WithServerSocketMode(0o600),
+	)
+	require.NoError(t, err)
+
+	info, err := os.Stat(filepath.Join(dir, "http.sock"))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	runErr := make(chan error, 1)
+	go func() { runErr <- app.Run(ctx) }()
+
+	value := 2.5
+	metrics := []*types.Metrics{{ID: "UnixSocketGauge", Type: types.Gauge, Value: &value}}
+
+	httpFacade, err := facades.NewMetricHTTPFacade(facades.WithMetricFacadeServerAddress(httpAddr))
+	require.NoError(t, err)
+	require.NoError(t, httpFacade.Updates(context.Background(), metrics))
+
+	grpcFacade, err := facades.NewMetricGRPCFacade(facades.WithMetricGRPCServerAddress(grpcAddr))
+	require.NoError(t, err)
+	defer grpcFacade.Close()
+	require.NoError(t, grpcFacade.Updates(context.Background(), metrics))
+
+	cancel()
+	select {
+	case err := <-runErr:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("server did not stop")
+	}
+
+	// Sockets are removed on shutdown
+	_, err = os.Stat(filepath.Join(dir, "http.sock"))
+	assert.True(t, os.IsNotExist(err))
+	_, err = os.Stat(filepath.Join(dir, "grpc.sock"))
+	assert.True(t, os.IsNotExist(err))
+}
@@ -2,9 +2,21 @@ package apps
 
 import (
 	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
 	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -13,6 +25,20 @@ import (
 	"github.com/stretchr/testify/require"
 	"github.com/testcontainers/testcontainers-go"
 	"github.com/testcontainers/testcontainers-go/wait"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/grpc/status"
+
+	"github.com/sbilibin2017/go-yandex-practicum/internal/facades"
+	"github.com/sbilibin2017/go-yandex-practicum/internal/middlewares"
+	"github.com/sbilibin2017/go-yandex-practicum/internal/systemd"
+	"github.com/sbilibin2017/go-yandex-practicum/internal/types"
+	pb "github.com/sbilibin2017/go-yandex-practicum/protos"
+	pbv2 "github.com/sbilibin2017/go-yandex-practicum/protos/v2"
 )
 
 func TestNewServerAppConfig_Options(t *testing.T) {
@@ -55,6 +81,35 @@ func TestNewServerAppConfig_Options(t *testing.T) {
 
 	cfg = newServerAppConfig(WithServerMigrationsDir("/migrations"))
 	assert.Equal(t, "/migrations", cfg.MigrationsDir)
+
+	cfg = newServerAppConfig(WithServerMetricTTL(300))
+	assert.Equal(t, 300, cfg.MetricTTL)
+
+	cfg = newServerAppConfig(WithServerMetricTTLPrefixes(map[string]time.Duration{"CPU": time.Minute}))
+	assert.Equal(t, map[string]time.Duration{"CPU": time.Minute}, cfg.MetricTTLPrefixes)
+
+	cfg = newServerAppConfig(WithServerStaleMode("expire"))
+	assert.Equal(t, "expire", cfg.StaleMode)
+
+	cfg = newServerAppConfig(WithServerStaleCheckInterval(30))
+	assert.Equal(t, 30, cfg.StaleCheckInterval)
+}
+
+func TestNewServerApp_MetricTTL(t *testing.T) {
+	app, err := NewServerApp(
+		WithServerAddress(":0"),
+		WithServerMetricTTL(60),
+		WithServerStaleMode("expire"),
+	)
+	require.NoError(t, err)
+	assert.Len(t, app.Container.Workers, 1)
+
+	_, err = NewServerApp(
+		WithServerAddress(":0"),
+		WithServerMetricTTL(60),
+		WithServerStaleMode("unknown"),
+	)
+	assert.Error(t, err)
 }
 
 func TestNewServerApp(t *testing.T) {
@@ -79,6 +134,51 @@ func TestNewServerApp_FileStoragePath(t *testing.T) {
 	require.NotNil(t, app)
 }
 
+func TestNewServerApp_DeleteRequiresHash(t *testing.T) {
+	const key = "secret"
+	const header = "HashSHA256"
+
+	app, err := NewServerApp(
+		WithServerAddress(":0"),
+		WithServerKey(key),
+		WithServerHashHeader(header),
+	)
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodDelete, "/value/gauge/CPUutilization7", nil)
+	rr := httptest.NewRecorder()
+	app.Router.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	// A signature of the empty body alone no longer authorizes deletions
+	mac := hmac.New(sha256.New, []byte(key))
+	req = httptest.NewRequest(http.MethodDelete, "/value/gauge/CPUutilization7", nil)
+	req.Header.Set(header, hex.EncodeToString(mac.Sum(nil)))
+	rr = httptest.NewRecorder()
+	app.Router.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	req = httptest.NewRequest(http.MethodDelete, "/value/gauge/CPUutilization7", nil)
+	req.Header.Set(header, middlewares.RequestTargetHash(key, http.MethodDelete, "/value/gauge/CPUutilization7", nil))
+	rr = httptest.NewRecorder()
+	app.Router.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+
+	// The signature of one target is rejected for another
+	for _, target := range []struct{ method, uri string }{
+		{http.MethodDelete, "/value/gauge/Other"},
+		{http.MethodDelete, "/value/gauge"},
+		{http.MethodDelete, "/values/?prefix=CPU"},
+		{http.MethodPost, "/reset/gauge/CPUutilization7"},
+	} {
+		req = httptest.NewRequest(target.method, target.uri, nil)
+		req.Header.Set(header, middlewares.RequestTargetHash(key, http.MethodDelete, "/value/gauge/CPUutilization7", nil))
+		rr = httptest.NewRecorder()
+		app.Router.ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusBadRequest, rr.Code, target.uri)
+	}
+}
+
 func TestNewServerApp_WithDatabaseDSN_AndMigrations(t *testing.T) {
 	ctx := context.Background()
 
@@ -210,3 +310,373 @@ func TestNewServerGRPCApp_Success(t *testing.T) {
 		t.Fatal("Timeout waiting for app.Run to finish")
 	}
 }
+
+func TestServerGRPCApp_MetricService(t *testing.T) {
+	app, err := NewServerGRPCApp(
+		WithServerLogLevel("info"),
+		WithServerAddress("127.0.0.1:0"),
+	)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	runErr := make(chan error, 1)
+	go func() { runErr <- app.Run(ctx) }()
+
+	conn, err := grpc.NewClient(app.Listener.Addr().String(),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+	client := pb.NewMetricServiceClient(conn)
+
+	watch, err := client.Watch(context.Background(), &pb.WatchRequest{Prefix: "Poll"})
+	require.NoError(t, err)
+	// Headers arrive once the subscription is registered.
+	_, err = watch.Header()
+	require.NoError(t, err)
+
+	stream, err := client.StreamUpdates(context.Background())
+	require.NoError(t, err)
+	require.NoError(t, stream.Send(&pb.UpdateMetricsRequest{Metrics: []*pb.Metric{
+		{Id: "Alloc", Type: "gauge", Value: 1.5},
+	}}))
+	require.NoError(t, stream.Send(&pb.UpdateMetricsRequest{Metrics: []*pb.Metric{
+		{Id: "PollCount", Type: "counter", Delta: 2},
+	}}))
+	resp, err := stream.CloseAndRecv()
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), resp.GetBatches())
+
+	event, err := watch.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, pb.MetricEventType_METRIC_EVENT_TYPE_UPDATED, event.GetType())
+	assert.Equal(t, "PollCount", event.GetMetric().GetId())
+
+	got, err := client.Get(context.Background(), &pb.GetMetricRequest{Id: &pb.MetricID{Id: "PollCount", Type: "counter"}})
+	require.NoError(t, err)
+	assert.Equal(t, event.GetMetric().GetDelta(), got.GetMetric().GetDelta())
+
+	// The v2 service shares the same storage.
+	gotV2, err := pbv2.NewMetricServiceClient(conn).Get(context.Background(), &pbv2.GetMetricRequest{
+		Id: &pbv2.MetricID{Id: "Alloc", Type: pbv2.MetricType_METRIC_TYPE_GAUGE},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1.5, gotV2.GetMetric().GetGauge())
+
+	// Shutdown ends the open watch stream instead of waiting for the forced stop.
+	cancel()
+	select {
+	case err := <-runErr:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("server did not stop")
+	}
+}
+
+func TestServerGRPCApp_TrustedSubnet(t *testing.T) {
+	_, err := NewServerGRPCApp(
+		WithServerAddress("127.0.0.1:0"),
+		WithServerTrustedSubnet("not-a-cidr"),
+	)
+	assert.Error(t, err)
+
+	app, err := NewServerGRPCApp(
+		WithServerAddress("127.0.0.1:0"),
+		WithServerTrustedSubnet("10.0.0.0/8"),
+	)
+	require.NoError(t, err)
+	go app.Server.Serve(app.Listener)
+	defer app.Server.Stop()
+
+	conn, err := grpc.NewClient(app.Listener.Addr().String(),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+	client := pb.NewMetricServiceClient(conn)
+	req := &pb.GetMetricRequest{Id: &pb.MetricID{Id: "TrustedSubnetProbe", Type: "gauge"}}
+
+	// Loopback peers are outside the trusted subnet.
+	_, err = client.Get(context.Background(), req)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "x-real-ip", "10.1.2.3")
+	_, err = client.Get(ctx, req)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestServerGRPCApp_SignedEncryptedUpdates(t *testing.T) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	dir := t.TempDir()
+
+	privPath := filepath.Join(dir, "private.pem")
+	require.NoError(t, os.WriteFile(privPath, pem.EncodeToMemory(&pem.Block{
+		Type:  "RSA PRIVATE KEY",
+		Bytes: x509.MarshalPKCS1PrivateKey(privateKey),
+	}), 0600))
+	pubBytes, err := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
+	require.NoError(t, err)
+	pubPath := filepath.Join(dir, "public.pem")
+	require.NoError(t, os.WriteFile(pubPath, pem.EncodeToMemory(&pem.Block{
+		Type:  "PUBLIC KEY",
+		Bytes: pubBytes,
+	}), 0644))
+
+	app, err := NewServerGRPCApp(
+		WithServerAddress("127.0.0.1:0"),
+		WithServerKey("secret"),
+		WithServerHashHeader("HashSHA256"),
+		WithServerCryptoKey(privPath),
+	)
+	require.NoError(t, err)
+	go app.Server.Serve(app.Listener)
+	defer app.Server.Stop()
+
+	addr := app.Listener.Addr().String()
+	value := 4.2
+
+	facade, err := facades.NewMetricGRPCFacade(
+		facades.WithMetricGRPCServerAddress(addr),
+		facades.WithMetricGRPCKey("secret"),
+		facades.WithMetricGRPCHeader("HashSHA256"),
+		facades.WithMetricGRPCCryptoKeyPath(pubPath),
+	)
+	require.NoError(t, err)
+	defer facade.Close()
+	require.NoError(t, facade.Updates(context.Background(), []*types.Metrics{
+		{ID: "Alloc", Type: types.Gauge, Value: &value},
+	}))
+
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+	got, err := pb.NewMetricServiceClient(conn).Get(context.Background(), &pb.GetMetricRequest{
+		Id: &pb.MetricID{Id: "Alloc", Type: types.Gauge},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, value, got.GetMetric().GetValue())
+
+	// A facade signing with another key is rejected.
+	wrongKey, err := facades.NewMetricGRPCFacade(
+		facades.WithMetricGRPCServerAddress(addr),
+		facades.WithMetricGRPCKey("other"),
+		facades.WithMetricGRPCHeader("HashSHA256"),
+		facades.WithMetricGRPCCryptoKeyPath(pubPath),
+	)
+	require.NoError(t, err)
+	defer wrongKey.Close()
+	err = wrongKey.Updates(context.Background(), []*types.Metrics{
+		{ID: "Alloc", Type: types.Gauge, Value: &value},
+	})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
+
+func TestServerGRPCApp_HealthAndReflection(t *testing.T) {
+	app, err := NewServerGRPCApp(
+		WithServerAddress("127.0.0.1:0"),
+		WithServerGRPCReflection(true),
+	)
+	require.NoError(t, err)
+	go app.Server.Serve(app.Listener)
+	defer app.Server.Stop()
+
+	conn, err := grpc.NewClient(app.Listener.Addr().String(),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+
+	health := healthpb.NewHealthClient(conn)
+	for _, service := range []string{"", pb.MetricService_ServiceDesc.ServiceName, pbv2.MetricService_ServiceDesc.ServiceName} {
+		resp, err := health.Check(context.Background(), &healthpb.HealthCheckRequest{Service: service})
+		require.NoError(t, err)
+		assert.Equal(t, healthpb.HealthCheckResponse_SERVING, resp.GetStatus(), service)
+	}
+
+	stream, err := reflectionpb.NewServerReflectionClient(conn).ServerReflectionInfo(context.Background())
+	require.NoError(t, err)
+	require.NoError(t, stream.Send(&reflectionpb.ServerReflectionRequest{
+		MessageRequest: &reflectionpb.ServerReflectionRequest_ListServices{},
+	}))
+	resp, err := stream.Recv()
+	require.NoError(t, err)
+	var names []string
+	for _, s := range resp.GetListServicesResponse().GetService() {
+		names = append(names, s.GetName())
+	}
+	assert.Contains(t, names, pb.MetricService_ServiceDesc.ServiceName)
+	assert.Contains(t, names, "grpc.health.v1.Health")
+
+	app.HealthGRPCHandler.Shutdown()
+	resp2, err := health.Check(context.Background(), &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, resp2.GetStatus())
+}
+
+func TestServerGRPCApp_ReflectionDisabledByDefault(t *testing.T) {
+	app, err := NewServerGRPCApp(WithServerAddress("127.0.0.1:0"))
+	require.NoError(t, err)
+	defer app.Listener.Close()
+
+	_, ok := app.Server.GetServiceInfo()["grpc.reflection.v1.ServerReflection"]
+	assert.False(t, ok)
+}
+
+func TestNewServerApp_PingReportsStorage(t *testing.T) {
+	app, err := NewServerApp(
+		WithServerAddress(":0"),
+		WithServerFileStoragePath(filepath.Join(t.TempDir(), "metrics.json")),
+	)
+	require.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	app.Router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/ping", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "file: ok\n", w.Body.String())
+}
+
+func TestNewServerApp_RESTTranscoding(t *testing.T) {
+	app, err := NewServerApp(WithServerAddress(":0"))
+	require.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	app.Router.ServeHTTP(w, httptest.NewRequest(
+		http.MethodPost,
+		"/api/v1/updates",
+		strings.NewReader(`{"metrics":[{"id":"RESTProbe","type":"gauge","value":4.5}]}`),
+	))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	w = httptest.NewRecorder()
+	app.Router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v2/metrics/METRIC_TYPE_GAUGE/RESTProbe", nil))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var got struct {
+		Metric struct {
+			ID    string  `json:"id"`
+			Gauge float64 `json:"gauge"`
+		} `json:"metric"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+	assert.Equal(t, "RESTProbe", got.Metric.ID)
+	assert.Equal(t, 4.5, got.Metric.Gauge)
+
+	w = httptest.NewRecorder()
+	app.Router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/metrics/gauge/RESTMissing", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	// Legacy routes are still served
+	w = httptest.NewRecorder()
+	app.Router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/value/gauge/RESTProbe", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestServerApp_Run_SystemdActivation(t *testing.T) {
+	notifyPath := filepath.Join(t.TempDir(), "notify.sock")
+	notify, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: notifyPath, Net: "unixgram"})
+	require.NoError(t, err)
+	defer notify.Close()
+
+	t.Setenv("NOTIFY_SOCKET", notifyPath)
+	t.Setenv("WATCHDOG_USEC", "100000")
+
+	app, err := NewServerApp(
+		WithServerAddress("127.0.0.1:1"), // never bound: the inherited socket is used
+		WithServerRestore(false),
+	)
+	require.NoError(t, err)
+
+	inherited, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	app.Container.Listeners = []systemd.Listener{{Listener: inherited, Name: TransportHTTP}}
+
+	readState := func() string {
+		buf := make([]byte, 64)
+		notify.SetReadDeadline(time.Now().Add(2 * time.Second))
+		n, err := notify.Read(buf)
+		require.NoError(t, err)
+		return string(buf[:n])
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	runErr := make(chan error, 1)
+	go func() { runErr <- app.Run(ctx) }()
+
+	assert.Equal(t, systemd.Ready, readState())
+
+	resp, err := http.Get("http://" + inherited.Addr().String() + "/ping")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	assert.Equal(t, systemd.Watchdog, readState())
+	assert.Empty(t, app.Container.Listeners, "the inherited socket is taken")
+
+	cancel()
+	for state := readState(); state != systemd.Stopping; state = readState() {
+		assert.Equal(t, systemd.Watchdog, state)
+	}
+	select {
+	case err := <-runErr:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("server did not stop")
+	}
+}
+
+func TestContainer_ListenInherited(t *testing.T) {
+	tests := []struct {
+		name      string
+		inherited []string
+		take      string
+		wantIndex int // index into inherited, -1 for a fresh listener
+	}{
+		{name: "not activated", take: TransportHTTP, wantIndex: -1},
+		{name: "named match", inherited: []string{TransportGRPC, TransportHTTP}, take: TransportHTTP, wantIndex: 1},
+		{name: "unnamed in order", inherited: []string{"metrics.socket", "metrics.socket"}, take: TransportGRPC, wantIndex: 0},
+		{name: "named for the other transport", inherited: []string{TransportGRPC}, take: TransportHTTP, wantIndex: -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &container{}
+			var addrs []string
+			for _, name := range tt.inherited {
+				lis, err := net.Listen("tcp", "127.0.0.1:0")
+				require.NoError(t, err)
+				defer lis.Close()
+				c.Listeners = append(c.Listeners, systemd.Listener{Listener: lis, Name: name})
+				addrs = append(addrs, lis.Addr().String())
+			}
+
+			lis, err := c.listen(newServerAppConfig(), tt.take, "127.0.0.1:0")
+			require.NoError(t, err)
+			defer lis.Close()
+
+			if tt.wantIndex < 0 {
+				assert.NotContains(t, addrs, lis.Addr().String())
+				assert.Len(t, c.Listeners, len(tt.inherited))
+				return
+			}
+			assert.Equal(t, addrs[tt.wantIndex], lis.Addr().String())
+			assert.Len(t, c.Listeners, len(tt.inherited)-1)
+		})
+	}
+}
+
+func TestContainer_Close(t *testing.T) {
+	c, err := newContainer(newServerAppConfig())
+	require.NoError(t, err)
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	c.Listeners = append(c.Listeners, systemd.Listener{Listener: lis, Name: TransportHTTP})
+	events := c.MetricWatchService.Watch(context.Background(), types.MetricFilter{})
+
+	require.NoError(t, c.close())
+
+	_, ok := <-events
+	assert.False(t, ok, "watch subscriptions are ended")
+	_, err = lis.Accept()
+	assert.ErrorIs(t, err, net.ErrClosed, "untaken inherited listeners are closed")
+	assert.Empty(t, c.Listeners)
+}
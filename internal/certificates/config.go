@@ -0,0 +1,160 @@
+package certificates
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+)
+
+// TLSOpt defines a functional option for building TLS configurations.
+type TLSOpt func(*tlsOptions)
+
+type tlsOptions struct {
+	certFile string
+	keyFile  string
+	caFile   string
+}
+
+// WithCertificate sets the certificate and key files the side presents:
+// the server certificate, or the client certificate for mutual TLS.
+func WithCertificate(certFile, keyFile string) TLSOpt {
+	return func(o *tlsOptions) {
+		o.certFile = certFile
+		o.keyFile = keyFile
+	}
+}
+
+// WithCAFile sets the CA bundle the peer certificate is verified against:
+// client certificates on the server, the server certificate on the client.
+func WithCAFile(caFile string) TLSOpt {
+	return func(o *tlsOptions) {
+		o.caFile = caFile
+	}
+}
+
+func newTLSOptions(opts ...TLSOpt) *tlsOptions {
+	o := &tlsOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// NewServerTLSConfig builds the server TLS configuration. It returns nil when
+// no certificate is configured, meaning the server runs plaintext.
+//
+// With a CA bundle, clients must present a certificate signed by it (mutual TLS).
+// Both the key pair and the bundle are reloaded when their files change.
+func NewServerTLSConfig(opts ...TLSOpt) (*tls.Config, error) {
+	o := newTLSOptions(opts...)
+	if o.certFile == "" && o.keyFile == "" {
+		if o.caFile != "" {
+			return nil, errors.New("client CA requires a server certificate")
+		}
+		return nil, nil
+	}
+
+	keyPair, err := NewKeyPairReloader(o.certFile, o.keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("error loading server certificate: %w", err)
+	}
+	cfg := &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: keyPair.GetCertificate,
+	}
+
+	if o.caFile != "" {
+		clientCAs, err := NewPoolReloader(o.caFile)
+		if err != nil {
+			return nil, fmt.Errorf("error loading client CA: %w", err)
+		}
+		// ClientCAs is fixed once the server starts, so the chain is verified
+		// here against the current bundle instead. VerifyConnection also runs
+		// for resumed sessions, which skip VerifyPeerCertificate.
+		cfg.ClientAuth = tls.RequireAnyClientCert
+		cfg.VerifyConnection = func(state tls.ConnectionState) error {
+			if len(state.PeerCertificates) == 0 {
+				return errors.New("client certificate required")
+			}
+			return verifyChain(state.PeerCertificates, x509.VerifyOptions{
+				Roots:     clientCAs.Pool(),
+				KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+			})
+		}
+	}
+	return cfg, nil
+}
+
+// verifyChain verifies the leaf of certs, using the rest as intermediates.
+func verifyChain(certs []*x509.Certificate, opts x509.VerifyOptions) error {
+	opts.Intermediates = x509.NewCertPool()
+	for _, cert := range certs[1:] {
+		opts.Intermediates.AddCert(cert)
+	}
+	_, err := certs[0].Verify(opts)
+	return err
+}
+
+// NewClientTLSConfig builds the client TLS configuration. It returns nil when
+// neither a CA bundle nor a client certificate is configured, meaning the
+// client connects in plaintext.
+//
+// Without a CA bundle the system roots are used. A CA bundle is reloaded when
+// its file changes, so the server certificate is verified here against the
+// current bundle instead of the fixed RootCAs. A client certificate is
+// presented for mutual TLS and reloaded when its files change.
+func NewClientTLSConfig(opts ...TLSOpt) (*tls.Config, error) {
+	o := newTLSOptions(opts...)
+	if o.certFile == "" && o.keyFile == "" && o.caFile == "" {
+		return nil, nil
+	}
+
+	cfg := &tls.Config{MinVersion: tls.VersionTLS12}
+	if o.caFile != "" {
+		roots, err := NewPoolReloader(o.caFile)
+		if err != nil {
+			return nil, fmt.Errorf("error loading CA: %w", err)
+		}
+		// The default verification is replaced, not skipped
+		cfg.InsecureSkipVerify = true
+		cfg.VerifyConnection = func(state tls.ConnectionState) error {
+			if len(state.PeerCertificates) == 0 {
+				return errors.New("server certificate required")
+			}
+			return verifyChain(state.PeerCertificates, x509.VerifyOptions{
+				Roots:   roots.Pool(),
+				DNSName: state.ServerName,
+			})
+		}
+	}
+	if o.certFile != "" || o.keyFile != "" {
+		keyPair, err := NewKeyPairReloader(o.certFile, o.keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("error loading client certificate: %w", err)
+		}
+		cfg.GetClientCertificate = keyPair.GetClientCertificate
+	}
+	return cfg, nil
+}
+
+// Identity maps a client certificate to the agent identity: the subject
+// common name, or the whole subject when it has none.
+func Identity(cert *x509.Certificate) string {
+	if cert == nil {
+		return ""
+	}
+	if cert.Subject.CommonName != "" {
+		return cert.Subject.CommonName
+	}
+	return cert.Subject.String()
+}
+
+// PeerIdentity returns the identity of the client certificate in a TLS
+// connection state, or "" when the client presented none.
+func PeerIdentity(state *tls.ConnectionState) string {
+	if state == nil || len(state.PeerCertificates) == 0 {
+		return ""
+	}
+	return Identity(state.PeerCertificates[0])
+}
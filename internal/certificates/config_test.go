@@ -0,0 +1,305 @@
+package certificates
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	file string
+}
+
+func newTestCA(t *testing.T, dir, name string) *testCA {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	file := filepath.Join(dir, name+".pem")
+	writePEM(t, file, "CERTIFICATE", der)
+	return &testCA{cert: cert, key: key, file: file}
+}
+
+// issue writes a leaf certificate and key signed by the CA and returns their paths.
+func (ca *testCA) issue(t *testing.T, dir, name string, usage x509.ExtKeyUsage) (string, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+		DNSNames:     []string{"localhost"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.cert, &key.PublicKey, ca.key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	certFile := filepath.Join(dir, name+".crt")
+	keyFile := filepath.Join(dir, name+".key")
+	writePEM(t, certFile, "CERTIFICATE", der)
+	writePEM(t, keyFile, "EC PRIVATE KEY", keyDER)
+	return certFile, keyFile
+}
+
+func writePEM(t *testing.T, file, typ string, der []byte) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(file, pem.EncodeToMemory(&pem.Block{Type: typ, Bytes: der}), 0o600))
+}
+
+// handshake runs a TLS handshake between the configs over loopback and returns
+// the server side connection state and the first error of either side.
+func handshake(t *testing.T, serverCfg, clientCfg *tls.Config) (tls.ConnectionState, error) {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer lis.Close()
+
+	type result struct {
+		state tls.ConnectionState
+		err   error
+	}
+	resCh := make(chan result, 1)
+	go func() {
+		conn, err := lis.Accept()
+		if err != nil {
+			resCh <- result{err: err}
+			return
+		}
+		defer conn.Close()
+		srv := tls.Server(conn, serverCfg)
+		err = srv.Handshake()
+		if err == nil {
+			// TLS 1.3 clients finish before the server has checked their certificate
+			_, err = srv.Write([]byte{1})
+		}
+		resCh <- result{state: srv.ConnectionState(), err: err}
+	}()
+
+	conn, err := tls.Dial("tcp", lis.Addr().String(), clientCfg)
+	if err == nil {
+		_, err = conn.Read(make([]byte, 1))
+		conn.Close()
+	}
+	res := <-resCh
+	if res.err != nil {
+		return tls.ConnectionState{}, res.err
+	}
+	return res.state, err
+}
+
+func TestNewServerTLSConfig(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCA(t, dir, "ca")
+	certFile, keyFile := ca.issue(t, dir, "server", x509.ExtKeyUsageServerAuth)
+
+	tests := []struct {
+		name    string
+		opts    []TLSOpt
+		wantNil bool
+		wantErr bool
+	}{
+		{name: "plaintext", wantNil: true},
+		{name: "certificate", opts: []TLSOpt{WithCertificate(certFile, keyFile)}},
+		{name: "mutual TLS", opts: []TLSOpt{WithCertificate(certFile, keyFile), WithCAFile(ca.file)}},
+		{name: "client CA without certificate", opts: []TLSOpt{WithCAFile(ca.file)}, wantErr: true},
+		{name: "missing key", opts: []TLSOpt{WithCertificate(certFile, filepath.Join(dir, "nope"))}, wantErr: true},
+		{name: "invalid CA", opts: []TLSOpt{WithCertificate(certFile, keyFile), WithCAFile(keyFile)}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := NewServerTLSConfig(tt.opts...)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantNil, cfg == nil)
+		})
+	}
+}
+
+func TestMutualTLS(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCA(t, dir, "ca")
+	otherCA := newTestCA(t, dir, "other-ca")
+	serverCert, serverKey := ca.issue(t, dir, "server", x509.ExtKeyUsageServerAuth)
+	agentCert, agentKey := ca.issue(t, dir, "agent-1", x509.ExtKeyUsageClientAuth)
+	strangerCert, strangerKey := otherCA.issue(t, dir, "stranger", x509.ExtKeyUsageClientAuth)
+
+	serverCfg, err := NewServerTLSConfig(WithCertificate(serverCert, serverKey), WithCAFile(ca.file))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name         string
+		opts         []TLSOpt
+		wantErr      bool
+		wantIdentity string
+	}{
+		{
+			name:         "trusted client certificate",
+			opts:         []TLSOpt{WithCAFile(ca.file), WithCertificate(agentCert, agentKey)},
+			wantIdentity: "agent-1",
+		},
+		{
+			name:    "no client certificate",
+			opts:    []TLSOpt{WithCAFile(ca.file)},
+			wantErr: true,
+		},
+		{
+			name:    "untrusted client certificate",
+			opts:    []TLSOpt{WithCAFile(ca.file), WithCertificate(strangerCert, strangerKey)},
+			wantErr: true,
+		},
+		{
+			name:    "untrusted server certificate",
+			opts:    []TLSOpt{WithCAFile(otherCA.file), WithCertificate(agentCert, agentKey)},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clientCfg, err := NewClientTLSConfig(tt.opts...)
+			require.NoError(t, err)
+			clientCfg.ServerName = "localhost"
+
+			state, err := handshake(t, serverCfg, clientCfg)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantIdentity, PeerIdentity(&state))
+		})
+	}
+
+	t.Run("resumed session", func(t *testing.T) {
+		clientCAFile := filepath.Join(dir, "client-ca.pem")
+		caPEM, err := os.ReadFile(ca.file)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(clientCAFile, caPEM, 0600))
+
+		serverCfg, err := NewServerTLSConfig(WithCertificate(serverCert, serverKey), WithCAFile(clientCAFile))
+		require.NoError(t, err)
+		clientCfg, err := NewClientTLSConfig(WithCAFile(ca.file), WithCertificate(agentCert, agentKey))
+		require.NoError(t, err)
+		clientCfg.ServerName = "localhost"
+		clientCfg.ClientSessionCache = tls.NewLRUClientSessionCache(1)
+
+		state, err := handshake(t, serverCfg, clientCfg)
+		require.NoError(t, err)
+		assert.False(t, state.DidResume)
+
+		state, err = handshake(t, serverCfg, clientCfg)
+		require.NoError(t, err)
+		require.True(t, state.DidResume)
+		assert.Equal(t, "agent-1", PeerIdentity(&state))
+
+		// Once the client CA is replaced, resumed sessions are rejected too
+		otherPEM, err := os.ReadFile(otherCA.file)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(clientCAFile, otherPEM, 0600))
+		later := time.Now().Add(time.Minute)
+		require.NoError(t, os.Chtimes(clientCAFile, later, later))
+
+		_, err = handshake(t, serverCfg, clientCfg)
+		assert.Error(t, err)
+	})
+}
+
+func TestNewClientTLSConfig_ReloadsCA(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCA(t, dir, "ca")
+	oldCA := newTestCA(t, dir, "old-ca")
+	serverCert, serverKey := ca.issue(t, dir, "server", x509.ExtKeyUsageServerAuth)
+
+	serverCfg, err := NewServerTLSConfig(WithCertificate(serverCert, serverKey))
+	require.NoError(t, err)
+
+	caFile := filepath.Join(dir, "server-ca.pem")
+	oldPEM, err := os.ReadFile(oldCA.file)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(caFile, oldPEM, 0o600))
+
+	clientCfg, err := NewClientTLSConfig(WithCAFile(caFile))
+	require.NoError(t, err)
+	clientCfg.ServerName = "localhost"
+
+	// The server certificate is not signed by the bundle yet
+	_, err = handshake(t, serverCfg, clientCfg)
+	assert.Error(t, err)
+
+	// Once the bundle is rotated, the server is trusted without a new config
+	caPEM, err := os.ReadFile(ca.file)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(caFile, caPEM, 0o600))
+	later := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(caFile, later, later))
+
+	_, err = handshake(t, serverCfg, clientCfg)
+	require.NoError(t, err)
+
+	// The server name is still checked
+	clientCfg.ServerName = "metrics.example.com"
+	_, err = handshake(t, serverCfg, clientCfg)
+	assert.Error(t, err)
+}
+
+func TestNewClientTLSConfig_Plaintext(t *testing.T) {
+	cfg, err := NewClientTLSConfig(WithCertificate("", ""))
+	require.NoError(t, err)
+	assert.Nil(t, cfg)
+}
+
+func TestIdentity(t *testing.T) {
+	tests := []struct {
+		name string
+		cert *x509.Certificate
+		want string
+	}{
+		{name: "nil", want: ""},
+		{name: "common name", cert: &x509.Certificate{Subject: pkix.Name{CommonName: "agent-1", Organization: []string{"metrics"}}}, want: "agent-1"},
+		{name: "no common name", cert: &x509.Certificate{Subject: pkix.Name{Organization: []string{"metrics"}}}, want: "O=metrics"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Identity(tt.cert))
+		})
+	}
+
+	assert.Empty(t, PeerIdentity(nil))
+	assert.Empty(t, PeerIdentity(&tls.ConnectionState{}))
+}
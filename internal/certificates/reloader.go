@@ -0,0 +1,183 @@
+package certificates
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/sbilibin2017/go-yandex-practicum/internal/logger"
+)
+
+// KeyPairReloader serves a certificate and key pair from disk, reloading them
+// when either file changes. The files are checked on every handshake, so a
+// renewed certificate is picked up without a restart.
+type KeyPairReloader struct {
+	certFile string
+	keyFile  string
+
+	mu     sync.RWMutex
+	cert   *tls.Certificate
+	loaded []fileState
+	failed []fileState
+}
+
+// NewKeyPairReloader loads the pair once and fails if it cannot be loaded.
+func NewKeyPairReloader(certFile, keyFile string) (*KeyPairReloader, error) {
+	r := &KeyPairReloader{certFile: certFile, keyFile: keyFile}
+	state, err := statFiles(certFile, keyFile)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.load(state); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *KeyPairReloader) load(state []fileState) error {
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err != nil {
+		r.failed = state
+		return err
+	}
+	r.cert = &cert
+	r.loaded = state
+	return nil
+}
+
+// Certificate returns the current pair, reloading it first if the files changed.
+// A pair that fails to load, e.g. while being rewritten, keeps the previous one
+// in use and is not tried again until the files change once more.
+func (r *KeyPairReloader) Certificate() *tls.Certificate {
+	state, err := statFiles(r.certFile, r.keyFile)
+
+	r.mu.RLock()
+	cert, changed := r.cert, err == nil && !sameFiles(state, r.loaded) && !sameFiles(state, r.failed)
+	r.mu.RUnlock()
+
+	if changed {
+		if err := r.load(state); err != nil {
+			logger.Log.Errorw("Failed to reload certificate", "cert", r.certFile, "error", err)
+			return cert
+		}
+		logger.Log.Infow("Certificate reloaded", "cert", r.certFile)
+		r.mu.RLock()
+		cert = r.cert
+		r.mu.RUnlock()
+	}
+	return cert
+}
+
+// GetCertificate implements tls.Config.GetCertificate.
+func (r *KeyPairReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	return r.Certificate(), nil
+}
+
+// GetClientCertificate implements tls.Config.GetClientCertificate.
+func (r *KeyPairReloader) GetClientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	return r.Certificate(), nil
+}
+
+// PoolReloader serves a CA bundle from disk, reloading it when the file changes.
+type PoolReloader struct {
+	caFile string
+
+	mu     sync.RWMutex
+	pool   *x509.CertPool
+	loaded []fileState
+	failed []fileState
+}
+
+// NewPoolReloader loads the bundle once and fails if it holds no certificates.
+func NewPoolReloader(caFile string) (*PoolReloader, error) {
+	r := &PoolReloader{caFile: caFile}
+	state, err := statFiles(caFile)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.load(state); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *PoolReloader) load(state []fileState) error {
+	pool, err := LoadCertPool(r.caFile)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err != nil {
+		r.failed = state
+		return err
+	}
+	r.pool = pool
+	r.loaded = state
+	return nil
+}
+
+// Pool returns the current bundle, reloading it first if the file changed.
+// A bundle that fails to load keeps the previous one in use and is not tried
+// again until the file changes once more.
+func (r *PoolReloader) Pool() *x509.CertPool {
+	state, err := statFiles(r.caFile)
+
+	r.mu.RLock()
+	pool, changed := r.pool, err == nil && !sameFiles(state, r.loaded) && !sameFiles(state, r.failed)
+	r.mu.RUnlock()
+
+	if changed {
+		if err := r.load(state); err != nil {
+			logger.Log.Errorw("Failed to reload CA bundle", "ca", r.caFile, "error", err)
+			return pool
+		}
+		logger.Log.Infow("CA bundle reloaded", "ca", r.caFile)
+		r.mu.RLock()
+		pool = r.pool
+		r.mu.RUnlock()
+	}
+	return pool
+}
+
+// LoadCertPool reads a PEM bundle of CA certificates.
+func LoadCertPool(caFile string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, errors.New("no certificates found in " + caFile)
+	}
+	return pool, nil
+}
+
+// fileState identifies a version of a file by its modification time and size.
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+func statFiles(files ...string) ([]fileState, error) {
+	states := make([]fileState, 0, len(files))
+	for _, f := range files {
+		info, err := os.Stat(f)
+		if err != nil {
+			return nil, err
+		}
+		states = append(states, fileState{modTime: info.ModTime(), size: info.Size()})
+	}
+	return states, nil
+}
+
+func sameFiles(a, b []fileState) bool {
+	return slices.EqualFunc(a, b, func(x, y fileState) bool {
+		return x.modTime.Equal(y.modTime) && x.size == y.size
+	})
+}
@@ -0,0 +1,120 @@
+package certificates
+
+import (
+	"bytes"
+	"crypto/x509"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyPairReloader_ReloadsOnChange(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCA(t, dir, "ca")
+	certFile, keyFile := ca.issue(t, dir, "server", x509.ExtKeyUsageServerAuth)
+
+	r, err := NewKeyPairReloader(certFile, keyFile)
+	require.NoError(t, err)
+	first := r.Certificate()
+
+	// Unchanged files keep the same certificate
+	assert.Same(t, first, r.Certificate())
+
+	// A broken rewrite keeps the previous certificate in use
+	require.NoError(t, os.WriteFile(certFile, []byte("garbage"), 0o600))
+	future := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(certFile, future, future))
+	assert.Same(t, first, r.Certificate())
+
+	// A renewed certificate is picked up
+	newCert, newKey := ca.issue(t, dir, "renewed", x509.ExtKeyUsageServerAuth)
+	require.NoError(t, os.Rename(newCert, certFile))
+	require.NoError(t, os.Rename(newKey, keyFile))
+	later := future.Add(time.Minute)
+	require.NoError(t, os.Chtimes(certFile, later, later))
+
+	renewed := r.Certificate()
+	require.NotSame(t, first, renewed)
+	leaf, err := x509.ParseCertificate(renewed.Certificate[0])
+	require.NoError(t, err)
+	assert.Equal(t, "renewed", leaf.Subject.CommonName)
+}
+
+func TestPoolReloader_ReloadsOnChange(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCA(t, dir, "ca")
+	otherCA := newTestCA(t, dir, "other-ca")
+
+	r, err := NewPoolReloader(ca.file)
+	require.NoError(t, err)
+	first := r.Pool()
+	assert.Same(t, first, r.Pool())
+
+	data, err := os.ReadFile(otherCA.file)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(ca.file, data, 0o600))
+	future := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(ca.file, future, future))
+
+	assert.NotSame(t, first, r.Pool())
+}
+
+// breakPEM rewrites file with one character of the PEM body made invalid, so
+// the file keeps its size but no longer loads.
+func breakPEM(t *testing.T, file string, at time.Time) []byte {
+	t.Helper()
+	data, err := os.ReadFile(file)
+	require.NoError(t, err)
+	broken := bytes.Clone(data)
+	broken[len(broken)/2] = '!'
+	require.NoError(t, os.WriteFile(file, broken, 0o600))
+	require.NoError(t, os.Chtimes(file, at, at))
+	return data
+}
+
+func TestKeyPairReloader_SkipsBrokenFiles(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCA(t, dir, "ca")
+	certFile, keyFile := ca.issue(t, dir, "server", x509.ExtKeyUsageServerAuth)
+
+	r, err := NewKeyPairReloader(certFile, keyFile)
+	require.NoError(t, err)
+	first := r.Certificate()
+
+	future := time.Now().Add(time.Minute)
+	good := breakPEM(t, certFile, future)
+	assert.Same(t, first, r.Certificate())
+
+	// A file that failed to load is not read again while its time and size stay the same
+	require.NoError(t, os.WriteFile(certFile, good, 0o600))
+	require.NoError(t, os.Chtimes(certFile, future, future))
+	assert.Same(t, first, r.Certificate())
+
+	later := future.Add(time.Minute)
+	require.NoError(t, os.Chtimes(certFile, later, later))
+	assert.NotSame(t, first, r.Certificate())
+}
+
+func TestPoolReloader_SkipsBrokenFiles(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCA(t, dir, "ca")
+
+	r, err := NewPoolReloader(ca.file)
+	require.NoError(t, err)
+	first := r.Pool()
+
+	future := time.Now().Add(time.Minute)
+	good := breakPEM(t, ca.file, future)
+	assert.Same(t, first, r.Pool())
+
+	require.NoError(t, os.WriteFile(ca.file, good, 0o600))
+	require.NoError(t, os.Chtimes(ca.file, future, future))
+	assert.Same(t, first, r.Pool())
+
+	later := future.Add(time.Minute)
+	require.NoError(t, os.Chtimes(ca.file, later, later))
+	assert.NotSame(t, first, r.Pool())
+}
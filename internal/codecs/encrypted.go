@@ -0,0 +1,199 @@
+// Package codecs provides gRPC codecs used by the metrics agent and server.
+package codecs
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// EncryptedName is the content-subtype of encrypted gRPC requests
+// ("application/grpc+rsa-proto" on the wire).
+const EncryptedName = "rsa-proto"
+
+// aesKeySize is the size of the per-message AES-256 key.
+const aesKeySize = 32
+
+// EncryptCodec is the client side of the encrypted codec: requests are marshaled
+// and encrypted with the server public key, responses are plain protobuf.
+type EncryptCodec struct {
+	publicKey *rsa.PublicKey
+}
+
+// NewEncryptCodec creates an EncryptCodec for the given server public key.
+func NewEncryptCodec(publicKey *rsa.PublicKey) *EncryptCodec {
+	return &EncryptCodec{publicKey: publicKey}
+}
+
+// Marshal encodes v as protobuf and encrypts the result.
+func (c *EncryptCodec) Marshal(v any) ([]byte, error) {
+	body, err := marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return Encrypt(body, c.publicKey)
+}
+
+// Unmarshal decodes a plain protobuf response.
+func (c *EncryptCodec) Unmarshal(data []byte, v any) error {
+	return unmarshal(data, v)
+}
+
+// Name returns the content-subtype the codec is registered under.
+func (c *EncryptCodec) Name() string {
+	return EncryptedName
+}
+
+// DecryptCodec is the server side of the encrypted codec: requests are decrypted
+// with the private key before unmarshaling, responses are plain protobuf.
+type DecryptCodec struct {
+	privateKey *rsa.PrivateKey
+}
+
+// NewDecryptCodec creates a DecryptCodec for the given private key.
+func NewDecryptCodec(privateKey *rsa.PrivateKey) *DecryptCodec {
+	return &DecryptCodec{privateKey: privateKey}
+}
+
+// Marshal encodes a plain protobuf response.
+func (c *DecryptCodec) Marshal(v any) ([]byte, error) {
+	return marshal(v)
+}
+
+// Unmarshal decrypts data and decodes the protobuf request.
+func (c *DecryptCodec) Unmarshal(data []byte, v any) error {
+	body, err := Decrypt(data, c.privateKey)
+	if err != nil {
+		return err
+	}
+	return unmarshal(body, v)
+}
+
+// Name returns the content-subtype the codec is registered under.
+func (c *DecryptCodec) Name() string {
+	return EncryptedName
+}
+
+// Encrypt seals body with a random AES-256-GCM key and prepends that key
+// encrypted with RSA-OAEP (SHA-256). The output layout is
+// rsa(key) || nonce || ciphertext, where rsa(key) is publicKey.Size() bytes long.
+func Encrypt(body []byte, publicKey *rsa.PublicKey) ([]byte, error) {
+	key := make([]byte, aesKeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+
+	encKey, err := rsa.EncryptOAEP(sha256.New(), rand.Reader, publicKey, key, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	out := make([]byte, 0, len(encKey)+len(nonce)+len(body)+gcm.Overhead())
+	out = append(out, encKey...)
+	out = append(out, nonce...)
+	return gcm.Seal(out, nonce, body, nil), nil
+}
+
+// Decrypt reverses Encrypt.
+func Decrypt(data []byte, privateKey *rsa.PrivateKey) ([]byte, error) {
+	keySize := privateKey.Size()
+	if len(data) < keySize {
+		return nil, errors.New("encrypted payload is too short")
+	}
+
+	key, err := rsa.DecryptOAEP(sha256.New(), rand.Reader, privateKey, data[:keySize], nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt payload key: %w", err)
+	}
+
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	rest := data[keySize:]
+	if len(rest) < gcm.NonceSize() {
+		return nil, errors.New("encrypted payload is too short")
+	}
+
+	body, err := gcm.Open(nil, rest[:gcm.NonceSize()], rest[gcm.NonceSize():], nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt payload: %w", err)
+	}
+	return body, nil
+}
+
+// LoadPublicKey loads an RSA public key from a PKIX PEM file.
+func LoadPublicKey(path string) (*rsa.PublicKey, error) {
+	keyData, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(keyData)
+	if block == nil || block.Type != "PUBLIC KEY" {
+		return nil, errors.New("invalid PEM block or type")
+	}
+	pubKeyInterface, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	pubKey, ok := pubKeyInterface.(*rsa.PublicKey)
+	if !ok {
+		return nil, errors.New("not an RSA public key")
+	}
+	return pubKey, nil
+}
+
+// LoadPrivateKey loads an RSA private key from a PKCS1 PEM file.
+func LoadPrivateKey(path string) (*rsa.PrivateKey, error) {
+	keyData, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(keyData)
+	if block == nil || block.Type != "RSA PRIVATE KEY" {
+		return nil, errors.New("failed to decode PEM block containing private key")
+	}
+	return x509.ParsePKCS1PrivateKey(block.Bytes)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func marshal(v any) ([]byte, error) {
+	msg, ok := v.(proto.Message)
+	if !ok {
+		return nil, fmt.Errorf("failed to marshal, message is %T, want proto.Message", v)
+	}
+	return proto.Marshal(msg)
+}
+
+func unmarshal(data []byte, v any) error {
+	msg, ok := v.(proto.Message)
+	if !ok {
+		return fmt.Errorf("failed to unmarshal, message is %T, want proto.Message", v)
+	}
+	return proto.Unmarshal(data, msg)
+}
@@ -0,0 +1,108 @@
+package codecs
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/sbilibin2017/go-yandex-practicum/protos"
+)
+
+func TestEncryptDecrypt(t *testing.T) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	body := []byte("payload larger than an RSA block: " + string(make([]byte, 1024)))
+	encrypted, err := Encrypt(body, &privateKey.PublicKey)
+	require.NoError(t, err)
+	assert.NotEqual(t, body, encrypted)
+
+	decrypted, err := Decrypt(encrypted, privateKey)
+	require.NoError(t, err)
+	assert.Equal(t, body, decrypted)
+
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	_, err = Decrypt(encrypted, otherKey)
+	assert.Error(t, err)
+
+	_, err = Decrypt(encrypted[:10], privateKey)
+	assert.Error(t, err)
+
+	encrypted[len(encrypted)-1] ^= 0xff
+	_, err = Decrypt(encrypted, privateKey)
+	assert.Error(t, err)
+}
+
+func TestCodecs_RoundTrip(t *testing.T) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	client := NewEncryptCodec(&privateKey.PublicKey)
+	server := NewDecryptCodec(privateKey)
+	assert.Equal(t, EncryptedName, client.Name())
+	assert.Equal(t, EncryptedName, server.Name())
+
+	req := &pb.UpdateMetricsRequest{Metrics: []*pb.Metric{{Id: "Alloc", Type: "gauge", Value: 1.5}}}
+	data, err := client.Marshal(req)
+	require.NoError(t, err)
+
+	var got pb.UpdateMetricsRequest
+	require.NoError(t, server.Unmarshal(data, &got))
+	assert.True(t, proto.Equal(req, &got))
+
+	// Responses travel in plain protobuf.
+	resp := &pb.UpdateMetricsResponse{Error: "none"}
+	data, err = server.Marshal(resp)
+	require.NoError(t, err)
+	var gotResp pb.UpdateMetricsResponse
+	require.NoError(t, client.Unmarshal(data, &gotResp))
+	assert.True(t, proto.Equal(resp, &gotResp))
+
+	_, err = client.Marshal("not a message")
+	assert.Error(t, err)
+	assert.Error(t, server.Unmarshal(data, "not a message"))
+}
+
+func TestLoadKeys(t *testing.T) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	dir := t.TempDir()
+
+	privPath := filepath.Join(dir, "private.pem")
+	require.NoError(t, os.WriteFile(privPath, pem.EncodeToMemory(&pem.Block{
+		Type:  "RSA PRIVATE KEY",
+		Bytes: x509.MarshalPKCS1PrivateKey(privateKey),
+	}), 0600))
+
+	pubBytes, err := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
+	require.NoError(t, err)
+	pubPath := filepath.Join(dir, "public.pem")
+	require.NoError(t, os.WriteFile(pubPath, pem.EncodeToMemory(&pem.Block{
+		Type:  "PUBLIC KEY",
+		Bytes: pubBytes,
+	}), 0644))
+
+	loadedPriv, err := LoadPrivateKey(privPath)
+	require.NoError(t, err)
+	assert.True(t, privateKey.Equal(loadedPriv))
+
+	loadedPub, err := LoadPublicKey(pubPath)
+	require.NoError(t, err)
+	assert.True(t, privateKey.PublicKey.Equal(loadedPub))
+
+	_, err = LoadPrivateKey(pubPath)
+	assert.Error(t, err)
+	_, err = LoadPublicKey(privPath)
+	assert.Error(t, err)
+	_, err = LoadPublicKey(filepath.Join(dir, "missing.pem"))
+	assert.Error(t, err)
+}
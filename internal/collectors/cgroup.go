@@ -0,0 +1,311 @@
+package collectors
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sbilibin2017/go-yandex-practicum/internal/types"
+)
+
+// CgroupName is the name of the cgroup v2 collector.
+const CgroupName = "cgroup"
+
+// CgroupCollector reports the resource usage of a cgroup v2 group, by default
+// the one the agent runs in:
+//   - CgroupMemoryCurrent, CgroupMemoryMax and CgroupMemoryUsedPercent from memory.current and memory.max
+//   - CgroupCPUUsagePercent (100 is one full CPU), CgroupCPUQuotaUsedPercent and the
+//     CgroupCPUUsageUsec, CgroupCPUThrottledPeriods and CgroupCPUThrottledUsec counters from cpu.stat and cpu.max
+//   - CgroupIOReadBytes_<dev>, CgroupIOWriteBytes_<dev>, CgroupIOReads_<dev> and CgroupIOWrites_<dev> counters from io.stat
+//   - CgroupPidsCurrent and CgroupPidsMax from pids.current and pids.max
+//
+// Files of controllers not enabled for the group are skipped. Limits set to
+// "max" are not reported.
+type CgroupCollector struct {
+	root     string
+	path     string
+	selfFile string
+	now      func() time.Time
+
+	mu        sync.Mutex
+	deltas    *counterDeltas
+	lastUsage uint64
+	lastAt    time.Time
+}
+
+// CgroupCollectorOpt configures a CgroupCollector.
+type CgroupCollectorOpt func(*CgroupCollector)
+
+// WithCgroupPath sets the directory of the cgroup to report. By default it is
+// the agent's own cgroup under the cgroup v2 mount point.
+func WithCgroupPath(path string) CgroupCollectorOpt {
+	return func(c *CgroupCollector) {
+		c.path = path
+	}
+}
+
+// WithCgroupRoot sets the cgroup v2 mount point. Defaults to /sys/fs/cgroup.
+func WithCgroupRoot(root string) CgroupCollectorOpt {
+	return func(c *CgroupCollector) {
+		c.root = root
+	}
+}
+
+// WithCgroupSelfFile sets the file the agent's own cgroup is read from. Defaults to /proc/self/cgroup.
+func WithCgroupSelfFile(path string) CgroupCollectorOpt {
+	return func(c *CgroupCollector) {
+		c.selfFile = path
+	}
+}
+
+// WithCgroupClock sets the clock CPU usage is measured against.
+func WithCgroupClock(now func() time.Time) CgroupCollectorOpt {
+	return func(c *CgroupCollector) {
+		c.now = now
+	}
+}
+
+// NewCgroupCollector creates a CgroupCollector.
+func NewCgroupCollector(opts ...CgroupCollectorOpt) *CgroupCollector {
+	c := &CgroupCollector{
+		root:     "/sys/fs/cgroup",
+		selfFile: "/proc/self/cgroup",
+		now:      time.Now,
+		deltas:   newCounterDeltas(),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Name returns CgroupName.
+func (c *CgroupCollector) Name() string {
+	return CgroupName
+}
+
+// Collect reads the cgroup files. Without a configured path it reports
+// nothing on hosts that do not use the cgroup v2 unified hierarchy.
+func (c *CgroupCollector) Collect(ctx context.Context) ([]*types.Metrics, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	dir := c.path
+	if dir == "" {
+		if _, err := os.Stat(filepath.Join(c.root, "cgroup.controllers")); err != nil {
+			return nil, nil
+		}
+		self, err := c.ownCgroup()
+		if err != nil {
+			return nil, err
+		}
+		dir = filepath.Join(c.root, self)
+	}
+	if _, err := os.Stat(dir); err != nil {
+		return nil, fmt.Errorf("cgroup %s: %w", dir, err)
+	}
+
+	var metrics []*types.Metrics
+	var errs []error
+	for _, read := range []func(string, []*types.Metrics) ([]*types.Metrics, error){
+		c.readMemory,
+		c.readCPU,
+		c.readIO,
+		c.readPids,
+	} {
+		var err error
+		if metrics, err = read(dir, metrics); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return metrics, errors.Join(errs...)
+}
+
+// ownCgroup returns the agent's cgroup v2 path from the "0::/path" line.
+func (c *CgroupCollector) ownCgroup() (string, error) {
+	data, err := os.ReadFile(c.selfFile)
+	if err != nil {
+		return "", fmt.Errorf("failed to read own cgroup: %w", err)
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if path, ok := strings.CutPrefix(line, "0::"); ok {
+			return path, nil
+		}
+	}
+	return "", fmt.Errorf("no cgroup v2 entry in %s", c.selfFile)
+}
+
+func (c *CgroupCollector) readMemory(dir string, metrics []*types.Metrics) ([]*types.Metrics, error) {
+	current, ok, err := readCgroupValue(dir, "memory.current")
+	if err != nil || !ok {
+		return metrics, err
+	}
+	metrics = append(metrics, gauge("CgroupMemoryCurrent", float64(current)))
+
+	limit, ok, err := readCgroupValue(dir, "memory.max")
+	if err != nil || !ok {
+		return metrics, err
+	}
+	return append(metrics,
+		gauge("CgroupMemoryMax", float64(limit)),
+		gauge("CgroupMemoryUsedPercent", percent(float64(current), float64(limit))),
+	), nil
+}
+
+func (c *CgroupCollector) readCPU(dir string, metrics []*types.Metrics) ([]*types.Metrics, error) {
+	stat, ok, err := readCgroupKeyed(filepath.Join(dir, "cpu.stat"))
+	if err != nil || !ok {
+		return metrics, err
+	}
+	now := c.now()
+
+	usage := stat["usage_usec"]
+	metrics = c.deltas.Append(metrics, "CgroupCPUUsageUsec", usage)
+	metrics = c.deltas.Append(metrics, "CgroupCPUThrottledPeriods", stat["nr_throttled"])
+	metrics = c.deltas.Append(metrics, "CgroupCPUThrottledUsec", stat["throttled_usec"])
+
+	if !c.lastAt.IsZero() && now.After(c.lastAt) && usage >= c.lastUsage {
+		elapsed := float64(now.Sub(c.lastAt).Microseconds())
+		used := percent(float64(usage-c.lastUsage), elapsed)
+		metrics = append(metrics, gauge("CgroupCPUUsagePercent", used))
+
+		quota, period, limited, err := readCgroupCPUMax(dir)
+		if err != nil {
+			return metrics, err
+		}
+		if limited {
+			metrics = append(metrics, gauge("CgroupCPUQuotaUsedPercent", used*period/quota))
+		}
+	}
+	c.lastUsage, c.lastAt = usage, now
+	return metrics, nil
+}
+
+func (c *CgroupCollector) readIO(dir string, metrics []*types.Metrics) ([]*types.Metrics, error) {
+	data, err := os.ReadFile(filepath.Join(dir, "io.stat"))
+	if errors.Is(err, os.ErrNotExist) {
+		return metrics, nil
+	}
+	if err != nil {
+		return metrics, fmt.Errorf("failed to read io.stat: %w", err)
+	}
+
+	// Each line is "major:minor key=value key=value ..."
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 {
+			continue
+		}
+		stat := make(map[string]uint64, len(fields)-1)
+		for _, field := range fields[1:] {
+			key, value, ok := strings.Cut(field, "=")
+			if !ok {
+				continue
+			}
+			if n, err := strconv.ParseUint(value, 10, 64); err == nil {
+				stat[key] = n
+			}
+		}
+		dev := fields[0]
+		metrics = c.deltas.Append(metrics, labeled("CgroupIOReadBytes", dev), stat["rbytes"])
+		metrics = c.deltas.Append(metrics, labeled("CgroupIOWriteBytes", dev), stat["wbytes"])
+		metrics = c.deltas.Append(metrics, labeled("CgroupIOReads", dev), stat["rios"])
+		metrics = c.deltas.Append(metrics, labeled("CgroupIOWrites", dev), stat["wios"])
+	}
+	return metrics, scanner.Err()
+}
+
+func (c *CgroupCollector) readPids(dir string, metrics []*types.Metrics) ([]*types.Metrics, error) {
+	current, ok, err := readCgroupValue(dir, "pids.current")
+	if err != nil || !ok {
+		return metrics, err
+	}
+	metrics = append(metrics, gauge("CgroupPidsCurrent", float64(current)))
+
+	limit, ok, err := readCgroupValue(dir, "pids.max")
+	if err != nil || !ok {
+		return metrics, err
+	}
+	return append(metrics, gauge("CgroupPidsMax", float64(limit))), nil
+}
+
+// readCgroupValue reads a single-value file. It reports false when the file
+// does not exist or holds "max".
+func readCgroupValue(dir, name string) (uint64, bool, error) {
+	data, err := os.ReadFile(filepath.Join(dir, name))
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to read %s: %w", name, err)
+	}
+	value := strings.TrimSpace(string(data))
+	if value == "max" {
+		return 0, false, nil
+	}
+	n, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return 0, false, fmt.Errorf("invalid %s: %w", name, err)
+	}
+	return n, true, nil
+}
+
+// readCgroupKeyed reads a flat keyed file of "key value" lines.
+func readCgroupKeyed(path string) (map[string]uint64, bool, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to read %s: %w", filepath.Base(path), err)
+	}
+	values := make(map[string]uint64)
+	for _, line := range strings.Split(string(data), "\n") {
+		key, value, ok := strings.Cut(strings.TrimSpace(line), " ")
+		if !ok {
+			continue
+		}
+		if n, err := strconv.ParseUint(value, 10, 64); err == nil {
+			values[key] = n
+		}
+	}
+	return values, true, nil
+}
+
+// readCgroupCPUMax reads the "quota period" pair of cpu.max.
+func readCgroupCPUMax(dir string) (quota, period float64, limited bool, err error) {
+	data, err := os.ReadFile(filepath.Join(dir, "cpu.max"))
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, 0, false, nil
+	}
+	if err != nil {
+		return 0, 0, false, fmt.Errorf("failed to read cpu.max: %w", err)
+	}
+	fields := strings.Fields(string(data))
+	if len(fields) != 2 || fields[0] == "max" {
+		return 0, 0, false, nil
+	}
+	if quota, err = strconv.ParseFloat(fields[0], 64); err != nil {
+		return 0, 0, false, fmt.Errorf("invalid cpu.max: %w", err)
+	}
+	if period, err = strconv.ParseFloat(fields[1], 64); err != nil {
+		return 0, 0, false, fmt.Errorf("invalid cpu.max: %w", err)
+	}
+	return quota, period, quota > 0 && period > 0, nil
+}
+
+func percent(part, whole float64) float64 {
+	if whole <= 0 {
+		return 0
+	}
+	return part / whole * 100
+}
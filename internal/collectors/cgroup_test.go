@@ -0,0 +1,112 @@
+package collectors
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// copyFixture copies a testdata directory so a test can advance its counters.
+func copyFixture(t *testing.T, dir string) string {
+	t.Helper()
+	dst := filepath.Join(t.TempDir(), filepath.Base(dir))
+	require.NoError(t, os.CopyFS(dst, os.DirFS(dir)))
+	return dst
+}
+
+func TestCgroupCollector_Collect(t *testing.T) {
+	root := copyFixture(t, "testdata/cgroupv2")
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	c := NewCgroupCollector(
+		WithCgroupRoot(root),
+		WithCgroupSelfFile("testdata/self_cgroup"),
+		WithCgroupClock(func() time.Time { return now }),
+	)
+	assert.Equal(t, CgroupName, c.Name())
+
+	metrics, err := c.Collect(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]float64{
+		"CgroupMemoryCurrent":     268435456,
+		"CgroupMemoryMax":         536870912,
+		"CgroupMemoryUsedPercent": 50,
+		"CgroupPidsCurrent":       12,
+		"CgroupPidsMax":           100,
+	}, values(metrics), "the first poll only sets the CPU baseline")
+	assert.Empty(t, deltas(metrics))
+
+	// A quarter of a CPU second used over one second
+	now = now.Add(time.Second)
+	dir := filepath.Join(root, "agent.slice")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "cpu.stat"),
+		[]byte("usage_usec 1250000\nuser_usec 700000\nsystem_usec 550000\nnr_periods 110\nnr_throttled 7\nthrottled_usec 30000\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "io.stat"),
+		[]byte("8:0 rbytes=8192 wbytes=8192 rios=2 wios=2 dbytes=0 dios=0\n253:1 rbytes=0 wbytes=0 rios=0 wios=0 dbytes=0 dios=0\n"), 0o644))
+
+	metrics, err = c.Collect(context.Background())
+	require.NoError(t, err)
+	gauges := values(metrics)
+	assert.InDelta(t, 25, gauges["CgroupCPUUsagePercent"], 1e-9)
+	assert.InDelta(t, 50, gauges["CgroupCPUQuotaUsedPercent"], 1e-9, "the quota is half a CPU")
+
+	counters := deltas(metrics)
+	assert.Equal(t, int64(250000), counters["CgroupCPUUsageUsec"])
+	assert.Equal(t, int64(2), counters["CgroupCPUThrottledPeriods"])
+	assert.Equal(t, int64(10000), counters["CgroupCPUThrottledUsec"])
+	assert.Equal(t, int64(4096), counters["CgroupIOReadBytes_8_0"])
+	assert.Equal(t, int64(0), counters["CgroupIOWriteBytes_8_0"])
+	assert.Equal(t, int64(1), counters["CgroupIOReads_8_0"])
+	assert.Contains(t, counters, "CgroupIOWrites_253_1")
+}
+
+func TestCgroupCollector_Collect_Unlimited(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	c := NewCgroupCollector(
+		WithCgroupPath("testdata/cgroupv2/unlimited"),
+		WithCgroupClock(func() time.Time { return now }),
+	)
+
+	metrics, err := c.Collect(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]float64{"CgroupMemoryCurrent": 1024, "CgroupPidsCurrent": 3}, values(metrics),
+		"limits set to max are not reported")
+
+	now = now.Add(time.Second)
+	metrics, err = c.Collect(context.Background())
+	require.NoError(t, err)
+	gauges := values(metrics)
+	assert.Contains(t, gauges, "CgroupCPUUsagePercent")
+	assert.NotContains(t, gauges, "CgroupCPUQuotaUsedPercent")
+}
+
+func TestCgroupCollector_Collect_NoCgroup(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    []CgroupCollectorOpt
+		wantErr bool
+	}{
+		{name: "no unified hierarchy", opts: []CgroupCollectorOpt{WithCgroupRoot(t.TempDir())}},
+		{name: "configured path is missing", opts: []CgroupCollectorOpt{WithCgroupPath(filepath.Join(t.TempDir(), "gone"))}, wantErr: true},
+		{
+			name:    "no v2 entry for the agent",
+			opts:    []CgroupCollectorOpt{WithCgroupRoot("testdata/cgroupv2"), WithCgroupSelfFile("testdata/cgroupv2/cgroup.controllers")},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metrics, err := NewCgroupCollector(tt.opts...).Collect(context.Background())
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Empty(t, metrics)
+		})
+	}
+}
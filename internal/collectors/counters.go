@@ -0,0 +1,55 @@
+package collectors
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/sbilibin2017/go-yandex-practicum/internal/types"
+)
+
+// counterDeltas turns cumulative kernel counters into the per-poll deltas the
+// server adds up. The first reading of a counter only sets its baseline.
+type counterDeltas struct {
+	mu   sync.Mutex
+	last map[string]uint64
+}
+
+func newCounterDeltas() *counterDeltas {
+	return &counterDeltas{last: make(map[string]uint64)}
+}
+
+// Append adds a counter metric with the growth of value since the previous
+// poll. A value below the previous one means the counter was reset, so the
+// whole value counts as growth.
+func (d *counterDeltas) Append(metrics []*types.Metrics, id string, value uint64) []*types.Metrics {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	prev, seen := d.last[id]
+	d.last[id] = value
+	if !seen {
+		return metrics
+	}
+	if value < prev {
+		prev = 0
+	}
+	return append(metrics, counter(id, int64(value-prev)))
+}
+
+// labeled names a metric of one device, interface or mount point, for example
+// "DiskReadBytes_sda" or "FSUsedBytes_var_lib". Characters other than letters,
+// digits and underscores become underscores; the root mount point is "root".
+func labeled(name, label string) string {
+	label = strings.Trim(strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_':
+			return r
+		default:
+			return '_'
+		}
+	}, label), "_")
+	if label == "" {
+		label = "root"
+	}
+	return name + "_" + label
+}
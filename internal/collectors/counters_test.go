@@ -0,0 +1,49 @@
+package collectors
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sbilibin2017/go-yandex-practicum/internal/types"
+)
+
+func TestCounterDeltas_Append(t *testing.T) {
+	d := newCounterDeltas()
+	steps := []struct {
+		name  string
+		value uint64
+		want  []int64
+	}{
+		{name: "first reading sets the baseline", value: 100},
+		{name: "growth", value: 150, want: []int64{50}},
+		{name: "no growth", value: 150, want: []int64{0}},
+		{name: "reset", value: 20, want: []int64{20}},
+	}
+	for _, step := range steps {
+		metrics := d.Append(nil, "Bytes", step.value)
+		var got []int64
+		for _, m := range metrics {
+			require.Equal(t, types.Counter, m.Type, step.name)
+			got = append(got, *m.Delta)
+		}
+		assert.Equal(t, step.want, got, step.name)
+	}
+}
+
+func TestLabeled(t *testing.T) {
+	tests := []struct {
+		label string
+		want  string
+	}{
+		{label: "sda", want: "FS_sda"},
+		{label: "/", want: "FS_root"},
+		{label: "/var/lib", want: "FS_var_lib"},
+		{label: "C:", want: "FS_C"},
+		{label: "eth0.100", want: "FS_eth0_100"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, labeled("FS", tt.label), tt.label)
+	}
+}
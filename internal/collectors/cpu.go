@@ -0,0 +1,40 @@
+package collectors
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/sbilibin2017/go-yandex-practicum/internal/types"
+	"github.com/shirou/gopsutil/v4/cpu"
+)
+
+// CPUName is the name of the CPU utilization collector.
+const CPUName = "cpu"
+
+// CPUCollector reports CPUutilizationN, the utilization of each logical CPU
+// since the previous poll.
+type CPUCollector struct{}
+
+// NewCPUCollector creates a CPUCollector.
+func NewCPUCollector() *CPUCollector {
+	return &CPUCollector{}
+}
+
+// Name returns CPUName.
+func (c *CPUCollector) Name() string {
+	return CPUName
+}
+
+// Collect reads the per-CPU utilization.
+func (c *CPUCollector) Collect(ctx context.Context) ([]*types.Metrics, error) {
+	percents, err := cpu.PercentWithContext(ctx, 0, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cpu utilization: %w", err)
+	}
+	metrics := make([]*types.Metrics, 0, len(percents))
+	for i, percent := range percents {
+		metrics = append(metrics, gauge("CPUutilization"+strconv.Itoa(i), percent))
+	}
+	return metrics, nil
+}
@@ -0,0 +1,19 @@
+package collectors
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCPUCollector_Collect(t *testing.T) {
+	c := NewCPUCollector()
+	assert.Equal(t, CPUName, c.Name())
+
+	metrics, err := c.Collect(context.Background())
+	require.NoError(t, err)
+	require.NotEmpty(t, metrics)
+	assert.Equal(t, "CPUutilization0", metrics[0].ID)
+}
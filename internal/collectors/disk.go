@@ -0,0 +1,60 @@
+package collectors
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/sbilibin2017/go-yandex-practicum/internal/types"
+	"github.com/shirou/gopsutil/v4/disk"
+)
+
+// DiskName is the name of the disk I/O collector.
+const DiskName = "disk"
+
+// DiskCollector reports I/O counters per block device: DiskReadBytes_<dev>,
+// DiskWriteBytes_<dev>, DiskReads_<dev>, DiskWrites_<dev> and DiskIOTimeMs_<dev>.
+type DiskCollector struct {
+	ioCounters func(ctx context.Context) (map[string]disk.IOCountersStat, error)
+	deltas     *counterDeltas
+}
+
+// NewDiskCollector creates a DiskCollector.
+func NewDiskCollector() *DiskCollector {
+	return &DiskCollector{
+		ioCounters: func(ctx context.Context) (map[string]disk.IOCountersStat, error) {
+			return disk.IOCountersWithContext(ctx)
+		},
+		deltas: newCounterDeltas(),
+	}
+}
+
+// Name returns DiskName.
+func (c *DiskCollector) Name() string {
+	return DiskName
+}
+
+// Collect reads the device I/O counters.
+func (c *DiskCollector) Collect(ctx context.Context) ([]*types.Metrics, error) {
+	stats, err := c.ioCounters(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read disk counters: %w", err)
+	}
+
+	devices := make([]string, 0, len(stats))
+	for dev := range stats {
+		devices = append(devices, dev)
+	}
+	sort.Strings(devices)
+
+	var metrics []*types.Metrics
+	for _, dev := range devices {
+		s := stats[dev]
+		metrics = c.deltas.Append(metrics, labeled("DiskReadBytes", dev), s.ReadBytes)
+		metrics = c.deltas.Append(metrics, labeled("DiskWriteBytes", dev), s.WriteBytes)
+		metrics = c.deltas.Append(metrics, labeled("DiskReads", dev), s.ReadCount)
+		metrics = c.deltas.Append(metrics, labeled("DiskWrites", dev), s.WriteCount)
+		metrics = c.deltas.Append(metrics, labeled("DiskIOTimeMs", dev), s.IoTime)
+	}
+	return metrics, nil
+}
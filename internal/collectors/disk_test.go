@@ -0,0 +1,65 @@
+package collectors
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/shirou/gopsutil/v4/disk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sbilibin2017/go-yandex-practicum/internal/types"
+)
+
+func TestDiskCollector_Collect(t *testing.T) {
+	c := NewDiskCollector()
+	assert.Equal(t, DiskName, c.Name())
+
+	stats := map[string]disk.IOCountersStat{
+		"sdb": {ReadBytes: 10},
+		"sda": {ReadBytes: 100, WriteBytes: 200, ReadCount: 1, WriteCount: 2, IoTime: 5},
+	}
+	c.ioCounters = func(context.Context) (map[string]disk.IOCountersStat, error) { return stats, nil }
+
+	metrics, err := c.Collect(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, metrics, "the first poll only sets the baseline")
+
+	stats["sda"] = disk.IOCountersStat{ReadBytes: 164, WriteBytes: 200, ReadCount: 3, WriteCount: 2, IoTime: 9}
+	metrics, err = c.Collect(context.Background())
+	require.NoError(t, err)
+	got := deltas(metrics)
+	assert.Equal(t, int64(64), got["DiskReadBytes_sda"])
+	assert.Equal(t, int64(0), got["DiskWriteBytes_sda"])
+	assert.Equal(t, int64(2), got["DiskReads_sda"])
+	assert.Equal(t, int64(4), got["DiskIOTimeMs_sda"])
+	assert.Contains(t, got, "DiskReadBytes_sdb")
+	assert.Equal(t, "DiskReadBytes_sda", metrics[0].ID, "devices are sorted")
+
+	c.ioCounters = func(context.Context) (map[string]disk.IOCountersStat, error) { return nil, errors.New("no /proc") }
+	_, err = c.Collect(context.Background())
+	assert.Error(t, err)
+}
+
+// deltas indexes counter metrics by ID.
+func deltas(metrics []*types.Metrics) map[string]int64 {
+	out := make(map[string]int64)
+	for _, m := range metrics {
+		if m.Delta != nil {
+			out[m.ID] = *m.Delta
+		}
+	}
+	return out
+}
+
+// values indexes gauge metrics by ID.
+func values(metrics []*types.Metrics) map[string]float64 {
+	out := make(map[string]float64)
+	for _, m := range metrics {
+		if m.Value != nil {
+			out[m.ID] = *m.Value
+		}
+	}
+	return out
+}
@@ -0,0 +1,257 @@
+package collectors
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sbilibin2017/go-yandex-practicum/internal/types"
+)
+
+// ExecPrefix starts the collector name of every exec plugin, as in "exec:myapp".
+const ExecPrefix = "exec:"
+
+// execReportMargin is kept from the registry deadline so a timed-out command
+// is killed early enough for its failure to be reported.
+const execReportMargin = 100 * time.Millisecond
+
+// ExecPlugin is a command run by an ExecCollector.
+type ExecPlugin struct {
+	Name    string   // names the collector "exec:<Name>" and the ExecErrors_<Name> counter
+	Command []string // program and arguments, run without a shell
+}
+
+// ParseExecPlugin parses a plugin of the form "name=command arg1 arg2".
+func ParseExecPlugin(spec string) (ExecPlugin, error) {
+	name, command, ok := strings.Cut(spec, "=")
+	name = strings.TrimSpace(name)
+	if !ok || name == "" || strings.TrimSpace(command) == "" {
+		return ExecPlugin{}, fmt.Errorf("invalid exec plugin %q, expected name=command [args]", spec)
+	}
+	for _, r := range name {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' || r == '-') {
+			return ExecPlugin{}, fmt.Errorf("invalid exec plugin name %q: use letters, digits, '_' and '-'", name)
+		}
+	}
+	return ExecPlugin{Name: name, Command: strings.Fields(command)}, nil
+}
+
+// ExecCollector runs a command on every poll and reports the metrics it prints.
+//
+// Each stdout line is either "name type value", where type is gauge or
+// counter, or a JSON object like {"id":"Queue","type":"gauge","value":3};
+// output starting with "[" is read as a JSON array of such objects. Blank
+// lines and lines starting with "#" are ignored.
+//
+// Every poll also reports the ExecErrors_<name> counter: 1 when the command
+// failed, timed out or printed lines that could not be parsed, 0 otherwise.
+// The metrics from valid lines are kept even when others are invalid, but
+// nothing is kept from a command that failed.
+type ExecCollector struct {
+	plugin    ExecPlugin
+	timeout   time.Duration
+	maxOutput int
+}
+
+// ExecCollectorOpt configures an ExecCollector.
+type ExecCollectorOpt func(*ExecCollector)
+
+// WithExecTimeout limits how long the command may run. Defaults to 10 seconds.
+func WithExecTimeout(timeout time.Duration) ExecCollectorOpt {
+	return func(c *ExecCollector) {
+		c.timeout = timeout
+	}
+}
+
+// WithExecMaxOutput limits the size of the command output. Defaults to 1 MiB.
+func WithExecMaxOutput(n int) ExecCollectorOpt {
+	return func(c *ExecCollector) {
+		c.maxOutput = n
+	}
+}
+
+// NewExecCollector creates an ExecCollector running plugin.
+func NewExecCollector(plugin ExecPlugin, opts ...ExecCollectorOpt) *ExecCollector {
+	c := &ExecCollector{
+		plugin:    plugin,
+		timeout:   10 * time.Second,
+		maxOutput: 1 << 20,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Name returns "exec:" followed by the plugin name.
+func (c *ExecCollector) Name() string {
+	return ExecPrefix + c.plugin.Name
+}
+
+// Collect runs the command and parses its output.
+func (c *ExecCollector) Collect(ctx context.Context) ([]*types.Metrics, error) {
+	metrics, err := c.run(ctx)
+	failed := int64(0)
+	if err != nil {
+		failed = 1
+		err = fmt.Errorf("exec plugin %s: %w", c.plugin.Name, err)
+	}
+	return append(metrics, counter(labeled("ExecErrors", c.plugin.Name), failed)), err
+}
+
+func (c *ExecCollector) run(ctx context.Context) ([]*types.Metrics, error) {
+	if len(c.plugin.Command) == 0 {
+		return nil, errors.New("no command")
+	}
+
+	deadline := time.Now().Add(c.timeout)
+	if d, ok := ctx.Deadline(); ok && d.Add(-execReportMargin).Before(deadline) {
+		deadline = d.Add(-execReportMargin)
+	}
+	ctx, cancel := context.WithDeadline(ctx, deadline)
+	defer cancel()
+
+	stdout := &limitedBuffer{limit: c.maxOutput}
+	stderr := &limitedBuffer{limit: 512}
+	cmd := exec.CommandContext(ctx, c.plugin.Command[0], c.plugin.Command[1:]...)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	cmd.WaitDelay = time.Second
+
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("timed out: %w", ctx.Err())
+		}
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%w: %s", err, msg)
+		}
+		return nil, err
+	}
+	if stdout.overflow {
+		return nil, fmt.Errorf("output exceeds %d bytes", c.maxOutput)
+	}
+
+	return parseExecOutput(stdout.Bytes())
+}
+
+// parseExecOutput reads metrics in the line or JSON format, returning the
+// valid ones together with an error describing the invalid lines.
+func parseExecOutput(out []byte) ([]*types.Metrics, error) {
+	trimmed := bytes.TrimSpace(out)
+	if bytes.HasPrefix(trimmed, []byte("[")) {
+		var items []types.Metrics
+		if err := json.Unmarshal(trimmed, &items); err != nil {
+			return nil, fmt.Errorf("invalid JSON output: %w", err)
+		}
+		var metrics []*types.Metrics
+		var errs []error
+		for i := range items {
+			m, err := validExecMetric(items[i])
+			if err != nil {
+				errs = append(errs, fmt.Errorf("item %d: %w", i, err))
+				continue
+			}
+			metrics = append(metrics, m)
+		}
+		return metrics, errors.Join(errs...)
+	}
+
+	var metrics []*types.Metrics
+	var errs []error
+	scanner := bufio.NewScanner(bytes.NewReader(trimmed))
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		m, err := parseExecLine(line)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("line %d: %w", n, err))
+			continue
+		}
+		metrics = append(metrics, m)
+	}
+	if err := scanner.Err(); err != nil {
+		errs = append(errs, err)
+	}
+	return metrics, errors.Join(errs...)
+}
+
+func parseExecLine(line string) (*types.Metrics, error) {
+	if strings.HasPrefix(line, "{") {
+		var m types.Metrics
+		if err := json.Unmarshal([]byte(line), &m); err != nil {
+			return nil, fmt.Errorf("invalid JSON: %w", err)
+		}
+		return validExecMetric(m)
+	}
+
+	fields := strings.Fields(line)
+	if len(fields) != 3 {
+		return nil, fmt.Errorf("expected \"name type value\", got %q", line)
+	}
+	switch fields[1] {
+	case types.Gauge:
+		v, err := strconv.ParseFloat(fields[2], 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid gauge value %q", fields[2])
+		}
+		return gauge(fields[0], v), nil
+	case types.Counter:
+		d, err := strconv.ParseInt(fields[2], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid counter value %q", fields[2])
+		}
+		return counter(fields[0], d), nil
+	default:
+		return nil, fmt.Errorf("unknown metric type %q", fields[1])
+	}
+}
+
+// validExecMetric keeps the ID, type and value of a metric printed as JSON.
+func validExecMetric(m types.Metrics) (*types.Metrics, error) {
+	if m.ID == "" {
+		return nil, errors.New("metric without id")
+	}
+	switch {
+	case m.Type == types.Gauge && m.Value != nil:
+		return gauge(m.ID, *m.Value), nil
+	case m.Type == types.Counter && m.Delta != nil:
+		return counter(m.ID, *m.Delta), nil
+	default:
+		return nil, fmt.Errorf("metric %q needs a gauge value or a counter delta", m.ID)
+	}
+}
+
+// limitedBuffer keeps the first limit bytes written to it and discards the rest.
+// The buffer is a field rather than embedded so io.Copy cannot bypass Write
+// through bytes.Buffer's ReadFrom.
+type limitedBuffer struct {
+	buf      bytes.Buffer
+	limit    int
+	overflow bool
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if room := b.limit - b.buf.Len(); len(p) > room {
+		b.overflow = true
+		b.buf.Write(p[:max(room, 0)])
+		return len(p), nil
+	}
+	return b.buf.Write(p)
+}
+
+func (b *limitedBuffer) Bytes() []byte {
+	return b.buf.Bytes()
+}
+
+func (b *limitedBuffer) String() string {
+	return b.buf.String()
+}
@@ -0,0 +1,140 @@
+package collectors
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseExecPlugin(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    ExecPlugin
+		wantErr bool
+	}{
+		{spec: "queue=/usr/local/bin/queue-stats --json", want: ExecPlugin{Name: "queue", Command: []string{"/usr/local/bin/queue-stats", "--json"}}},
+		{spec: " app-db = check.sh ", want: ExecPlugin{Name: "app-db", Command: []string{"check.sh"}}},
+		{spec: "check.sh", wantErr: true},
+		{spec: "queue=", wantErr: true},
+		{spec: "=check.sh", wantErr: true},
+		{spec: "my queue=check.sh", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			got, err := ParseExecPlugin(tt.spec)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestExecCollector_Collect(t *testing.T) {
+	tests := []struct {
+		name        string
+		script      string
+		opts        []ExecCollectorOpt
+		wantGauges  map[string]float64
+		wantDeltas  map[string]int64
+		wantErr     string
+		wantFailure int64
+	}{
+		{
+			name:       "line format",
+			script:     "echo '# queue stats'; echo 'QueueDepth gauge 12.5'; echo; echo 'JobsDone counter 3'",
+			wantGauges: map[string]float64{"QueueDepth": 12.5},
+			wantDeltas: map[string]int64{"JobsDone": 3},
+		},
+		{
+			name:       "JSON lines",
+			script:     `echo '{"id":"QueueDepth","type":"gauge","value":4}'; echo '{"id":"JobsDone","type":"counter","delta":2}'`,
+			wantGauges: map[string]float64{"QueueDepth": 4},
+			wantDeltas: map[string]int64{"JobsDone": 2},
+		},
+		{
+			name:       "JSON array",
+			script:     `echo '[{"id":"QueueDepth","type":"gauge","value":1},{"id":"JobsDone","type":"counter","delta":5}]'`,
+			wantGauges: map[string]float64{"QueueDepth": 1},
+			wantDeltas: map[string]int64{"JobsDone": 5},
+		},
+		{
+			name:        "invalid lines are counted, valid ones kept",
+			script:      `echo 'QueueDepth gauge 7'; echo 'JobsDone counter 1.5'; echo 'Broken'; echo '{"id":"NoValue","type":"gauge"}'`,
+			wantGauges:  map[string]float64{"QueueDepth": 7},
+			wantErr:     "line 2",
+			wantFailure: 1,
+		},
+		{
+			name:        "failed command",
+			script:      "echo 'QueueDepth gauge 1'; echo 'queue is down' >&2; exit 3",
+			wantErr:     "queue is down",
+			wantFailure: 1,
+		},
+		{
+			name:        "timeout",
+			script:      "sleep 5",
+			opts:        []ExecCollectorOpt{WithExecTimeout(50 * time.Millisecond)},
+			wantErr:     "timed out",
+			wantFailure: 1,
+		},
+		{
+			name:        "output too large",
+			script:      "yes 'QueueDepth gauge 1' | head -c 4096",
+			opts:        []ExecCollectorOpt{WithExecMaxOutput(1024)},
+			wantErr:     "exceeds 1024 bytes",
+			wantFailure: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewExecCollector(ExecPlugin{Name: "queue", Command: []string{"sh", "-c", tt.script}}, tt.opts...)
+			assert.Equal(t, "exec:queue", c.Name())
+
+			start := time.Now()
+			metrics, err := c.Collect(context.Background())
+			assert.Less(t, time.Since(start), 3*time.Second)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			gotDeltas := deltas(metrics)
+			assert.Equal(t, tt.wantFailure, gotDeltas["ExecErrors_queue"])
+			delete(gotDeltas, "ExecErrors_queue")
+			if tt.wantDeltas == nil {
+				tt.wantDeltas = map[string]int64{}
+			}
+			if tt.wantGauges == nil {
+				tt.wantGauges = map[string]float64{}
+			}
+			assert.Equal(t, tt.wantDeltas, gotDeltas)
+			assert.Equal(t, tt.wantGauges, values(metrics))
+		})
+	}
+}
+
+func TestExecCollector_Collect_RegistryDeadline(t *testing.T) {
+	c := NewExecCollector(ExecPlugin{Name: "slow", Command: []string{"sleep", "5"}}, WithExecTimeout(time.Minute))
+
+	// The command is stopped before the registry gives up on the poll, so the failure is reported
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+	metrics, err := c.Collect(ctx)
+	assert.ErrorContains(t, err, "timed out")
+	assert.NoError(t, ctx.Err())
+	assert.Equal(t, map[string]int64{"ExecErrors_slow": 1}, deltas(metrics))
+}
+
+func TestExecCollector_Collect_MissingCommand(t *testing.T) {
+	c := NewExecCollector(ExecPlugin{Name: "gone", Command: []string{"/nonexistent/plugin"}})
+	metrics, err := c.Collect(context.Background())
+	assert.Error(t, err)
+	assert.Equal(t, map[string]int64{"ExecErrors_gone": 1}, deltas(metrics))
+}
@@ -0,0 +1,69 @@
+package collectors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/sbilibin2017/go-yandex-practicum/internal/types"
+	"github.com/shirou/gopsutil/v4/disk"
+)
+
+// FilesystemName is the name of the filesystem usage collector.
+const FilesystemName = "filesystem"
+
+// FilesystemCollector reports usage per mounted physical filesystem:
+// FSTotalBytes_<mount>, FSUsedBytes_<mount>, FSFreeBytes_<mount>,
+// FSUsedPercent_<mount> and FSInodesUsedPercent_<mount>.
+type FilesystemCollector struct {
+	partitions func(ctx context.Context) ([]disk.PartitionStat, error)
+	usage      func(ctx context.Context, path string) (*disk.UsageStat, error)
+}
+
+// NewFilesystemCollector creates a FilesystemCollector.
+func NewFilesystemCollector() *FilesystemCollector {
+	return &FilesystemCollector{
+		partitions: func(ctx context.Context) ([]disk.PartitionStat, error) {
+			return disk.PartitionsWithContext(ctx, false)
+		},
+		usage: disk.UsageWithContext,
+	}
+}
+
+// Name returns FilesystemName.
+func (c *FilesystemCollector) Name() string {
+	return FilesystemName
+}
+
+// Collect reads the usage of every mount point. A mount point that cannot be
+// read is reported as an error without dropping the others.
+func (c *FilesystemCollector) Collect(ctx context.Context) ([]*types.Metrics, error) {
+	partitions, err := c.partitions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list filesystems: %w", err)
+	}
+
+	var metrics []*types.Metrics
+	var errs []error
+	seen := make(map[string]bool)
+	for _, p := range partitions {
+		if seen[p.Mountpoint] {
+			continue
+		}
+		seen[p.Mountpoint] = true
+
+		u, err := c.usage(ctx, p.Mountpoint)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to read usage of %s: %w", p.Mountpoint, err))
+			continue
+		}
+		metrics = append(metrics,
+			gauge(labeled("FSTotalBytes", p.Mountpoint), float64(u.Total)),
+			gauge(labeled("FSUsedBytes", p.Mountpoint), float64(u.Used)),
+			gauge(labeled("FSFreeBytes", p.Mountpoint), float64(u.Free)),
+			gauge(labeled("FSUsedPercent", p.Mountpoint), u.UsedPercent),
+			gauge(labeled("FSInodesUsedPercent", p.Mountpoint), u.InodesUsedPercent),
+		)
+	}
+	return metrics, errors.Join(errs...)
+}
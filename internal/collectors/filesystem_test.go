@@ -0,0 +1,45 @@
+package collectors
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/shirou/gopsutil/v4/disk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilesystemCollector_Collect(t *testing.T) {
+	c := NewFilesystemCollector()
+	assert.Equal(t, FilesystemName, c.Name())
+
+	c.partitions = func(context.Context) ([]disk.PartitionStat, error) {
+		return []disk.PartitionStat{
+			{Device: "/dev/sda1", Mountpoint: "/"},
+			{Device: "/dev/sda1", Mountpoint: "/"},
+			{Device: "/dev/sdb1", Mountpoint: "/var/lib"},
+			{Device: "/dev/sdc1", Mountpoint: "/mnt/gone"},
+		}, nil
+	}
+	c.usage = func(_ context.Context, path string) (*disk.UsageStat, error) {
+		if path == "/mnt/gone" {
+			return nil, errors.New("stale handle")
+		}
+		return &disk.UsageStat{Total: 100, Used: 25, Free: 75, UsedPercent: 25, InodesUsedPercent: 1}, nil
+	}
+
+	metrics, err := c.Collect(context.Background())
+	assert.ErrorContains(t, err, "/mnt/gone")
+	assert.Len(t, metrics, 10, "duplicate mounts are reported once, the others are kept")
+	got := values(metrics)
+	assert.Equal(t, 100.0, got["FSTotalBytes_root"])
+	assert.Equal(t, 25.0, got["FSUsedBytes_var_lib"])
+	assert.Equal(t, 75.0, got["FSFreeBytes_root"])
+	assert.Equal(t, 25.0, got["FSUsedPercent_root"])
+	assert.Equal(t, 1.0, got["FSInodesUsedPercent_var_lib"])
+
+	c.partitions = func(context.Context) ([]disk.PartitionStat, error) { return nil, errors.New("no mtab") }
+	_, err = c.Collect(context.Background())
+	require.Error(t, err)
+}
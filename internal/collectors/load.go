@@ -0,0 +1,40 @@
+package collectors
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sbilibin2017/go-yandex-practicum/internal/types"
+	"github.com/shirou/gopsutil/v4/load"
+)
+
+// LoadName is the name of the load average collector.
+const LoadName = "load"
+
+// LoadCollector reports the Load1, Load5 and Load15 load averages.
+type LoadCollector struct {
+	avg func(ctx context.Context) (*load.AvgStat, error)
+}
+
+// NewLoadCollector creates a LoadCollector.
+func NewLoadCollector() *LoadCollector {
+	return &LoadCollector{avg: load.AvgWithContext}
+}
+
+// Name returns LoadName.
+func (c *LoadCollector) Name() string {
+	return LoadName
+}
+
+// Collect reads the load averages.
+func (c *LoadCollector) Collect(ctx context.Context) ([]*types.Metrics, error) {
+	avg, err := c.avg(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read load average: %w", err)
+	}
+	return []*types.Metrics{
+		gauge("Load1", avg.Load1),
+		gauge("Load5", avg.Load5),
+		gauge("Load15", avg.Load15),
+	}, nil
+}
@@ -0,0 +1,27 @@
+package collectors
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/shirou/gopsutil/v4/load"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadCollector_Collect(t *testing.T) {
+	c := NewLoadCollector()
+	assert.Equal(t, LoadName, c.Name())
+
+	c.avg = func(context.Context) (*load.AvgStat, error) {
+		return &load.AvgStat{Load1: 1.5, Load5: 1, Load15: 0.5}, nil
+	}
+	metrics, err := c.Collect(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]float64{"Load1": 1.5, "Load5": 1, "Load15": 0.5}, values(metrics))
+
+	c.avg = func(context.Context) (*load.AvgStat, error) { return nil, errors.New("unsupported") }
+	_, err = c.Collect(context.Background())
+	assert.Error(t, err)
+}
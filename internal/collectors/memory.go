@@ -0,0 +1,37 @@
+package collectors
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sbilibin2017/go-yandex-practicum/internal/types"
+	"github.com/shirou/gopsutil/v4/mem"
+)
+
+// MemoryName is the name of the host memory collector.
+const MemoryName = "memory"
+
+// MemoryCollector reports the host's TotalMemory and FreeMemory.
+type MemoryCollector struct{}
+
+// NewMemoryCollector creates a MemoryCollector.
+func NewMemoryCollector() *MemoryCollector {
+	return &MemoryCollector{}
+}
+
+// Name returns MemoryName.
+func (c *MemoryCollector) Name() string {
+	return MemoryName
+}
+
+// Collect reads the virtual memory statistics.
+func (c *MemoryCollector) Collect(ctx context.Context) ([]*types.Metrics, error) {
+	vmStat, err := mem.VirtualMemoryWithContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read memory stats: %w", err)
+	}
+	return []*types.Metrics{
+		gauge("TotalMemory", float64(vmStat.Total)),
+		gauge("FreeMemory", float64(vmStat.Free)),
+	}, nil
+}
@@ -0,0 +1,21 @@
+package collectors
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryCollector_Collect(t *testing.T) {
+	c := NewMemoryCollector()
+	assert.Equal(t, MemoryName, c.Name())
+
+	metrics, err := c.Collect(context.Background())
+	require.NoError(t, err)
+	require.Len(t, metrics, 2)
+	assert.Equal(t, "TotalMemory", metrics[0].ID)
+	assert.Positive(t, *metrics[0].Value)
+	assert.Equal(t, "FreeMemory", metrics[1].ID)
+}
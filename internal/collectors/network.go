@@ -0,0 +1,56 @@
+package collectors
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sbilibin2017/go-yandex-practicum/internal/types"
+	"github.com/shirou/gopsutil/v4/net"
+)
+
+// NetworkName is the name of the network interface collector.
+const NetworkName = "network"
+
+// NetworkCollector reports counters per network interface: NetBytesSent_<if>,
+// NetBytesRecv_<if>, NetPacketsSent_<if>, NetPacketsRecv_<if>, NetErrorsIn_<if>,
+// NetErrorsOut_<if>, NetDropsIn_<if> and NetDropsOut_<if>.
+type NetworkCollector struct {
+	ioCounters func(ctx context.Context) ([]net.IOCountersStat, error)
+	deltas     *counterDeltas
+}
+
+// NewNetworkCollector creates a NetworkCollector.
+func NewNetworkCollector() *NetworkCollector {
+	return &NetworkCollector{
+		ioCounters: func(ctx context.Context) ([]net.IOCountersStat, error) {
+			return net.IOCountersWithContext(ctx, true)
+		},
+		deltas: newCounterDeltas(),
+	}
+}
+
+// Name returns NetworkName.
+func (c *NetworkCollector) Name() string {
+	return NetworkName
+}
+
+// Collect reads the interface counters.
+func (c *NetworkCollector) Collect(ctx context.Context) ([]*types.Metrics, error) {
+	stats, err := c.ioCounters(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read network counters: %w", err)
+	}
+
+	var metrics []*types.Metrics
+	for _, s := range stats {
+		metrics = c.deltas.Append(metrics, labeled("NetBytesSent", s.Name), s.BytesSent)
+		metrics = c.deltas.Append(metrics, labeled("NetBytesRecv", s.Name), s.BytesRecv)
+		metrics = c.deltas.Append(metrics, labeled("NetPacketsSent", s.Name), s.PacketsSent)
+		metrics = c.deltas.Append(metrics, labeled("NetPacketsRecv", s.Name), s.PacketsRecv)
+		metrics = c.deltas.Append(metrics, labeled("NetErrorsIn", s.Name), s.Errin)
+		metrics = c.deltas.Append(metrics, labeled("NetErrorsOut", s.Name), s.Errout)
+		metrics = c.deltas.Append(metrics, labeled("NetDropsIn", s.Name), s.Dropin)
+		metrics = c.deltas.Append(metrics, labeled("NetDropsOut", s.Name), s.Dropout)
+	}
+	return metrics, nil
+}
@@ -0,0 +1,33 @@
+package collectors
+
+import (
+	"context"
+	"testing"
+
+	"github.com/shirou/gopsutil/v4/net"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNetworkCollector_Collect(t *testing.T) {
+	c := NewNetworkCollector()
+	assert.Equal(t, NetworkName, c.Name())
+
+	stats := []net.IOCountersStat{{Name: "eth0", BytesSent: 1000, BytesRecv: 2000, Errin: 1}}
+	c.ioCounters = func(context.Context) ([]net.IOCountersStat, error) { return stats, nil }
+
+	metrics, err := c.Collect(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, metrics)
+
+	stats[0] = net.IOCountersStat{Name: "eth0", BytesSent: 1500, BytesRecv: 2100, PacketsSent: 3, Errin: 2}
+	metrics, err = c.Collect(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, metrics, 8)
+	got := deltas(metrics)
+	assert.Equal(t, int64(500), got["NetBytesSent_eth0"])
+	assert.Equal(t, int64(100), got["NetBytesRecv_eth0"])
+	assert.Equal(t, int64(3), got["NetPacketsSent_eth0"])
+	assert.Equal(t, int64(1), got["NetErrorsIn_eth0"])
+	assert.Equal(t, int64(0), got["NetDropsOut_eth0"])
+}
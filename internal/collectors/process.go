@@ -0,0 +1,405 @@
+package collectors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sbilibin2017/go-yandex-practicum/internal/types"
+	"github.com/shirou/gopsutil/v4/process"
+)
+
+// ProcessName is the name of the per-process collector.
+const ProcessName = "process"
+
+// ProcessTarget selects the processes to report: those whose name matches a
+// glob pattern, the one whose PID is in a PID file, or a fixed PID.
+type ProcessTarget struct {
+	Name    string // glob pattern matched against the process name
+	PIDFile string // path of a file holding the PID
+	PID     int32
+}
+
+// label names the target in ProcessCount_<label> and the metrics of the
+// processes it matches.
+func (t ProcessTarget) label() string {
+	switch {
+	case t.Name != "":
+		return t.Name
+	case t.PIDFile != "":
+		return strings.TrimSuffix(filepath.Base(t.PIDFile), filepath.Ext(t.PIDFile))
+	default:
+		return strconv.Itoa(int(t.PID))
+	}
+}
+
+// ParseProcessTargets parses comma-separated targets of the form
+// "name:<glob>", "pidfile:<path>" or "pid:<number>".
+func ParseProcessTargets(s string) ([]ProcessTarget, error) {
+	var targets []ProcessTarget
+	for _, spec := range strings.Split(s, ",") {
+		spec = strings.TrimSpace(spec)
+		if spec == "" {
+			continue
+		}
+		kind, value, ok := strings.Cut(spec, ":")
+		if !ok || value == "" {
+			return nil, fmt.Errorf("invalid process target %q, expected name:<glob>, pidfile:<path> or pid:<number>", spec)
+		}
+		switch kind {
+		case "name":
+			if _, err := path.Match(value, ""); err != nil {
+				return nil, fmt.Errorf("invalid process name pattern %q: %w", value, err)
+			}
+			targets = append(targets, ProcessTarget{Name: value})
+		case "pidfile":
+			targets = append(targets, ProcessTarget{PIDFile: value})
+		case "pid":
+			pid, err := strconv.ParseInt(value, 10, 32)
+			if err != nil || pid <= 0 {
+				return nil, fmt.Errorf("invalid process target %q: expected a positive PID", spec)
+			}
+			targets = append(targets, ProcessTarget{PID: int32(pid)})
+		default:
+			return nil, fmt.Errorf("unknown process target kind %q in %q", kind, spec)
+		}
+	}
+	return targets, nil
+}
+
+// processInfo is one reading of a process.
+type processInfo struct {
+	Name       string
+	RSS        uint64
+	CPUSeconds float64 // user and system time since the process started
+	NumFDs     int32   // -1 when the agent may not read it
+	NumThreads int32
+	CreateTime time.Time
+}
+
+// processSource reads the process table.
+type processSource interface {
+	PIDs(ctx context.Context) ([]int32, error)
+	Name(ctx context.Context, pid int32) (string, error)
+	Inspect(ctx context.Context, pid int32) (*processInfo, error)
+}
+
+// errProcessGone reports a process that exited between two reads.
+var errProcessGone = errors.New("process is not running")
+
+// cpuReading is the CPU time of a process at one poll.
+type cpuReading struct {
+	seconds float64
+	at      time.Time
+}
+
+// processKey tells apart processes that reuse a PID.
+type processKey struct {
+	pid     int32
+	created time.Time
+}
+
+// ProcessCollector reports, for every process matched by its targets,
+// ProcessRSSBytes_<target>, ProcessCPUPercent_<target> (100 is one full CPU),
+// ProcessOpenFDs_<target>, ProcessThreads_<target> and
+// ProcessUptimeSeconds_<target>, along with ProcessCount_<target>, the number
+// of processes each target matched. Further processes a target matches at the
+// same time are reported as <metric>_<target>_<n>: a process keeps its ordinal
+// n while it runs and ordinals freed by exited processes are reused, so the
+// metric IDs do not grow as processes restart. Processes may start and exit
+// between polls; the CPU percentage of a process appears from its second poll.
+type ProcessCollector struct {
+	targets []ProcessTarget
+	source  processSource
+	now     func() time.Time
+
+	mu  sync.Mutex
+	cpu map[processKey]cpuReading
+	// slots holds, per target, the process reported under each ordinal
+	slots [][]processKey
+}
+
+// ProcessCollectorOpt configures a ProcessCollector.
+type ProcessCollectorOpt func(*ProcessCollector)
+
+// WithProcessTargets adds the processes to report.
+func WithProcessTargets(targets ...ProcessTarget) ProcessCollectorOpt {
+	return func(c *ProcessCollector) {
+		c.targets = append(c.targets, targets...)
+	}
+}
+
+// WithProcessClock sets the clock CPU usage and uptime are measured against.
+func WithProcessClock(now func() time.Time) ProcessCollectorOpt {
+	return func(c *ProcessCollector) {
+		c.now = now
+	}
+}
+
+// NewProcessCollector creates a ProcessCollector. Without targets it reports nothing.
+func NewProcessCollector(opts ...ProcessCollectorOpt) *ProcessCollector {
+	c := &ProcessCollector{
+		source: gopsutilProcesses{},
+		now:    time.Now,
+		cpu:    make(map[processKey]cpuReading),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	c.slots = make([][]processKey, len(c.targets))
+	return c
+}
+
+// Name returns ProcessName.
+func (c *ProcessCollector) Name() string {
+	return ProcessName
+}
+
+// Collect reads the matched processes.
+func (c *ProcessCollector) Collect(ctx context.Context) ([]*types.Metrics, error) {
+	if len(c.targets) == 0 {
+		return nil, nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var metrics []*types.Metrics
+	var errs []error
+	now := c.now()
+	seen := make(map[processKey]bool)
+	reported := make(map[int32]bool)
+
+	matches, err := c.match(ctx)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	for i, target := range c.targets {
+		count := 0
+		var keys []processKey
+		var infos []*processInfo
+		for _, pid := range matches[i] {
+			info, err := c.source.Inspect(ctx, pid)
+			if errors.Is(err, errProcessGone) {
+				continue
+			}
+			if err != nil {
+				errs = append(errs, fmt.Errorf("failed to read process %d: %w", pid, err))
+				continue
+			}
+			count++
+			key := processKey{pid: pid, created: info.CreateTime}
+			seen[key] = true
+			if reported[pid] {
+				continue
+			}
+			reported[pid] = true
+			keys = append(keys, key)
+			infos = append(infos, info)
+		}
+		for j, n := range c.assignSlots(i, keys) {
+			metrics = c.appendProcess(metrics, target.label(), n, keys[j], infos[j], now)
+		}
+		metrics = append(metrics, gauge(labeled("ProcessCount", target.label()), float64(count)))
+	}
+
+	// Forget processes that exited
+	for key := range c.cpu {
+		if !seen[key] {
+			delete(c.cpu, key)
+		}
+	}
+	return metrics, errors.Join(errs...)
+}
+
+// match returns the PIDs each target selects, in target order.
+func (c *ProcessCollector) match(ctx context.Context) ([][]int32, error) {
+	matches := make([][]int32, len(c.targets))
+	var errs []error
+
+	var names map[int32]string
+	for i, target := range c.targets {
+		switch {
+		case target.Name != "":
+			if names == nil {
+				var err error
+				if names, err = c.processNames(ctx); err != nil {
+					errs = append(errs, err)
+					continue
+				}
+			}
+			for pid, name := range names {
+				if ok, _ := path.Match(target.Name, name); ok {
+					matches[i] = append(matches[i], pid)
+				}
+			}
+		case target.PIDFile != "":
+			pid, err := readPIDFile(target.PIDFile)
+			if errors.Is(err, os.ErrNotExist) {
+				continue // the daemon is not running
+			}
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			matches[i] = []int32{pid}
+		default:
+			matches[i] = []int32{target.PID}
+		}
+		slices.Sort(matches[i])
+	}
+	return matches, errors.Join(errs...)
+}
+
+func (c *ProcessCollector) processNames(ctx context.Context) (map[int32]string, error) {
+	pids, err := c.source.PIDs(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list processes: %w", err)
+	}
+	names := make(map[int32]string, len(pids))
+	for _, pid := range pids {
+		// Processes that exit while the table is read are skipped
+		if name, err := c.source.Name(ctx, pid); err == nil {
+			names[pid] = name
+		}
+	}
+	return names, nil
+}
+
+// assignSlots returns the ordinals of the processes a target reports. A
+// process keeps the ordinal it got first; a new one takes the lowest ordinal
+// no running process holds.
+func (c *ProcessCollector) assignSlots(target int, keys []processKey) []int {
+	slots := c.slots[target]
+	for n, key := range slots {
+		if !slices.Contains(keys, key) {
+			slots[n] = processKey{}
+		}
+	}
+
+	ordinals := make([]int, len(keys))
+	for i, key := range keys {
+		n := slices.Index(slots, key)
+		if n < 0 {
+			if n = slices.Index(slots, processKey{}); n < 0 {
+				n = len(slots)
+				slots = append(slots, processKey{})
+			}
+			slots[n] = key
+		}
+		ordinals[i] = n
+	}
+
+	for len(slots) > 0 && slots[len(slots)-1] == (processKey{}) {
+		slots = slots[:len(slots)-1]
+	}
+	c.slots[target] = slots
+	return ordinals
+}
+
+func (c *ProcessCollector) appendProcess(
+	metrics []*types.Metrics, label string, ordinal int, key processKey, info *processInfo, now time.Time,
+) []*types.Metrics {
+	id := func(name string) string {
+		if ordinal == 0 {
+			return labeled(name, label)
+		}
+		return labeled(name, label) + "_" + strconv.Itoa(ordinal)
+	}
+
+	metrics = append(metrics,
+		gauge(id("ProcessRSSBytes"), float64(info.RSS)),
+		gauge(id("ProcessThreads"), float64(info.NumThreads)),
+		gauge(id("ProcessUptimeSeconds"), now.Sub(info.CreateTime).Seconds()),
+	)
+	if info.NumFDs >= 0 {
+		metrics = append(metrics, gauge(id("ProcessOpenFDs"), float64(info.NumFDs)))
+	}
+
+	if prev, ok := c.cpu[key]; ok && now.After(prev.at) && info.CPUSeconds >= prev.seconds {
+		used := percent(info.CPUSeconds-prev.seconds, now.Sub(prev.at).Seconds())
+		metrics = append(metrics, gauge(id("ProcessCPUPercent"), used))
+	}
+	c.cpu[key] = cpuReading{seconds: info.CPUSeconds, at: now}
+	return metrics
+}
+
+func readPIDFile(path string) (int32, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read PID file: %w", err)
+	}
+	pid, err := strconv.ParseInt(strings.TrimSpace(string(data)), 10, 32)
+	if err != nil || pid <= 0 {
+		return 0, fmt.Errorf("invalid PID in %s", path)
+	}
+	return int32(pid), nil
+}
+
+// gopsutilProcesses reads the process table through gopsutil.
+type gopsutilProcesses struct{}
+
+func (gopsutilProcesses) PIDs(ctx context.Context) ([]int32, error) {
+	return process.PidsWithContext(ctx)
+}
+
+func (gopsutilProcesses) Name(ctx context.Context, pid int32) (string, error) {
+	p, err := process.NewProcessWithContext(ctx, pid)
+	if err != nil {
+		return "", err
+	}
+	return p.NameWithContext(ctx)
+}
+
+func (gopsutilProcesses) Inspect(ctx context.Context, pid int32) (*processInfo, error) {
+	p, err := process.NewProcessWithContext(ctx, pid)
+	if errors.Is(err, process.ErrorProcessNotRunning) {
+		return nil, errProcessGone
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	info := &processInfo{NumFDs: -1}
+	if info.Name, err = p.NameWithContext(ctx); err != nil {
+		return nil, processError(err)
+	}
+	created, err := p.CreateTimeWithContext(ctx)
+	if err != nil {
+		return nil, processError(err)
+	}
+	info.CreateTime = time.UnixMilli(created)
+	mem, err := p.MemoryInfoWithContext(ctx)
+	if err != nil {
+		return nil, processError(err)
+	}
+	info.RSS = mem.RSS
+	times, err := p.TimesWithContext(ctx)
+	if err != nil {
+		return nil, processError(err)
+	}
+	info.CPUSeconds = times.User + times.System
+	if info.NumThreads, err = p.NumThreadsWithContext(ctx); err != nil {
+		return nil, processError(err)
+	}
+	// Reading the descriptors of another user's process needs privileges
+	if fds, err := p.NumFDsWithContext(ctx); err == nil {
+		info.NumFDs = fds
+	}
+	return info, nil
+}
+
+// processError maps the errors of a process that exited mid-read to errProcessGone.
+func processError(err error) error {
+	if errors.Is(err, os.ErrNotExist) || errors.Is(err, process.ErrorProcessNotRunning) {
+		return errProcessGone
+	}
+	return err
+}
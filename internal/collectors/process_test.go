@@ -0,0 +1,175 @@
+package collectors
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeProcesses is a process table the test edits between polls.
+type fakeProcesses map[int32]*processInfo
+
+func (f fakeProcesses) PIDs(context.Context) ([]int32, error) {
+	var pids []int32
+	for pid := range f {
+		pids = append(pids, pid)
+	}
+	return pids, nil
+}
+
+func (f fakeProcesses) Name(_ context.Context, pid int32) (string, error) {
+	if p, ok := f[pid]; ok {
+		return p.Name, nil
+	}
+	return "", errProcessGone
+}
+
+func (f fakeProcesses) Inspect(_ context.Context, pid int32) (*processInfo, error) {
+	if p, ok := f[pid]; ok {
+		info := *p
+		return &info, nil
+	}
+	return nil, errProcessGone
+}
+
+func TestParseProcessTargets(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    []ProcessTarget
+		wantErr bool
+	}{
+		{in: ""},
+		{
+			in:   "name:postgres*, pidfile:/run/nginx.pid,pid:1",
+			want: []ProcessTarget{{Name: "postgres*"}, {PIDFile: "/run/nginx.pid"}, {PID: 1}},
+		},
+		{in: "postgres", wantErr: true},
+		{in: "name:", wantErr: true},
+		{in: "name:[", wantErr: true},
+		{in: "pid:-1", wantErr: true},
+		{in: "pid:abc", wantErr: true},
+		{in: "user:root", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseProcessTargets(tt.in)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestProcessCollector_Collect(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	started := now.Add(-time.Hour)
+
+	pidFile := filepath.Join(t.TempDir(), "nginx.pid")
+	require.NoError(t, os.WriteFile(pidFile, []byte("300\n"), 0o644))
+
+	table := fakeProcesses{
+		100: {Name: "postgres", RSS: 1 << 20, CPUSeconds: 10, NumFDs: 20, NumThreads: 4, CreateTime: started},
+		101: {Name: "postgres", RSS: 2 << 20, CPUSeconds: 5, NumFDs: -1, NumThreads: 1, CreateTime: started},
+		200: {Name: "bash", CreateTime: started},
+		300: {Name: "nginx", RSS: 512, CPUSeconds: 1, NumFDs: 8, NumThreads: 2, CreateTime: started},
+	}
+	c := NewProcessCollector(
+		WithProcessTargets(ProcessTarget{Name: "postgres*"}, ProcessTarget{PIDFile: pidFile}, ProcessTarget{PID: 999}),
+		WithProcessClock(func() time.Time { return now }),
+	)
+	c.source = table
+	assert.Equal(t, ProcessName, c.Name())
+
+	metrics, err := c.Collect(context.Background())
+	require.NoError(t, err)
+	got := values(metrics)
+	assert.Equal(t, 2.0, got["ProcessCount_postgres"])
+	assert.Equal(t, 1.0, got["ProcessCount_nginx"])
+	assert.Equal(t, 0.0, got["ProcessCount_999"], "a missing PID is counted as no match")
+	assert.Equal(t, float64(1<<20), got["ProcessRSSBytes_postgres"])
+	assert.Equal(t, 20.0, got["ProcessOpenFDs_postgres"])
+	assert.Equal(t, float64(2<<20), got["ProcessRSSBytes_postgres_1"])
+	assert.NotContains(t, got, "ProcessOpenFDs_postgres_1", "unreadable descriptors are not reported")
+	assert.Equal(t, 4.0, got["ProcessThreads_postgres"])
+	assert.Equal(t, 3600.0, got["ProcessUptimeSeconds_nginx"])
+	assert.NotContains(t, got, "ProcessRSSBytes_bash")
+	assert.NotContains(t, got, "ProcessCPUPercent_postgres", "CPU usage needs two polls")
+
+	// Ten seconds later one backend exits, one is restarted under the same PID
+	// and a new one starts
+	now = now.Add(10 * time.Second)
+	table[100].CPUSeconds = 15
+	delete(table, 300)
+	table[101] = &processInfo{Name: "postgres", CPUSeconds: 0.5, NumThreads: 1, CreateTime: now.Add(-time.Second)}
+	table[102] = &processInfo{Name: "postgres", CPUSeconds: 0, NumThreads: 1, CreateTime: now}
+
+	metrics, err = c.Collect(context.Background())
+	require.NoError(t, err)
+	got = values(metrics)
+	assert.Equal(t, 3.0, got["ProcessCount_postgres"])
+	assert.Equal(t, 0.0, got["ProcessCount_nginx"])
+	assert.InDelta(t, 50, got["ProcessCPUPercent_postgres"], 1e-9)
+	assert.Equal(t, 1.0, got["ProcessThreads_postgres_1"], "the restarted backend takes the free ordinal")
+	assert.NotContains(t, got, "ProcessCPUPercent_postgres_1", "a reused PID starts over")
+	assert.Equal(t, 1.0, got["ProcessThreads_postgres_2"])
+	assert.NotContains(t, got, "ProcessCPUPercent_postgres_2")
+	assert.NotContains(t, got, "ProcessRSSBytes_nginx")
+	assert.Len(t, c.cpu, 3, "exited processes are forgotten")
+
+	// A backend exits and another one starts: the new one reuses its ordinal
+	// while the others keep theirs
+	now = now.Add(10 * time.Second)
+	delete(table, 101)
+	table[102].NumThreads = 3
+	table[103] = &processInfo{Name: "postgres", NumThreads: 5, CreateTime: now}
+
+	metrics, err = c.Collect(context.Background())
+	require.NoError(t, err)
+	got = values(metrics)
+	assert.Equal(t, 3.0, got["ProcessCount_postgres"])
+	assert.Equal(t, 4.0, got["ProcessThreads_postgres"])
+	assert.Equal(t, 5.0, got["ProcessThreads_postgres_1"])
+	assert.Equal(t, 3.0, got["ProcessThreads_postgres_2"])
+	assert.NotContains(t, got, "ProcessThreads_postgres_3")
+}
+
+func TestProcessCollector_Collect_Errors(t *testing.T) {
+	badPIDFile := filepath.Join(t.TempDir(), "bad.pid")
+	require.NoError(t, os.WriteFile(badPIDFile, []byte("not a pid"), 0o644))
+
+	c := NewProcessCollector(WithProcessTargets(
+		ProcessTarget{PIDFile: badPIDFile},
+		ProcessTarget{PIDFile: filepath.Join(t.TempDir(), "stopped.pid")},
+	))
+	c.source = fakeProcesses{}
+
+	metrics, err := c.Collect(context.Background())
+	assert.ErrorContains(t, err, "bad.pid")
+	assert.Equal(t, map[string]float64{"ProcessCount_bad": 0, "ProcessCount_stopped": 0}, values(metrics))
+}
+
+func TestProcessCollector_Collect_NoTargets(t *testing.T) {
+	metrics, err := NewProcessCollector().Collect(context.Background())
+	assert.NoError(t, err)
+	assert.Empty(t, metrics)
+}
+
+func TestProcessCollector_Collect_Self(t *testing.T) {
+	c := NewProcessCollector(WithProcessTargets(ProcessTarget{PID: int32(os.Getpid())}))
+	metrics, err := c.Collect(context.Background())
+	require.NoError(t, err)
+
+	pid := strconv.Itoa(os.Getpid())
+	got := values(metrics)
+	assert.Equal(t, 1.0, got["ProcessCount_"+pid])
+	assert.Positive(t, got["ProcessRSSBytes_"+pid])
+}
@@ -0,0 +1,47 @@
+package collectors
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sbilibin2017/go-yandex-practicum/internal/types"
+	"github.com/shirou/gopsutil/v4/load"
+)
+
+// ProcessesName is the name of the process count collector.
+const ProcessesName = "processes"
+
+// ProcessesCollector reports the ProcessCount, ProcessesRunning and
+// ProcessesBlocked gauges and the ProcessesCreated counter.
+type ProcessesCollector struct {
+	misc   func(ctx context.Context) (*load.MiscStat, error)
+	deltas *counterDeltas
+}
+
+// NewProcessesCollector creates a ProcessesCollector.
+func NewProcessesCollector() *ProcessesCollector {
+	return &ProcessesCollector{
+		misc:   load.MiscWithContext,
+		deltas: newCounterDeltas(),
+	}
+}
+
+// Name returns ProcessesName.
+func (c *ProcessesCollector) Name() string {
+	return ProcessesName
+}
+
+// Collect reads the process statistics.
+func (c *ProcessesCollector) Collect(ctx context.Context) ([]*types.Metrics, error) {
+	s, err := c.misc(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read process stats: %w", err)
+	}
+	metrics := []*types.Metrics{
+		gauge("ProcessCount", float64(s.ProcsTotal)),
+		gauge("ProcessesRunning", float64(s.ProcsRunning)),
+		gauge("ProcessesBlocked", float64(s.ProcsBlocked)),
+	}
+	metrics = c.deltas.Append(metrics, "ProcessesCreated", uint64(max(s.ProcsCreated, 0)))
+	return metrics, nil
+}
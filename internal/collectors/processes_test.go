@@ -0,0 +1,27 @@
+package collectors
+
+import (
+	"context"
+	"testing"
+
+	"github.com/shirou/gopsutil/v4/load"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProcessesCollector_Collect(t *testing.T) {
+	c := NewProcessesCollector()
+	assert.Equal(t, ProcessesName, c.Name())
+
+	stat := &load.MiscStat{ProcsTotal: 120, ProcsRunning: 2, ProcsBlocked: 1, ProcsCreated: 5000}
+	c.misc = func(context.Context) (*load.MiscStat, error) { return stat, nil }
+
+	metrics, err := c.Collect(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]float64{"ProcessCount": 120, "ProcessesRunning": 2, "ProcessesBlocked": 1}, values(metrics))
+
+	stat.ProcsCreated = 5007
+	metrics, err = c.Collect(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int64{"ProcessesCreated": 7}, deltas(metrics))
+}
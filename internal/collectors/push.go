@@ -0,0 +1,101 @@
+package collectors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/sbilibin2017/go-yandex-practicum/internal/types"
+)
+
+// PushName is the name of the collector of metrics pushed to the agent.
+const PushName = "push"
+
+// ErrPushBufferFull is returned when pushed metrics would not fit in the buffer.
+var ErrPushBufferFull = errors.New("push buffer is full")
+
+// PushCollector buffers metrics that local applications push to the agent,
+// through its HTTP endpoint or StatsD listener, until the next poll hands them
+// to the reporting pipeline. Pushed counters are summed and gauges reduced
+// like polled ones.
+//
+// Only plain updates are accepted: a metric carrying an operation other than
+// its type's default cannot be merged with the others before reporting.
+type PushCollector struct {
+	maxBuffer int
+
+	mu      sync.Mutex
+	pending []*types.Metrics
+}
+
+// PushCollectorOpt configures a PushCollector.
+type PushCollectorOpt func(*PushCollector)
+
+// WithPushMaxBuffer limits how many pushed metrics are kept between polls. Defaults to 10000.
+func WithPushMaxBuffer(n int) PushCollectorOpt {
+	return func(c *PushCollector) {
+		c.maxBuffer = n
+	}
+}
+
+// NewPushCollector creates an empty PushCollector.
+func NewPushCollector(opts ...PushCollectorOpt) *PushCollector {
+	c := &PushCollector{maxBuffer: 10000}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Name returns PushName.
+func (c *PushCollector) Name() string {
+	return PushName
+}
+
+// Updates buffers the metrics and returns them, so the collector can back the
+// server's update handlers. Either all metrics are buffered or none is.
+func (c *PushCollector) Updates(ctx context.Context, metrics []*types.Metrics) ([]*types.Metrics, error) {
+	accepted := make([]*types.Metrics, 0, len(metrics))
+	for _, m := range metrics {
+		p, err := pushedMetric(m)
+		if err != nil {
+			return nil, err
+		}
+		accepted = append(accepted, p)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.pending)+len(accepted) > c.maxBuffer {
+		return nil, ErrPushBufferFull
+	}
+	c.pending = append(c.pending, accepted...)
+	return metrics, nil
+}
+
+// Collect returns the metrics pushed since the previous poll.
+func (c *PushCollector) Collect(ctx context.Context) ([]*types.Metrics, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	metrics := c.pending
+	c.pending = nil
+	return metrics, nil
+}
+
+// pushedMetric copies the ID, type and value of a pushed metric.
+func pushedMetric(m *types.Metrics) (*types.Metrics, error) {
+	if m == nil || m.ID == "" {
+		return nil, errors.New("metric without id")
+	}
+	switch {
+	case m.Type == types.Gauge && m.Value != nil && (m.Op == "" || m.Op == types.OpSet):
+		return gauge(m.ID, *m.Value), nil
+	case m.Type == types.Counter && m.Delta != nil && (m.Op == "" || m.Op == types.OpAdd):
+		return counter(m.ID, *m.Delta), nil
+	case m.Op != "":
+		return nil, fmt.Errorf("metric %q: operation %q is not supported by the agent", m.ID, m.Op)
+	default:
+		return nil, fmt.Errorf("metric %q needs a gauge value or a counter delta", m.ID)
+	}
+}
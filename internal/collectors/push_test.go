@@ -0,0 +1,94 @@
+package collectors
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sbilibin2017/go-yandex-practicum/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPushCollector_Collect(t *testing.T) {
+	c := NewPushCollector()
+	ctx := context.Background()
+
+	version := int64(3)
+	pushed := []*types.Metrics{
+		{ID: "QueueDepth", Type: types.Gauge, Value: ptrFloat(4), Op: types.OpSet, Version: version},
+		{ID: "JobsDone", Type: types.Counter, Delta: ptrInt(2)},
+	}
+	got, err := c.Updates(ctx, pushed)
+	require.NoError(t, err)
+	assert.Equal(t, pushed, got)
+	_, err = c.Updates(ctx, []*types.Metrics{{ID: "JobsDone", Type: types.Counter, Delta: ptrInt(5), Op: types.OpAdd}})
+	require.NoError(t, err)
+
+	metrics, err := c.Collect(ctx)
+	require.NoError(t, err)
+	require.Len(t, metrics, 3)
+	assert.Equal(t, map[string]float64{"QueueDepth": 4}, values(metrics))
+	assert.Equal(t, int64(2), *metrics[1].Delta)
+	assert.Equal(t, int64(5), *metrics[2].Delta)
+	assert.Empty(t, metrics[0].Op)
+	assert.Zero(t, metrics[0].Version)
+
+	// The buffer is drained by every poll
+	metrics, err = c.Collect(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, metrics)
+}
+
+func TestPushCollector_Updates_Invalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		metric *types.Metrics
+	}{
+		{name: "no id", metric: &types.Metrics{Type: types.Gauge, Value: ptrFloat(1)}},
+		{name: "gauge without value", metric: &types.Metrics{ID: "Queue", Type: types.Gauge}},
+		{name: "counter without delta", metric: &types.Metrics{ID: "Jobs", Type: types.Counter}},
+		{name: "unknown type", metric: &types.Metrics{ID: "Jobs", Type: "histogram", Value: ptrFloat(1)}},
+		{name: "operation", metric: &types.Metrics{ID: "Queue", Type: types.Gauge, Value: ptrFloat(1), Op: types.OpMax}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewPushCollector()
+			valid := &types.Metrics{ID: "Valid", Type: types.Gauge, Value: ptrFloat(1)}
+			_, err := c.Updates(context.Background(), []*types.Metrics{valid, tt.metric})
+			assert.Error(t, err)
+
+			// A rejected batch is dropped as a whole
+			metrics, err := c.Collect(context.Background())
+			require.NoError(t, err)
+			assert.Empty(t, metrics)
+		})
+	}
+}
+
+func TestPushCollector_Updates_BufferFull(t *testing.T) {
+	c := NewPushCollector(WithPushMaxBuffer(2))
+	ctx := context.Background()
+
+	_, err := c.Updates(ctx, []*types.Metrics{{ID: "A", Type: types.Counter, Delta: ptrInt(1)}})
+	require.NoError(t, err)
+	_, err = c.Updates(ctx, []*types.Metrics{
+		{ID: "B", Type: types.Counter, Delta: ptrInt(1)},
+		{ID: "C", Type: types.Counter, Delta: ptrInt(1)},
+	})
+	assert.ErrorIs(t, err, ErrPushBufferFull)
+
+	metrics, err := c.Collect(ctx)
+	require.NoError(t, err)
+	assert.Len(t, metrics, 1)
+
+	// Polling frees the buffer
+	_, err = c.Updates(ctx, []*types.Metrics{
+		{ID: "B", Type: types.Counter, Delta: ptrInt(1)},
+		{ID: "C", Type: types.Counter, Delta: ptrInt(1)},
+	})
+	assert.NoError(t, err)
+}
+
+func ptrFloat(v float64) *float64 { return &v }
+
+func ptrInt(v int64) *int64 { return &v }
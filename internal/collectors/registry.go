@@ -0,0 +1,351 @@
+// Package collectors holds the agent's metric sources. Each source is a named
+// Collector polled by a Registry on its own interval, so a failing or slow
+// source does not hold up the others.
+package collectors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sbilibin2017/go-yandex-practicum/internal/logger"
+	"github.com/sbilibin2017/go-yandex-practicum/internal/types"
+)
+
+// Collector gathers one group of metrics.
+type Collector interface {
+	// Name identifies the collector in the agent configuration.
+	Name() string
+	// Collect returns the current metrics. It should return when ctx is done.
+	Collect(ctx context.Context) ([]*types.Metrics, error)
+}
+
+// errBusy is returned when a collector is polled while its previous call
+// is still running after timing out.
+var errBusy = errors.New("previous collection still running")
+
+// entry is a registered collector with its polling settings.
+type entry struct {
+	collector Collector
+	enabled   bool
+	interval  time.Duration
+	timeout   time.Duration
+
+	busy atomic.Bool
+}
+
+// Opt configures how a registered collector is polled.
+type Opt func(*entry)
+
+// WithEnabled enables or disables polling the collector.
+func WithEnabled(enabled bool) Opt {
+	return func(e *entry) {
+		e.enabled = enabled
+	}
+}
+
+// WithInterval sets how often the collector is polled.
+func WithInterval(interval time.Duration) Opt {
+	return func(e *entry) {
+		e.interval = interval
+	}
+}
+
+// WithTimeout limits how long a single poll may take. Zero means the poll interval.
+func WithTimeout(timeout time.Duration) Opt {
+	return func(e *entry) {
+		e.timeout = timeout
+	}
+}
+
+// Registry polls a set of named collectors.
+type Registry struct {
+	mu      sync.Mutex
+	entries []*entry
+}
+
+// NewRegistry creates an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// Register adds an enabled collector, polled every second unless opts say otherwise.
+func (r *Registry) Register(c Collector, opts ...Opt) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.find(c.Name()) != nil {
+		return fmt.Errorf("collector %q is already registered", c.Name())
+	}
+	e := &entry{collector: c, enabled: true, interval: time.Second}
+	for _, opt := range opts {
+		opt(e)
+	}
+	r.entries = append(r.entries, e)
+	return nil
+}
+
+// Configure changes the polling settings of a registered collector.
+func (r *Registry) Configure(name string, opts ...Opt) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	e := r.find(name)
+	if e == nil {
+		return fmt.Errorf("unknown collector %q, available: %s", name, strings.Join(r.names(), ", "))
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return nil
+}
+
+// EnableOnly enables the named collectors and disables all others.
+// An empty list leaves every collector enabled.
+func (r *Registry) EnableOnly(names []string) error {
+	if len(names) == 0 {
+		return nil
+	}
+	r.mu.Lock()
+	for _, e := range r.entries {
+		e.enabled = false
+	}
+	r.mu.Unlock()
+
+	for _, name := range names {
+		if err := r.Configure(name, WithEnabled(true)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Names returns the names of the registered collectors, sorted.
+func (r *Registry) Names() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.names()
+}
+
+func (r *Registry) names() []string {
+	names := make([]string, 0, len(r.entries))
+	for _, e := range r.entries {
+		names = append(names, e.collector.Name())
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (r *Registry) find(name string) *entry {
+	for _, e := range r.entries {
+		if e.collector.Name() == name {
+			return e
+		}
+	}
+	return nil
+}
+
+// Start polls every enabled collector on its own interval and streams the
+// collected metrics. The channel is closed once ctx is done.
+func (r *Registry) Start(ctx context.Context) <-chan *types.Metrics {
+	r.mu.Lock()
+	var entries []*entry
+	for _, e := range r.entries {
+		if e.enabled {
+			entries = append(entries, e)
+		}
+	}
+	r.mu.Unlock()
+
+	out := make(chan *types.Metrics, 100)
+	var wg sync.WaitGroup
+	for _, e := range entries {
+		wg.Add(1)
+		go func(e *entry) {
+			defer wg.Done()
+			e.run(ctx, out)
+		}(e)
+	}
+	go func() {
+		wg.Wait()
+		close(out)
+	}()
+	return out
+}
+
+func (e *entry) run(ctx context.Context, out chan<- *types.Metrics) {
+	name := e.collector.Name()
+	logger.Log.Debugf("Collector %s: polling every %s", name, e.interval)
+
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			metrics, err := e.collect(ctx)
+			if err != nil {
+				logger.Log.Errorw("Collector failed", "collector", name, "error", err)
+			}
+			for _, m := range metrics {
+				select {
+				case out <- m:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}
+}
+
+// collect runs one poll, giving up after the timeout even when the collector
+// ignores its context. Such a collector is skipped until the call returns.
+func (e *entry) collect(ctx context.Context) ([]*types.Metrics, error) {
+	if !e.busy.CompareAndSwap(false, true) {
+		return nil, errBusy
+	}
+
+	timeout := e.timeout
+	if timeout <= 0 {
+		timeout = e.interval
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	type result struct {
+		metrics []*types.Metrics
+		err     error
+	}
+	done := make(chan result, 1)
+	go func() {
+		defer e.busy.Store(false)
+		metrics, err := e.collector.Collect(ctx)
+		done <- result{metrics: metrics, err: err}
+	}()
+
+	select {
+	case res := <-done:
+		return res.metrics, res.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// ParseIntervals parses per-collector poll intervals in the form "name=seconds,name=seconds".
+func ParseIntervals(s string) (map[string]time.Duration, error) {
+	intervals := make(map[string]time.Duration)
+	if strings.TrimSpace(s) == "" {
+		return intervals, nil
+	}
+
+	for _, pair := range strings.Split(s, ",") {
+		name, seconds, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid collector interval %q, expected name=seconds", pair)
+		}
+		n, err := strconv.Atoi(seconds)
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("invalid collector interval %q: expected a positive number of seconds", pair)
+		}
+		intervals[name] = time.Duration(n) * time.Second
+	}
+
+	return intervals, nil
+}
+
+// ParseNames parses a comma-separated list of collector names.
+func ParseNames(s string) []string {
+	var names []string
+	for _, name := range strings.Split(s, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// defaultRegistryConfig configures the built-in collectors.
+type defaultRegistryConfig struct {
+	cgroup  []CgroupCollectorOpt
+	process []ProcessCollectorOpt
+	exec    []*ExecCollector
+	push    *PushCollector
+}
+
+// DefaultRegistryOpt configures the built-in collectors of NewDefaultRegistry.
+type DefaultRegistryOpt func(*defaultRegistryConfig)
+
+// WithCgroupCollector sets the options of the cgroup collector.
+func WithCgroupCollector(opts ...CgroupCollectorOpt) DefaultRegistryOpt {
+	return func(cfg *defaultRegistryConfig) {
+		cfg.cgroup = append(cfg.cgroup, opts...)
+	}
+}
+
+// WithProcessCollector sets the options of the per-process collector.
+func WithProcessCollector(opts ...ProcessCollectorOpt) DefaultRegistryOpt {
+	return func(cfg *defaultRegistryConfig) {
+		cfg.process = append(cfg.process, opts...)
+	}
+}
+
+// WithExecPlugins adds an exec collector for each plugin.
+func WithExecPlugins(plugins []ExecPlugin, opts ...ExecCollectorOpt) DefaultRegistryOpt {
+	return func(cfg *defaultRegistryConfig) {
+		for _, p := range plugins {
+			cfg.exec = append(cfg.exec, NewExecCollector(p, opts...))
+		}
+	}
+}
+
+// WithPushCollector registers the collector of metrics pushed to the agent.
+func WithPushCollector(c *PushCollector) DefaultRegistryOpt {
+	return func(cfg *defaultRegistryConfig) {
+		cfg.push = c
+	}
+}
+
+// NewDefaultRegistry creates a Registry with the built-in collectors, the
+// configured exec plugins and the push collector, each polled every interval. It fails when two
+// plugins share a name.
+func NewDefaultRegistry(interval time.Duration, opts ...DefaultRegistryOpt) (*Registry, error) {
+	cfg := &defaultRegistryConfig{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	r := NewRegistry()
+	for _, c := range []Collector{
+		NewRuntimeCollector(),
+		NewMemoryCollector(),
+		NewCPUCollector(),
+		NewDiskCollector(),
+		NewNetworkCollector(),
+		NewLoadCollector(),
+		NewFilesystemCollector(),
+		NewSwapCollector(),
+		NewProcessesCollector(),
+		NewCgroupCollector(cfg.cgroup...),
+		NewProcessCollector(cfg.process...),
+	} {
+		// Names of the built-in collectors are unique
+		_ = r.Register(c, WithInterval(interval))
+	}
+	if cfg.push != nil {
+		_ = r.Register(cfg.push, WithInterval(interval))
+	}
+	for _, c := range cfg.exec {
+		if err := r.Register(c, WithInterval(interval)); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
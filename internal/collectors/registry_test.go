@@ -0,0 +1,189 @@
+package collectors
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sbilibin2017/go-yandex-practicum/internal/types"
+)
+
+// stubCollector returns one gauge named after itself, or fails, or hangs.
+type stubCollector struct {
+	name  string
+	err   error
+	hang  bool
+	calls atomic.Int32
+}
+
+func (c *stubCollector) Name() string { return c.name }
+
+func (c *stubCollector) Collect(ctx context.Context) ([]*types.Metrics, error) {
+	c.calls.Add(1)
+	if c.hang {
+		// Ignores ctx on purpose, like a stuck syscall
+		time.Sleep(time.Second)
+	}
+	if c.err != nil {
+		return nil, c.err
+	}
+	return []*types.Metrics{gauge(c.name, 1)}, nil
+}
+
+func TestRegistry_Register(t *testing.T) {
+	r := NewRegistry()
+	require.NoError(t, r.Register(&stubCollector{name: "b"}))
+	require.NoError(t, r.Register(&stubCollector{name: "a"}))
+	assert.Error(t, r.Register(&stubCollector{name: "a"}), "duplicate name")
+	assert.Equal(t, []string{"a", "b"}, r.Names())
+
+	assert.NoError(t, r.Configure("a", WithInterval(time.Minute)))
+	assert.ErrorContains(t, r.Configure("c"), "available: a, b")
+}
+
+func TestRegistry_EnableOnly(t *testing.T) {
+	tests := []struct {
+		name    string
+		enable  []string
+		want    []string
+		wantErr bool
+	}{
+		{name: "empty list keeps all", enable: nil, want: []string{"a", "b"}},
+		{name: "subset", enable: []string{"b"}, want: []string{"b"}},
+		{name: "unknown", enable: []string{"c"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := &stubCollector{name: "a"}
+			b := &stubCollector{name: "b"}
+			r := NewRegistry()
+			require.NoError(t, r.Register(a, WithInterval(10*time.Millisecond)))
+			require.NoError(t, r.Register(b, WithInterval(10*time.Millisecond)))
+
+			err := r.EnableOnly(tt.enable)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+			defer cancel()
+			seen := map[string]bool{}
+			for m := range r.Start(ctx) {
+				seen[m.ID] = true
+			}
+			var got []string
+			for _, name := range []string{"a", "b"} {
+				if seen[name] {
+					got = append(got, name)
+				}
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestRegistry_Start_Isolation(t *testing.T) {
+	healthy := &stubCollector{name: "healthy"}
+	failing := &stubCollector{name: "failing", err: errors.New("boom")}
+	slow := &stubCollector{name: "slow", hang: true}
+
+	r := NewRegistry()
+	require.NoError(t, r.Register(healthy, WithInterval(10*time.Millisecond)))
+	require.NoError(t, r.Register(failing, WithInterval(10*time.Millisecond)))
+	require.NoError(t, r.Register(slow, WithInterval(10*time.Millisecond), WithTimeout(5*time.Millisecond)))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	counts := map[string]int{}
+	for m := range r.Start(ctx) {
+		counts[m.ID]++
+	}
+
+	assert.Greater(t, counts["healthy"], 5, "other collectors keep their pace")
+	assert.Zero(t, counts["failing"])
+	assert.Zero(t, counts["slow"])
+	assert.Greater(t, failing.calls.Load(), int32(5))
+	assert.Equal(t, int32(1), slow.calls.Load(), "a stuck collector is not called again until it returns")
+}
+
+func TestRegistry_Start_Intervals(t *testing.T) {
+	fast := &stubCollector{name: "fast"}
+	slow := &stubCollector{name: "slow"}
+	r := NewRegistry()
+	require.NoError(t, r.Register(fast, WithInterval(10*time.Millisecond)))
+	require.NoError(t, r.Register(slow, WithInterval(time.Hour)))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	for range r.Start(ctx) {
+	}
+
+	assert.Greater(t, fast.calls.Load(), int32(3))
+	assert.Zero(t, slow.calls.Load())
+}
+
+func TestParseIntervals(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    map[string]time.Duration
+		wantErr bool
+	}{
+		{in: "", want: map[string]time.Duration{}},
+		{in: "cpu=5, memory=10", want: map[string]time.Duration{"cpu": 5 * time.Second, "memory": 10 * time.Second}},
+		{in: "cpu", wantErr: true},
+		{in: "=5", wantErr: true},
+		{in: "cpu=0", wantErr: true},
+		{in: "cpu=5s", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseIntervals(tt.in)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestParseNames(t *testing.T) {
+	assert.Nil(t, ParseNames(""))
+	assert.Equal(t, []string{"cpu", "memory"}, ParseNames(" cpu,,memory "))
+}
+
+func TestNewDefaultRegistry(t *testing.T) {
+	r, err := NewDefaultRegistry(time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, []string{CgroupName, CPUName, DiskName, FilesystemName, LoadName, MemoryName, NetworkName, ProcessName, ProcessesName, RuntimeName, SwapName}, r.Names())
+}
+
+func TestNewDefaultRegistry_ExecPlugins(t *testing.T) {
+	queue := ExecPlugin{Name: "queue", Command: []string{"queue-stats"}}
+
+	r, err := NewDefaultRegistry(time.Second, WithExecPlugins([]ExecPlugin{queue}))
+	require.NoError(t, err)
+	assert.Contains(t, r.Names(), "exec:queue")
+
+	_, err = NewDefaultRegistry(time.Second, WithExecPlugins([]ExecPlugin{queue, queue}))
+	assert.Error(t, err)
+}
+
+func TestNewDefaultRegistry_PushCollector(t *testing.T) {
+	r, err := NewDefaultRegistry(time.Second)
+	require.NoError(t, err)
+	assert.NotContains(t, r.Names(), PushName)
+
+	r, err = NewDefaultRegistry(time.Second, WithPushCollector(NewPushCollector()))
+	require.NoError(t, err)
+	assert.Contains(t, r.Names(), PushName)
+}
@@ -0,0 +1,276 @@
+package collectors
+
+import (
+	"context"
+	"math"
+	"math/rand/v2"
+	"runtime/debug"
+	"runtime/metrics"
+	"strings"
+	"sync"
+
+	"github.com/sbilibin2017/go-yandex-practicum/internal/types"
+)
+
+// RuntimeName is the name of the Go runtime collector.
+const RuntimeName = "runtime"
+
+// runtimeQuantiles are the quantiles reported for runtime histograms.
+var runtimeQuantiles = []struct {
+	suffix string
+	q      float64
+}{
+	{"_p50", 0.5},
+	{"_p90", 0.9},
+	{"_p99", 0.99},
+}
+
+// RuntimeCollector reports the Go runtime statistics of the agent process
+// along with RandomValue and the PollCount counter.
+//
+// Every sample supported by runtime/metrics is reported under a name derived
+// from its key, "/sched/latencies:seconds" becoming "go_sched_latencies_seconds".
+// Cumulative integer samples are counters, other numeric samples gauges; the
+// cumulative CPU-seconds samples stay gauges holding the running total because
+// counters are integers. Each histogram becomes a "<name>_count" counter and
+// "<name>_p50", "_p90" and "_p99" gauges over the values observed since the
+// previous poll.
+//
+// The legacy runtime.MemStats gauges (Alloc, HeapAlloc, NumGC and so on) are
+// derived from the same samples, so reading them does not stop the world.
+type RuntimeCollector struct {
+	legacy  bool
+	metrics bool
+
+	mu         sync.Mutex
+	samples    []metrics.Sample
+	cumulative map[string]bool
+	deltas     *counterDeltas
+	histograms map[string][]uint64 // bucket counts at the previous poll
+}
+
+// RuntimeCollectorOpt configures a RuntimeCollector.
+type RuntimeCollectorOpt func(*RuntimeCollector)
+
+// WithRuntimeLegacyNames enables or disables the runtime.MemStats gauges. Enabled by default.
+func WithRuntimeLegacyNames(enabled bool) RuntimeCollectorOpt {
+	return func(c *RuntimeCollector) {
+		c.legacy = enabled
+	}
+}
+
+// WithRuntimeMetrics enables or disables the go_* runtime/metrics samples. Enabled by default.
+func WithRuntimeMetrics(enabled bool) RuntimeCollectorOpt {
+	return func(c *RuntimeCollector) {
+		c.metrics = enabled
+	}
+}
+
+// NewRuntimeCollector creates a RuntimeCollector.
+func NewRuntimeCollector(opts ...RuntimeCollectorOpt) *RuntimeCollector {
+	c := &RuntimeCollector{
+		legacy:     true,
+		metrics:    true,
+		cumulative: make(map[string]bool),
+		deltas:     newCounterDeltas(),
+		histograms: make(map[string][]uint64),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	for _, d := range metrics.All() {
+		if d.Kind == metrics.KindBad {
+			continue
+		}
+		c.samples = append(c.samples, metrics.Sample{Name: d.Name})
+		c.cumulative[d.Name] = d.Cumulative
+	}
+	return c
+}
+
+// Name returns RuntimeName.
+func (c *RuntimeCollector) Name() string {
+	return RuntimeName
+}
+
+// Collect reads the runtime samples.
+func (c *RuntimeCollector) Collect(ctx context.Context) ([]*types.Metrics, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	metrics.Read(c.samples)
+
+	var out []*types.Metrics
+	if c.metrics {
+		for _, s := range c.samples {
+			out = c.appendSample(out, s)
+		}
+	}
+	if c.legacy {
+		out = append(out, c.legacyGauges()...)
+	}
+	out = append(out,
+		gauge("RandomValue", rand.Float64()),
+		counter("PollCount", 1),
+	)
+	return out, nil
+}
+
+func (c *RuntimeCollector) appendSample(out []*types.Metrics, s metrics.Sample) []*types.Metrics {
+	name := runtimeMetricName(s.Name)
+	switch s.Value.Kind() {
+	case metrics.KindUint64:
+		if c.cumulative[s.Name] {
+			return c.deltas.Append(out, name, s.Value.Uint64())
+		}
+		return append(out, gauge(name, float64(s.Value.Uint64())))
+	case metrics.KindFloat64:
+		return append(out, gauge(name, s.Value.Float64()))
+	case metrics.KindFloat64Histogram:
+		return c.appendHistogram(out, name, s.Name, s.Value.Float64Histogram())
+	default:
+		return out
+	}
+}
+
+// appendHistogram reports the observations made since the previous poll.
+func (c *RuntimeCollector) appendHistogram(out []*types.Metrics, name, key string, h *metrics.Float64Histogram) []*types.Metrics {
+	counts := h.Counts
+	var total uint64
+	for _, n := range counts {
+		total += n
+	}
+	out = c.deltas.Append(out, name+"_count", total)
+
+	recent := counts
+	if prev := c.histograms[key]; c.cumulative[key] && len(prev) == len(counts) {
+		recent = make([]uint64, len(counts))
+		for i := range counts {
+			if counts[i] >= prev[i] {
+				recent[i] = counts[i] - prev[i]
+			}
+		}
+	}
+	c.histograms[key] = append(c.histograms[key][:0], counts...)
+
+	for _, rq := range runtimeQuantiles {
+		if v, ok := histogramQuantile(recent, h.Buckets, rq.q); ok {
+			out = append(out, gauge(name+rq.suffix, v))
+		}
+	}
+	return out
+}
+
+// histogramQuantile estimates the q-quantile as the upper bound of the bucket
+// holding it, or its lower bound for the open-ended last bucket.
+func histogramQuantile(counts []uint64, buckets []float64, q float64) (float64, bool) {
+	var total uint64
+	for _, n := range counts {
+		total += n
+	}
+	if total == 0 {
+		return 0, false
+	}
+
+	rank := uint64(math.Ceil(q * float64(total)))
+	var seen uint64
+	for i, n := range counts {
+		seen += n
+		if seen < rank || n == 0 {
+			continue
+		}
+		if upper := buckets[i+1]; !math.IsInf(upper, 1) {
+			return upper, true
+		}
+		return buckets[i], true
+	}
+	return 0, false
+}
+
+// legacyGauges derives the runtime.MemStats fields from the last read samples,
+// following the correspondence documented by runtime/metrics.
+func (c *RuntimeCollector) legacyGauges() []*types.Metrics {
+	values := make(map[string]float64, len(c.samples))
+	for _, s := range c.samples {
+		switch s.Value.Kind() {
+		case metrics.KindUint64:
+			values[s.Name] = float64(s.Value.Uint64())
+		case metrics.KindFloat64:
+			values[s.Name] = s.Value.Float64()
+		}
+	}
+
+	heapObjects := values["/memory/classes/heap/objects:bytes"]
+	heapInuse := heapObjects + values["/memory/classes/heap/unused:bytes"]
+	heapReleased := values["/memory/classes/heap/released:bytes"]
+	heapIdle := heapReleased + values["/memory/classes/heap/free:bytes"]
+	stackInuse := values["/memory/classes/heap/stacks:bytes"]
+	mcacheInuse := values["/memory/classes/metadata/mcache/inuse:bytes"]
+	mspanInuse := values["/memory/classes/metadata/mspan/inuse:bytes"]
+	tinyAllocs := values["/gc/heap/tiny/allocs:objects"]
+
+	var gcCPUFraction float64
+	if total := values["/cpu/classes/total:cpu-seconds"]; total > 0 {
+		gcCPUFraction = values["/cpu/classes/gc/total:cpu-seconds"] / total
+	}
+
+	// The last collection time and total pause are not runtime/metrics samples;
+	// ReadGCStats reads them without stopping the world.
+	var gcStats debug.GCStats
+	debug.ReadGCStats(&gcStats)
+	var lastGC float64
+	if !gcStats.LastGC.IsZero() {
+		lastGC = float64(gcStats.LastGC.UnixNano())
+	}
+
+	return []*types.Metrics{
+		gauge("Alloc", heapObjects),
+		gauge("BuckHashSys", values["/memory/classes/profiling/buckets:bytes"]),
+		gauge("Frees", values["/gc/heap/frees:objects"]+tinyAllocs),
+		gauge("GCCPUFraction", gcCPUFraction),
+		gauge("GCSys", values["/memory/classes/metadata/other:bytes"]),
+		gauge("HeapAlloc", heapObjects),
+		gauge("HeapIdle", heapIdle),
+		gauge("HeapInuse", heapInuse),
+		gauge("HeapObjects", values["/gc/heap/objects:objects"]),
+		gauge("HeapReleased", heapReleased),
+		gauge("HeapSys", heapInuse+heapIdle),
+		gauge("LastGC", lastGC),
+		gauge("Lookups", 0),
+		gauge("MCacheInuse", mcacheInuse),
+		gauge("MCacheSys", mcacheInuse+values["/memory/classes/metadata/mcache/free:bytes"]),
+		gauge("MSpanInuse", mspanInuse),
+		gauge("MSpanSys", mspanInuse+values["/memory/classes/metadata/mspan/free:bytes"]),
+		gauge("Mallocs", values["/gc/heap/allocs:objects"]+tinyAllocs),
+		gauge("NextGC", values["/gc/heap/goal:bytes"]),
+		gauge("NumForcedGC", values["/gc/cycles/forced:gc-cycles"]),
+		gauge("NumGC", values["/gc/cycles/total:gc-cycles"]),
+		gauge("OtherSys", values["/memory/classes/other:bytes"]),
+		gauge("PauseTotalNs", float64(gcStats.PauseTotal.Nanoseconds())),
+		gauge("StackInuse", stackInuse),
+		gauge("StackSys", stackInuse+values["/memory/classes/os-stacks:bytes"]),
+		gauge("Sys", values["/memory/classes/total:bytes"]),
+		gauge("TotalAlloc", values["/gc/heap/allocs:bytes"]),
+	}
+}
+
+// runtimeMetricName turns a runtime/metrics key into a metric ID.
+func runtimeMetricName(key string) string {
+	return "go_" + strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		default:
+			return '_'
+		}
+	}, strings.TrimPrefix(key, "/"))
+}
+
+func gauge(id string, value float64) *types.Metrics {
+	return &types.Metrics{ID: id, Type: types.Gauge, Value: &value}
+}
+
+func counter(id string, delta int64) *types.Metrics {
+	return &types.Metrics{ID: id, Type: types.Counter, Delta: &delta}
+}
@@ -0,0 +1,111 @@
+package collectors
+
+import (
+	"context"
+	"math"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sbilibin2017/go-yandex-practicum/internal/types"
+)
+
+func TestRuntimeCollector_Collect(t *testing.T) {
+	c := NewRuntimeCollector()
+	assert.Equal(t, RuntimeName, c.Name())
+
+	_, err := c.Collect(context.Background())
+	require.NoError(t, err)
+	runtime.GC()
+	metrics, err := c.Collect(context.Background())
+	require.NoError(t, err)
+
+	byID := map[string]*types.Metrics{}
+	for _, m := range metrics {
+		byID[m.ID] = m
+	}
+
+	legacy := []string{
+		"Alloc", "BuckHashSys", "Frees", "GCCPUFraction", "GCSys", "HeapAlloc", "HeapIdle",
+		"HeapInuse", "HeapObjects", "HeapReleased", "HeapSys", "LastGC", "Lookups",
+		"MCacheInuse", "MCacheSys", "MSpanInuse", "MSpanSys", "Mallocs", "NextGC",
+		"NumForcedGC", "NumGC", "OtherSys", "PauseTotalNs", "StackInuse", "StackSys",
+		"Sys", "TotalAlloc", "RandomValue",
+	}
+	for _, id := range legacy {
+		require.Contains(t, byID, id)
+		assert.Equal(t, types.Gauge, byID[id].Type, id)
+	}
+	assert.Positive(t, *byID["HeapAlloc"].Value)
+	assert.Positive(t, *byID["NumGC"].Value)
+	assert.Positive(t, *byID["LastGC"].Value)
+	assert.GreaterOrEqual(t, *byID["Sys"].Value, *byID["HeapSys"].Value)
+	assert.Equal(t, int64(1), *byID["PollCount"].Delta)
+
+	// Samples from runtime/metrics
+	assert.Equal(t, types.Gauge, byID["go_sched_goroutines_goroutines"].Type)
+	require.Contains(t, byID, "go_gc_cycles_total_gc_cycles")
+	assert.Equal(t, types.Counter, byID["go_gc_cycles_total_gc_cycles"].Type)
+	assert.GreaterOrEqual(t, *byID["go_gc_cycles_total_gc_cycles"].Delta, int64(1), "runtime.GC ran between the polls")
+	require.Contains(t, byID, "go_gc_pauses_seconds_count")
+	assert.Equal(t, types.Counter, byID["go_gc_pauses_seconds_count"].Type)
+	assert.Contains(t, byID, "go_gc_pauses_seconds_p99")
+}
+
+func TestRuntimeCollector_Options(t *testing.T) {
+	tests := []struct {
+		name       string
+		opts       []RuntimeCollectorOpt
+		wantLegacy bool
+		wantGo     bool
+	}{
+		{name: "defaults", wantLegacy: true, wantGo: true},
+		{name: "legacy only", opts: []RuntimeCollectorOpt{WithRuntimeMetrics(false)}, wantLegacy: true},
+		{name: "runtime/metrics only", opts: []RuntimeCollectorOpt{WithRuntimeLegacyNames(false)}, wantGo: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metrics, err := NewRuntimeCollector(tt.opts...).Collect(context.Background())
+			require.NoError(t, err)
+			ids := map[string]bool{}
+			for _, m := range metrics {
+				ids[m.ID] = true
+			}
+			assert.Equal(t, tt.wantLegacy, ids["HeapAlloc"])
+			assert.Equal(t, tt.wantGo, ids["go_memory_classes_total_bytes"])
+			assert.True(t, ids["PollCount"])
+			assert.True(t, ids["RandomValue"])
+		})
+	}
+}
+
+func TestHistogramQuantile(t *testing.T) {
+	buckets := []float64{math.Inf(-1), 1, 2, 4, math.Inf(1)}
+	tests := []struct {
+		name   string
+		counts []uint64
+		q      float64
+		want   float64
+		wantOK bool
+	}{
+		{name: "empty", counts: []uint64{0, 0, 0, 0}, q: 0.5},
+		{name: "median", counts: []uint64{0, 5, 5, 0}, q: 0.5, want: 2, wantOK: true},
+		{name: "upper tail", counts: []uint64{0, 5, 4, 1}, q: 0.99, want: 4, wantOK: true},
+		{name: "open-ended bucket uses lower bound", counts: []uint64{0, 0, 0, 3}, q: 0.5, want: 4, wantOK: true},
+		{name: "leading empty buckets", counts: []uint64{0, 0, 2, 0}, q: 0.5, want: 4, wantOK: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := histogramQuantile(tt.counts, buckets, tt.q)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestRuntimeMetricName(t *testing.T) {
+	assert.Equal(t, "go_sched_latencies_seconds", runtimeMetricName("/sched/latencies:seconds"))
+	assert.Equal(t, "go_gc_heap_allocs_by_size_bytes", runtimeMetricName("/gc/heap/allocs-by-size:bytes"))
+}
@@ -0,0 +1,49 @@
+package collectors
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sbilibin2017/go-yandex-practicum/internal/types"
+	"github.com/shirou/gopsutil/v4/mem"
+)
+
+// SwapName is the name of the swap collector.
+const SwapName = "swap"
+
+// SwapCollector reports the SwapTotal, SwapUsed, SwapFree and SwapUsedPercent
+// gauges and the SwapInBytes and SwapOutBytes counters.
+type SwapCollector struct {
+	swap   func(ctx context.Context) (*mem.SwapMemoryStat, error)
+	deltas *counterDeltas
+}
+
+// NewSwapCollector creates a SwapCollector.
+func NewSwapCollector() *SwapCollector {
+	return &SwapCollector{
+		swap:   mem.SwapMemoryWithContext,
+		deltas: newCounterDeltas(),
+	}
+}
+
+// Name returns SwapName.
+func (c *SwapCollector) Name() string {
+	return SwapName
+}
+
+// Collect reads the swap statistics.
+func (c *SwapCollector) Collect(ctx context.Context) ([]*types.Metrics, error) {
+	s, err := c.swap(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read swap stats: %w", err)
+	}
+	metrics := []*types.Metrics{
+		gauge("SwapTotal", float64(s.Total)),
+		gauge("SwapUsed", float64(s.Used)),
+		gauge("SwapFree", float64(s.Free)),
+		gauge("SwapUsedPercent", s.UsedPercent),
+	}
+	metrics = c.deltas.Append(metrics, "SwapInBytes", s.Sin)
+	metrics = c.deltas.Append(metrics, "SwapOutBytes", s.Sout)
+	return metrics, nil
+}
@@ -0,0 +1,28 @@
+package collectors
+
+import (
+	"context"
+	"testing"
+
+	"github.com/shirou/gopsutil/v4/mem"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSwapCollector_Collect(t *testing.T) {
+	c := NewSwapCollector()
+	assert.Equal(t, SwapName, c.Name())
+
+	stat := &mem.SwapMemoryStat{Total: 1000, Used: 100, Free: 900, UsedPercent: 10, Sin: 4096, Sout: 8192}
+	c.swap = func(context.Context) (*mem.SwapMemoryStat, error) { return stat, nil }
+
+	metrics, err := c.Collect(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, metrics, 4, "counters start at the second poll")
+	assert.Equal(t, 100.0, values(metrics)["SwapUsed"])
+
+	stat.Sin += 4096
+	metrics, err = c.Collect(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int64{"SwapInBytes": 4096, "SwapOutBytes": 0}, deltas(metrics))
+}
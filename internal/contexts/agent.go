@@ -0,0 +1,20 @@
+package contexts
+
+import "context"
+
+// agentContextKey is an unexported type used as the key for storing the
+// agent identity in a context.Context to avoid key collisions.
+type agentContextKey struct{}
+
+// SetAgentToContext returns a new context carrying the identity of the agent
+// that made the request, as taken from its client certificate.
+func SetAgentToContext(ctx context.Context, agent string) context.Context {
+	return context.WithValue(ctx, agentContextKey{}, agent)
+}
+
+// GetAgentFromContext retrieves the agent identity from the provided context.
+// It returns false when the request was not made with a client certificate.
+func GetAgentFromContext(ctx context.Context) (string, bool) {
+	agent, ok := ctx.Value(agentContextKey{}).(string)
+	return agent, ok && agent != ""
+}
@@ -0,0 +1,22 @@
+package contexts
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetAndGetAgentFromContext(t *testing.T) {
+	ctx := SetAgentToContext(context.Background(), "agent-1")
+
+	agent, ok := GetAgentFromContext(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, "agent-1", agent)
+
+	_, ok = GetAgentFromContext(context.Background())
+	assert.False(t, ok)
+
+	_, ok = GetAgentFromContext(SetAgentToContext(context.Background(), ""))
+	assert.False(t, ok)
+}
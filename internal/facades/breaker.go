@@ -0,0 +1,161 @@
+package facades
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/sbilibin2017/go-yandex-practicum/internal/logger"
+)
+
+// BreakerState is the state of a CircuitBreaker.
+type BreakerState int
+
+// Circuit breaker states.
+const (
+	BreakerClosed   BreakerState = iota // BreakerClosed lets requests through.
+	BreakerOpen                         // BreakerOpen rejects requests until the cooldown passes.
+	BreakerHalfOpen                     // BreakerHalfOpen lets a single probe through.
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// ErrCircuitOpen is returned instead of contacting a server the breaker considers down.
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// CircuitBreaker stops requests to a server after consecutive failures and
+// probes it again, one request at a time, once a cooldown has passed.
+type CircuitBreaker struct {
+	threshold int
+	cooldown  time.Duration
+	now       func() time.Time
+
+	mu       sync.Mutex
+	state    BreakerState
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+// CircuitBreakerOpt defines functional option type for CircuitBreaker.
+type CircuitBreakerOpt func(*CircuitBreaker)
+
+// WithCircuitBreakerThreshold sets how many consecutive failures open the breaker.
+// Zero disables the breaker.
+func WithCircuitBreakerThreshold(n int) CircuitBreakerOpt {
+	return func(b *CircuitBreaker) {
+		b.threshold = n
+	}
+}
+
+// WithCircuitBreakerCooldown sets how long the breaker stays open before probing the server.
+func WithCircuitBreakerCooldown(d time.Duration) CircuitBreakerOpt {
+	return func(b *CircuitBreaker) {
+		b.cooldown = d
+	}
+}
+
+// WithCircuitBreakerClock sets the clock used to time the cooldown.
+func WithCircuitBreakerClock(now func() time.Time) CircuitBreakerOpt {
+	return func(b *CircuitBreaker) {
+		b.now = now
+	}
+}
+
+// NewCircuitBreaker creates a closed CircuitBreaker.
+func NewCircuitBreaker(opts ...CircuitBreakerOpt) *CircuitBreaker {
+	b := &CircuitBreaker{
+		threshold: 5,
+		cooldown:  30 * time.Second,
+		now:       time.Now,
+	}
+	for _, opt := range opts {
+		opt(b)
+	}
+	return b
+}
+
+// State returns the current state, moving an open breaker whose cooldown
+// has passed to half-open.
+func (b *CircuitBreaker) State() BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.checkCooldown()
+	return b.state
+}
+
+// Allow reports whether a request may be sent, returning ErrCircuitOpen if not.
+// Every allowed request must be followed by Success or Failure.
+func (b *CircuitBreaker) Allow() error {
+	if b.threshold <= 0 {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.checkCooldown()
+	switch b.state {
+	case BreakerOpen:
+		return ErrCircuitOpen
+	case BreakerHalfOpen:
+		if b.probing {
+			return ErrCircuitOpen
+		}
+		b.probing = true
+	}
+	return nil
+}
+
+// Success records that the server handled a request.
+func (b *CircuitBreaker) Success() {
+	if b.threshold <= 0 {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures = 0
+	b.probing = false
+	b.setState(BreakerClosed)
+}
+
+// Failure records that the server could not be reached or failed to handle a request.
+func (b *CircuitBreaker) Failure() {
+	if b.threshold <= 0 {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	b.probing = false
+	if b.state == BreakerHalfOpen || b.failures >= b.threshold {
+		b.openedAt = b.now()
+		b.setState(BreakerOpen)
+	}
+}
+
+func (b *CircuitBreaker) checkCooldown() {
+	if b.state == BreakerOpen && !b.now().Before(b.openedAt.Add(b.cooldown)) {
+		b.setState(BreakerHalfOpen)
+	}
+}
+
+func (b *CircuitBreaker) setState(state BreakerState) {
+	if b.state == state {
+		return
+	}
+	logger.Log.Infow("Circuit breaker state changed", "from", b.state.String(), "to", state.String(), "failures", b.failures)
+	b.state = state
+}
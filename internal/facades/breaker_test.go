@@ -0,0 +1,65 @@
+package facades
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	b := NewCircuitBreaker(
+		WithCircuitBreakerThreshold(2),
+		WithCircuitBreakerCooldown(time.Minute),
+		WithCircuitBreakerClock(func() time.Time { return now }),
+	)
+
+	steps := []struct {
+		name      string
+		advance   time.Duration
+		record    func()
+		wantAllow error
+		wantState BreakerState
+	}{
+		{name: "starts closed", wantState: BreakerClosed},
+		{name: "one failure keeps it closed", record: b.Failure, wantState: BreakerClosed},
+		{name: "success resets the count", record: b.Success, wantState: BreakerClosed},
+		{name: "failure after reset", record: b.Failure, wantState: BreakerClosed},
+		{name: "threshold opens it", record: b.Failure, wantAllow: ErrCircuitOpen, wantState: BreakerOpen},
+		{name: "still open during cooldown", advance: 30 * time.Second, wantAllow: ErrCircuitOpen, wantState: BreakerOpen},
+		{name: "half-open after cooldown", advance: 30 * time.Second, wantState: BreakerHalfOpen},
+		{name: "failed probe reopens it", record: b.Failure, wantAllow: ErrCircuitOpen, wantState: BreakerOpen},
+		{name: "half-open again", advance: time.Minute, wantState: BreakerHalfOpen},
+		{name: "successful probe closes it", record: b.Success, wantState: BreakerClosed},
+	}
+	for _, step := range steps {
+		now = now.Add(step.advance)
+		if step.record != nil {
+			step.record()
+		}
+		assert.Equal(t, step.wantState, b.State(), step.name)
+
+		err := b.Allow()
+		assert.ErrorIs(t, err, step.wantAllow, step.name)
+		if err == nil && step.wantState == BreakerHalfOpen {
+			assert.ErrorIs(t, b.Allow(), ErrCircuitOpen, "%s: one probe at a time", step.name)
+		}
+	}
+}
+
+func TestCircuitBreaker_Disabled(t *testing.T) {
+	b := NewCircuitBreaker(WithCircuitBreakerThreshold(0))
+	for i := 0; i < 10; i++ {
+		b.Failure()
+	}
+	assert.NoError(t, b.Allow())
+	assert.Equal(t, BreakerClosed, b.State())
+}
+
+func TestBreakerState_String(t *testing.T) {
+	assert.Equal(t, "closed", BreakerClosed.String())
+	assert.Equal(t, "open", BreakerOpen.String())
+	assert.Equal(t, "half-open", BreakerHalfOpen.String())
+	assert.Equal(t, "unknown", BreakerState(42).String())
+}
@@ -13,13 +13,24 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"net/http"
 	"os"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/go-resty/resty/v2"
+	"github.com/sbilibin2017/go-yandex-practicum/internal/certificates"
+	"github.com/sbilibin2017/go-yandex-practicum/internal/codecs"
+	"github.com/sbilibin2017/go-yandex-practicum/internal/sockets"
 	"github.com/sbilibin2017/go-yandex-practicum/internal/types"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/encoding/gzip"
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
 
 	pb "github.com/sbilibin2017/go-yandex-practicum/protos"
 )
@@ -30,6 +41,9 @@ type MetricHTTPFacade struct {
 	header        string
 	key           string
 	cryptoKeyPath string
+	tlsCAPath     string
+	tlsCertPath   string
+	tlsKeyPath    string
 
 	client    *resty.Client
 	publicKey *rsa.PublicKey
@@ -62,6 +76,21 @@ func WithMetricFacadeCryptoKeyPath(path string) MetricHTTPFacadeOpt {
 	}
 }
 
+// WithMetricFacadeTLSCAPath sets the CA bundle the server certificate is verified against.
+func WithMetricFacadeTLSCAPath(path string) MetricHTTPFacadeOpt {
+	return func(f *MetricHTTPFacade) {
+		f.tlsCAPath = path
+	}
+}
+
+// WithMetricFacadeTLSCertificate sets the client certificate presented for mutual TLS.
+func WithMetricFacadeTLSCertificate(certPath, keyPath string) MetricHTTPFacadeOpt {
+	return func(f *MetricHTTPFacade) {
+		f.tlsCertPath = certPath
+		f.tlsKeyPath = keyPath
+	}
+}
+
 // NewMetricHTTPFacade creates a new MetricHTTPFacade configured with options.
 func NewMetricHTTPFacade(opts ...MetricHTTPFacadeOpt) (*MetricHTTPFacade, error) {
 	f := &MetricHTTPFacade{}
@@ -71,9 +100,35 @@ func NewMetricHTTPFacade(opts ...MetricHTTPFacadeOpt) (*MetricHTTPFacade, error)
 
 	f.client = resty.New()
 
+	// Requests to a Unix socket address go to a fixed host over that socket.
+	if sockets.IsUnix(f.serverAddress) {
+		socket := f.serverAddress
+		f.client.SetTransport(&http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				return sockets.DialContext(ctx, socket)
+			},
+		})
+		f.serverAddress = "localhost"
+	}
+
+	tlsConfig, err := certificates.NewClientTLSConfig(
+		certificates.WithCAFile(f.tlsCAPath),
+		certificates.WithCertificate(f.tlsCertPath, f.tlsKeyPath),
+	)
+	if err != nil {
+		return nil, err
+	}
+	if tlsConfig != nil {
+		f.client.SetTLSClientConfig(tlsConfig)
+	}
+
 	if f.serverAddress != "" {
 		if !strings.HasPrefix(f.serverAddress, "http://") && !strings.HasPrefix(f.serverAddress, "https://") {
-			f.serverAddress = "http://" + f.serverAddress
+			scheme := "http://"
+			if tlsConfig != nil {
+				scheme = "https://"
+			}
+			f.serverAddress = scheme + f.serverAddress
 		}
 		f.client.SetBaseURL(f.serverAddress)
 	}
@@ -137,11 +192,40 @@ func sendRequest(
 		return fmt.Errorf("failed to send metrics: %w", err)
 	}
 	if resp.IsError() {
-		return fmt.Errorf("error response from server for metrics: %s", resp.String())
+		return &HTTPStatusError{
+			StatusCode: resp.StatusCode(),
+			RetryAfter: parseRetryAfter(resp.Header().Get("Retry-After"), time.Now()),
+			Body:       resp.String(),
+		}
 	}
 	return nil
 }
 
+// HTTPStatusError is returned when the server answers with an error status.
+type HTTPStatusError struct {
+	StatusCode int
+	RetryAfter time.Duration // delay the server asked for, zero if none
+	Body       string
+}
+
+func (e *HTTPStatusError) Error() string {
+	return fmt.Sprintf("error response from server for metrics: %d %s", e.StatusCode, e.Body)
+}
+
+// parseRetryAfter parses a Retry-After header given in seconds or as an HTTP date.
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil && at.After(now) {
+		return at.Sub(now)
+	}
+	return 0
+}
+
 func calcBodyHashSum(body []byte, key string) string {
 	h := hmac.New(sha256.New, []byte(key))
 	h.Write(body)
@@ -175,9 +259,22 @@ func loadPublicKey(path string) (*rsa.PublicKey, error) {
 	return pubKey, nil
 }
 
+// Keepalive defaults for the gRPC connection. The server enforcement policy
+// must allow pings at least this often.
+const (
+	grpcKeepaliveTime    = 30 * time.Second
+	grpcKeepaliveTimeout = 10 * time.Second
+)
+
 // MetricGRPCFacade provides methods to send metrics data to a remote gRPC server.
 type MetricGRPCFacade struct {
 	serverAddress string
+	header        string
+	key           string
+	cryptoKeyPath string
+	tlsCAPath     string
+	tlsCertPath   string
+	tlsKeyPath    string
 
 	client pb.MetricUpdaterClient
 	conn   *grpc.ClientConn
@@ -191,18 +288,90 @@ func WithMetricGRPCServerAddress(address string) MetricGRPCFacadeOpt {
 	}
 }
 
+// WithMetricGRPCHeader sets the metadata key the request signature is sent in.
+func WithMetricGRPCHeader(header string) MetricGRPCFacadeOpt {
+	return func(f *MetricGRPCFacade) {
+		f.header = header
+	}
+}
+
+// WithMetricGRPCKey sets the key requests are signed with.
+func WithMetricGRPCKey(key string) MetricGRPCFacadeOpt {
+	return func(f *MetricGRPCFacade) {
+		f.key = key
+	}
+}
+
+// WithMetricGRPCCryptoKeyPath sets the path to the server public key requests are encrypted with.
+func WithMetricGRPCCryptoKeyPath(path string) MetricGRPCFacadeOpt {
+	return func(f *MetricGRPCFacade) {
+		f.cryptoKeyPath = path
+	}
+}
+
+// WithMetricGRPCTLSCAPath sets the CA bundle the server certificate is verified against.
+func WithMetricGRPCTLSCAPath(path string) MetricGRPCFacadeOpt {
+	return func(f *MetricGRPCFacade) {
+		f.tlsCAPath = path
+	}
+}
+
+// WithMetricGRPCTLSCertificate sets the client certificate presented for mutual TLS.
+func WithMetricGRPCTLSCertificate(certPath, keyPath string) MetricGRPCFacadeOpt {
+	return func(f *MetricGRPCFacade) {
+		f.tlsCertPath = certPath
+		f.tlsKeyPath = keyPath
+	}
+}
+
+// NewMetricGRPCFacade creates a MetricGRPCFacade connected to the server.
+// Each Updates call is a single attempt: the connection has no gRPC retry
+// policy, since MetricResilientFacade is the only retry layer. It replaces the
+// retry policy on Unavailable the connection used to have, which would retry
+// every attempt of the resilient facade again and hide failures from its
+// circuit breaker.
 func NewMetricGRPCFacade(opts ...MetricGRPCFacadeOpt) (*MetricGRPCFacade, error) {
 	f := &MetricGRPCFacade{}
 	for _, opt := range opts {
 		opt(f)
 	}
 
+	callOpts := []grpc.CallOption{grpc.UseCompressor(gzip.Name)}
+	if f.cryptoKeyPath != "" {
+		pubKey, err := loadPublicKey(f.cryptoKeyPath)
+		if err != nil {
+			return nil, fmt.Errorf("error loading public key: %w", err)
+		}
+		callOpts = append(callOpts, grpc.ForceCodec(codecs.NewEncryptCodec(pubKey)))
+	}
+
+	creds := insecure.NewCredentials()
+	tlsConfig, err := certificates.NewClientTLSConfig(
+		certificates.WithCAFile(f.tlsCAPath),
+		certificates.WithCertificate(f.tlsCertPath, f.tlsKeyPath),
+	)
+	if err != nil {
+		return nil, err
+	}
+	if tlsConfig != nil {
+		creds = credentials.NewTLS(tlsConfig)
+	}
+
 	conn, err := grpc.NewClient(
 		f.serverAddress,
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithTransportCredentials(creds),
 		grpc.WithContextDialer(func(ctx context.Context, addr string) (net.Conn, error) {
-			d := net.Dialer{}
-			return d.DialContext(ctx, "tcp", addr)
+			// gRPC resolves "unix://" targets to the bare socket path
+			if sockets.IsUnix(f.serverAddress) {
+				return sockets.DialContext(ctx, f.serverAddress)
+			}
+			return sockets.DialContext(ctx, addr)
+		}),
+		grpc.WithDefaultCallOptions(callOpts...),
+		grpc.WithKeepaliveParams(keepalive.ClientParameters{
+			Time:                grpcKeepaliveTime,
+			Timeout:             grpcKeepaliveTimeout,
+			PermitWithoutStream: true,
 		}),
 	)
 	if err != nil {
@@ -227,6 +396,7 @@ func (f *MetricGRPCFacade) Updates(ctx context.Context, metrics []*types.Metrics
 			Type:  m.Type,
 			Value: 0,
 			Delta: 0,
+			Op:    m.Op,
 		}
 
 		if m.Value != nil {
@@ -243,6 +413,15 @@ func (f *MetricGRPCFacade) Updates(ctx context.Context, metrics []*types.Metrics
 		Metrics: pbMetrics,
 	}
 
+	// The signature covers the plain message, so the server can verify it after decryption.
+	if f.key != "" && f.header != "" {
+		body, err := proto.MarshalOptions{Deterministic: true}.Marshal(req)
+		if err != nil {
+			return err
+		}
+		ctx = metadata.AppendToOutgoingContext(ctx, strings.ToLower(f.header), calcBodyHashSum(body, f.key))
+	}
+
 	resp, err := f.client.Updates(ctx, req)
 	if err != nil {
 		return err
@@ -12,11 +12,14 @@ import (
 	"net/http/httptest"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
 
 	"github.com/sbilibin2017/go-yandex-practicum/internal/types"
 	pb "github.com/sbilibin2017/go-yandex-practicum/protos"
@@ -362,6 +365,51 @@ func TestMetricGRPCFacade_Updates(t *testing.T) {
 		assert.NoError(t, err)
 		mockClient.AssertExpectations(t)
 	})
+
+	t.Run("sends the update operation", func(t *testing.T) {
+		mockClient.On("Updates", mock.Anything, mock.MatchedBy(func(req *pb.UpdateMetricsRequest) bool {
+			return len(req.Metrics) == 2 && req.Metrics[0].Op == types.OpMax && req.Metrics[1].Op == ""
+		})).Return(&pb.UpdateMetricsResponse{}, nil).Once()
+
+		metrics := []*types.Metrics{
+			{ID: "HeapPeak", Type: "gauge", Value: float64Ptr(2.5), Op: types.OpMax},
+			{ID: "PollCount", Type: "counter", Delta: int64Ptr(1)},
+		}
+		err := facade.Updates(context.Background(), metrics)
+		assert.NoError(t, err)
+		mockClient.AssertExpectations(t)
+	})
+}
+
+func TestMetricGRPCFacade_Updates_SignsRequest(t *testing.T) {
+	value := 1.5
+	mockClient := new(MockMetricUpdaterClient)
+	facade := &MetricGRPCFacade{client: mockClient, key: "secret", header: "HashSHA256"}
+
+	mockClient.On("Updates", mock.MatchedBy(func(ctx context.Context) bool {
+		md, _ := metadata.FromOutgoingContext(ctx)
+		return len(md.Get("hashsha256")) == 1
+	}), mock.Anything).Return(&pb.UpdateMetricsResponse{}, nil).Once()
+
+	err := facade.Updates(context.Background(), []*types.Metrics{{ID: "Alloc", Type: "gauge", Value: &value}})
+	assert.NoError(t, err)
+	mockClient.AssertExpectations(t)
+
+	// The signature is the HMAC of the deterministic encoding of the request.
+	ctx := mockClient.Calls[0].Arguments.Get(0).(context.Context)
+	req := mockClient.Calls[0].Arguments.Get(1).(*pb.UpdateMetricsRequest)
+	body, err := proto.MarshalOptions{Deterministic: true}.Marshal(req)
+	require.NoError(t, err)
+	md, _ := metadata.FromOutgoingContext(ctx)
+	assert.Equal(t, calcBodyHashSum(body, "secret"), md.Get("hashsha256")[0])
+}
+
+func TestNewMetricGRPCFacade_WithInvalidCryptoKeyPath(t *testing.T) {
+	_, err := NewMetricGRPCFacade(
+		WithMetricGRPCServerAddress("localhost:50051"),
+		WithMetricGRPCCryptoKeyPath("/nonexistent/key.pem"),
+	)
+	assert.Error(t, err)
 }
 
 func TestNewMetricGRPCFacade(t *testing.T) {
@@ -379,3 +427,24 @@ func TestNewMetricGRPCFacade(t *testing.T) {
 		}
 	})
 }
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		value string
+		want  time.Duration
+	}{
+		{value: "", want: 0},
+		{value: "5", want: 5 * time.Second},
+		{value: "0", want: 0},
+		{value: "-3", want: 0},
+		{value: now.Add(30 * time.Second).Format(http.TimeFormat), want: 30 * time.Second},
+		{value: now.Add(-time.Minute).Format(http.TimeFormat), want: 0},
+		{value: "soon", want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			assert.Equal(t, tt.want, parseRetryAfter(tt.value, now))
+		})
+	}
+}
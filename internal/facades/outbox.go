@@ -0,0 +1,96 @@
+package facades
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/sbilibin2017/go-yandex-practicum/internal/logger"
+	"github.com/sbilibin2017/go-yandex-practicum/internal/types"
+)
+
+// Outbox defines the durable queue batches are kept in while the server is unreachable.
+type Outbox interface {
+	Append(metrics []*types.Metrics) error
+	Replay(ctx context.Context, send func(ctx context.Context, metrics []*types.Metrics) error) error
+	Len() int
+}
+
+// MetricOutboxFacade sends metrics through another facade, keeping the
+// batches it fails to deliver in an outbox and replaying them, oldest first,
+// before anything newer is sent.
+type MetricOutboxFacade struct {
+	next   MetricFacade
+	outbox Outbox
+
+	mu sync.Mutex
+}
+
+// MetricOutboxFacadeOpt defines functional option type for MetricOutboxFacade.
+type MetricOutboxFacadeOpt func(*MetricOutboxFacade)
+
+// WithMetricOutboxNext sets the facade batches are delivered through.
+func WithMetricOutboxNext(next MetricFacade) MetricOutboxFacadeOpt {
+	return func(f *MetricOutboxFacade) {
+		f.next = next
+	}
+}
+
+// WithMetricOutbox sets the outbox undelivered batches are kept in.
+func WithMetricOutbox(outbox Outbox) MetricOutboxFacadeOpt {
+	return func(f *MetricOutboxFacade) {
+		f.outbox = outbox
+	}
+}
+
+// NewMetricOutboxFacade creates a MetricOutboxFacade.
+func NewMetricOutboxFacade(opts ...MetricOutboxFacadeOpt) *MetricOutboxFacade {
+	f := &MetricOutboxFacade{}
+	for _, opt := range opts {
+		opt(f)
+	}
+	return f
+}
+
+// Updates delivers pending batches and then metrics. A batch that fails with
+// a retriable error is queued in the outbox; other errors, and a batch the
+// outbox cannot take, are reported.
+func (f *MetricOutboxFacade) Updates(ctx context.Context, metrics []*types.Metrics) error {
+	// Held throughout, so a new batch never overtakes the ones being replayed
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.outbox.Len() > 0 {
+		if err := f.outbox.Replay(ctx, f.replay); err != nil {
+			return f.queue(metrics, err)
+		}
+		logger.Log.Infow("Outbox drained")
+	}
+	if err := f.next.Updates(ctx, metrics); err != nil {
+		return f.queue(metrics, err)
+	}
+	return nil
+}
+
+// replay delivers a pending batch. A batch the server rejects for good would
+// block the outbox forever, so it is dropped instead.
+func (f *MetricOutboxFacade) replay(ctx context.Context, metrics []*types.Metrics) error {
+	err := f.next.Updates(ctx, metrics)
+	if err == nil || IsRetriable(err) || ctx.Err() != nil ||
+		errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return err
+	}
+	logger.Log.Errorw("Outbox: dropping batch rejected by the server", "error", err, "metrics", len(metrics))
+	return nil
+}
+
+func (f *MetricOutboxFacade) queue(metrics []*types.Metrics, cause error) error {
+	if !IsRetriable(cause) {
+		return cause
+	}
+	if err := f.outbox.Append(metrics); err != nil {
+		return errors.Join(cause, err)
+	}
+	logger.Log.Warnw("Metrics not delivered, queued in outbox", "error", cause, "pending", f.outbox.Len())
+	return nil
+}
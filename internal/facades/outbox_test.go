@@ -0,0 +1,204 @@
+package facades
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/sbilibin2017/go-yandex-practicum/internal/outbox"
+	"github.com/sbilibin2017/go-yandex-practicum/internal/types"
+)
+
+type MockMetricFacade struct {
+	mock.Mock
+}
+
+func (m *MockMetricFacade) Updates(ctx context.Context, metrics []*types.Metrics) error {
+	return m.Called(ctx, metrics).Error(0)
+}
+
+func counterBatch(id string) []*types.Metrics {
+	delta := int64(1)
+	return []*types.Metrics{{ID: id, Type: types.Counter, Delta: &delta}}
+}
+
+func batchID(id string) any {
+	return mock.MatchedBy(func(metrics []*types.Metrics) bool {
+		return len(metrics) == 1 && metrics[0].ID == id
+	})
+}
+
+func TestMetricOutboxFacade_Updates(t *testing.T) {
+	box, err := outbox.Open(outbox.WithDir(t.TempDir()))
+	require.NoError(t, err)
+	defer box.Close()
+
+	next := &MockMetricFacade{}
+	f := NewMetricOutboxFacade(WithMetricOutboxNext(next), WithMetricOutbox(box))
+	ctx := context.Background()
+	down := &HTTPStatusError{StatusCode: http.StatusServiceUnavailable}
+
+	// Delivered directly while the outbox is empty
+	next.On("Updates", mock.Anything, batchID("b0")).Return(nil).Once()
+	require.NoError(t, f.Updates(ctx, counterBatch("b0")))
+	assert.Zero(t, box.Len())
+
+	// Queued while the server is down
+	next.On("Updates", mock.Anything, batchID("b1")).Return(down).Once()
+	next.On("Updates", mock.Anything, batchID("b1")).Return(down).Once()
+	require.NoError(t, f.Updates(ctx, counterBatch("b1")))
+	require.NoError(t, f.Updates(ctx, counterBatch("b2")))
+	assert.Equal(t, 2, box.Len())
+
+	// Replayed in order before the new batch once the server recovers
+	var order []string
+	next.ExpectedCalls = nil
+	next.On("Updates", mock.Anything, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		order = append(order, args.Get(1).([]*types.Metrics)[0].ID)
+	})
+	require.NoError(t, f.Updates(ctx, counterBatch("b3")))
+	assert.Equal(t, []string{"b1", "b2", "b3"}, order)
+	assert.Zero(t, box.Len())
+}
+
+func TestMetricOutboxFacade_Updates_OutboxFull(t *testing.T) {
+	box, err := outbox.Open(outbox.WithDir(t.TempDir()), outbox.WithMaxBytes(10), outbox.WithDropPolicy(outbox.DropNewest))
+	require.NoError(t, err)
+	defer box.Close()
+
+	next := &MockMetricFacade{}
+	next.On("Updates", mock.Anything, mock.Anything).Return(&HTTPStatusError{StatusCode: http.StatusBadGateway})
+	f := NewMetricOutboxFacade(WithMetricOutboxNext(next), WithMetricOutbox(box))
+
+	err = f.Updates(context.Background(), counterBatch("b0"))
+	assert.ErrorIs(t, err, outbox.ErrFull)
+}
+
+func TestMetricOutboxFacade_Updates_PermanentError(t *testing.T) {
+	box, err := outbox.Open(outbox.WithDir(t.TempDir()))
+	require.NoError(t, err)
+	defer box.Close()
+
+	next := &MockMetricFacade{}
+	f := NewMetricOutboxFacade(WithMetricOutboxNext(next), WithMetricOutbox(box))
+	ctx := context.Background()
+	down := &HTTPStatusError{StatusCode: http.StatusServiceUnavailable}
+	rejected := &HTTPStatusError{StatusCode: http.StatusBadRequest}
+
+	// A rejected batch is reported, not queued
+	next.On("Updates", mock.Anything, batchID("b0")).Return(rejected).Once()
+	assert.ErrorIs(t, f.Updates(ctx, counterBatch("b0")), rejected)
+	assert.Zero(t, box.Len())
+
+	next.On("Updates", mock.Anything, batchID("b1")).Return(down).Twice()
+	require.NoError(t, f.Updates(ctx, counterBatch("b1")))
+	require.NoError(t, f.Updates(ctx, counterBatch("b2")))
+	assert.Equal(t, 2, box.Len())
+
+	// A queued batch rejected on replay is dropped and the rest delivered
+	var order []string
+	next.ExpectedCalls = nil
+	next.On("Updates", mock.Anything, batchID("b1")).Return(rejected).Once()
+	next.On("Updates", mock.Anything, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		order = append(order, args.Get(1).([]*types.Metrics)[0].ID)
+	})
+	require.NoError(t, f.Updates(ctx, counterBatch("b3")))
+	assert.Equal(t, []string{"b2", "b3"}, order)
+	assert.Zero(t, box.Len())
+}
+
+func TestMetricOutboxFacade_Updates_Concurrent(t *testing.T) {
+	box, err := outbox.Open(outbox.WithDir(t.TempDir()))
+	require.NoError(t, err)
+	defer box.Close()
+	require.NoError(t, box.Append(counterBatch("b0")))
+
+	var (
+		mu    sync.Mutex
+		order []string
+	)
+	record := func(args mock.Arguments) {
+		mu.Lock()
+		defer mu.Unlock()
+		order = append(order, args.Get(1).([]*types.Metrics)[0].ID)
+	}
+	sending := make(chan struct{})
+	release := make(chan struct{})
+	next := &MockMetricFacade{}
+	next.On("Updates", mock.Anything, batchID("b1")).Return(nil).Once().Run(func(args mock.Arguments) {
+		close(sending)
+		<-release
+		record(args)
+	})
+	next.On("Updates", mock.Anything, mock.Anything).Return(nil).Run(record)
+	f := NewMetricOutboxFacade(WithMetricOutboxNext(next), WithMetricOutbox(box))
+	ctx := context.Background()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- f.Updates(ctx, counterBatch("b1"))
+	}()
+	<-sending
+
+	// The outbox is empty while b1 is sent after the replay, yet b2 must wait for it
+	late := make(chan error, 1)
+	go func() {
+		late <- f.Updates(ctx, counterBatch("b2"))
+	}()
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	require.NoError(t, <-done)
+	require.NoError(t, <-late)
+	assert.Equal(t, []string{"b0", "b1", "b2"}, order)
+}
+
+func TestMetricOutboxFacade_Updates_BreakerOpen(t *testing.T) {
+	box, err := outbox.Open(outbox.WithDir(t.TempDir()))
+	require.NoError(t, err)
+	defer box.Close()
+
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	breaker := NewCircuitBreaker(
+		WithCircuitBreakerThreshold(1),
+		WithCircuitBreakerCooldown(time.Minute),
+		WithCircuitBreakerClock(func() time.Time { return now }),
+	)
+	next := &MockMetricFacade{}
+	resilient := NewMetricResilientFacade(
+		WithMetricResilientNext(next),
+		WithMetricResilientMaxAttempts(1),
+		WithMetricResilientBreaker(breaker),
+	)
+	f := NewMetricOutboxFacade(WithMetricOutboxNext(resilient), WithMetricOutbox(box))
+	ctx := context.Background()
+
+	// The server goes down and the breaker opens
+	next.On("Updates", mock.Anything, batchID("b0")).Return(status.Error(codes.Unavailable, "down")).Once()
+	require.NoError(t, f.Updates(ctx, counterBatch("b0")))
+	require.Equal(t, BreakerOpen, breaker.State())
+	assert.Equal(t, 1, box.Len())
+
+	// While it is open nothing is sent, and nothing is lost either
+	require.NoError(t, f.Updates(ctx, counterBatch("b1")))
+	require.NoError(t, f.Updates(ctx, counterBatch("b2")))
+	assert.Equal(t, 3, box.Len())
+	next.AssertNumberOfCalls(t, "Updates", 1)
+
+	// Once the cooldown passes, every batch is delivered in order
+	now = now.Add(2 * time.Minute)
+	var order []string
+	next.On("Updates", mock.Anything, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		order = append(order, args.Get(1).([]*types.Metrics)[0].ID)
+	})
+	require.NoError(t, f.Updates(ctx, counterBatch("b3")))
+	assert.Equal(t, []string{"b0", "b1", "b2", "b3"}, order)
+	assert.Zero(t, box.Len())
+}
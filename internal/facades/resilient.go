@@ -0,0 +1,199 @@
+package facades
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/sbilibin2017/go-yandex-practicum/internal/logger"
+	"github.com/sbilibin2017/go-yandex-practicum/internal/types"
+)
+
+// MetricResilientFacade sends metrics through another facade, retrying
+// transient failures with exponential backoff and jitter, and guarding the
+// server with a CircuitBreaker. It is the only retry layer of the agent: the
+// HTTP and gRPC facades send each batch once, so every attempt is counted by
+// the breaker.
+type MetricResilientFacade struct {
+	next        MetricFacade
+	breaker     *CircuitBreaker
+	maxAttempts int
+	initial     time.Duration
+	max         time.Duration
+	multiplier  float64
+	jitter      float64
+	random      func() float64
+}
+
+// MetricResilientFacadeOpt defines functional option type for MetricResilientFacade.
+type MetricResilientFacadeOpt func(*MetricResilientFacade)
+
+// WithMetricResilientNext sets the facade requests are sent through.
+func WithMetricResilientNext(next MetricFacade) MetricResilientFacadeOpt {
+	return func(f *MetricResilientFacade) {
+		f.next = next
+	}
+}
+
+// WithMetricResilientBreaker sets the circuit breaker guarding the server.
+func WithMetricResilientBreaker(breaker *CircuitBreaker) MetricResilientFacadeOpt {
+	return func(f *MetricResilientFacade) {
+		f.breaker = breaker
+	}
+}
+
+// WithMetricResilientMaxAttempts sets how many times a batch is sent, including the first attempt.
+func WithMetricResilientMaxAttempts(n int) MetricResilientFacadeOpt {
+	return func(f *MetricResilientFacade) {
+		f.maxAttempts = n
+	}
+}
+
+// WithMetricResilientBackoff sets the delay before the first retry, the cap on
+// the delay and the factor it grows by after each retry.
+func WithMetricResilientBackoff(initial, max time.Duration, multiplier float64) MetricResilientFacadeOpt {
+	return func(f *MetricResilientFacade) {
+		f.initial = initial
+		f.max = max
+		f.multiplier = multiplier
+	}
+}
+
+// WithMetricResilientJitter randomizes each delay by up to ±fraction of it,
+// so agents restarted together do not retry in lockstep.
+func WithMetricResilientJitter(fraction float64) MetricResilientFacadeOpt {
+	return func(f *MetricResilientFacade) {
+		f.jitter = fraction
+	}
+}
+
+// WithMetricResilientRandom sets the source of jitter, returning values in [0, 1).
+func WithMetricResilientRandom(random func() float64) MetricResilientFacadeOpt {
+	return func(f *MetricResilientFacade) {
+		f.random = random
+	}
+}
+
+// NewMetricResilientFacade creates a MetricResilientFacade. The defaults
+// retry up to three times after 1s, 3s and 5s, and never open the breaker.
+func NewMetricResilientFacade(opts ...MetricResilientFacadeOpt) *MetricResilientFacade {
+	f := &MetricResilientFacade{
+		maxAttempts: 4,
+		initial:     time.Second,
+		max:         5 * time.Second,
+		multiplier:  3,
+		jitter:      0.2,
+		random:      rand.Float64,
+	}
+	for _, opt := range opts {
+		opt(f)
+	}
+	if f.breaker == nil {
+		f.breaker = NewCircuitBreaker(WithCircuitBreakerThreshold(0))
+	}
+	return f
+}
+
+// Breaker returns the circuit breaker guarding the server.
+func (f *MetricResilientFacade) Breaker() *CircuitBreaker {
+	return f.breaker
+}
+
+// Updates sends metrics, retrying retriable errors until the attempts run
+// out, the context is done or the circuit breaker opens.
+func (f *MetricResilientFacade) Updates(ctx context.Context, metrics []*types.Metrics) error {
+	var err error
+	for attempt := 1; ; attempt++ {
+		if berr := f.breaker.Allow(); berr != nil {
+			return errors.Join(berr, err)
+		}
+
+		err = f.next.Updates(ctx, metrics)
+		if err == nil || !IsRetriable(err) {
+			// The server answered, even if it rejected the batch
+			f.breaker.Success()
+			return err
+		}
+		f.breaker.Failure()
+
+		if attempt >= f.maxAttempts || ctx.Err() != nil {
+			return err
+		}
+
+		delay := f.backoff(attempt, err)
+		logger.Log.Warnw("Sending metrics failed, retrying", "attempt", attempt, "delay", delay, "error", err)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
+
+// backoff returns the delay before retry number attempt, or the server's
+// Retry-After when that is longer.
+func (f *MetricResilientFacade) backoff(attempt int, err error) time.Duration {
+	d := float64(f.initial) * math.Pow(f.multiplier, float64(attempt-1))
+	if f.max > 0 {
+		d = math.Min(d, float64(f.max))
+	}
+	if f.jitter > 0 {
+		d *= 1 - f.jitter + 2*f.jitter*f.random()
+	}
+	delay := time.Duration(d)
+
+	var statusErr *HTTPStatusError
+	if errors.As(err, &statusErr) && statusErr.RetryAfter > delay {
+		delay = statusErr.RetryAfter
+	}
+	return delay
+}
+
+// IsRetriable reports whether err is a transient failure worth retrying:
+// server errors and throttling responses, refused or reset connections, the
+// gRPC Unavailable and ResourceExhausted codes, and an open circuit breaker.
+// Context cancellation is not.
+func IsRetriable(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, ErrCircuitOpen) {
+		return true
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var statusErr *HTTPStatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode >= 500 || statusErr.StatusCode == http.StatusTooManyRequests
+	}
+
+	if s, ok := status.FromError(err); ok && s.Code() != codes.Unknown {
+		switch s.Code() {
+		case codes.Unavailable, codes.ResourceExhausted:
+			return true
+		default:
+			return false
+		}
+	}
+
+	if errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+	var opErr *net.OpError
+	return errors.As(err, &opErr)
+}
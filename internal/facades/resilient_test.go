@@ -0,0 +1,201 @@
+package facades
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestIsRetriable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "nil", err: nil},
+		{name: "server error", err: &HTTPStatusError{StatusCode: http.StatusBadGateway}, want: true},
+		{name: "throttled", err: &HTTPStatusError{StatusCode: http.StatusTooManyRequests}, want: true},
+		{name: "bad request", err: &HTTPStatusError{StatusCode: http.StatusBadRequest}},
+		{name: "wrapped server error", err: fmt.Errorf("send: %w", &HTTPStatusError{StatusCode: 503}), want: true},
+		{name: "connection refused", err: fmt.Errorf("failed to send metrics: %w", syscall.ECONNREFUSED), want: true},
+		{name: "connection reset", err: syscall.ECONNRESET, want: true},
+		{name: "dial error", err: &net.OpError{Op: "dial", Err: errors.New("no route to host")}, want: true},
+		{name: "unexpected EOF", err: io.ErrUnexpectedEOF, want: true},
+		{name: "grpc unavailable", err: status.Error(codes.Unavailable, "down"), want: true},
+		{name: "grpc resource exhausted", err: status.Error(codes.ResourceExhausted, "slow down"), want: true},
+		{name: "grpc invalid argument", err: status.Error(codes.InvalidArgument, "bad")},
+		{name: "grpc permission denied", err: status.Error(codes.PermissionDenied, "no")},
+		{name: "canceled", err: context.Canceled},
+		{name: "deadline", err: fmt.Errorf("send: %w", context.DeadlineExceeded)},
+		{name: "application error", err: errors.New("invalid metric")},
+		{name: "circuit open", err: ErrCircuitOpen, want: true},
+		{name: "circuit open after failure", err: errors.Join(ErrCircuitOpen, status.Error(codes.Unavailable, "down")), want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, IsRetriable(tt.err))
+		})
+	}
+}
+
+func TestMetricResilientFacade_Backoff(t *testing.T) {
+	tests := []struct {
+		name    string
+		jitter  float64
+		random  float64
+		attempt int
+		err     error
+		want    time.Duration
+	}{
+		{name: "first retry", attempt: 1, want: time.Second},
+		{name: "grows", attempt: 2, want: 3 * time.Second},
+		{name: "capped", attempt: 3, want: 5 * time.Second},
+		{name: "jitter low", jitter: 0.2, random: 0, attempt: 1, want: 800 * time.Millisecond},
+		{name: "jitter high", jitter: 0.2, random: 1, attempt: 1, want: 1200 * time.Millisecond},
+		{name: "retry-after is longer", attempt: 1, err: &HTTPStatusError{StatusCode: 503, RetryAfter: 10 * time.Second}, want: 10 * time.Second},
+		{name: "retry-after is shorter", attempt: 2, err: &HTTPStatusError{StatusCode: 503, RetryAfter: time.Second}, want: 3 * time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := NewMetricResilientFacade(
+				WithMetricResilientBackoff(time.Second, 5*time.Second, 3),
+				WithMetricResilientJitter(tt.jitter),
+				WithMetricResilientRandom(func() float64 { return tt.random }),
+			)
+			assert.Equal(t, tt.want, f.backoff(tt.attempt, tt.err))
+		})
+	}
+}
+
+func TestMetricResilientFacade_Updates(t *testing.T) {
+	unavailable := status.Error(codes.Unavailable, "down")
+	invalid := status.Error(codes.InvalidArgument, "bad")
+
+	tests := []struct {
+		name      string
+		results   []error
+		wantErr   error
+		wantCalls int
+	}{
+		{name: "first attempt succeeds", results: []error{nil}, wantCalls: 1},
+		{name: "recovers after retries", results: []error{unavailable, unavailable, nil}, wantCalls: 3},
+		{name: "gives up after max attempts", results: []error{unavailable, unavailable, unavailable}, wantErr: unavailable, wantCalls: 3},
+		{name: "non-retriable error is returned at once", results: []error{invalid}, wantErr: invalid, wantCalls: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next := &MockMetricFacade{}
+			for _, res := range tt.results {
+				next.On("Updates", mock.Anything, mock.Anything).Return(res).Once()
+			}
+			f := NewMetricResilientFacade(
+				WithMetricResilientNext(next),
+				WithMetricResilientMaxAttempts(3),
+				WithMetricResilientBackoff(time.Millisecond, 5*time.Millisecond, 2),
+			)
+
+			err := f.Updates(context.Background(), counterBatch("Retried"))
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			next.AssertNumberOfCalls(t, "Updates", tt.wantCalls)
+			assert.Equal(t, BreakerClosed, f.Breaker().State())
+		})
+	}
+}
+
+func TestMetricResilientFacade_Updates_BreakerOpens(t *testing.T) {
+	next := &MockMetricFacade{}
+	next.On("Updates", mock.Anything, mock.Anything).Return(status.Error(codes.Unavailable, "down"))
+
+	breaker := NewCircuitBreaker(WithCircuitBreakerThreshold(2), WithCircuitBreakerCooldown(time.Hour))
+	f := NewMetricResilientFacade(
+		WithMetricResilientNext(next),
+		WithMetricResilientMaxAttempts(5),
+		WithMetricResilientBackoff(time.Millisecond, time.Millisecond, 1),
+		WithMetricResilientBreaker(breaker),
+	)
+
+	err := f.Updates(context.Background(), counterBatch("Tripped"))
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+	assert.Contains(t, err.Error(), "down", "the last error is kept")
+	next.AssertNumberOfCalls(t, "Updates", 2)
+	assert.Equal(t, BreakerOpen, f.Breaker().State())
+
+	// The dead server is not contacted again until the cooldown passes
+	err = f.Updates(context.Background(), counterBatch("Tripped"))
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+	next.AssertNumberOfCalls(t, "Updates", 2)
+}
+
+func TestMetricResilientFacade_Updates_ContextDone(t *testing.T) {
+	next := &MockMetricFacade{}
+	next.On("Updates", mock.Anything, mock.Anything).Return(status.Error(codes.Unavailable, "down"))
+	f := NewMetricResilientFacade(
+		WithMetricResilientNext(next),
+		WithMetricResilientBackoff(time.Hour, time.Hour, 1),
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	err := f.Updates(ctx, counterBatch("Canceled"))
+	assert.Error(t, err)
+	assert.Less(t, time.Since(start), time.Second)
+	next.AssertNumberOfCalls(t, "Updates", 1)
+}
+
+func TestMetricResilientFacade_HTTP_RetryAfter(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	httpFacade, err := NewMetricHTTPFacade(WithMetricFacadeServerAddress(srv.URL))
+	require.NoError(t, err)
+	f := NewMetricResilientFacade(
+		WithMetricResilientNext(httpFacade),
+		WithMetricResilientBackoff(time.Millisecond, time.Millisecond, 1),
+	)
+
+	start := time.Now()
+	require.NoError(t, f.Updates(context.Background(), counterBatch("RetryAfter")))
+	assert.GreaterOrEqual(t, time.Since(start), time.Second, "Retry-After is honoured")
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func TestMetricResilientFacade_HTTP_ConnectionRefused(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := lis.Addr().String()
+	lis.Close()
+
+	httpFacade, err := NewMetricHTTPFacade(WithMetricFacadeServerAddress(addr))
+	require.NoError(t, err)
+
+	err = httpFacade.Updates(context.Background(), counterBatch("Refused"))
+	require.Error(t, err)
+	assert.True(t, IsRetriable(err))
+}
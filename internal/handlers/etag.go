@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/sbilibin2017/go-yandex-practicum/internal/types"
+)
+
+// metricETag formats a metric version as a strong entity tag.
+func metricETag(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+// setMetricETag writes the ETag header for the given metric.
+func setMetricETag(w http.ResponseWriter, metric *types.Metrics) {
+	w.Header().Set("ETag", metricETag(metric.Version))
+}
+
+// ifNoneMatch reports whether the If-None-Match header matches the metric version,
+// in which case the client copy is current and 304 should be returned.
+// Entity tags are compared weakly, as RFC 9110 requires for this header.
+func ifNoneMatch(r *http.Request, version int64) bool {
+	header := r.Header.Get("If-None-Match")
+	if header == "" {
+		return false
+	}
+	etag := metricETag(version)
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// ifMatchVersion extracts the expected metric version from the If-Match header.
+// It returns nil when the header is absent or "*", and ok=false when the header
+// is not a single strong entity tag holding a version.
+func ifMatchVersion(r *http.Request) (version *int64, ok bool) {
+	header := strings.TrimSpace(r.Header.Get("If-Match"))
+	if header == "" || header == "*" {
+		return nil, true
+	}
+	if len(header) < 2 || header[0] != '"' || header[len(header)-1] != '"' {
+		return nil, false
+	}
+	v, err := strconv.ParseInt(header[1:len(header)-1], 10, 64)
+	if err != nil || v < 0 {
+		return nil, false
+	}
+	return &v, true
+}
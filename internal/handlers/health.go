@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/sbilibin2017/go-yandex-practicum/internal/types"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+// HealthChecker defines an interface for checking storage health.
+type HealthChecker interface {
+	Check(ctx context.Context) []types.ComponentHealth
+}
+
+// HealthGRPCHandler implements the standard grpc.health.v1 service on top of a HealthChecker.
+// The empty service name and every registered service share the storage status.
+type HealthGRPCHandler struct {
+	healthpb.UnimplementedHealthServer
+
+	checker       HealthChecker
+	services      map[string]struct{}
+	watchInterval time.Duration
+
+	shutdownOnce sync.Once
+	shutdown     chan struct{}
+}
+
+// HealthGRPCHandlerOption defines a functional option for configuring HealthGRPCHandler.
+type HealthGRPCHandlerOption func(*HealthGRPCHandler)
+
+// WithHealthGRPCChecker sets the checker used to compute the serving status.
+func WithHealthGRPCChecker(checker HealthChecker) HealthGRPCHandlerOption {
+	return func(h *HealthGRPCHandler) {
+		h.checker = checker
+	}
+}
+
+// WithHealthGRPCServices sets the fully qualified service names the handler reports on.
+func WithHealthGRPCServices(names ...string) HealthGRPCHandlerOption {
+	return func(h *HealthGRPCHandler) {
+		for _, name := range names {
+			h.services[name] = struct{}{}
+		}
+	}
+}
+
+// WithHealthGRPCWatchInterval sets how often Watch re-checks the status (default 5s).
+func WithHealthGRPCWatchInterval(interval time.Duration) HealthGRPCHandlerOption {
+	return func(h *HealthGRPCHandler) {
+		h.watchInterval = interval
+	}
+}
+
+// NewHealthGRPCHandler creates a new HealthGRPCHandler with the given options.
+func NewHealthGRPCHandler(opts ...HealthGRPCHandlerOption) *HealthGRPCHandler {
+	h := &HealthGRPCHandler{
+		services:      map[string]struct{}{"": {}},
+		watchInterval: 5 * time.Second,
+		shutdown:      make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
+}
+
+// Check returns the current serving status, or NotFound for unknown services.
+func (h *HealthGRPCHandler) Check(
+	ctx context.Context,
+	req *healthpb.HealthCheckRequest,
+) (*healthpb.HealthCheckResponse, error) {
+	if _, ok := h.services[req.GetService()]; !ok {
+		return nil, status.Error(codes.NotFound, "unknown service")
+	}
+	return &healthpb.HealthCheckResponse{Status: h.status(ctx)}, nil
+}
+
+// Watch streams the serving status whenever it changes. Unknown services report
+// SERVICE_UNKNOWN, as the health protocol requires. After Shutdown the stream
+// sends NOT_SERVING and ends.
+func (h *HealthGRPCHandler) Watch(
+	req *healthpb.HealthCheckRequest,
+	stream healthpb.Health_WatchServer,
+) error {
+	ctx := stream.Context()
+	_, known := h.services[req.GetService()]
+
+	ticker := time.NewTicker(h.watchInterval)
+	defer ticker.Stop()
+
+	last := healthpb.HealthCheckResponse_UNKNOWN
+	for {
+		current := healthpb.HealthCheckResponse_SERVICE_UNKNOWN
+		if known {
+			current = h.status(ctx)
+		}
+		if current != last {
+			if err := stream.Send(&healthpb.HealthCheckResponse{Status: current}); err != nil {
+				return err
+			}
+			last = current
+		}
+
+		select {
+		case <-ctx.Done():
+			return status.FromContextError(ctx.Err()).Err()
+		case <-h.shutdown:
+			if known && last != healthpb.HealthCheckResponse_NOT_SERVING {
+				return stream.Send(&healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_NOT_SERVING})
+			}
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Shutdown makes every service report NOT_SERVING and ends open Watch streams,
+// so orchestrators stop routing traffic before the server stops.
+func (h *HealthGRPCHandler) Shutdown() {
+	h.shutdownOnce.Do(func() { close(h.shutdown) })
+}
+
+func (h *HealthGRPCHandler) status(ctx context.Context) healthpb.HealthCheckResponse_ServingStatus {
+	select {
+	case <-h.shutdown:
+		return healthpb.HealthCheckResponse_NOT_SERVING
+	default:
+	}
+	if h.checker != nil && !types.AllHealthy(h.checker.Check(ctx)) {
+		return healthpb.HealthCheckResponse_NOT_SERVING
+	}
+	return healthpb.HealthCheckResponse_SERVING
+}
@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+
+	"github.com/sbilibin2017/go-yandex-practicum/internal/types"
+)
+
+var (
+	healthy   = []types.ComponentHealth{{Name: types.HealthComponentDatabase, Healthy: true}}
+	unhealthy = []types.ComponentHealth{{Name: types.HealthComponentDatabase, Error: "connection refused"}}
+)
+
+func startHealthServer(t *testing.T, h *HealthGRPCHandler) healthpb.HealthClient {
+	conn := startGRPCServer(t, func(srv *grpc.Server) {
+		healthpb.RegisterHealthServer(srv, h)
+	})
+	return healthpb.NewHealthClient(conn)
+}
+
+func TestHealthGRPCHandler_Check(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tests := []struct {
+		name       string
+		service    string
+		components []types.ComponentHealth
+		want       healthpb.HealthCheckResponse_ServingStatus
+		wantCode   codes.Code
+	}{
+		{
+			name:       "overall serving",
+			components: healthy,
+			want:       healthpb.HealthCheckResponse_SERVING,
+		},
+		{
+			name:       "registered service not serving",
+			service:    "svc.Metrics",
+			components: unhealthy,
+			want:       healthpb.HealthCheckResponse_NOT_SERVING,
+		},
+		{
+			name:     "unknown service",
+			service:  "svc.Unknown",
+			wantCode: codes.NotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checker := NewMockHealthChecker(ctrl)
+			if tt.components != nil {
+				checker.EXPECT().Check(gomock.Any()).Return(tt.components)
+			}
+			client := startHealthServer(t, NewHealthGRPCHandler(
+				WithHealthGRPCChecker(checker),
+				WithHealthGRPCServices("svc.Metrics"),
+			))
+
+			resp, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{Service: tt.service})
+			if tt.wantCode != codes.OK {
+				assert.Equal(t, tt.wantCode, status.Code(err))
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, resp.GetStatus())
+		})
+	}
+}
+
+func TestHealthGRPCHandler_Watch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	checker := NewMockHealthChecker(ctrl)
+	gomock.InOrder(
+		checker.EXPECT().Check(gomock.Any()).Return(healthy),
+		checker.EXPECT().Check(gomock.Any()).Return(healthy),
+		checker.EXPECT().Check(gomock.Any()).Return(unhealthy).AnyTimes(),
+	)
+
+	h := NewHealthGRPCHandler(
+		WithHealthGRPCChecker(checker),
+		WithHealthGRPCWatchInterval(10*time.Millisecond),
+	)
+	client := startHealthServer(t, h)
+
+	stream, err := client.Watch(context.Background(), &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+
+	// Only changes are sent.
+	resp, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, resp.GetStatus())
+	resp, err = stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, resp.GetStatus())
+
+	// Shutdown ends the stream.
+	h.Shutdown()
+	_, err = stream.Recv()
+	assert.Error(t, err)
+
+	resp, err = client.Check(context.Background(), &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, resp.GetStatus())
+}
+
+func TestHealthGRPCHandler_WatchUnknownService(t *testing.T) {
+	h := NewHealthGRPCHandler()
+	client := startHealthServer(t, h)
+
+	stream, err := client.Watch(context.Background(), &healthpb.HealthCheckRequest{Service: "svc.Unknown"})
+	require.NoError(t, err)
+
+	resp, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVICE_UNKNOWN, resp.GetStatus())
+
+	h.Shutdown()
+	_, err = stream.Recv()
+	assert.Error(t, err)
+}
@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/sbilibin2017/go-yandex-practicum/internal/types"
+)
+
+// MetricDeleter defines the interface for deleting a metric by ID.
+type MetricDeleter interface {
+	Delete(ctx context.Context, metricID types.MetricID) (bool, error)
+}
+
+// MetricPrefixDeleter defines the interface for deleting all metrics with an ID prefix.
+type MetricPrefixDeleter interface {
+	DeleteByPrefix(ctx context.Context, prefix string) ([]types.MetricID, error)
+}
+
+// MetricDeletePathHandler handles metric deletion via URL path parameters.
+type MetricDeletePathHandler struct {
+	svc MetricDeleter
+}
+
+// MetricDeletePathHandlerOption defines a functional option for configuring MetricDeletePathHandler.
+type MetricDeletePathHandlerOption func(*MetricDeletePathHandler)
+
+// WithMetricDeleterPath sets the MetricDeleter service on MetricDeletePathHandler.
+func WithMetricDeleterPath(svc MetricDeleter) MetricDeletePathHandlerOption {
+	return func(h *MetricDeletePathHandler) {
+		h.svc = svc
+	}
+}
+
+// NewMetricDeletePathHandler creates a new MetricDeletePathHandler with the provided options.
+func NewMetricDeletePathHandler(opts ...MetricDeletePathHandlerOption) *MetricDeletePathHandler {
+	h := &MetricDeletePathHandler{}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
+}
+
+// serveHTTP deletes the metric identified by the {type} and {name} URL parameters.
+func (h *MetricDeletePathHandler) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain")
+
+	Type := chi.URLParam(r, "type")
+	name := chi.URLParam(r, "name")
+
+	if name == "" {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	if Type != types.Counter && Type != types.Gauge {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	deleted, err := h.svc.Delete(r.Context(), types.MetricID{ID: name, Type: Type})
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	if !deleted {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// RegisterRoute registers the metric deletion routes on the provided router.
+func (h *MetricDeletePathHandler) RegisterRoute(r chi.Router) {
+	r.Delete("/value/{type}/{name}", h.serveHTTP)
+	r.Delete("/value/{type}", h.serveHTTP)
+}
+
+// MetricDeleteByPrefixHandler handles bulk deletion of metrics sharing an ID prefix.
+type MetricDeleteByPrefixHandler struct {
+	svc MetricPrefixDeleter
+}
+
+// MetricDeleteByPrefixHandlerOption defines a functional option for configuring MetricDeleteByPrefixHandler.
+type MetricDeleteByPrefixHandlerOption func(*MetricDeleteByPrefixHandler)
+
+// WithMetricPrefixDeleter sets the MetricPrefixDeleter service on MetricDeleteByPrefixHandler.
+func WithMetricPrefixDeleter(svc MetricPrefixDeleter) MetricDeleteByPrefixHandlerOption {
+	return func(h *MetricDeleteByPrefixHandler) {
+		h.svc = svc
+	}
+}
+
+// NewMetricDeleteByPrefixHandler creates a new MetricDeleteByPrefixHandler with the provided options.
+func NewMetricDeleteByPrefixHandler(opts ...MetricDeleteByPrefixHandlerOption) *MetricDeleteByPrefixHandler {
+	h := &MetricDeleteByPrefixHandler{}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
+}
+
+// serveHTTP deletes all metrics whose ID starts with the "prefix" query parameter
+// and responds with the JSON array of deleted metric IDs.
+func (h *MetricDeleteByPrefixHandler) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	prefix := r.URL.Query().Get("prefix")
+	if prefix == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	deleted, err := h.svc.DeleteByPrefix(r.Context(), prefix)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	if deleted == nil {
+		deleted = []types.MetricID{}
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(deleted)
+}
+
+// RegisterRoute registers the bulk deletion route on the provided router.
+func (h *MetricDeleteByPrefixHandler) RegisterRoute(r chi.Router) {
+	r.Delete("/values/", h.serveHTTP)
+}
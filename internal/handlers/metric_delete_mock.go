@@ -0,0 +1,89 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: /home/sergey/Go/go-yandex-practicum/internal/handlers/metric_delete.go
+
+// Package handlers is a generated GoMock package.
+package handlers
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	types "github.com/sbilibin2017/go-yandex-practicum/internal/types"
+)
+
+// MockMetricDeleter is a mock of MetricDeleter interface.
+type MockMetricDeleter struct {
+	ctrl     *gomock.Controller
+	recorder *MockMetricDeleterMockRecorder
+}
+
+// MockMetricDeleterMockRecorder is the mock recorder for MockMetricDeleter.
+type MockMetricDeleterMockRecorder struct {
+	mock *MockMetricDeleter
+}
+
+// NewMockMetricDeleter creates a new mock instance.
+func NewMockMetricDeleter(ctrl *gomock.Controller) *MockMetricDeleter {
+	mock := &MockMetricDeleter{ctrl: ctrl}
+	mock.recorder = &MockMetricDeleterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMetricDeleter) EXPECT() *MockMetricDeleterMockRecorder {
+	return m.recorder
+}
+
+// Delete mocks base method.
+func (m *MockMetricDeleter) Delete(ctx context.Context, metricID types.MetricID) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, metricID)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockMetricDeleterMockRecorder) Delete(ctx, metricID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockMetricDeleter)(nil).Delete), ctx, metricID)
+}
+
+// MockMetricPrefixDeleter is a mock of MetricPrefixDeleter interface.
+type MockMetricPrefixDeleter struct {
+	ctrl     *gomock.Controller
+	recorder *MockMetricPrefixDeleterMockRecorder
+}
+
+// MockMetricPrefixDeleterMockRecorder is the mock recorder for MockMetricPrefixDeleter.
+type MockMetricPrefixDeleterMockRecorder struct {
+	mock *MockMetricPrefixDeleter
+}
+
+// NewMockMetricPrefixDeleter creates a new mock instance.
+func NewMockMetricPrefixDeleter(ctrl *gomock.Controller) *MockMetricPrefixDeleter {
+	mock := &MockMetricPrefixDeleter{ctrl: ctrl}
+	mock.recorder = &MockMetricPrefixDeleterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMetricPrefixDeleter) EXPECT() *MockMetricPrefixDeleterMockRecorder {
+	return m.recorder
+}
+
+// DeleteByPrefix mocks base method.
+func (m *MockMetricPrefixDeleter) DeleteByPrefix(ctx context.Context, prefix string) ([]types.MetricID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteByPrefix", ctx, prefix)
+	ret0, _ := ret[0].([]types.MetricID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteByPrefix indicates an expected call of DeleteByPrefix.
+func (mr *MockMetricPrefixDeleterMockRecorder) DeleteByPrefix(ctx, prefix interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteByPrefix", reflect.TypeOf((*MockMetricPrefixDeleter)(nil).DeleteByPrefix), ctx, prefix)
+}
@@ -0,0 +1,161 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/golang/mock/gomock"
+	"github.com/sbilibin2017/go-yandex-practicum/internal/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetricDeletePathHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDeleter := NewMockMetricDeleter(ctrl)
+	handler := NewMetricDeletePathHandler(WithMetricDeleterPath(mockDeleter))
+
+	r := chi.NewRouter()
+	handler.RegisterRoute(r)
+
+	tests := []struct {
+		name         string
+		url          string
+		mockExpect   func()
+		expectedCode int
+	}{
+		{
+			name:         "Missing metric name",
+			url:          "/value/gauge",
+			mockExpect:   func() {},
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:         "Invalid metric type",
+			url:          "/value/unknown/CPUutilization7",
+			mockExpect:   func() {},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name: "Metric deleted",
+			url:  "/value/gauge/CPUutilization7",
+			mockExpect: func() {
+				mockDeleter.EXPECT().
+					Delete(gomock.Any(), types.MetricID{ID: "CPUutilization7", Type: types.Gauge}).
+					Return(true, nil)
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name: "Metric not found",
+			url:  "/value/counter/missing",
+			mockExpect: func() {
+				mockDeleter.EXPECT().
+					Delete(gomock.Any(), types.MetricID{ID: "missing", Type: types.Counter}).
+					Return(false, nil)
+			},
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name: "Deleter returns error",
+			url:  "/value/counter/errorMetric",
+			mockExpect: func() {
+				mockDeleter.EXPECT().
+					Delete(gomock.Any(), types.MetricID{ID: "errorMetric", Type: types.Counter}).
+					Return(false, context.DeadlineExceeded)
+			},
+			expectedCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockExpect()
+
+			req := httptest.NewRequest(http.MethodDelete, tt.url, nil)
+			rr := httptest.NewRecorder()
+
+			r.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedCode, rr.Code)
+		})
+	}
+}
+
+func TestMetricDeleteByPrefixHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDeleter := NewMockMetricPrefixDeleter(ctrl)
+	handler := NewMetricDeleteByPrefixHandler(WithMetricPrefixDeleter(mockDeleter))
+
+	r := chi.NewRouter()
+	handler.RegisterRoute(r)
+
+	tests := []struct {
+		name         string
+		url          string
+		mockExpect   func()
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			name:         "Missing prefix",
+			url:          "/values/",
+			mockExpect:   func() {},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name: "Metrics deleted",
+			url:  "/values/?prefix=CPU",
+			mockExpect: func() {
+				mockDeleter.EXPECT().
+					DeleteByPrefix(gomock.Any(), "CPU").
+					Return([]types.MetricID{{ID: "CPUutilization7", Type: types.Gauge}}, nil)
+			},
+			expectedCode: http.StatusOK,
+			expectedBody: `[{"id":"CPUutilization7","type":"gauge"}]`,
+		},
+		{
+			name: "Nothing matched",
+			url:  "/values/?prefix=none",
+			mockExpect: func() {
+				mockDeleter.EXPECT().
+					DeleteByPrefix(gomock.Any(), "none").
+					Return(nil, nil)
+			},
+			expectedCode: http.StatusOK,
+			expectedBody: `[]`,
+		},
+		{
+			name: "Deleter returns error",
+			url:  "/values/?prefix=CPU",
+			mockExpect: func() {
+				mockDeleter.EXPECT().
+					DeleteByPrefix(gomock.Any(), "CPU").
+					Return(nil, context.DeadlineExceeded)
+			},
+			expectedCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockExpect()
+
+			req := httptest.NewRequest(http.MethodDelete, tt.url, nil)
+			rr := httptest.NewRecorder()
+
+			r.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedCode, rr.Code)
+			if tt.expectedBody != "" {
+				assert.JSONEq(t, tt.expectedBody, rr.Body.String())
+			}
+		})
+	}
+}
@@ -15,6 +15,12 @@ type MetricGetter interface {
 	Get(ctx context.Context, metricID types.MetricID) (*types.Metrics, error)
 }
 
+// MetricBatchGetter defines the interface for retrieving several metrics by their IDs.
+// It returns the found metrics and the IDs that do not exist.
+type MetricBatchGetter interface {
+	GetBatch(ctx context.Context, metricIDs []types.MetricID) ([]*types.Metrics, []types.MetricID, error)
+}
+
 // --- Functional Options for MetricGetPathHandler ---
 
 type MetricGetPathHandler struct {
@@ -82,6 +88,12 @@ func (h *MetricGetPathHandler) serveHTTP(w http.ResponseWriter, r *http.Request)
 		valueString = strconv.FormatFloat(*metric.Value, 'f', -1, 64)
 	}
 
+	setMetricETag(w, metric)
+	if ifNoneMatch(r, metric.Version) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
 	w.WriteHeader(http.StatusOK)
 	w.Write([]byte(valueString))
 }
@@ -145,6 +157,12 @@ func (h *MetricGetBodyHandler) serveHTTP(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	setMetricETag(w, metric)
+	if ifNoneMatch(r, metric.Version) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(metric)
 }
@@ -152,3 +170,84 @@ func (h *MetricGetBodyHandler) serveHTTP(w http.ResponseWriter, r *http.Request)
 func (h *MetricGetBodyHandler) RegisterRoute(r chi.Router) {
 	r.Post("/value/", h.serveHTTP)
 }
+
+// --- Functional Options for MetricGetBatchBodyHandler ---
+
+// MetricGetBatchBodyHandler returns several metrics requested as a JSON array of MetricID.
+type MetricGetBatchBodyHandler struct {
+	svc MetricBatchGetter
+}
+
+type MetricGetBatchBodyHandlerOption func(*MetricGetBatchBodyHandler)
+
+// WithMetricBatchGetterBody sets the MetricBatchGetter service on MetricGetBatchBodyHandler.
+func WithMetricBatchGetterBody(svc MetricBatchGetter) MetricGetBatchBodyHandlerOption {
+	return func(h *MetricGetBatchBodyHandler) {
+		h.svc = svc
+	}
+}
+
+func NewMetricGetBatchBodyHandler(opts ...MetricGetBatchBodyHandlerOption) *MetricGetBatchBodyHandler {
+	h := &MetricGetBatchBodyHandler{}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
+}
+
+// metricGetBatchResponse is the JSON body returned by MetricGetBatchBodyHandler.
+type metricGetBatchResponse struct {
+	Metrics  []*types.Metrics `json:"metrics"`
+	NotFound []types.MetricID `json:"not_found"`
+}
+
+func (h *MetricGetBatchBodyHandler) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var metricIDs []types.MetricID
+	if err := json.NewDecoder(r.Body).Decode(&metricIDs); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	if len(metricIDs) == 0 {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	for _, metricID := range metricIDs {
+		if metricID.ID == "" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if metricID.Type != types.Counter && metricID.Type != types.Gauge {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+	}
+
+	metrics, notFound, err := h.svc.GetBatch(r.Context(), metricIDs)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	resp := metricGetBatchResponse{
+		Metrics:  metrics,
+		NotFound: notFound,
+	}
+	if resp.Metrics == nil {
+		resp.Metrics = []*types.Metrics{}
+	}
+	if resp.NotFound == nil {
+		resp.NotFound = []types.MetricID{}
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(resp)
+}
+
+func (h *MetricGetBatchBodyHandler) RegisterRoute(r chi.Router) {
+	r.Post("/values/", h.serveHTTP)
+}
@@ -49,3 +49,42 @@ func (mr *MockMetricGetterMockRecorder) Get(ctx, metricID interface{}) *gomock.C
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockMetricGetter)(nil).Get), ctx, metricID)
 }
+
+// MockMetricBatchGetter is a mock of MetricBatchGetter interface.
+type MockMetricBatchGetter struct {
+	ctrl     *gomock.Controller
+	recorder *MockMetricBatchGetterMockRecorder
+}
+
+// MockMetricBatchGetterMockRecorder is the mock recorder for MockMetricBatchGetter.
+type MockMetricBatchGetterMockRecorder struct {
+	mock *MockMetricBatchGetter
+}
+
+// NewMockMetricBatchGetter creates a new mock instance.
+func NewMockMetricBatchGetter(ctrl *gomock.Controller) *MockMetricBatchGetter {
+	mock := &MockMetricBatchGetter{ctrl: ctrl}
+	mock.recorder = &MockMetricBatchGetterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMetricBatchGetter) EXPECT() *MockMetricBatchGetterMockRecorder {
+	return m.recorder
+}
+
+// GetBatch mocks base method.
+func (m *MockMetricBatchGetter) GetBatch(ctx context.Context, metricIDs []types.MetricID) ([]*types.Metrics, []types.MetricID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBatch", ctx, metricIDs)
+	ret0, _ := ret[0].([]*types.Metrics)
+	ret1, _ := ret[1].([]types.MetricID)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetBatch indicates an expected call of GetBatch.
+func (mr *MockMetricBatchGetterMockRecorder) GetBatch(ctx, metricIDs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBatch", reflect.TypeOf((*MockMetricBatchGetter)(nil).GetBatch), ctx, metricIDs)
+}
@@ -266,3 +266,199 @@ func TestMetricGetBodyHandler(t *testing.T) {
 		})
 	}
 }
+
+func TestMetricGetBatchBodyHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ptrInt64 := func(i int64) *int64 { return &i }
+	ptrFloat64 := func(f float64) *float64 { return &f }
+
+	mockBatchGetter := NewMockMetricBatchGetter(ctrl)
+	handler := NewMetricGetBatchBodyHandler(WithMetricBatchGetterBody(mockBatchGetter))
+
+	r := chi.NewRouter()
+	handler.RegisterRoute(r)
+
+	tests := []struct {
+		name         string
+		requestBody  string
+		mockExpect   func()
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			name:        "Found and not found metrics",
+			requestBody: `[{"id":"myCounter","type":"counter"},{"id":"myGauge","type":"gauge"},{"id":"missing","type":"gauge"}]`,
+			mockExpect: func() {
+				mockBatchGetter.EXPECT().
+					GetBatch(gomock.Any(), []types.MetricID{
+						{ID: "myCounter", Type: types.Counter},
+						{ID: "myGauge", Type: types.Gauge},
+						{ID: "missing", Type: types.Gauge},
+					}).
+					Return(
+						[]*types.Metrics{
+							{ID: "myCounter", Type: types.Counter, Delta: ptrInt64(7)},
+							{ID: "myGauge", Type: types.Gauge, Value: ptrFloat64(1.5)},
+						},
+						[]types.MetricID{{ID: "missing", Type: types.Gauge}},
+						nil,
+					)
+			},
+			expectedCode: http.StatusOK,
+			expectedBody: `{"metrics":[{"id":"myCounter","type":"counter","delta":7},{"id":"myGauge","type":"gauge","value":1.5}],"not_found":[{"id":"missing","type":"gauge"}]}`,
+		},
+		{
+			name:        "Nothing found",
+			requestBody: `[{"id":"missing","type":"counter"}]`,
+			mockExpect: func() {
+				mockBatchGetter.EXPECT().
+					GetBatch(gomock.Any(), []types.MetricID{{ID: "missing", Type: types.Counter}}).
+					Return(nil, []types.MetricID{{ID: "missing", Type: types.Counter}}, nil)
+			},
+			expectedCode: http.StatusOK,
+			expectedBody: `{"metrics":[],"not_found":[{"id":"missing","type":"counter"}]}`,
+		},
+		{
+			name:         "Invalid JSON body",
+			requestBody:  `[{"id": "missing quote}]`,
+			mockExpect:   func() {},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "Empty array",
+			requestBody:  `[]`,
+			mockExpect:   func() {},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "Missing metric ID",
+			requestBody:  `[{"id":"ok","type":"gauge"},{"type":"counter"}]`,
+			mockExpect:   func() {},
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:         "Invalid metric type",
+			requestBody:  `[{"id":"myMetric","type":"unknown"}]`,
+			mockExpect:   func() {},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:        "Batch getter returns error",
+			requestBody: `[{"id":"errorMetric","type":"gauge"}]`,
+			mockExpect: func() {
+				mockBatchGetter.EXPECT().
+					GetBatch(gomock.Any(), []types.MetricID{{ID: "errorMetric", Type: types.Gauge}}).
+					Return(nil, nil, context.DeadlineExceeded)
+			},
+			expectedCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockExpect()
+
+			req := httptest.NewRequest(http.MethodPost, "/values/", strings.NewReader(tt.requestBody))
+			req.Header.Set("Content-Type", "application/json")
+			defer req.Body.Close()
+
+			rr := httptest.NewRecorder()
+
+			r.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedCode, rr.Code)
+
+			if tt.expectedBody != "" {
+				assert.JSONEq(t, tt.expectedBody, rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestMetricGetHandlers_ETag(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ptrFloat64 := func(f float64) *float64 { return &f }
+
+	mockGetter := NewMockMetricGetter(ctrl)
+
+	r := chi.NewRouter()
+	NewMetricGetPathHandler(WithMetricGetterPath(mockGetter)).RegisterRoute(r)
+	NewMetricGetBodyHandler(WithMetricGetterBody(mockGetter)).RegisterRoute(r)
+
+	tests := []struct {
+		name         string
+		method       string
+		url          string
+		body         string
+		ifNoneMatch  string
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			name:         "Path returns ETag",
+			method:       http.MethodGet,
+			url:          "/value/gauge/Alloc",
+			expectedCode: http.StatusOK,
+			expectedBody: "1.5",
+		},
+		{
+			name:         "Path not modified",
+			method:       http.MethodGet,
+			url:          "/value/gauge/Alloc",
+			ifNoneMatch:  `"3"`,
+			expectedCode: http.StatusNotModified,
+		},
+		{
+			name:         "Path weak tag in list matches",
+			method:       http.MethodGet,
+			url:          "/value/gauge/Alloc",
+			ifNoneMatch:  `"1", W/"3"`,
+			expectedCode: http.StatusNotModified,
+		},
+		{
+			name:         "Path stale tag",
+			method:       http.MethodGet,
+			url:          "/value/gauge/Alloc",
+			ifNoneMatch:  `"2"`,
+			expectedCode: http.StatusOK,
+			expectedBody: "1.5",
+		},
+		{
+			name:         "Body not modified",
+			method:       http.MethodPost,
+			url:          "/value/",
+			body:         `{"id":"Alloc","type":"gauge"}`,
+			ifNoneMatch:  `"3"`,
+			expectedCode: http.StatusNotModified,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockGetter.EXPECT().
+				Get(gomock.Any(), types.MetricID{ID: "Alloc", Type: types.Gauge}).
+				Return(&types.Metrics{ID: "Alloc", Type: types.Gauge, Value: ptrFloat64(1.5), Version: 3}, nil)
+
+			req := httptest.NewRequest(tt.method, tt.url, strings.NewReader(tt.body))
+			if tt.ifNoneMatch != "" {
+				req.Header.Set("If-None-Match", tt.ifNoneMatch)
+			}
+			rr := httptest.NewRecorder()
+
+			r.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedCode, rr.Code)
+			assert.Equal(t, `"3"`, rr.Header().Get("ETag"))
+			if tt.expectedCode == http.StatusNotModified {
+				assert.Empty(t, rr.Body.String())
+			}
+			if tt.expectedBody != "" {
+				assert.Equal(t, tt.expectedBody, rr.Body.String())
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"strings"
@@ -52,14 +53,18 @@ func (h *MetricListHTMLHandler) serveHTTP(w http.ResponseWriter, r *http.Request
 	var builder strings.Builder
 	builder.WriteString("<!DOCTYPE html><html><head><title>Metrics</title></head><body><ul>\n")
 	for _, m := range metrics {
+		var suffix string
+		if m.Stale {
+			suffix = " (stale)"
+		}
 		switch m.Type {
 		case types.Gauge:
 			if m.Value != nil {
-				builder.WriteString(fmt.Sprintf("<li>%s: %v</li>\n", m.ID, *m.Value))
+				builder.WriteString(fmt.Sprintf("<li>%s: %v%s</li>\n", m.ID, *m.Value, suffix))
 			}
 		case types.Counter:
 			if m.Delta != nil {
-				builder.WriteString(fmt.Sprintf("<li>%s: %d</li>\n", m.ID, *m.Delta))
+				builder.WriteString(fmt.Sprintf("<li>%s: %d%s</li>\n", m.ID, *m.Delta, suffix))
 			}
 		}
 	}
@@ -74,3 +79,50 @@ func (h *MetricListHTMLHandler) serveHTTP(w http.ResponseWriter, r *http.Request
 func (h *MetricListHTMLHandler) RegisterRoute(r chi.Router) {
 	r.Get("/", h.serveHTTP)
 }
+
+// MetricListJSONHandler handles HTTP requests to list all metrics as JSON.
+type MetricListJSONHandler struct {
+	svc MetricLister
+}
+
+// MetricListJSONHandlerOption defines a functional option for configuring MetricListJSONHandler.
+type MetricListJSONHandlerOption func(*MetricListJSONHandler)
+
+// WithMetricListerJSON sets the MetricLister service on MetricListJSONHandler.
+func WithMetricListerJSON(svc MetricLister) MetricListJSONHandlerOption {
+	return func(h *MetricListJSONHandler) {
+		h.svc = svc
+	}
+}
+
+// NewMetricListJSONHandler creates a new MetricListJSONHandler with the provided options.
+func NewMetricListJSONHandler(opts ...MetricListJSONHandlerOption) *MetricListJSONHandler {
+	h := &MetricListJSONHandler{}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
+}
+
+// serveHTTP responds with a JSON array of all metrics, including their update time and staleness.
+func (h *MetricListJSONHandler) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	metrics, err := h.svc.List(r.Context())
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	if metrics == nil {
+		metrics = []*types.Metrics{}
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(metrics)
+}
+
+// RegisterRoute registers the route for serving the metrics list as JSON.
+func (h *MetricListJSONHandler) RegisterRoute(r chi.Router) {
+	r.Get("/values/", h.serveHTTP)
+}
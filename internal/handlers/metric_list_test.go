@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/golang/mock/gomock"
@@ -46,6 +47,21 @@ func TestMetricListHTMLHandler_serveHTTP(t *testing.T) {
 			expectedCode: http.StatusOK,
 			expectedBody: "<li>metric_gauge: 3.14</li>",
 		},
+		{
+			name: "stale metric is flagged",
+			mockSetup: func(m *MockMetricLister) {
+				m.EXPECT().List(gomock.Any()).Return([]*types.Metrics{
+					{
+						ID:    "CPUutilization7",
+						Type:  types.Gauge,
+						Value: float64Ptr(12.5),
+						Stale: true,
+					},
+				}, nil)
+			},
+			expectedCode: http.StatusOK,
+			expectedBody: "<li>CPUutilization7: 12.5 (stale)</li>",
+		},
 		{
 			name: "error listing metrics",
 			mockSetup: func(m *MockMetricLister) {
@@ -136,3 +152,72 @@ func TestMetricListHTMLHandler_RegisterRoute(t *testing.T) {
 	assert.Equal(t, http.StatusOK, resp.StatusCode)
 	assert.Contains(t, w.Body.String(), "<ul>")
 }
+
+func TestMetricListJSONHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	float64Ptr := func(f float64) *float64 { return &f }
+	updatedAt := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name         string
+		mockSetup    func(m *MockMetricLister)
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			name: "success with stale metric",
+			mockSetup: func(m *MockMetricLister) {
+				m.EXPECT().List(gomock.Any()).Return([]*types.Metrics{
+					{
+						ID:        "CPUutilization7",
+						Type:      types.Gauge,
+						Value:     float64Ptr(12.5),
+						UpdatedAt: &updatedAt,
+						Stale:     true,
+					},
+				}, nil)
+			},
+			expectedCode: http.StatusOK,
+			expectedBody: `[{"id":"CPUutilization7","type":"gauge","value":12.5,"updated_at":"2026-01-01T12:00:00Z","stale":true}]`,
+		},
+		{
+			name: "empty list",
+			mockSetup: func(m *MockMetricLister) {
+				m.EXPECT().List(gomock.Any()).Return(nil, nil)
+			},
+			expectedCode: http.StatusOK,
+			expectedBody: `[]`,
+		},
+		{
+			name: "error listing metrics",
+			mockSetup: func(m *MockMetricLister) {
+				m.EXPECT().List(gomock.Any()).Return(nil, errors.New("db failure"))
+			},
+			expectedCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockLister := NewMockMetricLister(ctrl)
+			tt.mockSetup(mockLister)
+
+			handler := NewMetricListJSONHandler(WithMetricListerJSON(mockLister))
+
+			r := chi.NewRouter()
+			handler.RegisterRoute(r)
+
+			req := httptest.NewRequest(http.MethodGet, "/values/", nil)
+			w := httptest.NewRecorder()
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+			if tt.expectedBody != "" {
+				assert.JSONEq(t, tt.expectedBody, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/sbilibin2017/go-yandex-practicum/internal/types"
+)
+
+// MetricResetter defines the interface for resetting a counter to zero.
+type MetricResetter interface {
+	Reset(ctx context.Context, metricID types.MetricID) (bool, error)
+}
+
+// MetricResetPathHandler handles counter resets via URL path parameters.
+type MetricResetPathHandler struct {
+	svc MetricResetter
+}
+
+// MetricResetPathHandlerOption defines a functional option for configuring MetricResetPathHandler.
+type MetricResetPathHandlerOption func(*MetricResetPathHandler)
+
+// WithMetricResetterPath sets the MetricResetter service on MetricResetPathHandler.
+func WithMetricResetterPath(svc MetricResetter) MetricResetPathHandlerOption {
+	return func(h *MetricResetPathHandler) {
+		h.svc = svc
+	}
+}
+
+// NewMetricResetPathHandler creates a new MetricResetPathHandler with the provided options.
+func NewMetricResetPathHandler(opts ...MetricResetPathHandlerOption) *MetricResetPathHandler {
+	h := &MetricResetPathHandler{}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
+}
+
+// serveHTTP resets the counter identified by the {type} and {name} URL parameters.
+// Only counters can be reset.
+func (h *MetricResetPathHandler) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain")
+
+	Type := chi.URLParam(r, "type")
+	name := chi.URLParam(r, "name")
+
+	if name == "" {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	if Type != types.Counter {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	reset, err := h.svc.Reset(r.Context(), types.MetricID{ID: name, Type: Type})
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	if !reset {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// RegisterRoute registers the counter reset routes on the provided router.
+func (h *MetricResetPathHandler) RegisterRoute(r chi.Router) {
+	r.Post("/reset/{type}/{name}", h.serveHTTP)
+	r.Post("/reset/{type}", h.serveHTTP)
+}
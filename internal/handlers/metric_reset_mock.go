@@ -0,0 +1,51 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: /home/sergey/Go/go-yandex-practicum/internal/handlers/metric_reset.go
+
+// Package handlers is a generated GoMock package.
+package handlers
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	types "github.com/sbilibin2017/go-yandex-practicum/internal/types"
+)
+
+// MockMetricResetter is a mock of MetricResetter interface.
+type MockMetricResetter struct {
+	ctrl     *gomock.Controller
+	recorder *MockMetricResetterMockRecorder
+}
+
+// MockMetricResetterMockRecorder is the mock recorder for MockMetricResetter.
+type MockMetricResetterMockRecorder struct {
+	mock *MockMetricResetter
+}
+
+// NewMockMetricResetter creates a new mock instance.
+func NewMockMetricResetter(ctrl *gomock.Controller) *MockMetricResetter {
+	mock := &MockMetricResetter{ctrl: ctrl}
+	mock.recorder = &MockMetricResetterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMetricResetter) EXPECT() *MockMetricResetterMockRecorder {
+	return m.recorder
+}
+
+// Reset mocks base method.
+func (m *MockMetricResetter) Reset(ctx context.Context, metricID types.MetricID) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reset", ctx, metricID)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Reset indicates an expected call of Reset.
+func (mr *MockMetricResetterMockRecorder) Reset(ctx, metricID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reset", reflect.TypeOf((*MockMetricResetter)(nil).Reset), ctx, metricID)
+}
@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/golang/mock/gomock"
+	"github.com/sbilibin2017/go-yandex-practicum/internal/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetricResetPathHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockResetter := NewMockMetricResetter(ctrl)
+	handler := NewMetricResetPathHandler(WithMetricResetterPath(mockResetter))
+
+	r := chi.NewRouter()
+	handler.RegisterRoute(r)
+
+	tests := []struct {
+		name         string
+		url          string
+		mockExpect   func()
+		expectedCode int
+	}{
+		{
+			name:         "Missing metric name",
+			url:          "/reset/counter",
+			mockExpect:   func() {},
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:         "Gauge cannot be reset",
+			url:          "/reset/gauge/Alloc",
+			mockExpect:   func() {},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name: "Counter reset",
+			url:  "/reset/counter/PollCount",
+			mockExpect: func() {
+				mockResetter.EXPECT().
+					Reset(gomock.Any(), types.MetricID{ID: "PollCount", Type: types.Counter}).
+					Return(true, nil)
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name: "Counter not found",
+			url:  "/reset/counter/missing",
+			mockExpect: func() {
+				mockResetter.EXPECT().
+					Reset(gomock.Any(), types.MetricID{ID: "missing", Type: types.Counter}).
+					Return(false, nil)
+			},
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name: "Resetter returns error",
+			url:  "/reset/counter/errorMetric",
+			mockExpect: func() {
+				mockResetter.EXPECT().
+					Reset(gomock.Any(), types.MetricID{ID: "errorMetric", Type: types.Counter}).
+					Return(false, context.DeadlineExceeded)
+			},
+			expectedCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockExpect()
+
+			req := httptest.NewRequest(http.MethodPost, tt.url, nil)
+			rr := httptest.NewRecorder()
+
+			r.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedCode, rr.Code)
+		})
+	}
+}
@@ -0,0 +1,430 @@
+package handlers
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"strings"
+
+	"github.com/sbilibin2017/go-yandex-practicum/internal/types"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/sbilibin2017/go-yandex-practicum/protos"
+)
+
+// MetricWatcher defines the interface for subscribing to metric change events.
+type MetricWatcher interface {
+	Watch(ctx context.Context, filter types.MetricFilter) <-chan types.MetricEvent
+}
+
+// MetricGRPCServiceHandler implements the MetricService gRPC server interface.
+type MetricGRPCServiceHandler struct {
+	pb.UnimplementedMetricServiceServer
+	getter        MetricGetter
+	lister        MetricLister
+	updater       MetricUpdater
+	watcher       MetricWatcher
+	batchGetter   MetricBatchGetter
+	deleter       MetricDeleter
+	prefixDeleter MetricPrefixDeleter
+	resetter      MetricResetter
+	key           string
+	header        string
+}
+
+// MetricGRPCServiceHandlerOption defines a functional option for configuring MetricGRPCServiceHandler.
+type MetricGRPCServiceHandlerOption func(*MetricGRPCServiceHandler)
+
+// WithMetricGRPCGetter sets the MetricGetter service on MetricGRPCServiceHandler.
+func WithMetricGRPCGetter(svc MetricGetter) MetricGRPCServiceHandlerOption {
+	return func(h *MetricGRPCServiceHandler) {
+		h.getter = svc
+	}
+}
+
+// WithMetricGRPCLister sets the MetricLister service on MetricGRPCServiceHandler.
+func WithMetricGRPCLister(svc MetricLister) MetricGRPCServiceHandlerOption {
+	return func(h *MetricGRPCServiceHandler) {
+		h.lister = svc
+	}
+}
+
+// WithMetricGRPCUpdater sets the MetricUpdater service used by StreamUpdates.
+func WithMetricGRPCUpdater(svc MetricUpdater) MetricGRPCServiceHandlerOption {
+	return func(h *MetricGRPCServiceHandler) {
+		h.updater = svc
+	}
+}
+
+// WithMetricGRPCWatcher sets the MetricWatcher service used by Watch.
+func WithMetricGRPCWatcher(svc MetricWatcher) MetricGRPCServiceHandlerOption {
+	return func(h *MetricGRPCServiceHandler) {
+		h.watcher = svc
+	}
+}
+
+// WithMetricGRPCBatchGetter sets the MetricBatchGetter service on MetricGRPCServiceHandler.
+func WithMetricGRPCBatchGetter(svc MetricBatchGetter) MetricGRPCServiceHandlerOption {
+	return func(h *MetricGRPCServiceHandler) {
+		h.batchGetter = svc
+	}
+}
+
+// WithMetricGRPCDeleter sets the MetricDeleter service on MetricGRPCServiceHandler.
+func WithMetricGRPCDeleter(svc MetricDeleter) MetricGRPCServiceHandlerOption {
+	return func(h *MetricGRPCServiceHandler) {
+		h.deleter = svc
+	}
+}
+
+// WithMetricGRPCPrefixDeleter sets the MetricPrefixDeleter service on MetricGRPCServiceHandler.
+func WithMetricGRPCPrefixDeleter(svc MetricPrefixDeleter) MetricGRPCServiceHandlerOption {
+	return func(h *MetricGRPCServiceHandler) {
+		h.prefixDeleter = svc
+	}
+}
+
+// WithMetricGRPCResetter sets the MetricResetter service on MetricGRPCServiceHandler.
+func WithMetricGRPCResetter(svc MetricResetter) MetricGRPCServiceHandlerOption {
+	return func(h *MetricGRPCServiceHandler) {
+		h.resetter = svc
+	}
+}
+
+// WithMetricGRPCHashKey sets the HMAC key and the metadata header that
+// destructive methods (Delete, DeleteByPrefix, Reset) must be signed with.
+// An empty key disables the check.
+func WithMetricGRPCHashKey(key, header string) MetricGRPCServiceHandlerOption {
+	return func(h *MetricGRPCServiceHandler) {
+		h.key = key
+		h.header = strings.ToLower(header)
+	}
+}
+
+// NewMetricGRPCServiceHandler creates a new MetricGRPCServiceHandler with the provided options.
+func NewMetricGRPCServiceHandler(opts ...MetricGRPCServiceHandlerOption) *MetricGRPCServiceHandler {
+	h := &MetricGRPCServiceHandler{}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
+}
+
+// Convert pb.MetricID to types.MetricID
+func toMetricID(id *pb.MetricID) types.MetricID {
+	return types.MetricID{
+		ID:   id.GetId(),
+		Type: id.GetType(),
+	}
+}
+
+// Convert types.MetricID to pb.MetricID
+func fromMetricID(id types.MetricID) *pb.MetricID {
+	return &pb.MetricID{
+		Id:   id.ID,
+		Type: id.Type,
+	}
+}
+
+// Get returns a single metric.
+func (h *MetricGRPCServiceHandler) Get(
+	ctx context.Context,
+	req *pb.GetMetricRequest,
+) (*pb.GetMetricResponse, error) {
+	metricID, err := validateMetricID(req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	metric, err := h.getter.Get(ctx, metricID)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	if metric == nil {
+		return nil, status.Errorf(codes.NotFound, "metric %s/%s not found", metricID.Type, metricID.ID)
+	}
+
+	return &pb.GetMetricResponse{Metric: fromMetric(metric)}, nil
+}
+
+// List streams all stored metrics, optionally restricted to IDs with the given prefix.
+func (h *MetricGRPCServiceHandler) List(
+	req *pb.ListMetricsRequest,
+	stream pb.MetricService_ListServer,
+) error {
+	metrics, err := h.lister.List(stream.Context())
+	if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+
+	filter := types.MetricFilter{Prefix: req.GetPrefix()}
+	for _, m := range metrics {
+		if !filter.Match(types.MetricID{ID: m.ID, Type: m.Type}) {
+			continue
+		}
+		if err := stream.Send(fromMetric(m)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// StreamUpdates applies every batch received on a long-lived client stream as
+// soon as it arrives and reports the totals when the client closes the stream.
+func (h *MetricGRPCServiceHandler) StreamUpdates(stream pb.MetricService_StreamUpdatesServer) error {
+	resp := &pb.StreamUpdatesResponse{}
+	for {
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return stream.SendAndClose(resp)
+		}
+		if err != nil {
+			return err
+		}
+
+		metrics := make([]*types.Metrics, 0, len(req.GetMetrics()))
+		for _, m := range req.GetMetrics() {
+			if m.GetId() == "" {
+				return status.Error(codes.InvalidArgument, "metric id is empty")
+			}
+			if !types.IsValidOp(m.GetType(), m.GetOp()) {
+				return status.Errorf(codes.InvalidArgument,
+					"unsupported operation %q for metric type %q", m.GetOp(), m.GetType())
+			}
+			metrics = append(metrics, toMetric(m))
+		}
+
+		if _, err := h.updater.Updates(stream.Context(), metrics); err != nil {
+			return status.Error(codes.Internal, err.Error())
+		}
+
+		resp.Batches++
+		resp.Metrics += int64(len(metrics))
+	}
+}
+
+// Watch pushes change events for the selected metrics until the client goes away
+// or the server shuts down. An empty request watches every metric.
+// A client too slow to keep up gets ResourceExhausted and must resubscribe.
+func (h *MetricGRPCServiceHandler) Watch(
+	req *pb.WatchRequest,
+	stream pb.MetricService_WatchServer,
+) error {
+	filter := types.MetricFilter{Prefix: req.GetPrefix()}
+	for _, id := range req.GetIds() {
+		metricID, err := validateMetricID(id)
+		if err != nil {
+			return err
+		}
+		filter.IDs = append(filter.IDs, metricID)
+	}
+
+	ctx := stream.Context()
+	events := h.watcher.Watch(ctx, filter)
+
+	// Headers tell the client that the subscription is active and no later change will be missed.
+	if err := stream.SendHeader(metadata.MD{}); err != nil {
+		return err
+	}
+
+	for event := range events {
+		if event.Kind == types.MetricEventOverflow {
+			return status.Error(codes.ResourceExhausted, "watcher fell behind and missed events, resubscribe")
+		}
+		if err := stream.Send(fromMetricEvent(event)); err != nil {
+			return err
+		}
+	}
+
+	return status.FromContextError(ctx.Err()).Err()
+}
+
+// Convert types.MetricEvent to pb.MetricEvent
+func fromMetricEvent(event types.MetricEvent) *pb.MetricEvent {
+	eventType := pb.MetricEventType_METRIC_EVENT_TYPE_UNSPECIFIED
+	switch event.Kind {
+	case types.MetricEventUpdated:
+		eventType = pb.MetricEventType_METRIC_EVENT_TYPE_UPDATED
+	case types.MetricEventDeleted:
+		eventType = pb.MetricEventType_METRIC_EVENT_TYPE_DELETED
+	}
+	return &pb.MetricEvent{
+		Type:   eventType,
+		Metric: fromMetric(&event.Metric),
+	}
+}
+
+// GetBatch returns the requested metrics together with the IDs that were not found.
+func (h *MetricGRPCServiceHandler) GetBatch(
+	ctx context.Context,
+	req *pb.GetMetricsRequest,
+) (*pb.GetMetricsResponse, error) {
+	if len(req.GetIds()) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "no metric ids provided")
+	}
+
+	metricIDs := make([]types.MetricID, 0, len(req.GetIds()))
+	for _, id := range req.GetIds() {
+		metricID := toMetricID(id)
+		if metricID.ID == "" {
+			return nil, status.Error(codes.InvalidArgument, "metric id is empty")
+		}
+		if metricID.Type != types.Counter && metricID.Type != types.Gauge {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid metric type %q", metricID.Type)
+		}
+		metricIDs = append(metricIDs, metricID)
+	}
+
+	metrics, notFound, err := h.batchGetter.GetBatch(ctx, metricIDs)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	resp := &pb.GetMetricsResponse{
+		Metrics:  make([]*pb.Metric, 0, len(metrics)),
+		NotFound: make([]*pb.MetricID, 0, len(notFound)),
+	}
+	for _, m := range metrics {
+		resp.Metrics = append(resp.Metrics, fromMetric(m))
+	}
+	for _, id := range notFound {
+		resp.NotFound = append(resp.NotFound, fromMetricID(id))
+	}
+
+	return resp, nil
+}
+
+// verifyHash checks the HMAC SHA256 of the marshaled request against the value
+// passed in the configured metadata header.
+func (h *MetricGRPCServiceHandler) verifyHash(ctx context.Context, req proto.Message) error {
+	return verifyRequestHash(ctx, h.key, h.header, req)
+}
+
+// verifyRequestHash checks the HMAC SHA256 of the deterministically marshaled request
+// against the value of the given metadata header. An empty key disables the check.
+func verifyRequestHash(ctx context.Context, key, header string, req proto.Message) error {
+	if key == "" {
+		return nil
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get(header)
+	if len(values) == 0 || values[0] == "" {
+		return status.Error(codes.Unauthenticated, "missing request hash")
+	}
+
+	body, err := proto.MarshalOptions{Deterministic: true}.Marshal(req)
+	if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write(body)
+	expectedHash := hex.EncodeToString(mac.Sum(nil))
+
+	if !hmac.Equal([]byte(values[0]), []byte(expectedHash)) {
+		return status.Error(codes.Unauthenticated, "invalid request hash")
+	}
+
+	return nil
+}
+
+// validateMetricID checks that a metric ID is present and has a known type.
+func validateMetricID(id *pb.MetricID) (types.MetricID, error) {
+	if id == nil || id.GetId() == "" {
+		return types.MetricID{}, status.Error(codes.InvalidArgument, "metric id is empty")
+	}
+	metricID := toMetricID(id)
+	if metricID.Type != types.Counter && metricID.Type != types.Gauge {
+		return types.MetricID{}, status.Errorf(codes.InvalidArgument, "invalid metric type %q", metricID.Type)
+	}
+	return metricID, nil
+}
+
+// Delete removes a single metric.
+func (h *MetricGRPCServiceHandler) Delete(
+	ctx context.Context,
+	req *pb.DeleteMetricRequest,
+) (*pb.DeleteMetricResponse, error) {
+	if err := h.verifyHash(ctx, req); err != nil {
+		return nil, err
+	}
+
+	metricID, err := validateMetricID(req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	deleted, err := h.deleter.Delete(ctx, metricID)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	if !deleted {
+		return nil, status.Errorf(codes.NotFound, "metric %s/%s not found", metricID.Type, metricID.ID)
+	}
+
+	return &pb.DeleteMetricResponse{}, nil
+}
+
+// DeleteByPrefix removes all metrics whose ID starts with the given prefix.
+func (h *MetricGRPCServiceHandler) DeleteByPrefix(
+	ctx context.Context,
+	req *pb.DeleteMetricsByPrefixRequest,
+) (*pb.DeleteMetricsByPrefixResponse, error) {
+	if err := h.verifyHash(ctx, req); err != nil {
+		return nil, err
+	}
+
+	if req.GetPrefix() == "" {
+		return nil, status.Error(codes.InvalidArgument, "prefix is empty")
+	}
+
+	deleted, err := h.prefixDeleter.DeleteByPrefix(ctx, req.GetPrefix())
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	resp := &pb.DeleteMetricsByPrefixResponse{
+		Deleted: make([]*pb.MetricID, 0, len(deleted)),
+	}
+	for _, id := range deleted {
+		resp.Deleted = append(resp.Deleted, fromMetricID(id))
+	}
+
+	return resp, nil
+}
+
+// Reset sets a counter back to zero.
+func (h *MetricGRPCServiceHandler) Reset(
+	ctx context.Context,
+	req *pb.ResetMetricRequest,
+) (*pb.ResetMetricResponse, error) {
+	if err := h.verifyHash(ctx, req); err != nil {
+		return nil, err
+	}
+
+	metricID, err := validateMetricID(req.GetId())
+	if err != nil {
+		return nil, err
+	}
+	if metricID.Type != types.Counter {
+		return nil, status.Error(codes.InvalidArgument, "only counters can be reset")
+	}
+
+	reset, err := h.resetter.Reset(ctx, metricID)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	if !reset {
+		return nil, status.Errorf(codes.NotFound, "counter %s not found", metricID.ID)
+	}
+
+	return &pb.ResetMetricResponse{}, nil
+}
@@ -0,0 +1,50 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: /home/sergey/Go/go-yandex-practicum/internal/handlers/metric_service.go
+
+// Package handlers is a generated GoMock package.
+package handlers
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	types "github.com/sbilibin2017/go-yandex-practicum/internal/types"
+)
+
+// MockMetricWatcher is a mock of MetricWatcher interface.
+type MockMetricWatcher struct {
+	ctrl     *gomock.Controller
+	recorder *MockMetricWatcherMockRecorder
+}
+
+// MockMetricWatcherMockRecorder is the mock recorder for MockMetricWatcher.
+type MockMetricWatcherMockRecorder struct {
+	mock *MockMetricWatcher
+}
+
+// NewMockMetricWatcher creates a new mock instance.
+func NewMockMetricWatcher(ctrl *gomock.Controller) *MockMetricWatcher {
+	mock := &MockMetricWatcher{ctrl: ctrl}
+	mock.recorder = &MockMetricWatcherMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMetricWatcher) EXPECT() *MockMetricWatcherMockRecorder {
+	return m.recorder
+}
+
+// Watch mocks base method.
+func (m *MockMetricWatcher) Watch(ctx context.Context, filter types.MetricFilter) <-chan types.MetricEvent {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Watch", ctx, filter)
+	ret0, _ := ret[0].(<-chan types.MetricEvent)
+	return ret0
+}
+
+// Watch indicates an expected call of Watch.
+func (mr *MockMetricWatcherMockRecorder) Watch(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Watch", reflect.TypeOf((*MockMetricWatcher)(nil).Watch), ctx, filter)
+}
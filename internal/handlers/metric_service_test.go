@@ -0,0 +1,622 @@
+package handlers
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/sbilibin2017/go-yandex-practicum/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/sbilibin2017/go-yandex-practicum/protos"
+)
+
+func TestMetricGRPCServiceHandler_GetBatch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ptrFloat64 := func(f float64) *float64 { return &f }
+	ptrInt64 := func(i int64) *int64 { return &i }
+	ctx := context.Background()
+
+	tests := []struct {
+		name         string
+		ids          []*pb.MetricID
+		setup        func(m *MockMetricBatchGetter)
+		wantCode     codes.Code
+		wantMetrics  []*pb.Metric
+		wantNotFound []*pb.MetricID
+	}{
+		{
+			name: "found and not found",
+			ids: []*pb.MetricID{
+				{Id: "g", Type: types.Gauge},
+				{Id: "c", Type: types.Counter},
+				{Id: "x", Type: types.Gauge},
+			},
+			setup: func(m *MockMetricBatchGetter) {
+				m.EXPECT().
+					GetBatch(ctx, []types.MetricID{
+						{ID: "g", Type: types.Gauge},
+						{ID: "c", Type: types.Counter},
+						{ID: "x", Type: types.Gauge},
+					}).
+					Return(
+						[]*types.Metrics{
+							{ID: "c", Type: types.Counter, Delta: ptrInt64(3)},
+							{ID: "g", Type: types.Gauge, Value: ptrFloat64(1.5)},
+						},
+						[]types.MetricID{{ID: "x", Type: types.Gauge}},
+						nil,
+					)
+			},
+			wantCode: codes.OK,
+			wantMetrics: []*pb.Metric{
+				{Id: "c", Type: types.Counter, Delta: 3},
+				{Id: "g", Type: types.Gauge, Value: 1.5},
+			},
+			wantNotFound: []*pb.MetricID{{Id: "x", Type: types.Gauge}},
+		},
+		{
+			name:     "no ids",
+			ids:      nil,
+			setup:    func(m *MockMetricBatchGetter) {},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "empty id",
+			ids:      []*pb.MetricID{{Id: "", Type: types.Gauge}},
+			setup:    func(m *MockMetricBatchGetter) {},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "invalid type",
+			ids:      []*pb.MetricID{{Id: "m", Type: "unknown"}},
+			setup:    func(m *MockMetricBatchGetter) {},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name: "service error",
+			ids:  []*pb.MetricID{{Id: "m", Type: types.Gauge}},
+			setup: func(m *MockMetricBatchGetter) {
+				m.EXPECT().
+					GetBatch(ctx, []types.MetricID{{ID: "m", Type: types.Gauge}}).
+					Return(nil, nil, errors.New("db failure"))
+			},
+			wantCode: codes.Internal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockBatchGetter := NewMockMetricBatchGetter(ctrl)
+			tt.setup(mockBatchGetter)
+
+			h := NewMetricGRPCServiceHandler(WithMetricGRPCBatchGetter(mockBatchGetter))
+
+			resp, err := h.GetBatch(ctx, &pb.GetMetricsRequest{Ids: tt.ids})
+			if tt.wantCode != codes.OK {
+				require.Error(t, err)
+				assert.Equal(t, tt.wantCode, status.Code(err))
+				assert.Nil(t, resp)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Len(t, resp.Metrics, len(tt.wantMetrics))
+			for i, m := range resp.Metrics {
+				assert.Equal(t, tt.wantMetrics[i].Id, m.Id)
+				assert.Equal(t, tt.wantMetrics[i].Type, m.Type)
+				assert.Equal(t, tt.wantMetrics[i].Value, m.Value)
+				assert.Equal(t, tt.wantMetrics[i].Delta, m.Delta)
+			}
+			require.Len(t, resp.NotFound, len(tt.wantNotFound))
+			for i, id := range resp.NotFound {
+				assert.Equal(t, tt.wantNotFound[i].Id, id.Id)
+				assert.Equal(t, tt.wantNotFound[i].Type, id.Type)
+			}
+		})
+	}
+}
+
+func TestMetricGRPCServiceHandler_Delete(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	id := types.MetricID{ID: "CPUutilization7", Type: types.Gauge}
+
+	tests := []struct {
+		name     string
+		id       *pb.MetricID
+		setup    func(m *MockMetricDeleter)
+		wantCode codes.Code
+	}{
+		{
+			name: "deleted",
+			id:   &pb.MetricID{Id: id.ID, Type: id.Type},
+			setup: func(m *MockMetricDeleter) {
+				m.EXPECT().Delete(ctx, id).Return(true, nil)
+			},
+			wantCode: codes.OK,
+		},
+		{
+			name: "not found",
+			id:   &pb.MetricID{Id: id.ID, Type: id.Type},
+			setup: func(m *MockMetricDeleter) {
+				m.EXPECT().Delete(ctx, id).Return(false, nil)
+			},
+			wantCode: codes.NotFound,
+		},
+		{
+			name:     "missing id",
+			id:       nil,
+			setup:    func(m *MockMetricDeleter) {},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "invalid type",
+			id:       &pb.MetricID{Id: id.ID, Type: "unknown"},
+			setup:    func(m *MockMetricDeleter) {},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name: "service error",
+			id:   &pb.MetricID{Id: id.ID, Type: id.Type},
+			setup: func(m *MockMetricDeleter) {
+				m.EXPECT().Delete(ctx, id).Return(false, errors.New("db failure"))
+			},
+			wantCode: codes.Internal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDeleter := NewMockMetricDeleter(ctrl)
+			tt.setup(mockDeleter)
+
+			h := NewMetricGRPCServiceHandler(WithMetricGRPCDeleter(mockDeleter))
+
+			resp, err := h.Delete(ctx, &pb.DeleteMetricRequest{Id: tt.id})
+			assert.Equal(t, tt.wantCode, status.Code(err))
+			if tt.wantCode == codes.OK {
+				assert.NotNil(t, resp)
+			} else {
+				assert.Nil(t, resp)
+			}
+		})
+	}
+}
+
+func TestMetricGRPCServiceHandler_DeleteByPrefix(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+
+	tests := []struct {
+		name        string
+		prefix      string
+		setup       func(m *MockMetricPrefixDeleter)
+		wantCode    codes.Code
+		wantDeleted []*pb.MetricID
+	}{
+		{
+			name:   "deleted",
+			prefix: "CPU",
+			setup: func(m *MockMetricPrefixDeleter) {
+				m.EXPECT().DeleteByPrefix(ctx, "CPU").
+					Return([]types.MetricID{{ID: "CPUutilization7", Type: types.Gauge}}, nil)
+			},
+			wantCode:    codes.OK,
+			wantDeleted: []*pb.MetricID{{Id: "CPUutilization7", Type: types.Gauge}},
+		},
+		{
+			name:     "empty prefix",
+			prefix:   "",
+			setup:    func(m *MockMetricPrefixDeleter) {},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:   "service error",
+			prefix: "CPU",
+			setup: func(m *MockMetricPrefixDeleter) {
+				m.EXPECT().DeleteByPrefix(ctx, "CPU").Return(nil, errors.New("db failure"))
+			},
+			wantCode: codes.Internal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDeleter := NewMockMetricPrefixDeleter(ctrl)
+			tt.setup(mockDeleter)
+
+			h := NewMetricGRPCServiceHandler(WithMetricGRPCPrefixDeleter(mockDeleter))
+
+			resp, err := h.DeleteByPrefix(ctx, &pb.DeleteMetricsByPrefixRequest{Prefix: tt.prefix})
+			assert.Equal(t, tt.wantCode, status.Code(err))
+			if tt.wantCode != codes.OK {
+				assert.Nil(t, resp)
+				return
+			}
+
+			require.Len(t, resp.Deleted, len(tt.wantDeleted))
+			for i, id := range resp.Deleted {
+				assert.Equal(t, tt.wantDeleted[i].Id, id.Id)
+				assert.Equal(t, tt.wantDeleted[i].Type, id.Type)
+			}
+		})
+	}
+}
+
+func TestMetricGRPCServiceHandler_Reset(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	const (
+		key    = "secret"
+		header = "HashSHA256"
+	)
+
+	sign := func(req proto.Message) string {
+		body, err := proto.MarshalOptions{Deterministic: true}.Marshal(req)
+		require.NoError(t, err)
+		mac := hmac.New(sha256.New, []byte(key))
+		mac.Write(body)
+		return hex.EncodeToString(mac.Sum(nil))
+	}
+
+	id := types.MetricID{ID: "PollCount", Type: types.Counter}
+	validReq := &pb.ResetMetricRequest{Id: &pb.MetricID{Id: id.ID, Type: id.Type}}
+	gaugeReq := &pb.ResetMetricRequest{Id: &pb.MetricID{Id: "Alloc", Type: types.Gauge}}
+
+	tests := []struct {
+		name     string
+		req      *pb.ResetMetricRequest
+		hash     string
+		setup    func(m *MockMetricResetter)
+		wantCode codes.Code
+	}{
+		{
+			name: "reset with valid hash",
+			req:  validReq,
+			hash: sign(validReq),
+			setup: func(m *MockMetricResetter) {
+				m.EXPECT().Reset(gomock.Any(), id).Return(true, nil)
+			},
+			wantCode: codes.OK,
+		},
+		{
+			name: "counter not found",
+			req:  validReq,
+			hash: sign(validReq),
+			setup: func(m *MockMetricResetter) {
+				m.EXPECT().Reset(gomock.Any(), id).Return(false, nil)
+			},
+			wantCode: codes.NotFound,
+		},
+		{
+			name:     "gauge cannot be reset",
+			req:      gaugeReq,
+			hash:     sign(gaugeReq),
+			setup:    func(m *MockMetricResetter) {},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "missing hash",
+			req:      validReq,
+			hash:     "",
+			setup:    func(m *MockMetricResetter) {},
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			name:     "invalid hash",
+			req:      validReq,
+			hash:     "deadbeef",
+			setup:    func(m *MockMetricResetter) {},
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			name: "service error",
+			req:  validReq,
+			hash: sign(validReq),
+			setup: func(m *MockMetricResetter) {
+				m.EXPECT().Reset(gomock.Any(), id).Return(false, errors.New("db failure"))
+			},
+			wantCode: codes.Internal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockResetter := NewMockMetricResetter(ctrl)
+			tt.setup(mockResetter)
+
+			h := NewMetricGRPCServiceHandler(
+				WithMetricGRPCResetter(mockResetter),
+				WithMetricGRPCHashKey(key, header),
+			)
+
+			ctx := context.Background()
+			if tt.hash != "" {
+				ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(header, tt.hash))
+			}
+
+			resp, err := h.Reset(ctx, tt.req)
+			assert.Equal(t, tt.wantCode, status.Code(err))
+			if tt.wantCode == codes.OK {
+				assert.NotNil(t, resp)
+			} else {
+				assert.Nil(t, resp)
+			}
+		})
+	}
+}
+
+// startGRPCServer serves the services set up by register over an in-memory listener
+// and returns a connection to it.
+func startGRPCServer(t *testing.T, register func(*grpc.Server)) *grpc.ClientConn {
+	t.Helper()
+
+	lis := bufconn.Listen(1024 * 1024)
+	srv := grpc.NewServer()
+	register(srv)
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	return conn
+}
+
+// startMetricServiceServer serves h and returns a connected client.
+func startMetricServiceServer(t *testing.T, h *MetricGRPCServiceHandler) pb.MetricServiceClient {
+	conn := startGRPCServer(t, func(srv *grpc.Server) {
+		pb.RegisterMetricServiceServer(srv, h)
+	})
+	return pb.NewMetricServiceClient(conn)
+}
+
+func TestMetricGRPCServiceHandler_Get(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ptrFloat64 := func(f float64) *float64 { return &f }
+
+	getter := NewMockMetricGetter(ctrl)
+	h := NewMetricGRPCServiceHandler(WithMetricGRPCGetter(getter))
+
+	tests := []struct {
+		name       string
+		id         *pb.MetricID
+		setup      func()
+		wantCode   codes.Code
+		wantMetric *pb.Metric
+	}{
+		{
+			name: "found",
+			id:   &pb.MetricID{Id: "Alloc", Type: types.Gauge},
+			setup: func() {
+				getter.EXPECT().
+					Get(gomock.Any(), types.MetricID{ID: "Alloc", Type: types.Gauge}).
+					Return(&types.Metrics{ID: "Alloc", Type: types.Gauge, Value: ptrFloat64(1.5)}, nil)
+			},
+			wantCode:   codes.OK,
+			wantMetric: &pb.Metric{Id: "Alloc", Type: types.Gauge, Value: 1.5},
+		},
+		{
+			name: "not found",
+			id:   &pb.MetricID{Id: "missing", Type: types.Gauge},
+			setup: func() {
+				getter.EXPECT().Get(gomock.Any(), gomock.Any()).Return(nil, nil)
+			},
+			wantCode: codes.NotFound,
+		},
+		{
+			name:     "invalid type",
+			id:       &pb.MetricID{Id: "Alloc", Type: "unknown"},
+			setup:    func() {},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name: "getter error",
+			id:   &pb.MetricID{Id: "Alloc", Type: types.Gauge},
+			setup: func() {
+				getter.EXPECT().Get(gomock.Any(), gomock.Any()).Return(nil, errors.New("db down"))
+			},
+			wantCode: codes.Internal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setup()
+
+			resp, err := h.Get(context.Background(), &pb.GetMetricRequest{Id: tt.id})
+			require.Equal(t, tt.wantCode, status.Code(err))
+			if tt.wantMetric != nil {
+				assert.True(t, proto.Equal(tt.wantMetric, resp.GetMetric()))
+			}
+		})
+	}
+}
+
+func TestMetricGRPCServiceHandler_List(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ptrFloat64 := func(f float64) *float64 { return &f }
+	ptrInt64 := func(i int64) *int64 { return &i }
+
+	lister := NewMockMetricLister(ctrl)
+	client := startMetricServiceServer(t, NewMetricGRPCServiceHandler(WithMetricGRPCLister(lister)))
+
+	lister.EXPECT().List(gomock.Any()).Return([]*types.Metrics{
+		{ID: "CPUutilization1", Type: types.Gauge, Value: ptrFloat64(10)},
+		{ID: "PollCount", Type: types.Counter, Delta: ptrInt64(5)},
+	}, nil).Times(2)
+
+	tests := []struct {
+		name    string
+		prefix  string
+		wantIDs []string
+	}{
+		{name: "all metrics", prefix: "", wantIDs: []string{"CPUutilization1", "PollCount"}},
+		{name: "by prefix", prefix: "CPU", wantIDs: []string{"CPUutilization1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stream, err := client.List(context.Background(), &pb.ListMetricsRequest{Prefix: tt.prefix})
+			require.NoError(t, err)
+
+			var gotIDs []string
+			for {
+				m, err := stream.Recv()
+				if errors.Is(err, io.EOF) {
+					break
+				}
+				require.NoError(t, err)
+				gotIDs = append(gotIDs, m.GetId())
+			}
+			assert.Equal(t, tt.wantIDs, gotIDs)
+		})
+	}
+}
+
+func TestMetricGRPCServiceHandler_StreamUpdates(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	updater := NewMockMetricUpdater(ctrl)
+	client := startMetricServiceServer(t, NewMetricGRPCServiceHandler(WithMetricGRPCUpdater(updater)))
+
+	t.Run("batches applied as received", func(t *testing.T) {
+		updater.EXPECT().Updates(gomock.Any(), gomock.Len(2)).Return(nil, nil)
+		updater.EXPECT().Updates(gomock.Any(), gomock.Len(1)).Return(nil, nil)
+
+		stream, err := client.StreamUpdates(context.Background())
+		require.NoError(t, err)
+		require.NoError(t, stream.Send(&pb.UpdateMetricsRequest{Metrics: []*pb.Metric{
+			{Id: "Alloc", Type: types.Gauge, Value: 1},
+			{Id: "PollCount", Type: types.Counter, Delta: 1},
+		}}))
+		require.NoError(t, stream.Send(&pb.UpdateMetricsRequest{Metrics: []*pb.Metric{
+			{Id: "PollCount", Type: types.Counter, Delta: 1},
+		}}))
+
+		resp, err := stream.CloseAndRecv()
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), resp.GetBatches())
+		assert.Equal(t, int64(3), resp.GetMetrics())
+	})
+
+	t.Run("invalid metric aborts the stream", func(t *testing.T) {
+		stream, err := client.StreamUpdates(context.Background())
+		require.NoError(t, err)
+		require.NoError(t, stream.Send(&pb.UpdateMetricsRequest{Metrics: []*pb.Metric{
+			{Id: "PollCount", Type: types.Counter, Delta: 1, Op: types.OpMax},
+		}}))
+
+		_, err = stream.CloseAndRecv()
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("updater error", func(t *testing.T) {
+		updater.EXPECT().Updates(gomock.Any(), gomock.Any()).Return(nil, errors.New("db down"))
+
+		stream, err := client.StreamUpdates(context.Background())
+		require.NoError(t, err)
+		require.NoError(t, stream.Send(&pb.UpdateMetricsRequest{Metrics: []*pb.Metric{
+			{Id: "Alloc", Type: types.Gauge, Value: 1},
+		}}))
+
+		_, err = stream.CloseAndRecv()
+		assert.Equal(t, codes.Internal, status.Code(err))
+	})
+}
+
+func TestMetricGRPCServiceHandler_Watch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ptrFloat64 := func(f float64) *float64 { return &f }
+
+	watcher := NewMockMetricWatcher(ctrl)
+	client := startMetricServiceServer(t, NewMetricGRPCServiceHandler(WithMetricGRPCWatcher(watcher)))
+
+	events := make(chan types.MetricEvent, 2)
+	events <- types.MetricEvent{Kind: types.MetricEventUpdated, Metric: types.Metrics{ID: "CPU1", Type: types.Gauge, Value: ptrFloat64(3)}}
+	events <- types.MetricEvent{Kind: types.MetricEventDeleted, Metric: types.Metrics{ID: "CPU2", Type: types.Gauge}}
+	close(events)
+
+	watcher.EXPECT().
+		Watch(gomock.Any(), types.MetricFilter{
+			IDs:    []types.MetricID{{ID: "PollCount", Type: types.Counter}},
+			Prefix: "CPU",
+		}).
+		Return(events)
+
+	stream, err := client.Watch(context.Background(), &pb.WatchRequest{
+		Ids:    []*pb.MetricID{{Id: "PollCount", Type: types.Counter}},
+		Prefix: "CPU",
+	})
+	require.NoError(t, err)
+
+	event, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, pb.MetricEventType_METRIC_EVENT_TYPE_UPDATED, event.GetType())
+	assert.True(t, proto.Equal(&pb.Metric{Id: "CPU1", Type: types.Gauge, Value: 3}, event.GetMetric()))
+
+	event, err = stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, pb.MetricEventType_METRIC_EVENT_TYPE_DELETED, event.GetType())
+	assert.Equal(t, "CPU2", event.GetMetric().GetId())
+
+	t.Run("overflow", func(t *testing.T) {
+		events := make(chan types.MetricEvent, 2)
+		events <- types.MetricEvent{Kind: types.MetricEventDeleted, Metric: types.Metrics{ID: "CPU2", Type: types.Gauge}}
+		events <- types.MetricEvent{Kind: types.MetricEventOverflow}
+		close(events)
+		watcher.EXPECT().Watch(gomock.Any(), types.MetricFilter{}).Return(events)
+
+		stream, err := client.Watch(context.Background(), &pb.WatchRequest{})
+		require.NoError(t, err)
+		event, err := stream.Recv()
+		require.NoError(t, err)
+		assert.Equal(t, "CPU2", event.GetMetric().GetId())
+		_, err = stream.Recv()
+		assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	})
+
+	t.Run("invalid id", func(t *testing.T) {
+		stream, err := client.Watch(context.Background(), &pb.WatchRequest{
+			Ids: []*pb.MetricID{{Id: "", Type: types.Gauge}},
+		})
+		require.NoError(t, err)
+		_, err = stream.Recv()
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+}
@@ -0,0 +1,351 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/sbilibin2017/go-yandex-practicum/internal/types"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+
+	pbv2 "github.com/sbilibin2017/go-yandex-practicum/protos/v2"
+)
+
+// errorDomain identifies this service in google.rpc.ErrorInfo details.
+const errorDomain = "metrics.go-yandex-practicum"
+
+// MetricGRPCV2Handler implements the v2 MetricService gRPC server interface.
+// Unlike v1 it reports every failure through the gRPC status with error details.
+type MetricGRPCV2Handler struct {
+	pbv2.UnimplementedMetricServiceServer
+	updater     MetricUpdater
+	getter      MetricGetter
+	batchGetter MetricBatchGetter
+	lister      MetricLister
+	deleter     MetricDeleter
+	key         string
+	header      string
+}
+
+// MetricGRPCV2HandlerOption defines a functional option for configuring MetricGRPCV2Handler.
+type MetricGRPCV2HandlerOption func(*MetricGRPCV2Handler)
+
+// WithMetricGRPCV2Updater sets the MetricUpdater service on MetricGRPCV2Handler.
+func WithMetricGRPCV2Updater(svc MetricUpdater) MetricGRPCV2HandlerOption {
+	return func(h *MetricGRPCV2Handler) {
+		h.updater = svc
+	}
+}
+
+// WithMetricGRPCV2Getter sets the MetricGetter service on MetricGRPCV2Handler.
+func WithMetricGRPCV2Getter(svc MetricGetter) MetricGRPCV2HandlerOption {
+	return func(h *MetricGRPCV2Handler) {
+		h.getter = svc
+	}
+}
+
+// WithMetricGRPCV2BatchGetter sets the MetricBatchGetter service on MetricGRPCV2Handler.
+func WithMetricGRPCV2BatchGetter(svc MetricBatchGetter) MetricGRPCV2HandlerOption {
+	return func(h *MetricGRPCV2Handler) {
+		h.batchGetter = svc
+	}
+}
+
+// WithMetricGRPCV2Lister sets the MetricLister service on MetricGRPCV2Handler.
+func WithMetricGRPCV2Lister(svc MetricLister) MetricGRPCV2HandlerOption {
+	return func(h *MetricGRPCV2Handler) {
+		h.lister = svc
+	}
+}
+
+// WithMetricGRPCV2Deleter sets the MetricDeleter service on MetricGRPCV2Handler.
+func WithMetricGRPCV2Deleter(svc MetricDeleter) MetricGRPCV2HandlerOption {
+	return func(h *MetricGRPCV2Handler) {
+		h.deleter = svc
+	}
+}
+
+// WithMetricGRPCV2HashKey sets the HMAC key and the metadata header that Delete must be signed with.
+// An empty key disables the check.
+func WithMetricGRPCV2HashKey(key, header string) MetricGRPCV2HandlerOption {
+	return func(h *MetricGRPCV2Handler) {
+		h.key = key
+		h.header = strings.ToLower(header)
+	}
+}
+
+// NewMetricGRPCV2Handler creates a new MetricGRPCV2Handler with the provided options.
+func NewMetricGRPCV2Handler(opts ...MetricGRPCV2HandlerOption) *MetricGRPCV2Handler {
+	h := &MetricGRPCV2Handler{}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
+}
+
+// Updates validates all metrics first and reports every violation at once;
+// nothing is stored when any metric is invalid.
+func (h *MetricGRPCV2Handler) Updates(
+	ctx context.Context,
+	req *pbv2.UpdateMetricsRequest,
+) (*pbv2.UpdateMetricsResponse, error) {
+	if len(req.GetMetrics()) == 0 {
+		return nil, invalidArgument(fieldViolation("metrics", "at least one metric is required"))
+	}
+
+	var violations []*errdetails.BadRequest_FieldViolation
+	metrics := make([]*types.Metrics, 0, len(req.GetMetrics()))
+	for i, m := range req.GetMetrics() {
+		metric, vs := toMetricV2(m, fmt.Sprintf("metrics[%d]", i))
+		violations = append(violations, vs...)
+		metrics = append(metrics, metric)
+	}
+	if len(violations) > 0 {
+		return nil, invalidArgument(violations...)
+	}
+
+	updatedMetrics, err := h.updater.Updates(ctx, metrics)
+	if err != nil {
+		return nil, storageError(err)
+	}
+
+	resp := &pbv2.UpdateMetricsResponse{
+		Metrics: make([]*pbv2.Metric, 0, len(updatedMetrics)),
+	}
+	for _, m := range updatedMetrics {
+		resp.Metrics = append(resp.Metrics, fromMetricV2(m))
+	}
+	return resp, nil
+}
+
+// Get returns a single metric.
+func (h *MetricGRPCV2Handler) Get(
+	ctx context.Context,
+	req *pbv2.GetMetricRequest,
+) (*pbv2.GetMetricResponse, error) {
+	metricID, vs := toMetricIDV2(req.GetId(), "id")
+	if len(vs) > 0 {
+		return nil, invalidArgument(vs...)
+	}
+
+	metric, err := h.getter.Get(ctx, metricID)
+	if err != nil {
+		return nil, storageError(err)
+	}
+	if metric == nil {
+		return nil, notFound(metricID)
+	}
+
+	return &pbv2.GetMetricResponse{Metric: fromMetricV2(metric)}, nil
+}
+
+// GetBatch returns the requested metrics together with the IDs that were not found.
+func (h *MetricGRPCV2Handler) GetBatch(
+	ctx context.Context,
+	req *pbv2.GetMetricsRequest,
+) (*pbv2.GetMetricsResponse, error) {
+	if len(req.GetIds()) == 0 {
+		return nil, invalidArgument(fieldViolation("ids", "at least one metric id is required"))
+	}
+
+	var violations []*errdetails.BadRequest_FieldViolation
+	metricIDs := make([]types.MetricID, 0, len(req.GetIds()))
+	for i, id := range req.GetIds() {
+		metricID, vs := toMetricIDV2(id, fmt.Sprintf("ids[%d]", i))
+		violations = append(violations, vs...)
+		metricIDs = append(metricIDs, metricID)
+	}
+	if len(violations) > 0 {
+		return nil, invalidArgument(violations...)
+	}
+
+	metrics, missing, err := h.batchGetter.GetBatch(ctx, metricIDs)
+	if err != nil {
+		return nil, storageError(err)
+	}
+
+	resp := &pbv2.GetMetricsResponse{
+		Metrics:  make([]*pbv2.Metric, 0, len(metrics)),
+		NotFound: make([]*pbv2.MetricID, 0, len(missing)),
+	}
+	for _, m := range metrics {
+		resp.Metrics = append(resp.Metrics, fromMetricV2(m))
+	}
+	for _, id := range missing {
+		resp.NotFound = append(resp.NotFound, fromMetricIDV2(id))
+	}
+	return resp, nil
+}
+
+// List streams all stored metrics, optionally restricted to IDs with the given prefix.
+func (h *MetricGRPCV2Handler) List(
+	req *pbv2.ListMetricsRequest,
+	stream pbv2.MetricService_ListServer,
+) error {
+	metrics, err := h.lister.List(stream.Context())
+	if err != nil {
+		return storageError(err)
+	}
+
+	filter := types.MetricFilter{Prefix: req.GetPrefix()}
+	for _, m := range metrics {
+		if !filter.Match(types.MetricID{ID: m.ID, Type: m.Type}) {
+			continue
+		}
+		if err := stream.Send(fromMetricV2(m)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Delete removes a single metric.
+func (h *MetricGRPCV2Handler) Delete(
+	ctx context.Context,
+	req *pbv2.DeleteMetricRequest,
+) (*pbv2.DeleteMetricResponse, error) {
+	if err := verifyRequestHash(ctx, h.key, h.header, req); err != nil {
+		return nil, err
+	}
+
+	metricID, vs := toMetricIDV2(req.GetId(), "id")
+	if len(vs) > 0 {
+		return nil, invalidArgument(vs...)
+	}
+
+	deleted, err := h.deleter.Delete(ctx, metricID)
+	if err != nil {
+		return nil, storageError(err)
+	}
+	if !deleted {
+		return nil, notFound(metricID)
+	}
+
+	return &pbv2.DeleteMetricResponse{}, nil
+}
+
+// toMetricV2 converts a v2 metric, reporting violations for the request field at path.
+func toMetricV2(m *pbv2.Metric, path string) (*types.Metrics, []*errdetails.BadRequest_FieldViolation) {
+	var violations []*errdetails.BadRequest_FieldViolation
+
+	metric := &types.Metrics{ID: m.GetId(), Op: m.GetOp()}
+	if metric.ID == "" {
+		violations = append(violations, fieldViolation(path+".id", "metric id is empty"))
+	}
+
+	switch v := m.GetValue().(type) {
+	case *pbv2.Metric_Gauge:
+		metric.Type = types.Gauge
+		metric.Value = &v.Gauge
+	case *pbv2.Metric_Counter:
+		metric.Type = types.Counter
+		metric.Delta = &v.Counter
+	default:
+		violations = append(violations, fieldViolation(path+".value", "one of gauge or counter must be set"))
+		return metric, violations
+	}
+
+	if !types.IsValidOp(metric.Type, metric.Op) {
+		violations = append(violations, fieldViolation(path+".op",
+			fmt.Sprintf("unsupported operation %q for metric type %q", metric.Op, metric.Type)))
+	}
+
+	return metric, violations
+}
+
+// fromMetricV2 converts a stored metric; a missing value leaves the oneof unset.
+func fromMetricV2(m *types.Metrics) *pbv2.Metric {
+	metric := &pbv2.Metric{
+		Id:      m.ID,
+		Version: m.Version,
+		Stale:   m.Stale,
+	}
+	switch {
+	case m.Type == types.Gauge && m.Value != nil:
+		metric.Value = &pbv2.Metric_Gauge{Gauge: *m.Value}
+	case m.Type == types.Counter && m.Delta != nil:
+		metric.Value = &pbv2.Metric_Counter{Counter: *m.Delta}
+	}
+	return metric
+}
+
+// toMetricIDV2 converts a v2 metric ID, reporting violations for the request field at path.
+func toMetricIDV2(id *pbv2.MetricID, path string) (types.MetricID, []*errdetails.BadRequest_FieldViolation) {
+	var violations []*errdetails.BadRequest_FieldViolation
+
+	metricID := types.MetricID{ID: id.GetId()}
+	if metricID.ID == "" {
+		violations = append(violations, fieldViolation(path+".id", "metric id is empty"))
+	}
+
+	switch id.GetType() {
+	case pbv2.MetricType_METRIC_TYPE_GAUGE:
+		metricID.Type = types.Gauge
+	case pbv2.MetricType_METRIC_TYPE_COUNTER:
+		metricID.Type = types.Counter
+	default:
+		violations = append(violations, fieldViolation(path+".type", "metric type must be gauge or counter"))
+	}
+
+	return metricID, violations
+}
+
+// fromMetricIDV2 converts a metric ID to its v2 representation.
+func fromMetricIDV2(id types.MetricID) *pbv2.MetricID {
+	metricID := &pbv2.MetricID{Id: id.ID}
+	switch id.Type {
+	case types.Gauge:
+		metricID.Type = pbv2.MetricType_METRIC_TYPE_GAUGE
+	case types.Counter:
+		metricID.Type = pbv2.MetricType_METRIC_TYPE_COUNTER
+	}
+	return metricID
+}
+
+func fieldViolation(field, description string) *errdetails.BadRequest_FieldViolation {
+	return &errdetails.BadRequest_FieldViolation{Field: field, Description: description}
+}
+
+// invalidArgument builds an InvalidArgument status carrying BadRequest details.
+func invalidArgument(violations ...*errdetails.BadRequest_FieldViolation) error {
+	return withDetails(
+		status.New(codes.InvalidArgument, "invalid request"),
+		&errdetails.BadRequest{FieldViolations: violations},
+	)
+}
+
+// notFound builds a NotFound status carrying ResourceInfo details.
+func notFound(id types.MetricID) error {
+	return withDetails(
+		status.Newf(codes.NotFound, "metric %s/%s not found", id.Type, id.ID),
+		&errdetails.ResourceInfo{ResourceType: id.Type, ResourceName: id.ID},
+	)
+}
+
+// storageError maps a service failure to a status: version conflicts become Aborted,
+// everything else Internal, both with ErrorInfo details.
+func storageError(err error) error {
+	if errors.Is(err, types.ErrVersionConflict) {
+		return withDetails(
+			status.New(codes.Aborted, err.Error()),
+			&errdetails.ErrorInfo{Reason: "VERSION_CONFLICT", Domain: errorDomain},
+		)
+	}
+	return withDetails(
+		status.New(codes.Internal, err.Error()),
+		&errdetails.ErrorInfo{Reason: "STORAGE_FAILURE", Domain: errorDomain},
+	)
+}
+
+// withDetails attaches details to st; if they cannot be encoded the bare status is returned.
+func withDetails(st *status.Status, details ...protoadapt.MessageV1) error {
+	detailed, err := st.WithDetails(details...)
+	if err != nil {
+		return st.Err()
+	}
+	return detailed.Err()
+}
@@ -0,0 +1,285 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/sbilibin2017/go-yandex-practicum/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	pbv2 "github.com/sbilibin2017/go-yandex-practicum/protos/v2"
+)
+
+// badRequestFields returns the fields reported in the BadRequest details of err.
+func badRequestFields(t *testing.T, err error) []string {
+	t.Helper()
+
+	var fields []string
+	for _, d := range status.Convert(err).Details() {
+		if br, ok := d.(*errdetails.BadRequest); ok {
+			for _, v := range br.GetFieldViolations() {
+				fields = append(fields, v.GetField())
+			}
+		}
+	}
+	return fields
+}
+
+// errorInfoReason returns the reason of the ErrorInfo details of err.
+func errorInfoReason(err error) string {
+	for _, d := range status.Convert(err).Details() {
+		if info, ok := d.(*errdetails.ErrorInfo); ok {
+			return info.GetReason()
+		}
+	}
+	return ""
+}
+
+func TestMetricGRPCV2Handler_Updates(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ptrFloat64 := func(f float64) *float64 { return &f }
+	ptrInt64 := func(i int64) *int64 { return &i }
+
+	updater := NewMockMetricUpdater(ctrl)
+	h := NewMetricGRPCV2Handler(WithMetricGRPCV2Updater(updater))
+
+	tests := []struct {
+		name        string
+		metrics     []*pbv2.Metric
+		setup       func()
+		wantCode    codes.Code
+		wantFields  []string
+		wantReason  string
+		wantMetrics []*pbv2.Metric
+	}{
+		{
+			name: "gauge and counter keep only their own value",
+			metrics: []*pbv2.Metric{
+				{Id: "Alloc", Value: &pbv2.Metric_Gauge{Gauge: 1.5}},
+				{Id: "PollCount", Value: &pbv2.Metric_Counter{Counter: 2}},
+			},
+			setup: func() {
+				updater.EXPECT().
+					Updates(gomock.Any(), []*types.Metrics{
+						{ID: "Alloc", Type: types.Gauge, Value: ptrFloat64(1.5)},
+						{ID: "PollCount", Type: types.Counter, Delta: ptrInt64(2)},
+					}).
+					Return([]*types.Metrics{
+						{ID: "Alloc", Type: types.Gauge, Value: ptrFloat64(1.5), Version: 1},
+						{ID: "PollCount", Type: types.Counter, Delta: ptrInt64(7), Version: 3},
+					}, nil)
+			},
+			wantCode: codes.OK,
+			wantMetrics: []*pbv2.Metric{
+				{Id: "Alloc", Value: &pbv2.Metric_Gauge{Gauge: 1.5}, Version: 1},
+				{Id: "PollCount", Value: &pbv2.Metric_Counter{Counter: 7}, Version: 3},
+			},
+		},
+		{
+			name: "all violations are reported",
+			metrics: []*pbv2.Metric{
+				{Id: "", Value: &pbv2.Metric_Gauge{Gauge: 1}},
+				{Id: "NoValue"},
+				{Id: "PollCount", Value: &pbv2.Metric_Counter{Counter: 1}, Op: types.OpMax},
+			},
+			setup:      func() {},
+			wantCode:   codes.InvalidArgument,
+			wantFields: []string{"metrics[0].id", "metrics[1].value", "metrics[2].op"},
+		},
+		{
+			name:       "empty request",
+			metrics:    nil,
+			setup:      func() {},
+			wantCode:   codes.InvalidArgument,
+			wantFields: []string{"metrics"},
+		},
+		{
+			name:    "version conflict",
+			metrics: []*pbv2.Metric{{Id: "Alloc", Value: &pbv2.Metric_Gauge{Gauge: 1}}},
+			setup: func() {
+				updater.EXPECT().Updates(gomock.Any(), gomock.Any()).Return(nil, types.ErrVersionConflict)
+			},
+			wantCode:   codes.Aborted,
+			wantReason: "VERSION_CONFLICT",
+		},
+		{
+			name:    "storage failure",
+			metrics: []*pbv2.Metric{{Id: "Alloc", Value: &pbv2.Metric_Gauge{Gauge: 1}}},
+			setup: func() {
+				updater.EXPECT().Updates(gomock.Any(), gomock.Any()).Return(nil, errors.New("db down"))
+			},
+			wantCode:   codes.Internal,
+			wantReason: "STORAGE_FAILURE",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setup()
+
+			resp, err := h.Updates(context.Background(), &pbv2.UpdateMetricsRequest{Metrics: tt.metrics})
+			require.Equal(t, tt.wantCode, status.Code(err))
+			if tt.wantFields != nil {
+				assert.Equal(t, tt.wantFields, badRequestFields(t, err))
+			}
+			if tt.wantReason != "" {
+				assert.Equal(t, tt.wantReason, errorInfoReason(err))
+			}
+			if tt.wantMetrics != nil {
+				require.Len(t, resp.GetMetrics(), len(tt.wantMetrics))
+				for i, m := range tt.wantMetrics {
+					assert.True(t, proto.Equal(m, resp.GetMetrics()[i]), "metric %d: %v", i, resp.GetMetrics()[i])
+				}
+			}
+		})
+	}
+}
+
+func TestMetricGRPCV2Handler_GetAndDelete(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	getter := NewMockMetricGetter(ctrl)
+	deleter := NewMockMetricDeleter(ctrl)
+	h := NewMetricGRPCV2Handler(
+		WithMetricGRPCV2Getter(getter),
+		WithMetricGRPCV2Deleter(deleter),
+	)
+	ctx := context.Background()
+
+	t.Run("counter without value does not panic", func(t *testing.T) {
+		getter.EXPECT().
+			Get(gomock.Any(), types.MetricID{ID: "PollCount", Type: types.Counter}).
+			Return(&types.Metrics{ID: "PollCount", Type: types.Counter}, nil)
+
+		resp, err := h.Get(ctx, &pbv2.GetMetricRequest{
+			Id: &pbv2.MetricID{Id: "PollCount", Type: pbv2.MetricType_METRIC_TYPE_COUNTER},
+		})
+		require.NoError(t, err)
+		assert.Nil(t, resp.GetMetric().GetValue())
+	})
+
+	t.Run("get not found", func(t *testing.T) {
+		getter.EXPECT().Get(gomock.Any(), gomock.Any()).Return(nil, nil)
+
+		_, err := h.Get(ctx, &pbv2.GetMetricRequest{
+			Id: &pbv2.MetricID{Id: "missing", Type: pbv2.MetricType_METRIC_TYPE_GAUGE},
+		})
+		assert.Equal(t, codes.NotFound, status.Code(err))
+	})
+
+	t.Run("get unspecified type", func(t *testing.T) {
+		_, err := h.Get(ctx, &pbv2.GetMetricRequest{Id: &pbv2.MetricID{Id: "Alloc"}})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		assert.Equal(t, []string{"id.type"}, badRequestFields(t, err))
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		deleter.EXPECT().
+			Delete(gomock.Any(), types.MetricID{ID: "Alloc", Type: types.Gauge}).
+			Return(true, nil)
+
+		_, err := h.Delete(ctx, &pbv2.DeleteMetricRequest{
+			Id: &pbv2.MetricID{Id: "Alloc", Type: pbv2.MetricType_METRIC_TYPE_GAUGE},
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("delete requires hash when key is set", func(t *testing.T) {
+		signed := NewMetricGRPCV2Handler(
+			WithMetricGRPCV2Deleter(deleter),
+			WithMetricGRPCV2HashKey("secret", "HashSHA256"),
+		)
+		_, err := signed.Delete(ctx, &pbv2.DeleteMetricRequest{
+			Id: &pbv2.MetricID{Id: "Alloc", Type: pbv2.MetricType_METRIC_TYPE_GAUGE},
+		})
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+}
+
+func TestMetricGRPCV2Handler_GetBatchAndList(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ptrFloat64 := func(f float64) *float64 { return &f }
+	ptrInt64 := func(i int64) *int64 { return &i }
+
+	batchGetter := NewMockMetricBatchGetter(ctrl)
+	lister := NewMockMetricLister(ctrl)
+	conn := startGRPCServer(t, func(srv *grpc.Server) {
+		pbv2.RegisterMetricServiceServer(srv, NewMetricGRPCV2Handler(
+			WithMetricGRPCV2BatchGetter(batchGetter),
+			WithMetricGRPCV2Lister(lister),
+		))
+	})
+	client := pbv2.NewMetricServiceClient(conn)
+
+	t.Run("get batch", func(t *testing.T) {
+		batchGetter.EXPECT().
+			GetBatch(gomock.Any(), []types.MetricID{
+				{ID: "Alloc", Type: types.Gauge},
+				{ID: "missing", Type: types.Counter},
+			}).
+			Return(
+				[]*types.Metrics{{ID: "Alloc", Type: types.Gauge, Value: ptrFloat64(2)}},
+				[]types.MetricID{{ID: "missing", Type: types.Counter}},
+				nil,
+			)
+
+		resp, err := client.GetBatch(context.Background(), &pbv2.GetMetricsRequest{Ids: []*pbv2.MetricID{
+			{Id: "Alloc", Type: pbv2.MetricType_METRIC_TYPE_GAUGE},
+			{Id: "missing", Type: pbv2.MetricType_METRIC_TYPE_COUNTER},
+		}})
+		require.NoError(t, err)
+		require.Len(t, resp.GetMetrics(), 1)
+		assert.Equal(t, 2.0, resp.GetMetrics()[0].GetGauge())
+		require.Len(t, resp.GetNotFound(), 1)
+		assert.Equal(t, pbv2.MetricType_METRIC_TYPE_COUNTER, resp.GetNotFound()[0].GetType())
+	})
+
+	t.Run("details survive the wire", func(t *testing.T) {
+		_, err := client.GetBatch(context.Background(), &pbv2.GetMetricsRequest{Ids: []*pbv2.MetricID{
+			{Id: "Alloc"},
+		}})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		assert.Equal(t, []string{"ids[0].type"}, badRequestFields(t, err))
+	})
+
+	t.Run("list by prefix", func(t *testing.T) {
+		lister.EXPECT().List(gomock.Any()).Return([]*types.Metrics{
+			{ID: "CPUutilization1", Type: types.Gauge, Value: ptrFloat64(10)},
+			{ID: "PollCount", Type: types.Counter, Delta: ptrInt64(5)},
+		}, nil)
+
+		stream, err := client.List(context.Background(), &pbv2.ListMetricsRequest{Prefix: "Poll"})
+		require.NoError(t, err)
+
+		m, err := stream.Recv()
+		require.NoError(t, err)
+		assert.Equal(t, int64(5), m.GetCounter())
+
+		_, err = stream.Recv()
+		assert.ErrorIs(t, err, io.EOF)
+	})
+
+	t.Run("list failure", func(t *testing.T) {
+		lister.EXPECT().List(gomock.Any()).Return(nil, errors.New("db down"))
+
+		stream, err := client.List(context.Background(), &pbv2.ListMetricsRequest{})
+		require.NoError(t, err)
+		_, err = stream.Recv()
+		assert.Equal(t, codes.Internal, status.Code(err))
+		assert.Equal(t, "STORAGE_FAILURE", errorInfoReason(err))
+	})
+}
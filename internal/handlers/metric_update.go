@@ -3,6 +3,8 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
 
@@ -81,11 +83,26 @@ func (h *MetricUpdatePathHandler) Update(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	if _, err := h.svc.Updates(r.Context(), []*types.Metrics{&metric}); err != nil {
+	expected, ok := ifMatchVersion(r)
+	if !ok {
+		w.WriteHeader(http.StatusPreconditionFailed)
+		return
+	}
+	metric.ExpectedVersion = expected
+
+	updatedMetrics, err := h.svc.Updates(r.Context(), []*types.Metrics{&metric})
+	if err != nil {
+		if errors.Is(err, types.ErrVersionConflict) {
+			w.WriteHeader(http.StatusPreconditionFailed)
+			return
+		}
 		w.WriteHeader(http.StatusInternalServerError)
 		return
 	}
 
+	if len(updatedMetrics) > 0 {
+		setMetricETag(w, updatedMetrics[0])
+	}
 	w.WriteHeader(http.StatusOK)
 }
 
@@ -147,13 +164,30 @@ func (h *MetricUpdateBodyHandler) Update(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	if !types.IsValidOp(metric.Type, metric.Op) {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	expected, ok := ifMatchVersion(r)
+	if !ok {
+		w.WriteHeader(http.StatusPreconditionFailed)
+		return
+	}
+	metric.ExpectedVersion = expected
+
 	updatedMetrics, err := h.svc.Updates(r.Context(), []*types.Metrics{&metric})
 	if err != nil {
+		if errors.Is(err, types.ErrVersionConflict) {
+			w.WriteHeader(http.StatusPreconditionFailed)
+			return
+		}
 		w.WriteHeader(http.StatusInternalServerError)
 		return
 	}
 
 	if len(updatedMetrics) > 0 {
+		setMetricETag(w, updatedMetrics[0])
 		w.WriteHeader(http.StatusOK)
 		json.NewEncoder(w).Encode(updatedMetrics[0])
 	}
@@ -220,6 +254,10 @@ func (h *MetricUpdatesBodyHandler) Updates(w http.ResponseWriter, r *http.Reques
 			w.WriteHeader(http.StatusBadRequest)
 			return
 		}
+		if !types.IsValidOp(m.Type, m.Op) {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
 	}
 
 	updatedMetrics, err := h.svc.Updates(r.Context(), metrics)
@@ -248,26 +286,37 @@ func NewMetricGRPCUpdaterHandler(updater MetricUpdater) *MetricGRPCUpdaterHandle
 	}
 }
 
-// Convert pb.Metric to types.Metrics
+// Convert pb.Metric to types.Metrics; only the field matching the metric type is set.
 func toMetric(m *pb.Metric) *types.Metrics {
-	v := m.GetValue()
-	d := m.GetDelta()
-	return &types.Metrics{
-		ID:    m.GetId(),
-		Type:  m.GetType(),
-		Value: &v,
-		Delta: &d,
+	metric := &types.Metrics{
+		ID:   m.GetId(),
+		Type: m.GetType(),
+		Op:   m.GetOp(),
 	}
+	switch m.GetType() {
+	case types.Gauge:
+		v := m.GetValue()
+		metric.Value = &v
+	case types.Counter:
+		d := m.GetDelta()
+		metric.Delta = &d
+	}
+	return metric
 }
 
 // Convert types.Metrics to pb.Metric
 func fromMetric(m *types.Metrics) *pb.Metric {
-	return &pb.Metric{
-		Id:    m.ID,
-		Type:  m.Type,
-		Value: *m.Value,
-		Delta: *m.Delta,
+	pbMetric := &pb.Metric{
+		Id:   m.ID,
+		Type: m.Type,
 	}
+	if m.Value != nil {
+		pbMetric.Value = *m.Value
+	}
+	if m.Delta != nil {
+		pbMetric.Delta = *m.Delta
+	}
+	return pbMetric
 }
 
 // Updates implements the gRPC server method, adapting calls to your internal interface.
@@ -277,6 +326,11 @@ func (s *MetricGRPCUpdaterHandler) Updates(
 ) (*pb.UpdateMetricsResponse, error) {
 	metrics := make([]*types.Metrics, 0, len(req.GetMetrics()))
 	for _, m := range req.GetMetrics() {
+		if !types.IsValidOp(m.GetType(), m.GetOp()) {
+			return &pb.UpdateMetricsResponse{
+				Error: fmt.Sprintf("unsupported operation %q for metric type %q", m.GetOp(), m.GetType()),
+			}, nil
+		}
 		metrics = append(metrics, toMetric(m))
 	}
 
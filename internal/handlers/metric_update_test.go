@@ -193,6 +193,59 @@ func TestMetricUpdateBodyHandler(t *testing.T) {
 			mockExpect:   func() {},
 			expectedCode: http.StatusBadRequest,
 		},
+		{
+			name: "Gauge max operation",
+			payload: types.Metrics{
+				ID:    "peak",
+				Type:  types.Gauge,
+				Value: ptrFloat64(12),
+				Op:    types.OpMax,
+			},
+			mockExpect: func() {
+				mockUpdater.EXPECT().
+					Updates(gomock.Any(), []*types.Metrics{{
+						ID:    "peak",
+						Type:  types.Gauge,
+						Value: ptrFloat64(12),
+						Op:    types.OpMax,
+					}}).
+					Return([]*types.Metrics{
+						{
+							ID:    "peak",
+							Type:  types.Gauge,
+							Value: ptrFloat64(20),
+						},
+					}, nil)
+			},
+			expectedCode: http.StatusOK,
+			expectedBody: &types.Metrics{
+				ID:    "peak",
+				Type:  types.Gauge,
+				Value: ptrFloat64(20),
+			},
+		},
+		{
+			name: "Unsupported counter operation",
+			payload: types.Metrics{
+				ID:    "myCounter",
+				Type:  types.Counter,
+				Delta: ptrInt64(1),
+				Op:    types.OpMax,
+			},
+			mockExpect:   func() {},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name: "Unknown operation",
+			payload: types.Metrics{
+				ID:    "myGauge",
+				Type:  types.Gauge,
+				Value: ptrFloat64(1),
+				Op:    "mul",
+			},
+			mockExpect:   func() {},
+			expectedCode: http.StatusBadRequest,
+		},
 		{
 			name:         "Missing metric ID",
 			payload:      types.Metrics{Type: types.Counter, Delta: ptrInt64(1)},
@@ -357,6 +410,40 @@ func TestMetricUpdatesBodyHandler(t *testing.T) {
 			},
 			expectedCode: http.StatusBadRequest,
 		},
+		{
+			name: "Batch with operations",
+			payload: []*types.Metrics{
+				{ID: "counter1", Type: types.Counter, Delta: ptrInt64(0), Op: types.OpSet},
+				{ID: "gauge1", Type: types.Gauge, Value: ptrFloat64(1), Op: types.OpSub},
+			},
+			mockExpect: func(mockUpdater *MockMetricUpdater) {
+				mockUpdater.EXPECT().
+					Updates(gomock.Any(), []*types.Metrics{
+						{ID: "counter1", Type: types.Counter, Delta: ptrInt64(0), Op: types.OpSet},
+						{ID: "gauge1", Type: types.Gauge, Value: ptrFloat64(1), Op: types.OpSub},
+					}).
+					Return([]*types.Metrics{
+						{ID: "counter1", Type: types.Counter, Delta: ptrInt64(0)},
+						{ID: "gauge1", Type: types.Gauge, Value: ptrFloat64(2)},
+					}, nil)
+			},
+			expectedCode: http.StatusOK,
+			expectedBody: []*types.Metrics{
+				{ID: "counter1", Type: types.Counter, Delta: ptrInt64(0)},
+				{ID: "gauge1", Type: types.Gauge, Value: ptrFloat64(2)},
+			},
+		},
+		{
+			name: "Batch with unsupported operation",
+			payload: []*types.Metrics{
+				{ID: "gauge1", Type: types.Gauge, Value: ptrFloat64(1), Op: types.OpMin},
+				{ID: "counter1", Type: types.Counter, Delta: ptrInt64(1), Op: types.OpSub},
+			},
+			mockExpect: func(_ *MockMetricUpdater) {
+				// No calls expected, handler returns 400 before calling Updates
+			},
+			expectedCode: http.StatusBadRequest,
+		},
 		{
 			name: "Updater returns error",
 			payload: []*types.Metrics{
@@ -440,12 +527,10 @@ func TestMetricUpdaterService_Updates(t *testing.T) {
 						ID:    "m1",
 						Type:  "gauge",
 						Value: ptrFloat64(1.23),
-						Delta: ptrInt64(0),
 					},
 					{
 						ID:    "m2",
 						Type:  "counter",
-						Value: ptrFloat64(0),
 						Delta: ptrInt64(42),
 					},
 				}
@@ -467,7 +552,6 @@ func TestMetricUpdaterService_Updates(t *testing.T) {
 						ID:    "m3",
 						Type:  "gauge",
 						Value: ptrFloat64(5.67),
-						Delta: ptrInt64(0),
 					},
 				}
 				mockUpdater.EXPECT().
@@ -477,6 +561,36 @@ func TestMetricUpdaterService_Updates(t *testing.T) {
 			wantErr: "update failed",
 			wantLen: 0,
 		},
+		{
+			name: "gauge max operation",
+			inputMetrics: []*pb.Metric{
+				{Id: "m4", Type: "gauge", Value: 9, Op: types.OpMax},
+			},
+			setup: func(mockUpdater *MockMetricUpdater) {
+				expectedMetrics := []*types.Metrics{
+					{
+						ID:    "m4",
+						Type:  "gauge",
+						Value: ptrFloat64(9),
+						Op:    types.OpMax,
+					},
+				}
+				mockUpdater.EXPECT().
+					Updates(ctx, gomock.Eq(expectedMetrics)).
+					Return(expectedMetrics, nil)
+			},
+			wantErr: "",
+			wantLen: 1,
+		},
+		{
+			name: "unsupported operation",
+			inputMetrics: []*pb.Metric{
+				{Id: "m5", Type: "counter", Delta: 1, Op: types.OpMax},
+			},
+			setup:   func(mockUpdater *MockMetricUpdater) {},
+			wantErr: `unsupported operation "max" for metric type "counter"`,
+			wantLen: 0,
+		},
 		{
 			name:         "empty input metrics",
 			inputMetrics: []*pb.Metric{},
@@ -524,3 +638,98 @@ func TestMetricUpdaterService_Updates(t *testing.T) {
 		})
 	}
 }
+
+func TestMetricUpdateHandlers_IfMatch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ptrFloat64 := func(f float64) *float64 { return &f }
+	ptrInt64 := func(i int64) *int64 { return &i }
+
+	mockUpdater := NewMockMetricUpdater(ctrl)
+
+	r := chi.NewRouter()
+	NewMetricUpdatePathHandler(WithMetricUpdaterPath(mockUpdater)).RegisterRoute(r)
+	NewMetricUpdateBodyHandler(WithMetricUpdaterBody(mockUpdater)).RegisterRoute(r)
+
+	tests := []struct {
+		name         string
+		url          string
+		body         string
+		ifMatch      string
+		mockExpect   func()
+		expectedCode int
+		expectedETag string
+	}{
+		{
+			name: "Unconditional update returns new ETag",
+			url:  "/update/gauge/Alloc/1.5",
+			mockExpect: func() {
+				mockUpdater.EXPECT().
+					Updates(gomock.Any(), []*types.Metrics{{ID: "Alloc", Type: types.Gauge, Value: ptrFloat64(1.5)}}).
+					Return([]*types.Metrics{{ID: "Alloc", Type: types.Gauge, Value: ptrFloat64(1.5), Version: 4}}, nil)
+			},
+			expectedCode: http.StatusOK,
+			expectedETag: `"4"`,
+		},
+		{
+			name:    "Matching version",
+			url:     "/update/gauge/Alloc/1.5",
+			ifMatch: `"3"`,
+			mockExpect: func() {
+				mockUpdater.EXPECT().
+					Updates(gomock.Any(), []*types.Metrics{{ID: "Alloc", Type: types.Gauge, Value: ptrFloat64(1.5), ExpectedVersion: ptrInt64(3)}}).
+					Return([]*types.Metrics{{ID: "Alloc", Type: types.Gauge, Value: ptrFloat64(1.5), Version: 4}}, nil)
+			},
+			expectedCode: http.StatusOK,
+			expectedETag: `"4"`,
+		},
+		{
+			name:    "Path version conflict",
+			url:     "/update/gauge/Alloc/1.5",
+			ifMatch: `"2"`,
+			mockExpect: func() {
+				mockUpdater.EXPECT().
+					Updates(gomock.Any(), gomock.Any()).
+					Return(nil, types.ErrVersionConflict)
+			},
+			expectedCode: http.StatusPreconditionFailed,
+		},
+		{
+			name:         "Malformed If-Match",
+			url:          "/update/gauge/Alloc/1.5",
+			ifMatch:      `W/"3"`,
+			mockExpect:   func() {},
+			expectedCode: http.StatusPreconditionFailed,
+		},
+		{
+			name:    "Body version conflict",
+			url:     "/update/",
+			body:    `{"id":"PollCount","type":"counter","delta":1}`,
+			ifMatch: `"7"`,
+			mockExpect: func() {
+				mockUpdater.EXPECT().
+					Updates(gomock.Any(), []*types.Metrics{{ID: "PollCount", Type: types.Counter, Delta: ptrInt64(1), ExpectedVersion: ptrInt64(7)}}).
+					Return(nil, types.ErrVersionConflict)
+			},
+			expectedCode: http.StatusPreconditionFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockExpect()
+
+			req := httptest.NewRequest(http.MethodPost, tt.url, bytes.NewBufferString(tt.body))
+			if tt.ifMatch != "" {
+				req.Header.Set("If-Match", tt.ifMatch)
+			}
+			rr := httptest.NewRecorder()
+
+			r.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedCode, rr.Code)
+			assert.Equal(t, tt.expectedETag, rr.Header().Get("ETag"))
+		})
+	}
+}
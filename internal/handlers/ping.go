@@ -1,15 +1,18 @@
 package handlers
 
 import (
+	"fmt"
 	"net/http"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/jmoiron/sqlx"
+	"github.com/sbilibin2017/go-yandex-practicum/internal/types"
 )
 
 // PingDBHandler handles HTTP requests for database connectivity check (ping).
 type PingDBHandler struct {
-	db *sqlx.DB
+	db      *sqlx.DB
+	checker HealthChecker
 }
 
 // PingHandlerOption defines a functional option for configuring PingDBHandler.
@@ -22,6 +25,14 @@ func WithPingDB(db *sqlx.DB) PingHandlerOption {
 	}
 }
 
+// WithPingHealthChecker makes /ping report every storage component through checker
+// instead of pinging the database directly.
+func WithPingHealthChecker(checker HealthChecker) PingHandlerOption {
+	return func(h *PingDBHandler) {
+		h.checker = checker
+	}
+}
+
 // NewPingDBHandler creates a new PingDBHandler with the given options.
 func NewPingDBHandler(opts ...PingHandlerOption) *PingDBHandler {
 	h := &PingDBHandler{}
@@ -35,6 +46,11 @@ func NewPingDBHandler(opts ...PingHandlerOption) *PingDBHandler {
 func (h *PingDBHandler) serveHTTP(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "text/plain")
 
+	if h.checker != nil {
+		h.writeHealth(w, r)
+		return
+	}
+
 	if h.db == nil {
 		w.WriteHeader(http.StatusServiceUnavailable)
 		return
@@ -48,6 +64,24 @@ func (h *PingDBHandler) serveHTTP(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 }
 
+// writeHealth writes one "component: status" line per storage component,
+// answering 500 if any of them is unhealthy.
+func (h *PingDBHandler) writeHealth(w http.ResponseWriter, r *http.Request) {
+	components := h.checker.Check(r.Context())
+	if types.AllHealthy(components) {
+		w.WriteHeader(http.StatusOK)
+	} else {
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+	for _, c := range components {
+		if c.Healthy {
+			fmt.Fprintf(w, "%s: ok\n", c.Name)
+		} else {
+			fmt.Fprintf(w, "%s: %s\n", c.Name, c.Error)
+		}
+	}
+}
+
 // RegisterRoute registers the /ping route on the provided router.
 func (h *PingDBHandler) RegisterRoute(r chi.Router) {
 	r.Get("/ping", h.serveHTTP)
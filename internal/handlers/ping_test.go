@@ -7,9 +7,12 @@ import (
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/go-chi/chi/v5"
+	"github.com/golang/mock/gomock"
 	"github.com/jmoiron/sqlx"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/sbilibin2017/go-yandex-practicum/internal/types"
 )
 
 func setupMockDB(t *testing.T) (*sqlx.DB, sqlmock.Sqlmock) {
@@ -100,3 +103,51 @@ func TestPingHandler_RegisterRoute(t *testing.T) {
 
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
+
+func TestPingHandler_ServeHTTP_HealthChecker(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tests := []struct {
+		name       string
+		components []types.ComponentHealth
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "healthy",
+			components: []types.ComponentHealth{{Name: types.HealthComponentMemory, Healthy: true}},
+			wantStatus: http.StatusOK,
+			wantBody:   "memory: ok\n",
+		},
+		{
+			name: "unhealthy component",
+			components: []types.ComponentHealth{
+				{Name: types.HealthComponentDatabase, Healthy: true},
+				{Name: types.HealthComponentFile, Error: "read-only file system"},
+			},
+			wantStatus: http.StatusInternalServerError,
+			wantBody:   "database: ok\nfile: read-only file system\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checker := NewMockHealthChecker(ctrl)
+			checker.EXPECT().Check(gomock.Any()).Return(tt.components)
+
+			// The checker takes precedence over the database ping.
+			handler := NewPingDBHandler(WithPingHealthChecker(checker))
+
+			req := httptest.NewRequest("GET", "/ping", nil)
+			w := httptest.NewRecorder()
+			handler.serveHTTP(w, req)
+
+			resp := w.Result()
+			defer resp.Body.Close()
+
+			assert.Equal(t, tt.wantStatus, resp.StatusCode)
+			assert.Equal(t, tt.wantBody, w.Body.String())
+		})
+	}
+}
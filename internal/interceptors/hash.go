@@ -0,0 +1,201 @@
+package interceptors
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// hashField names the string field carrying the hash of a client stream message.
+const hashField = "hash"
+
+// HashOption is a functional option for configuring the hash interceptors.
+type HashOption func(*hashInterceptor)
+
+// hashInterceptor holds interceptor runtime configuration.
+type hashInterceptor struct {
+	key      string
+	header   string
+	required bool
+}
+
+// WithHashKey sets the secret key for HMAC.
+func WithHashKey(key string) HashOption {
+	return func(i *hashInterceptor) {
+		i.key = key
+	}
+}
+
+// WithHashHeader sets the metadata key used to send/verify hashes.
+func WithHashHeader(header string) HashOption {
+	return func(i *hashInterceptor) {
+		i.header = strings.ToLower(header)
+	}
+}
+
+// WithHashRequired makes the hash metadata mandatory: calls without it are rejected.
+func WithHashRequired(required bool) HashOption {
+	return func(i *hashInterceptor) {
+		i.required = required
+	}
+}
+
+// HashUnaryInterceptor returns an interceptor that verifies the HMAC SHA256 of the
+// deterministically marshaled request passed in the configured metadata key and sends
+// the HMAC SHA256 of the response back in the same response header.
+// If the key is empty, the interceptor skips all processing.
+func HashUnaryInterceptor(opts ...HashOption) (grpc.UnaryServerInterceptor, error) {
+	i := &hashInterceptor{}
+	for _, opt := range opts {
+		opt(i)
+	}
+
+	return func(
+		ctx context.Context,
+		req any,
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (any, error) {
+		if i.key == "" {
+			return handler(ctx, req)
+		}
+
+		if err := i.verify(ctx, req); err != nil {
+			return nil, err
+		}
+
+		resp, err := handler(ctx, req)
+		if err != nil {
+			return resp, err
+		}
+
+		if msg, ok := resp.(proto.Message); ok {
+			if sum, err := i.sum(msg); err == nil {
+				_ = grpc.SetHeader(ctx, metadata.Pairs(i.header, sum))
+			}
+		}
+		return resp, nil
+	}, nil
+}
+
+// HashStreamInterceptor returns an interceptor that verifies the messages received on a
+// stream. The request of a server-streaming call is verified against the hash in the
+// stream metadata. Metadata is sent only once per stream, so every message of a client
+// stream carries its own hash in its hash field instead, computed with that field unset.
+func HashStreamInterceptor(opts ...HashOption) (grpc.StreamServerInterceptor, error) {
+	i := &hashInterceptor{}
+	for _, opt := range opts {
+		opt(i)
+	}
+
+	return func(
+		srv any,
+		ss grpc.ServerStream,
+		info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) error {
+		if i.key == "" {
+			return handler(srv, ss)
+		}
+		return handler(srv, &hashServerStream{
+			ServerStream: ss,
+			interceptor:  i,
+			clientStream: info.IsClientStream,
+		})
+	}, nil
+}
+
+// verify checks the request hash from the incoming metadata.
+func (i *hashInterceptor) verify(ctx context.Context, req any) error {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get(i.header)
+	if len(values) == 0 || values[0] == "" {
+		if i.required {
+			return status.Error(codes.Unauthenticated, "missing request hash")
+		}
+		return nil
+	}
+
+	msg, ok := req.(proto.Message)
+	if !ok {
+		return status.Error(codes.Internal, "request is not a protobuf message")
+	}
+	expected, err := i.sum(msg)
+	if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+	if !hmac.Equal([]byte(values[0]), []byte(expected)) {
+		return status.Error(codes.Unauthenticated, "invalid request hash")
+	}
+	return nil
+}
+
+// verifyMessage checks the hash carried in the hash field of the message itself.
+func (i *hashInterceptor) verifyMessage(m any) error {
+	msg, ok := m.(proto.Message)
+	if !ok {
+		return status.Error(codes.Internal, "request is not a protobuf message")
+	}
+	r := msg.ProtoReflect()
+	field := r.Descriptor().Fields().ByName(hashField)
+	if field == nil || field.Kind() != protoreflect.StringKind || r.Get(field).String() == "" {
+		if i.required {
+			return status.Error(codes.Unauthenticated, "missing message hash")
+		}
+		return nil
+	}
+
+	unsigned := proto.Clone(msg)
+	unsigned.ProtoReflect().Clear(field)
+	expected, err := i.sum(unsigned)
+	if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+	if !hmac.Equal([]byte(r.Get(field).String()), []byte(expected)) {
+		return status.Error(codes.Unauthenticated, "invalid message hash")
+	}
+	return nil
+}
+
+// sum returns the hex HMAC SHA256 of the deterministic encoding of msg.
+func (i *hashInterceptor) sum(msg proto.Message) (string, error) {
+	body, err := proto.MarshalOptions{Deterministic: true}.Marshal(msg)
+	if err != nil {
+		return "", err
+	}
+	mac := hmac.New(sha256.New, []byte(i.key))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+// hashServerStream verifies every message of a client stream, or the single
+// request of a server stream.
+type hashServerStream struct {
+	grpc.ServerStream
+	interceptor  *hashInterceptor
+	clientStream bool
+	verified     bool
+}
+
+func (s *hashServerStream) RecvMsg(m any) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	if s.clientStream {
+		return s.interceptor.verifyMessage(m)
+	}
+	if s.verified {
+		return nil
+	}
+	s.verified = true
+	return s.interceptor.verify(s.Context(), m)
+}
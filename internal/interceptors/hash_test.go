@@ -0,0 +1,167 @@
+package interceptors
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	pb "github.com/sbilibin2017/go-yandex-practicum/protos"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+func copyMsg(dst, src any) {
+	proto.Merge(dst.(proto.Message), src.(proto.Message))
+}
+
+func hashOf(t *testing.T, key string, msg proto.Message) string {
+	t.Helper()
+	body, err := proto.MarshalOptions{Deterministic: true}.Marshal(msg)
+	require.NoError(t, err)
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestHashUnaryInterceptor(t *testing.T) {
+	const key = "secret"
+	req := &pb.GetMetricRequest{Id: &pb.MetricID{Id: "Alloc", Type: "gauge"}}
+	info := &grpc.UnaryServerInfo{FullMethod: "/svc/Get"}
+	handler := func(ctx context.Context, req any) (any, error) {
+		return &pb.GetMetricResponse{}, nil
+	}
+
+	tests := []struct {
+		name     string
+		opts     []HashOption
+		md       metadata.MD
+		wantCode codes.Code
+	}{
+		{
+			name:     "no key skips verification",
+			md:       metadata.Pairs("hashsha256", "bogus"),
+			wantCode: codes.OK,
+		},
+		{
+			name:     "valid hash",
+			opts:     []HashOption{WithHashKey(key), WithHashHeader("HashSHA256")},
+			md:       metadata.Pairs("hashsha256", hashOf(t, key, req)),
+			wantCode: codes.OK,
+		},
+		{
+			name:     "invalid hash",
+			opts:     []HashOption{WithHashKey(key), WithHashHeader("HashSHA256")},
+			md:       metadata.Pairs("hashsha256", "bogus"),
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			name:     "missing hash allowed",
+			opts:     []HashOption{WithHashKey(key), WithHashHeader("HashSHA256")},
+			wantCode: codes.OK,
+		},
+		{
+			name:     "missing hash required",
+			opts:     []HashOption{WithHashKey(key), WithHashHeader("HashSHA256"), WithHashRequired(true)},
+			wantCode: codes.Unauthenticated,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			interceptor, err := HashUnaryInterceptor(tt.opts...)
+			require.NoError(t, err)
+
+			ctx := metadata.NewIncomingContext(context.Background(), tt.md)
+			_, err = interceptor(ctx, req, info, handler)
+			assert.Equal(t, tt.wantCode, status.Code(err))
+		})
+	}
+}
+
+func TestHashStreamInterceptor(t *testing.T) {
+	const key = "secret"
+	req := &pb.ListMetricsRequest{Prefix: "Alloc"}
+	info := &grpc.StreamServerInfo{FullMethod: "/svc/List"}
+
+	interceptor, err := HashStreamInterceptor(WithHashKey(key), WithHashHeader("HashSHA256"))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		hash     string
+		wantCode codes.Code
+	}{
+		{name: "valid hash", hash: hashOf(t, key, req), wantCode: codes.OK},
+		{name: "invalid hash", hash: "bogus", wantCode: codes.Unauthenticated},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("hashsha256", tt.hash))
+			ss := &fakeServerStream{ctx: ctx, recv: []any{req}}
+
+			err := interceptor(nil, ss, info, func(srv any, stream grpc.ServerStream) error {
+				return stream.RecvMsg(&pb.ListMetricsRequest{})
+			})
+			assert.Equal(t, tt.wantCode, status.Code(err))
+		})
+	}
+}
+
+func TestHashStreamInterceptor_ClientStream(t *testing.T) {
+	const key = "secret"
+	info := &grpc.StreamServerInfo{FullMethod: "/svc/StreamUpdates", IsClientStream: true}
+
+	signed := func(id string) *pb.UpdateMetricsRequest {
+		req := &pb.UpdateMetricsRequest{Metrics: []*pb.Metric{{Id: id, Type: "gauge", Value: 1}}}
+		req.Hash = hashOf(t, key, req)
+		return req
+	}
+	tampered := signed("Alloc")
+	tampered.Metrics[0].Value = 2
+	unsigned := &pb.UpdateMetricsRequest{Metrics: []*pb.Metric{{Id: "Frees", Type: "gauge", Value: 1}}}
+
+	tests := []struct {
+		name     string
+		opts     []HashOption
+		recv     []any
+		wantRecv int
+		wantCode codes.Code
+	}{
+		{name: "every message signed", recv: []any{signed("Alloc"), signed("Frees")}, wantRecv: 2, wantCode: codes.OK},
+		{name: "later message tampered", recv: []any{signed("Frees"), tampered}, wantRecv: 1, wantCode: codes.Unauthenticated},
+		{name: "later message unsigned", opts: []HashOption{WithHashRequired(true)}, recv: []any{signed("Alloc"), unsigned}, wantRecv: 1, wantCode: codes.Unauthenticated},
+		{name: "unsigned messages allowed", recv: []any{unsigned}, wantRecv: 1, wantCode: codes.OK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			interceptor, err := HashStreamInterceptor(append([]HashOption{WithHashKey(key)}, tt.opts...)...)
+			require.NoError(t, err)
+
+			// The stream metadata hash is not consulted for client streams
+			ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("hashsha256", "bogus"))
+			ss := &fakeServerStream{ctx: ctx, recv: tt.recv}
+
+			var received int
+			err = interceptor(nil, ss, info, func(srv any, stream grpc.ServerStream) error {
+				for range tt.recv {
+					if err := stream.RecvMsg(&pb.UpdateMetricsRequest{}); err != nil {
+						return err
+					}
+					received++
+				}
+				return nil
+			})
+			assert.Equal(t, tt.wantCode, status.Code(err))
+			assert.Equal(t, tt.wantRecv, received)
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package interceptors
+
+import (
+	"context"
+
+	"github.com/sbilibin2017/go-yandex-practicum/internal/certificates"
+	"github.com/sbilibin2017/go-yandex-practicum/internal/contexts"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+)
+
+// AgentIdentityUnaryInterceptor stores the identity of the client certificate
+// the call was made with in the call context, like AgentIdentityMiddleware does for HTTP.
+func AgentIdentityUnaryInterceptor(
+	ctx context.Context,
+	req any,
+	info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (any, error) {
+	return handler(withAgentIdentity(ctx), req)
+}
+
+// AgentIdentityStreamInterceptor is the streaming counterpart of AgentIdentityUnaryInterceptor.
+func AgentIdentityStreamInterceptor(
+	srv any,
+	ss grpc.ServerStream,
+	info *grpc.StreamServerInfo,
+	handler grpc.StreamHandler,
+) error {
+	ctx := withAgentIdentity(ss.Context())
+	if ctx == ss.Context() {
+		return handler(srv, ss)
+	}
+	return handler(srv, &contextServerStream{ServerStream: ss, ctx: ctx})
+}
+
+func withAgentIdentity(ctx context.Context) context.Context {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return ctx
+	}
+	tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok {
+		return ctx
+	}
+	if agent := certificates.PeerIdentity(&tlsInfo.State); agent != "" {
+		return contexts.SetAgentToContext(ctx, agent)
+	}
+	return ctx
+}
+
+// contextServerStream overrides the context of a server stream.
+type contextServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextServerStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,72 @@
+package interceptors
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net"
+	"testing"
+
+	"github.com/sbilibin2017/go-yandex-practicum/internal/contexts"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+)
+
+func peerContext(authInfo credentials.AuthInfo) context.Context {
+	return peer.NewContext(context.Background(), &peer.Peer{
+		Addr:     &net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: 1234},
+		AuthInfo: authInfo,
+	})
+}
+
+func TestAgentIdentityUnaryInterceptor(t *testing.T) {
+	agentTLS := credentials.TLSInfo{State: tls.ConnectionState{PeerCertificates: []*x509.Certificate{
+		{Subject: pkix.Name{CommonName: "agent-1"}},
+	}}}
+
+	tests := []struct {
+		name      string
+		ctx       context.Context
+		wantAgent string
+		wantOK    bool
+	}{
+		{name: "no peer", ctx: context.Background()},
+		{name: "insecure peer", ctx: peerContext(nil)},
+		{name: "TLS without client certificate", ctx: peerContext(credentials.TLSInfo{})},
+		{name: "client certificate", ctx: peerContext(agentTLS), wantAgent: "agent-1", wantOK: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotAgent string
+			var gotOK bool
+			_, err := AgentIdentityUnaryInterceptor(tt.ctx, "req", &grpc.UnaryServerInfo{FullMethod: "/svc/Get"},
+				func(ctx context.Context, req any) (any, error) {
+					gotAgent, gotOK = contexts.GetAgentFromContext(ctx)
+					return nil, nil
+				})
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantOK, gotOK)
+			assert.Equal(t, tt.wantAgent, gotAgent)
+		})
+	}
+}
+
+func TestAgentIdentityStreamInterceptor(t *testing.T) {
+	ctx := peerContext(credentials.TLSInfo{State: tls.ConnectionState{PeerCertificates: []*x509.Certificate{
+		{Subject: pkix.Name{CommonName: "agent-2"}},
+	}}})
+	ss := &fakeServerStream{ctx: ctx}
+
+	var gotAgent string
+	err := AgentIdentityStreamInterceptor(nil, ss, &grpc.StreamServerInfo{FullMethod: "/svc/Watch"},
+		func(srv any, stream grpc.ServerStream) error {
+			gotAgent, _ = contexts.GetAgentFromContext(stream.Context())
+			return nil
+		})
+	require.NoError(t, err)
+	assert.Equal(t, "agent-2", gotAgent)
+}
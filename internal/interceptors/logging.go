@@ -0,0 +1,91 @@
+package interceptors
+
+import (
+	"context"
+	"time"
+
+	"github.com/sbilibin2017/go-yandex-practicum/internal/contexts"
+	"github.com/sbilibin2017/go-yandex-practicum/internal/logger"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// LoggingUnaryInterceptor logs every unary call with its method, duration
+// and resulting status code, like LoggingMiddleware does for HTTP.
+func LoggingUnaryInterceptor(
+	ctx context.Context,
+	req any,
+	info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (any, error) {
+	start := time.Now()
+
+	resp, err := handler(ctx, req)
+
+	logger.Log.Infow("Request info", requestFields(ctx,
+		"method", info.FullMethod,
+		"duration", time.Since(start),
+	)...)
+	logger.Log.Infow("Response info",
+		"status", status.Code(err).String(),
+	)
+
+	return resp, err
+}
+
+// LoggingStreamInterceptor logs every streaming call once it finishes, including
+// the number of messages received from and sent to the client.
+func LoggingStreamInterceptor(
+	srv any,
+	ss grpc.ServerStream,
+	info *grpc.StreamServerInfo,
+	handler grpc.StreamHandler,
+) error {
+	start := time.Now()
+
+	counting := &countingServerStream{ServerStream: ss}
+	err := handler(srv, counting)
+
+	logger.Log.Infow("Request info", requestFields(ss.Context(),
+		"method", info.FullMethod,
+		"duration", time.Since(start),
+		"received", counting.received,
+	)...)
+	logger.Log.Infow("Response info",
+		"status", status.Code(err).String(),
+		"sent", counting.sent,
+	)
+
+	return err
+}
+
+// requestFields adds the agent identity, when known, to the request log fields.
+func requestFields(ctx context.Context, fields ...any) []any {
+	if agent, ok := contexts.GetAgentFromContext(ctx); ok {
+		fields = append(fields, "agent", agent)
+	}
+	return fields
+}
+
+// countingServerStream counts the messages passing through a server stream.
+type countingServerStream struct {
+	grpc.ServerStream
+	received int
+	sent     int
+}
+
+func (s *countingServerStream) RecvMsg(m any) error {
+	err := s.ServerStream.RecvMsg(m)
+	if err == nil {
+		s.received++
+	}
+	return err
+}
+
+func (s *countingServerStream) SendMsg(m any) error {
+	err := s.ServerStream.SendMsg(m)
+	if err == nil {
+		s.sent++
+	}
+	return err
+}
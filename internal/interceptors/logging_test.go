@@ -0,0 +1,70 @@
+package interceptors
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// fakeServerStream is a minimal grpc.ServerStream for interceptor tests.
+type fakeServerStream struct {
+	grpc.ServerStream
+	ctx  context.Context
+	recv []any
+	sent []any
+}
+
+func (s *fakeServerStream) Context() context.Context { return s.ctx }
+
+func (s *fakeServerStream) SetHeader(metadata.MD) error { return nil }
+
+func (s *fakeServerStream) RecvMsg(m any) error {
+	if len(s.recv) == 0 {
+		return errors.New("EOF")
+	}
+	copyMsg(m, s.recv[0])
+	s.recv = s.recv[1:]
+	return nil
+}
+
+func (s *fakeServerStream) SendMsg(m any) error {
+	s.sent = append(s.sent, m)
+	return nil
+}
+
+func TestLoggingUnaryInterceptor(t *testing.T) {
+	info := &grpc.UnaryServerInfo{FullMethod: "/svc/Method"}
+
+	resp, err := LoggingUnaryInterceptor(context.Background(), "req", info,
+		func(ctx context.Context, req any) (any, error) {
+			return "resp", nil
+		})
+	assert.NoError(t, err)
+	assert.Equal(t, "resp", resp)
+
+	handlerErr := errors.New("boom")
+	_, err = LoggingUnaryInterceptor(context.Background(), "req", info,
+		func(ctx context.Context, req any) (any, error) {
+			return nil, handlerErr
+		})
+	assert.ErrorIs(t, err, handlerErr)
+}
+
+func TestLoggingStreamInterceptor(t *testing.T) {
+	ss := &fakeServerStream{ctx: context.Background()}
+	info := &grpc.StreamServerInfo{FullMethod: "/svc/Stream"}
+
+	err := LoggingStreamInterceptor(nil, ss, info, func(srv any, stream grpc.ServerStream) error {
+		assert.NoError(t, stream.SendMsg("a"))
+		assert.NoError(t, stream.SendMsg("b"))
+		counting := stream.(*countingServerStream)
+		assert.Equal(t, 2, counting.sent)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Len(t, ss.sent, 2)
+}
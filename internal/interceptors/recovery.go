@@ -0,0 +1,51 @@
+package interceptors
+
+import (
+	"context"
+	"runtime/debug"
+
+	"github.com/sbilibin2017/go-yandex-practicum/internal/logger"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// RecoveryUnaryInterceptor converts a panic in a unary handler into an Internal
+// status instead of crashing the server.
+func RecoveryUnaryInterceptor(
+	ctx context.Context,
+	req any,
+	info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (resp any, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = recovered(info.FullMethod, r)
+		}
+	}()
+	return handler(ctx, req)
+}
+
+// RecoveryStreamInterceptor converts a panic in a stream handler into an Internal status.
+func RecoveryStreamInterceptor(
+	srv any,
+	ss grpc.ServerStream,
+	info *grpc.StreamServerInfo,
+	handler grpc.StreamHandler,
+) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = recovered(info.FullMethod, r)
+		}
+	}()
+	return handler(srv, ss)
+}
+
+func recovered(method string, r any) error {
+	logger.Log.Errorw("Recovered from panic in gRPC handler",
+		"method", method,
+		"panic", r,
+		"stack", string(debug.Stack()),
+	)
+	return status.Error(codes.Internal, "internal server error")
+}
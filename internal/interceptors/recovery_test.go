@@ -0,0 +1,44 @@
+package interceptors
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestRecoveryUnaryInterceptor(t *testing.T) {
+	info := &grpc.UnaryServerInfo{FullMethod: "/svc/Method"}
+
+	resp, err := RecoveryUnaryInterceptor(context.Background(), nil, info,
+		func(ctx context.Context, req any) (any, error) {
+			panic("boom")
+		})
+	assert.Nil(t, resp)
+	assert.Equal(t, codes.Internal, status.Code(err))
+
+	resp, err = RecoveryUnaryInterceptor(context.Background(), nil, info,
+		func(ctx context.Context, req any) (any, error) {
+			return "ok", nil
+		})
+	assert.NoError(t, err)
+	assert.Equal(t, "ok", resp)
+}
+
+func TestRecoveryStreamInterceptor(t *testing.T) {
+	ss := &fakeServerStream{ctx: context.Background()}
+	info := &grpc.StreamServerInfo{FullMethod: "/svc/Stream"}
+
+	err := RecoveryStreamInterceptor(nil, ss, info, func(srv any, stream grpc.ServerStream) error {
+		panic("boom")
+	})
+	assert.Equal(t, codes.Internal, status.Code(err))
+
+	err = RecoveryStreamInterceptor(nil, ss, info, func(srv any, stream grpc.ServerStream) error {
+		return nil
+	})
+	assert.NoError(t, err)
+}
@@ -0,0 +1,118 @@
+package interceptors
+
+import (
+	"context"
+	"net"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// RealIPHeader is the metadata key agents use to report their own IP address.
+const RealIPHeader = "x-real-ip"
+
+// TrustedSubnetOption is a functional option for configuring the trusted subnet interceptors.
+type TrustedSubnetOption func(*trustedSubnetInterceptor)
+
+type trustedSubnetInterceptor struct {
+	cidr   string
+	subnet *net.IPNet
+}
+
+// WithTrustedSubnet sets the CIDR that client addresses must belong to.
+func WithTrustedSubnet(cidr string) TrustedSubnetOption {
+	return func(i *trustedSubnetInterceptor) {
+		i.cidr = cidr
+	}
+}
+
+// TrustedSubnetUnaryInterceptor returns an interceptor that rejects unary calls whose
+// client address is outside the trusted subnet with PermissionDenied.
+// The address is taken from the x-real-ip metadata, falling back to the peer address.
+// If no subnet is configured, every call passes.
+func TrustedSubnetUnaryInterceptor(opts ...TrustedSubnetOption) (grpc.UnaryServerInterceptor, error) {
+	i, err := newTrustedSubnetInterceptor(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(
+		ctx context.Context,
+		req any,
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (any, error) {
+		if err := i.check(ctx); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}, nil
+}
+
+// TrustedSubnetStreamInterceptor is the streaming counterpart of TrustedSubnetUnaryInterceptor.
+func TrustedSubnetStreamInterceptor(opts ...TrustedSubnetOption) (grpc.StreamServerInterceptor, error) {
+	i, err := newTrustedSubnetInterceptor(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(
+		srv any,
+		ss grpc.ServerStream,
+		info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) error {
+		if err := i.check(ss.Context()); err != nil {
+			return err
+		}
+		return handler(srv, ss)
+	}, nil
+}
+
+func newTrustedSubnetInterceptor(opts ...TrustedSubnetOption) (*trustedSubnetInterceptor, error) {
+	i := &trustedSubnetInterceptor{}
+	for _, opt := range opts {
+		opt(i)
+	}
+	if i.cidr == "" {
+		return i, nil
+	}
+	_, subnet, err := net.ParseCIDR(i.cidr)
+	if err != nil {
+		return nil, err
+	}
+	i.subnet = subnet
+	return i, nil
+}
+
+func (i *trustedSubnetInterceptor) check(ctx context.Context) error {
+	if i.subnet == nil {
+		return nil
+	}
+	ip := clientIP(ctx)
+	if ip == nil || !i.subnet.Contains(ip) {
+		return status.Error(codes.PermissionDenied, "client address is not in trusted subnet")
+	}
+	return nil
+}
+
+// clientIP returns the address reported in x-real-ip, or the peer address.
+func clientIP(ctx context.Context) net.IP {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(RealIPHeader); len(values) > 0 {
+			return net.ParseIP(values[0])
+		}
+	}
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return nil
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		host = p.Addr.String()
+	}
+	return net.ParseIP(host)
+}
@@ -0,0 +1,96 @@
+package interceptors
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+func TestTrustedSubnetUnaryInterceptor(t *testing.T) {
+	info := &grpc.UnaryServerInfo{FullMethod: "/svc/Updates"}
+	handler := func(ctx context.Context, req any) (any, error) { return "ok", nil }
+	peerCtx := func(ip string) context.Context {
+		return peer.NewContext(context.Background(), &peer.Peer{
+			Addr: &net.TCPAddr{IP: net.ParseIP(ip), Port: 5000},
+		})
+	}
+
+	tests := []struct {
+		name     string
+		subnet   string
+		ctx      context.Context
+		wantCode codes.Code
+	}{
+		{
+			name:     "no subnet configured",
+			ctx:      context.Background(),
+			wantCode: codes.OK,
+		},
+		{
+			name:     "real ip inside subnet",
+			subnet:   "192.168.1.0/24",
+			ctx:      metadata.NewIncomingContext(peerCtx("10.0.0.1"), metadata.Pairs("x-real-ip", "192.168.1.10")),
+			wantCode: codes.OK,
+		},
+		{
+			name:     "real ip outside subnet",
+			subnet:   "192.168.1.0/24",
+			ctx:      metadata.NewIncomingContext(peerCtx("192.168.1.10"), metadata.Pairs("x-real-ip", "10.0.0.1")),
+			wantCode: codes.PermissionDenied,
+		},
+		{
+			name:     "malformed real ip",
+			subnet:   "192.168.1.0/24",
+			ctx:      metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-real-ip", "nope")),
+			wantCode: codes.PermissionDenied,
+		},
+		{
+			name:     "peer inside subnet",
+			subnet:   "127.0.0.0/8",
+			ctx:      peerCtx("127.0.0.1"),
+			wantCode: codes.OK,
+		},
+		{
+			name:     "no address",
+			subnet:   "127.0.0.0/8",
+			ctx:      context.Background(),
+			wantCode: codes.PermissionDenied,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			interceptor, err := TrustedSubnetUnaryInterceptor(WithTrustedSubnet(tt.subnet))
+			require.NoError(t, err)
+
+			_, err = interceptor(tt.ctx, nil, info, handler)
+			assert.Equal(t, tt.wantCode, status.Code(err))
+		})
+	}
+}
+
+func TestTrustedSubnetStreamInterceptor(t *testing.T) {
+	interceptor, err := TrustedSubnetStreamInterceptor(WithTrustedSubnet("192.168.1.0/24"))
+	require.NoError(t, err)
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-real-ip", "10.0.0.1"))
+	err = interceptor(nil, &fakeServerStream{ctx: ctx}, &grpc.StreamServerInfo{},
+		func(srv any, stream grpc.ServerStream) error { return nil })
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+}
+
+func TestTrustedSubnetInterceptor_InvalidCIDR(t *testing.T) {
+	_, err := TrustedSubnetUnaryInterceptor(WithTrustedSubnet("not-a-cidr"))
+	assert.Error(t, err)
+
+	_, err = TrustedSubnetStreamInterceptor(WithTrustedSubnet("not-a-cidr"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,87 @@
+package interceptors
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/jmoiron/sqlx"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// TxOption defines a functional option for configuring TxUnaryInterceptor.
+type TxOption func(*txInterceptor)
+
+type txInterceptor struct {
+	db       *sqlx.DB
+	txOpts   *sql.TxOptions
+	txSetter func(ctx context.Context, tx *sqlx.Tx) context.Context
+}
+
+// WithDB sets the database connection to be used by the transaction interceptor.
+func WithDB(db *sqlx.DB) TxOption {
+	return func(i *txInterceptor) {
+		i.db = db
+	}
+}
+
+// WithTxOptions sets transaction options such as isolation level or read-only flag.
+func WithTxOptions(opts *sql.TxOptions) TxOption {
+	return func(i *txInterceptor) {
+		i.txOpts = opts
+	}
+}
+
+// WithTxSetter sets a function to inject the started transaction into the call context.
+func WithTxSetter(setter func(ctx context.Context, tx *sqlx.Tx) context.Context) TxOption {
+	return func(i *txInterceptor) {
+		i.txSetter = setter
+	}
+}
+
+// TxUnaryInterceptor returns an interceptor that starts a DB transaction before handling
+// a unary call, commits if the handler succeeds, and rolls back if it returns an error.
+// If no DB is configured, it passes through without starting a transaction.
+//
+// Streaming calls are intentionally not wrapped: they may stay open for the lifetime
+// of a client, and holding a transaction that long would block other writers.
+func TxUnaryInterceptor(opts ...TxOption) (grpc.UnaryServerInterceptor, error) {
+	i := &txInterceptor{}
+	for _, opt := range opts {
+		opt(i)
+	}
+
+	return func(
+		ctx context.Context,
+		req any,
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (any, error) {
+		if i.db == nil {
+			return handler(ctx, req)
+		}
+
+		tx, err := i.db.BeginTxx(ctx, i.txOpts)
+		if err != nil {
+			return nil, status.Error(codes.Unavailable, "failed to begin transaction")
+		}
+
+		if i.txSetter != nil {
+			ctx = i.txSetter(ctx, tx)
+		}
+
+		resp, err := handler(ctx, req)
+		if err != nil {
+			_ = tx.Rollback() // ignore rollback error
+			return nil, err
+		}
+
+		if err := tx.Commit(); err != nil {
+			_ = tx.Rollback() // ignore rollback error
+			return nil, status.Error(codes.Aborted, "failed to commit transaction")
+		}
+
+		return resp, nil
+	}, nil
+}
@@ -0,0 +1,103 @@
+package interceptors
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/sbilibin2017/go-yandex-practicum/internal/contexts"
+)
+
+func setupMockDB(t *testing.T) (*sqlx.DB, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	t.Cleanup(func() { sqlxDB.Close() })
+	return sqlxDB, mock
+}
+
+func TestTxUnaryInterceptor(t *testing.T) {
+	info := &grpc.UnaryServerInfo{FullMethod: "/svc/Updates"}
+	handlerErr := errors.New("handler failed")
+
+	tests := []struct {
+		name       string
+		setupMock  func(mock sqlmock.Sqlmock)
+		handlerErr error
+		wantCode   codes.Code
+	}{
+		{
+			name: "commit on success",
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectCommit()
+			},
+			wantCode: codes.OK,
+		},
+		{
+			name: "rollback on handler error",
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectRollback()
+			},
+			handlerErr: handlerErr,
+			wantCode:   codes.Unknown,
+		},
+		{
+			name: "begin error",
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin().WillReturnError(errors.New("begin failed"))
+			},
+			wantCode: codes.Unavailable,
+		},
+		{
+			name: "commit error",
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectCommit().WillReturnError(errors.New("commit failed"))
+			},
+			wantCode: codes.Aborted,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock := setupMockDB(t)
+			tt.setupMock(mock)
+
+			interceptor, err := TxUnaryInterceptor(WithDB(db), WithTxSetter(contexts.SetTxToContext))
+			require.NoError(t, err)
+
+			_, err = interceptor(context.Background(), nil, info,
+				func(ctx context.Context, req any) (any, error) {
+					_, ok := contexts.GetTxFromContext(ctx)
+					assert.True(t, ok)
+					return "ok", tt.handlerErr
+				})
+			assert.Equal(t, tt.wantCode, status.Code(err))
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestTxUnaryInterceptor_NoDB(t *testing.T) {
+	interceptor, err := TxUnaryInterceptor()
+	require.NoError(t, err)
+
+	resp, err := interceptor(context.Background(), nil, &grpc.UnaryServerInfo{},
+		func(ctx context.Context, req any) (any, error) {
+			_, ok := contexts.GetTxFromContext(ctx)
+			assert.False(t, ok)
+			return "ok", nil
+		})
+	assert.NoError(t, err)
+	assert.Equal(t, "ok", resp)
+}
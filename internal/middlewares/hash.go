@@ -14,8 +14,10 @@ type HashOption func(*hashMiddleware)
 
 // hashMiddleware holds middleware runtime configuration.
 type hashMiddleware struct {
-	key    string
-	header string
+	key      string
+	header   string
+	required bool
+	target   bool
 }
 
 // WithHashKey sets the secret key for HMAC.
@@ -32,6 +34,32 @@ func WithHashHeader(header string) HashOption {
 	}
 }
 
+// WithHashRequired makes the hash header mandatory: requests without it are rejected.
+func WithHashRequired(required bool) HashOption {
+	return func(mw *hashMiddleware) {
+		mw.required = required
+	}
+}
+
+// WithHashRequestTarget makes the request hash cover the method and the
+// request URI (path and query) followed by a newline and the body, so a
+// signature made for one target cannot authorize a request to another.
+// Routes whose target is carried only by the URL, such as deletions, need it.
+func WithHashRequestTarget(enabled bool) HashOption {
+	return func(mw *hashMiddleware) {
+		mw.target = enabled
+	}
+}
+
+// RequestTargetHash returns the hex HMAC SHA256 of a request signed with
+// WithHashRequestTarget.
+func RequestTargetHash(key, method, requestURI string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(method + " " + requestURI + "\n"))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
 // HashMiddleware returns a middleware handler that verifies request body HMAC SHA256 and
 // adds response body HMAC SHA256 in the configured header.
 // If the key is empty, the middleware skips all processing.
@@ -55,11 +83,19 @@ func HashMiddleware(opts ...HashOption) (func(http.Handler) http.Handler, error)
 			r.Body = io.NopCloser(bytes.NewBuffer(bodyBytes))
 
 			receivedHash := r.Header.Get(mw.header)
+			if receivedHash == "" && mw.required {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
 			if receivedHash != "" {
-				mac := hmac.New(sha256.New, []byte(mw.key))
-				mac.Write(bodyBytes)
-				expectedMAC := mac.Sum(nil)
-				expectedHash := hex.EncodeToString(expectedMAC)
+				var expectedHash string
+				if mw.target {
+					expectedHash = RequestTargetHash(mw.key, r.Method, r.URL.RequestURI(), bodyBytes)
+				} else {
+					mac := hmac.New(sha256.New, []byte(mw.key))
+					mac.Write(bodyBytes)
+					expectedHash = hex.EncodeToString(mac.Sum(nil))
+				}
 
 				if !hmac.Equal([]byte(receivedHash), []byte(expectedHash)) {
 					w.WriteHeader(http.StatusBadRequest)
@@ -36,6 +36,7 @@ func TestHashMiddleware(t *testing.T) {
 		header         string
 		requestBody    string
 		requestHash    string
+		required       bool
 		wantStatusCode int
 		wantRespBody   string
 		wantRespHeader string
@@ -80,6 +81,28 @@ func TestHashMiddleware(t *testing.T) {
 			wantRespBody:   "",
 			wantRespHeader: "",
 		},
+		{
+			name:           "Key set, hash required, no hash header",
+			key:            key,
+			header:         headerName,
+			requestBody:    validBody,
+			requestHash:    "",
+			required:       true,
+			wantStatusCode: http.StatusBadRequest,
+			wantRespBody:   "",
+			wantRespHeader: "",
+		},
+		{
+			name:           "Key set, hash required, valid hash header",
+			key:            key,
+			header:         headerName,
+			requestBody:    validBody,
+			requestHash:    makeHash([]byte(validBody)),
+			required:       true,
+			wantStatusCode: http.StatusOK,
+			wantRespBody:   validResponseBody,
+			wantRespHeader: "",
+		},
 	}
 
 	for _, tt := range tests {
@@ -87,6 +110,7 @@ func TestHashMiddleware(t *testing.T) {
 			middleware, err := HashMiddleware(
 				WithHashKey(tt.key),
 				WithHashHeader(tt.header),
+				WithHashRequired(tt.required),
 			)
 			require.NoError(t, err)
 
@@ -127,3 +151,39 @@ func TestHashMiddleware(t *testing.T) {
 		})
 	}
 }
+
+func TestHashMiddleware_RequestTarget(t *testing.T) {
+	const headerName = "HashSHA256"
+	const key = "test-secret-key"
+
+	middleware, err := HashMiddleware(
+		WithHashKey(key),
+		WithHashHeader(headerName),
+		WithHashRequired(true),
+		WithHashRequestTarget(true),
+	)
+	require.NoError(t, err)
+	h := middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	signed := RequestTargetHash(key, http.MethodDelete, "/values/?prefix=CPU", nil)
+	tests := []struct {
+		name       string
+		method     string
+		target     string
+		wantStatus int
+	}{
+		{name: "signed target", method: http.MethodDelete, target: "/values/?prefix=CPU", wantStatus: http.StatusOK},
+		{name: "other query", method: http.MethodDelete, target: "/values/?prefix=", wantStatus: http.StatusBadRequest},
+		{name: "other path", method: http.MethodDelete, target: "/value/gauge/CPU", wantStatus: http.StatusBadRequest},
+		{name: "other method", method: http.MethodPost, target: "/values/?prefix=CPU", wantStatus: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.target, nil)
+			req.Header.Set(headerName, signed)
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, req)
+			require.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+}
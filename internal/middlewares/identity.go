@@ -0,0 +1,20 @@
+package middlewares
+
+import (
+	"net/http"
+
+	"github.com/sbilibin2017/go-yandex-practicum/internal/certificates"
+	"github.com/sbilibin2017/go-yandex-practicum/internal/contexts"
+)
+
+// AgentIdentityMiddleware stores the identity of the client certificate the
+// request was made with in the request context. Requests without a verified
+// client certificate pass through unchanged.
+func AgentIdentityMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if agent := certificates.PeerIdentity(r.TLS); agent != "" {
+			r = r.WithContext(contexts.SetAgentToContext(r.Context(), agent))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
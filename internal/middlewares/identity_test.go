@@ -0,0 +1,49 @@
+package middlewares
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sbilibin2017/go-yandex-practicum/internal/contexts"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAgentIdentityMiddleware(t *testing.T) {
+	tests := []struct {
+		name      string
+		tls       *tls.ConnectionState
+		wantAgent string
+		wantOK    bool
+	}{
+		{name: "plaintext"},
+		{name: "TLS without client certificate", tls: &tls.ConnectionState{}},
+		{
+			name: "client certificate",
+			tls: &tls.ConnectionState{PeerCertificates: []*x509.Certificate{
+				{Subject: pkix.Name{CommonName: "agent-1"}},
+			}},
+			wantAgent: "agent-1",
+			wantOK:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotAgent string
+			var gotOK bool
+			handler := AgentIdentityMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotAgent, gotOK = contexts.GetAgentFromContext(r.Context())
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.TLS = tt.tls
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			assert.Equal(t, tt.wantOK, gotOK)
+			assert.Equal(t, tt.wantAgent, gotAgent)
+		})
+	}
+}
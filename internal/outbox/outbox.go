@@ -0,0 +1,502 @@
+// Package outbox implements the agent's disk-backed outbox: metric batches the
+// server did not accept are appended to a bounded log of segment files and
+// replayed in order once it is reachable again.
+//
+// Each segment holds one JSON batch per line. A cursor file records the first
+// batch not yet delivered, so batches already replayed are not sent twice
+// after a restart.
+package outbox
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sbilibin2017/go-yandex-practicum/internal/logger"
+	"github.com/sbilibin2017/go-yandex-practicum/internal/types"
+)
+
+// Drop policies applied when a batch does not fit into the outbox.
+const (
+	DropOldest = "oldest" // DropOldest discards the oldest pending batches to make room.
+	DropNewest = "newest" // DropNewest rejects the batch being appended.
+)
+
+// ErrFull is returned by Append when the batch does not fit and the drop policy rejects it.
+var ErrFull = errors.New("outbox is full")
+
+const (
+	segmentExt = ".seg"
+	cursorFile = "cursor"
+)
+
+// Opt defines a functional option for Open.
+type Opt func(*options)
+
+type options struct {
+	dir          string
+	maxBytes     int64
+	maxAge       time.Duration
+	segmentBytes int64
+	dropPolicy   string
+	restore      bool
+	now          func() time.Time
+}
+
+// WithDir sets the directory holding the segment files.
+func WithDir(dir string) Opt {
+	return func(o *options) {
+		o.dir = dir
+	}
+}
+
+// WithMaxBytes limits the total size of pending batches. Zero means unlimited.
+func WithMaxBytes(n int64) Opt {
+	return func(o *options) {
+		o.maxBytes = n
+	}
+}
+
+// WithMaxAge discards batches older than age instead of replaying them. Zero keeps them forever.
+func WithMaxAge(age time.Duration) Opt {
+	return func(o *options) {
+		o.maxAge = age
+	}
+}
+
+// WithSegmentBytes sets the size after which a new segment file is started.
+func WithSegmentBytes(n int64) Opt {
+	return func(o *options) {
+		o.segmentBytes = n
+	}
+}
+
+// WithDropPolicy sets what happens when the outbox is full: DropOldest or DropNewest.
+func WithDropPolicy(policy string) Opt {
+	return func(o *options) {
+		o.dropPolicy = policy
+	}
+}
+
+// WithRestore keeps the batches left by a previous run; otherwise they are discarded on Open.
+func WithRestore(restore bool) Opt {
+	return func(o *options) {
+		o.restore = restore
+	}
+}
+
+// WithClock sets the clock used to timestamp and expire batches.
+func WithClock(now func() time.Time) Opt {
+	return func(o *options) {
+		o.now = now
+	}
+}
+
+// entry is one line of a segment file.
+type entry struct {
+	At      time.Time        `json:"at"`
+	Metrics []*types.Metrics `json:"metrics"`
+}
+
+// record locates a pending batch in the segment files.
+type record struct {
+	seq  uint64
+	off  int64
+	size int64
+	at   time.Time
+}
+
+// cursor is the position of the first undelivered batch.
+type cursor struct {
+	Segment uint64 `json:"segment"`
+	Offset  int64  `json:"offset"`
+}
+
+// Outbox is a bounded, ordered, disk-backed queue of metric batches.
+type Outbox struct {
+	opts options
+
+	mu       sync.Mutex
+	records  []record // pending batches, oldest first
+	size     int64    // total size of pending batches
+	tail     *os.File // segment being appended to
+	tailSeq  uint64
+	tailSize int64
+
+	replayMu sync.Mutex
+}
+
+// Open opens the outbox in its directory, creating it if needed.
+func Open(opts ...Opt) (*Outbox, error) {
+	o := &Outbox{opts: options{
+		segmentBytes: 1 << 20,
+		dropPolicy:   DropOldest,
+		now:          time.Now,
+	}}
+	for _, opt := range opts {
+		opt(&o.opts)
+	}
+
+	switch o.opts.dropPolicy {
+	case DropOldest, DropNewest:
+	default:
+		return nil, fmt.Errorf("unknown outbox drop policy %q", o.opts.dropPolicy)
+	}
+	if o.opts.dir == "" {
+		return nil, errors.New("outbox directory is not set")
+	}
+	if err := os.MkdirAll(o.opts.dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	seqs, err := o.segments()
+	if err != nil {
+		return nil, err
+	}
+
+	var cur cursor
+	if !o.opts.restore {
+		for _, seq := range seqs {
+			if err := os.Remove(o.segmentPath(seq)); err != nil {
+				return nil, err
+			}
+		}
+		if err := os.Remove(filepath.Join(o.opts.dir, cursorFile)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+		seqs = nil
+	} else if cur, err = o.load(seqs); err != nil {
+		return nil, err
+	}
+
+	// Never append behind the cursor, or the next restore would skip the batches
+	o.tailSeq = max(cur.Segment, 1)
+	if len(seqs) > 0 {
+		o.tailSeq = max(o.tailSeq, seqs[len(seqs)-1])
+	}
+	if err := o.openTail(); err != nil {
+		return nil, err
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if err := o.dropExpired(); err != nil {
+		o.tail.Close()
+		return nil, err
+	}
+	if len(o.records) > 0 {
+		logger.Log.Infow("Outbox restored", "batches", len(o.records), "bytes", o.size)
+	}
+	return o, nil
+}
+
+// Len returns the number of pending batches.
+func (o *Outbox) Len() int {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return len(o.records)
+}
+
+// Append stores a batch after all pending ones.
+func (o *Outbox) Append(metrics []*types.Metrics) error {
+	now := o.opts.now()
+	line, err := json.Marshal(entry{At: now, Metrics: metrics})
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+	n := int64(len(line))
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if err := o.dropExpired(); err != nil {
+		return err
+	}
+
+	if limit := o.opts.maxBytes; limit > 0 && o.size+n > limit {
+		if o.opts.dropPolicy == DropNewest || n > limit {
+			return ErrFull
+		}
+		drop := 0
+		freed := int64(0)
+		for o.size-freed+n > limit {
+			freed += o.records[drop].size
+			drop++
+		}
+		logger.Log.Warnw("Outbox full, dropping oldest batches", "batches", drop, "bytes", freed)
+		if err := o.advance(drop); err != nil {
+			return err
+		}
+	}
+
+	if o.tailSize > 0 && o.tailSize+n > o.opts.segmentBytes {
+		if err := o.tail.Close(); err != nil {
+			return err
+		}
+		o.tailSeq++
+		if err := o.openTail(); err != nil {
+			return err
+		}
+	}
+
+	if _, err := o.tail.Write(line); err != nil {
+		// Do not leave a torn line behind for the next append
+		o.tail.Truncate(o.tailSize)
+		return err
+	}
+	if err := o.tail.Sync(); err != nil {
+		return err
+	}
+
+	o.records = append(o.records, record{seq: o.tailSeq, off: o.tailSize, size: n, at: now})
+	o.tailSize += n
+	o.size += n
+	return nil
+}
+
+// Replay sends pending batches oldest first, removing each one send accepts.
+// It stops at the first error, leaving that batch and the later ones pending.
+func (o *Outbox) Replay(ctx context.Context, send func(ctx context.Context, metrics []*types.Metrics) error) error {
+	o.replayMu.Lock()
+	defer o.replayMu.Unlock()
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		o.mu.Lock()
+		if err := o.dropExpired(); err != nil {
+			o.mu.Unlock()
+			return err
+		}
+		if len(o.records) == 0 {
+			o.mu.Unlock()
+			return nil
+		}
+		rec := o.records[0]
+		o.mu.Unlock()
+
+		e, err := o.read(rec)
+		if err == nil {
+			if err := send(ctx, e.Metrics); err != nil {
+				return err
+			}
+		} else {
+			logger.Log.Errorw("Outbox: dropping unreadable batch", "segment", rec.seq, "offset", rec.off, "error", err)
+		}
+
+		o.mu.Lock()
+		// Append may have dropped the batch meanwhile to make room
+		if len(o.records) > 0 && o.records[0] == rec {
+			err = o.advance(1)
+		} else {
+			err = nil
+		}
+		o.mu.Unlock()
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// Close closes the segment being appended to. Pending batches stay on disk.
+func (o *Outbox) Close() error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.tail.Close()
+}
+
+// segments returns the sequence numbers of the segment files, in order.
+func (o *Outbox) segments() ([]uint64, error) {
+	entries, err := os.ReadDir(o.opts.dir)
+	if err != nil {
+		return nil, err
+	}
+	var seqs []uint64
+	for _, e := range entries {
+		name, ok := strings.CutSuffix(e.Name(), segmentExt)
+		if !ok || e.IsDir() {
+			continue
+		}
+		seq, err := strconv.ParseUint(name, 16, 64)
+		if err != nil {
+			continue
+		}
+		seqs = append(seqs, seq)
+	}
+	sort.Slice(seqs, func(i, j int) bool { return seqs[i] < seqs[j] })
+	return seqs, nil
+}
+
+func (o *Outbox) segmentPath(seq uint64) string {
+	return filepath.Join(o.opts.dir, fmt.Sprintf("%016x%s", seq, segmentExt))
+}
+
+// load indexes the pending batches of a previous run, starting at the cursor.
+// A line torn by a crash mid-append ends its segment and is cut off.
+func (o *Outbox) load(seqs []uint64) (cursor, error) {
+	var cur cursor
+	data, err := os.ReadFile(filepath.Join(o.opts.dir, cursorFile))
+	switch {
+	case err == nil:
+		if err := json.Unmarshal(data, &cur); err != nil {
+			return cur, fmt.Errorf("outbox cursor: %w", err)
+		}
+	case !errors.Is(err, os.ErrNotExist):
+		return cur, err
+	}
+
+	for _, seq := range seqs {
+		if seq < cur.Segment {
+			if err := os.Remove(o.segmentPath(seq)); err != nil {
+				return cur, err
+			}
+			continue
+		}
+		start := int64(0)
+		if seq == cur.Segment {
+			start = cur.Offset
+		}
+		if err := o.loadSegment(seq, start); err != nil {
+			return cur, err
+		}
+	}
+	return cur, nil
+}
+
+func (o *Outbox) loadSegment(seq uint64, start int64) error {
+	f, err := os.Open(o.segmentPath(seq))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if _, err := f.Seek(start, io.SeekStart); err != nil {
+		return err
+	}
+	r := bufio.NewReader(f)
+	off := start
+	for {
+		line, err := r.ReadBytes('\n')
+		if len(line) == 0 && errors.Is(err, io.EOF) {
+			return nil
+		}
+		var e entry
+		if err != nil || json.Unmarshal(line, &e) != nil {
+			logger.Log.Warnw("Outbox: truncating torn segment", "segment", seq, "offset", off)
+			return os.Truncate(o.segmentPath(seq), off)
+		}
+		n := int64(len(line))
+		o.records = append(o.records, record{seq: seq, off: off, size: n, at: e.At})
+		o.size += n
+		off += n
+	}
+}
+
+// openTail opens the current tail segment for appending.
+func (o *Outbox) openTail() error {
+	f, err := os.OpenFile(o.segmentPath(o.tailSeq), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	o.tail = f
+	o.tailSize = info.Size()
+	return nil
+}
+
+func (o *Outbox) read(rec record) (*entry, error) {
+	f, err := os.Open(o.segmentPath(rec.seq))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	buf := make([]byte, rec.size)
+	if _, err := f.ReadAt(buf, rec.off); err != nil {
+		return nil, err
+	}
+	var e entry
+	if err := json.Unmarshal(buf, &e); err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+// dropExpired discards pending batches older than the maximum age. Callers hold mu.
+func (o *Outbox) dropExpired() error {
+	if o.opts.maxAge <= 0 {
+		return nil
+	}
+	deadline := o.opts.now().Add(-o.opts.maxAge)
+	n := 0
+	for n < len(o.records) && o.records[n].at.Before(deadline) {
+		n++
+	}
+	if n == 0 {
+		return nil
+	}
+	logger.Log.Warnw("Outbox: dropping expired batches", "batches", n)
+	return o.advance(n)
+}
+
+// advance removes the first n pending batches, persists the new cursor and
+// deletes the segments left behind it. Callers hold mu.
+func (o *Outbox) advance(n int) error {
+	for _, r := range o.records[:n] {
+		o.size -= r.size
+	}
+	o.records = o.records[n:]
+
+	cur := cursor{Segment: o.tailSeq, Offset: o.tailSize}
+	if len(o.records) > 0 {
+		cur = cursor{Segment: o.records[0].seq, Offset: o.records[0].off}
+	}
+	if err := o.writeCursor(cur); err != nil {
+		return err
+	}
+
+	seqs, err := o.segments()
+	if err != nil {
+		return err
+	}
+	for _, seq := range seqs {
+		if seq >= cur.Segment {
+			break
+		}
+		if err := os.Remove(o.segmentPath(seq)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeCursor replaces the cursor file atomically.
+func (o *Outbox) writeCursor(cur cursor) error {
+	data, err := json.Marshal(cur)
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(o.opts.dir, cursorFile)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
@@ -0,0 +1,202 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sbilibin2017/go-yandex-practicum/internal/types"
+)
+
+func batch(ids ...string) []*types.Metrics {
+	var out []*types.Metrics
+	for _, id := range ids {
+		delta := int64(1)
+		out = append(out, &types.Metrics{ID: id, Type: types.Counter, Delta: &delta})
+	}
+	return out
+}
+
+// drain replays the outbox and returns the IDs of the batches sent.
+func drain(t *testing.T, o *Outbox) []string {
+	t.Helper()
+	var sent []string
+	err := o.Replay(context.Background(), func(ctx context.Context, metrics []*types.Metrics) error {
+		sent = append(sent, metrics[0].ID)
+		return nil
+	})
+	require.NoError(t, err)
+	return sent
+}
+
+func segmentFiles(t *testing.T, dir string) []string {
+	t.Helper()
+	files, err := filepath.Glob(filepath.Join(dir, "*"+segmentExt))
+	require.NoError(t, err)
+	return files
+}
+
+func TestOutbox_AppendReplay(t *testing.T) {
+	dir := t.TempDir()
+	o, err := Open(WithDir(dir), WithSegmentBytes(100))
+	require.NoError(t, err)
+	defer o.Close()
+
+	for i := 0; i < 5; i++ {
+		require.NoError(t, o.Append(batch("b"+strconv.Itoa(i))))
+	}
+	assert.Equal(t, 5, o.Len())
+	assert.Greater(t, len(segmentFiles(t, dir)), 1, "segments are rotated")
+
+	// A failing send keeps the batch and everything after it
+	calls := 0
+	err = o.Replay(context.Background(), func(ctx context.Context, metrics []*types.Metrics) error {
+		calls++
+		if calls == 3 {
+			return errors.New("server down")
+		}
+		return nil
+	})
+	require.Error(t, err)
+	assert.Equal(t, 3, o.Len())
+
+	assert.Equal(t, []string{"b2", "b3", "b4"}, drain(t, o))
+	assert.Zero(t, o.Len())
+	assert.Len(t, segmentFiles(t, dir), 1, "delivered segments are removed")
+}
+
+func TestOutbox_Restore(t *testing.T) {
+	tests := []struct {
+		name    string
+		restore bool
+		want    []string
+	}{
+		{name: "restore", restore: true, want: []string{"b1", "b2"}},
+		{name: "discard", restore: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			o, err := Open(WithDir(dir), WithSegmentBytes(100))
+			require.NoError(t, err)
+			for i := 0; i < 3; i++ {
+				require.NoError(t, o.Append(batch("b"+strconv.Itoa(i))))
+			}
+			// Deliver the first batch only
+			delivered := false
+			o.Replay(context.Background(), func(ctx context.Context, metrics []*types.Metrics) error {
+				if delivered {
+					return errors.New("server down")
+				}
+				delivered = true
+				return nil
+			})
+			require.NoError(t, o.Close())
+
+			o, err = Open(WithDir(dir), WithSegmentBytes(100), WithRestore(tt.restore))
+			require.NoError(t, err)
+			defer o.Close()
+			assert.Equal(t, tt.want, drain(t, o))
+
+			// Appends after a restore go after the cursor
+			require.NoError(t, o.Append(batch("b3")))
+			require.NoError(t, o.Close())
+			o, err = Open(WithDir(dir), WithSegmentBytes(100), WithRestore(true))
+			require.NoError(t, err)
+			assert.Equal(t, []string{"b3"}, drain(t, o))
+		})
+	}
+}
+
+func TestOutbox_TornLine(t *testing.T) {
+	dir := t.TempDir()
+	o, err := Open(WithDir(dir))
+	require.NoError(t, err)
+	require.NoError(t, o.Append(batch("b0")))
+	require.NoError(t, o.Close())
+
+	// Simulate a crash in the middle of an append
+	f, err := os.OpenFile(segmentFiles(t, dir)[0], os.O_WRONLY|os.O_APPEND, 0)
+	require.NoError(t, err)
+	_, err = f.WriteString(`{"at":"2026-01-01T00:00:00Z","metr`)
+	require.NoError(t, err)
+	f.Close()
+
+	o, err = Open(WithDir(dir), WithRestore(true))
+	require.NoError(t, err)
+	require.NoError(t, o.Append(batch("b1")))
+	assert.Equal(t, []string{"b0", "b1"}, drain(t, o))
+	o.Close()
+}
+
+func TestOutbox_Limits(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+
+	// Every batch encodes to the same size
+	line := int64(len(`{"at":"2026-01-01T12:00:00Z","metrics":[{"id":"b0","type":"counter","delta":1}]}`) + 1)
+
+	tests := []struct {
+		name    string
+		opts    []Opt
+		advance time.Duration // clock advance after the first two batches
+		wantErr error
+		want    []string
+	}{
+		{
+			name: "drop oldest",
+			opts: []Opt{WithMaxBytes(2 * line), WithDropPolicy(DropOldest)},
+			want: []string{"b1", "b2"},
+		},
+		{
+			name:    "drop newest",
+			opts:    []Opt{WithMaxBytes(2 * line), WithDropPolicy(DropNewest)},
+			wantErr: ErrFull,
+			want:    []string{"b0", "b1"},
+		},
+		{
+			name:    "max age",
+			opts:    []Opt{WithMaxAge(time.Minute)},
+			advance: 2 * time.Minute,
+			want:    []string{"b2"},
+		},
+		{
+			name: "unlimited",
+			want: []string{"b0", "b1", "b2"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now = time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+			o, err := Open(append([]Opt{WithDir(t.TempDir()), WithClock(clock)}, tt.opts...)...)
+			require.NoError(t, err)
+			defer o.Close()
+
+			require.NoError(t, o.Append(batch("b0")))
+			require.NoError(t, o.Append(batch("b1")))
+			now = now.Add(tt.advance)
+			err = o.Append(batch("b2"))
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, tt.want, drain(t, o))
+		})
+	}
+}
+
+func TestOpen_Errors(t *testing.T) {
+	_, err := Open()
+	assert.Error(t, err)
+
+	_, err = Open(WithDir(t.TempDir()), WithDropPolicy("random"))
+	assert.Error(t, err)
+}
@@ -2,6 +2,7 @@ package repositories
 
 import (
 	"context"
+	"time"
 
 	"github.com/sbilibin2017/go-yandex-practicum/internal/types"
 )
@@ -80,3 +81,147 @@ func (c *MetricContextListRepository) SetContext(strategy MetricLister) {
 func (c *MetricContextListRepository) List(ctx context.Context) ([]*types.Metrics, error) {
 	return c.strategy.List(ctx)
 }
+
+// MetricBatchGetter defines the interface for retrieving several metrics at once.
+type MetricBatchGetter interface {
+	GetBatch(ctx context.Context, ids []types.MetricID) ([]*types.Metrics, error)
+}
+
+// MetricContextGetBatchRepository uses a strategy pattern to get several metrics at once.
+type MetricContextGetBatchRepository struct {
+	strategy MetricBatchGetter
+}
+
+// NewMetricContextGetBatchRepository creates a new MetricContextGetBatchRepository.
+func NewMetricContextGetBatchRepository() *MetricContextGetBatchRepository {
+	return &MetricContextGetBatchRepository{}
+}
+
+// SetContext sets the batch retrieval strategy for the repository.
+func (c *MetricContextGetBatchRepository) SetContext(strategy MetricBatchGetter) {
+	c.strategy = strategy
+}
+
+// GetBatch retrieves several metrics using the current strategy.
+func (c *MetricContextGetBatchRepository) GetBatch(ctx context.Context, ids []types.MetricID) ([]*types.Metrics, error) {
+	return c.strategy.GetBatch(ctx, ids)
+}
+
+// MetricDeleter defines the interface for deleting metrics.
+type MetricDeleter interface {
+	Delete(ctx context.Context, id types.MetricID) (bool, error)
+	DeleteByPrefix(ctx context.Context, prefix string) ([]types.MetricID, error)
+}
+
+// MetricContextDeleteRepository uses a strategy pattern to delete metrics.
+type MetricContextDeleteRepository struct {
+	strategy MetricDeleter
+}
+
+// NewMetricContextDeleteRepository creates a new MetricContextDeleteRepository.
+func NewMetricContextDeleteRepository() *MetricContextDeleteRepository {
+	return &MetricContextDeleteRepository{}
+}
+
+// SetContext sets the deletion strategy for the repository.
+func (c *MetricContextDeleteRepository) SetContext(strategy MetricDeleter) {
+	c.strategy = strategy
+}
+
+// Delete deletes a metric using the current strategy.
+func (c *MetricContextDeleteRepository) Delete(ctx context.Context, id types.MetricID) (bool, error) {
+	return c.strategy.Delete(ctx, id)
+}
+
+// DeleteByPrefix deletes all metrics with the given ID prefix using the current strategy.
+func (c *MetricContextDeleteRepository) DeleteByPrefix(ctx context.Context, prefix string) ([]types.MetricID, error) {
+	return c.strategy.DeleteByPrefix(ctx, prefix)
+}
+
+// MetricResetter defines the interface for resetting counters.
+type MetricResetter interface {
+	Reset(ctx context.Context, id types.MetricID) (*types.Metrics, error)
+}
+
+// MetricContextResetRepository uses a strategy pattern to reset counters.
+type MetricContextResetRepository struct {
+	strategy MetricResetter
+}
+
+// NewMetricContextResetRepository creates a new MetricContextResetRepository.
+func NewMetricContextResetRepository() *MetricContextResetRepository {
+	return &MetricContextResetRepository{}
+}
+
+// SetContext sets the reset strategy for the repository.
+func (c *MetricContextResetRepository) SetContext(strategy MetricResetter) {
+	c.strategy = strategy
+}
+
+// Reset resets a counter using the current strategy.
+func (c *MetricContextResetRepository) Reset(ctx context.Context, id types.MetricID) (*types.Metrics, error) {
+	return c.strategy.Reset(ctx, id)
+}
+
+// MetricExpirer defines the interface for expiring metrics that were not updated since a cutoff.
+// Each method checks the metric and changes it atomically.
+type MetricExpirer interface {
+	Stamp(ctx context.Context, id types.MetricID, at time.Time) (bool, error)
+	MarkStale(ctx context.Context, id types.MetricID, cutoff time.Time) (*types.Metrics, error)
+	Expire(ctx context.Context, id types.MetricID, cutoff time.Time) (bool, error)
+}
+
+// MetricContextExpireRepository uses a strategy pattern to expire metrics.
+type MetricContextExpireRepository struct {
+	strategy MetricExpirer
+}
+
+// NewMetricContextExpireRepository creates a new MetricContextExpireRepository.
+func NewMetricContextExpireRepository() *MetricContextExpireRepository {
+	return &MetricContextExpireRepository{}
+}
+
+// SetContext sets the expire strategy for the repository.
+func (c *MetricContextExpireRepository) SetContext(strategy MetricExpirer) {
+	c.strategy = strategy
+}
+
+// Stamp sets a missing update time using the current strategy.
+func (c *MetricContextExpireRepository) Stamp(ctx context.Context, id types.MetricID, at time.Time) (bool, error) {
+	return c.strategy.Stamp(ctx, id, at)
+}
+
+// MarkStale marks a metric stale using the current strategy.
+func (c *MetricContextExpireRepository) MarkStale(ctx context.Context, id types.MetricID, cutoff time.Time) (*types.Metrics, error) {
+	return c.strategy.MarkStale(ctx, id, cutoff)
+}
+
+// Expire deletes a stale metric using the current strategy.
+func (c *MetricContextExpireRepository) Expire(ctx context.Context, id types.MetricID, cutoff time.Time) (bool, error) {
+	return c.strategy.Expire(ctx, id, cutoff)
+}
+
+// MetricApplier defines the interface for applying update operations to metrics.
+type MetricApplier interface {
+	Apply(ctx context.Context, metric types.Metrics) (*types.Metrics, error)
+}
+
+// MetricContextApplyRepository uses a strategy pattern to apply update operations.
+type MetricContextApplyRepository struct {
+	strategy MetricApplier
+}
+
+// NewMetricContextApplyRepository creates a new MetricContextApplyRepository.
+func NewMetricContextApplyRepository() *MetricContextApplyRepository {
+	return &MetricContextApplyRepository{}
+}
+
+// SetContext sets the apply strategy for the repository.
+func (c *MetricContextApplyRepository) SetContext(strategy MetricApplier) {
+	c.strategy = strategy
+}
+
+// Apply applies an update operation using the current strategy.
+func (c *MetricContextApplyRepository) Apply(ctx context.Context, metric types.Metrics) (*types.Metrics, error) {
+	return c.strategy.Apply(ctx, metric)
+}
@@ -7,6 +7,7 @@ package repositories
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 	types "github.com/sbilibin2017/go-yandex-practicum/internal/types"
@@ -124,3 +125,238 @@ func (mr *MockMetricListerMockRecorder) List(ctx interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockMetricLister)(nil).List), ctx)
 }
+
+// MockMetricBatchGetter is a mock of MetricBatchGetter interface.
+type MockMetricBatchGetter struct {
+	ctrl     *gomock.Controller
+	recorder *MockMetricBatchGetterMockRecorder
+}
+
+// MockMetricBatchGetterMockRecorder is the mock recorder for MockMetricBatchGetter.
+type MockMetricBatchGetterMockRecorder struct {
+	mock *MockMetricBatchGetter
+}
+
+// NewMockMetricBatchGetter creates a new mock instance.
+func NewMockMetricBatchGetter(ctrl *gomock.Controller) *MockMetricBatchGetter {
+	mock := &MockMetricBatchGetter{ctrl: ctrl}
+	mock.recorder = &MockMetricBatchGetterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMetricBatchGetter) EXPECT() *MockMetricBatchGetterMockRecorder {
+	return m.recorder
+}
+
+// GetBatch mocks base method.
+func (m *MockMetricBatchGetter) GetBatch(ctx context.Context, ids []types.MetricID) ([]*types.Metrics, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBatch", ctx, ids)
+	ret0, _ := ret[0].([]*types.Metrics)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBatch indicates an expected call of GetBatch.
+func (mr *MockMetricBatchGetterMockRecorder) GetBatch(ctx, ids interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBatch", reflect.TypeOf((*MockMetricBatchGetter)(nil).GetBatch), ctx, ids)
+}
+
+// MockMetricDeleter is a mock of MetricDeleter interface.
+type MockMetricDeleter struct {
+	ctrl     *gomock.Controller
+	recorder *MockMetricDeleterMockRecorder
+}
+
+// MockMetricDeleterMockRecorder is the mock recorder for MockMetricDeleter.
+type MockMetricDeleterMockRecorder struct {
+	mock *MockMetricDeleter
+}
+
+// NewMockMetricDeleter creates a new mock instance.
+func NewMockMetricDeleter(ctrl *gomock.Controller) *MockMetricDeleter {
+	mock := &MockMetricDeleter{ctrl: ctrl}
+	mock.recorder = &MockMetricDeleterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMetricDeleter) EXPECT() *MockMetricDeleterMockRecorder {
+	return m.recorder
+}
+
+// Delete mocks base method.
+func (m *MockMetricDeleter) Delete(ctx context.Context, id types.MetricID) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, id)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockMetricDeleterMockRecorder) Delete(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockMetricDeleter)(nil).Delete), ctx, id)
+}
+
+// DeleteByPrefix mocks base method.
+func (m *MockMetricDeleter) DeleteByPrefix(ctx context.Context, prefix string) ([]types.MetricID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteByPrefix", ctx, prefix)
+	ret0, _ := ret[0].([]types.MetricID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteByPrefix indicates an expected call of DeleteByPrefix.
+func (mr *MockMetricDeleterMockRecorder) DeleteByPrefix(ctx, prefix interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteByPrefix", reflect.TypeOf((*MockMetricDeleter)(nil).DeleteByPrefix), ctx, prefix)
+}
+
+// MockMetricResetter is a mock of MetricResetter interface.
+type MockMetricResetter struct {
+	ctrl     *gomock.Controller
+	recorder *MockMetricResetterMockRecorder
+}
+
+// MockMetricResetterMockRecorder is the mock recorder for MockMetricResetter.
+type MockMetricResetterMockRecorder struct {
+	mock *MockMetricResetter
+}
+
+// NewMockMetricResetter creates a new mock instance.
+func NewMockMetricResetter(ctrl *gomock.Controller) *MockMetricResetter {
+	mock := &MockMetricResetter{ctrl: ctrl}
+	mock.recorder = &MockMetricResetterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMetricResetter) EXPECT() *MockMetricResetterMockRecorder {
+	return m.recorder
+}
+
+// Reset mocks base method.
+func (m *MockMetricResetter) Reset(ctx context.Context, id types.MetricID) (*types.Metrics, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reset", ctx, id)
+	ret0, _ := ret[0].(*types.Metrics)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Reset indicates an expected call of Reset.
+func (mr *MockMetricResetterMockRecorder) Reset(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reset", reflect.TypeOf((*MockMetricResetter)(nil).Reset), ctx, id)
+}
+
+// MockMetricExpirer is a mock of MetricExpirer interface.
+type MockMetricExpirer struct {
+	ctrl     *gomock.Controller
+	recorder *MockMetricExpirerMockRecorder
+}
+
+// MockMetricExpirerMockRecorder is the mock recorder for MockMetricExpirer.
+type MockMetricExpirerMockRecorder struct {
+	mock *MockMetricExpirer
+}
+
+// NewMockMetricExpirer creates a new mock instance.
+func NewMockMetricExpirer(ctrl *gomock.Controller) *MockMetricExpirer {
+	mock := &MockMetricExpirer{ctrl: ctrl}
+	mock.recorder = &MockMetricExpirerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMetricExpirer) EXPECT() *MockMetricExpirerMockRecorder {
+	return m.recorder
+}
+
+// Expire mocks base method.
+func (m *MockMetricExpirer) Expire(ctx context.Context, id types.MetricID, cutoff time.Time) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Expire", ctx, id, cutoff)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Expire indicates an expected call of Expire.
+func (mr *MockMetricExpirerMockRecorder) Expire(ctx, id, cutoff interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Expire", reflect.TypeOf((*MockMetricExpirer)(nil).Expire), ctx, id, cutoff)
+}
+
+// MarkStale mocks base method.
+func (m *MockMetricExpirer) MarkStale(ctx context.Context, id types.MetricID, cutoff time.Time) (*types.Metrics, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkStale", ctx, id, cutoff)
+	ret0, _ := ret[0].(*types.Metrics)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MarkStale indicates an expected call of MarkStale.
+func (mr *MockMetricExpirerMockRecorder) MarkStale(ctx, id, cutoff interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkStale", reflect.TypeOf((*MockMetricExpirer)(nil).MarkStale), ctx, id, cutoff)
+}
+
+// Stamp mocks base method.
+func (m *MockMetricExpirer) Stamp(ctx context.Context, id types.MetricID, at time.Time) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Stamp", ctx, id, at)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Stamp indicates an expected call of Stamp.
+func (mr *MockMetricExpirerMockRecorder) Stamp(ctx, id, at interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stamp", reflect.TypeOf((*MockMetricExpirer)(nil).Stamp), ctx, id, at)
+}
+
+// MockMetricApplier is a mock of MetricApplier interface.
+type MockMetricApplier struct {
+	ctrl     *gomock.Controller
+	recorder *MockMetricApplierMockRecorder
+}
+
+// MockMetricApplierMockRecorder is the mock recorder for MockMetricApplier.
+type MockMetricApplierMockRecorder struct {
+	mock *MockMetricApplier
+}
+
+// NewMockMetricApplier creates a new mock instance.
+func NewMockMetricApplier(ctrl *gomock.Controller) *MockMetricApplier {
+	mock := &MockMetricApplier{ctrl: ctrl}
+	mock.recorder = &MockMetricApplierMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMetricApplier) EXPECT() *MockMetricApplierMockRecorder {
+	return m.recorder
+}
+
+// Apply mocks base method.
+func (m *MockMetricApplier) Apply(ctx context.Context, metric types.Metrics) (*types.Metrics, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Apply", ctx, metric)
+	ret0, _ := ret[0].(*types.Metrics)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Apply indicates an expected call of Apply.
+func (mr *MockMetricApplierMockRecorder) Apply(ctx, metric interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Apply", reflect.TypeOf((*MockMetricApplier)(nil).Apply), ctx, metric)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/sbilibin2017/go-yandex-practicum/internal/repositories"
@@ -170,3 +171,293 @@ func TestMetricContextListRepository_List(t *testing.T) {
 		})
 	}
 }
+
+func TestMetricContextGetBatchRepository_GetBatch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	ids := []types.MetricID{
+		{ID: "id1", Type: "gauge"},
+		{ID: "id2", Type: "counter"},
+	}
+	metricsList := []*types.Metrics{
+		{ID: "id1", Type: "gauge"},
+	}
+
+	tests := []struct {
+		name      string
+		mockSetup func(m *repositories.MockMetricBatchGetter)
+		wantList  []*types.Metrics
+		wantErr   bool
+	}{
+		{
+			name: "success get batch",
+			mockSetup: func(m *repositories.MockMetricBatchGetter) {
+				m.EXPECT().GetBatch(ctx, ids).Return(metricsList, nil)
+			},
+			wantList: metricsList,
+			wantErr:  false,
+		},
+		{
+			name: "error on get batch",
+			mockSetup: func(m *repositories.MockMetricBatchGetter) {
+				m.EXPECT().GetBatch(ctx, ids).Return(nil, errors.New("get batch error"))
+			},
+			wantList: nil,
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockBatchGetter := repositories.NewMockMetricBatchGetter(ctrl)
+			repo := repositories.NewMetricContextGetBatchRepository()
+			repo.SetContext(mockBatchGetter)
+
+			tt.mockSetup(mockBatchGetter)
+
+			list, err := repo.GetBatch(ctx, ids)
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Nil(t, list)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantList, list)
+			}
+		})
+	}
+}
+
+func TestMetricContextDeleteRepository_Delete(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	id := types.MetricID{ID: "id1", Type: "gauge"}
+
+	tests := []struct {
+		name        string
+		mockSetup   func(m *repositories.MockMetricDeleter)
+		wantDeleted bool
+		wantErr     bool
+	}{
+		{
+			name: "success delete",
+			mockSetup: func(m *repositories.MockMetricDeleter) {
+				m.EXPECT().Delete(ctx, id).Return(true, nil)
+			},
+			wantDeleted: true,
+			wantErr:     false,
+		},
+		{
+			name: "error on delete",
+			mockSetup: func(m *repositories.MockMetricDeleter) {
+				m.EXPECT().Delete(ctx, id).Return(false, errors.New("delete error"))
+			},
+			wantDeleted: false,
+			wantErr:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDeleter := repositories.NewMockMetricDeleter(ctrl)
+			repo := repositories.NewMetricContextDeleteRepository()
+			repo.SetContext(mockDeleter)
+
+			tt.mockSetup(mockDeleter)
+
+			deleted, err := repo.Delete(ctx, id)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.wantDeleted, deleted)
+		})
+	}
+}
+
+func TestMetricContextDeleteRepository_DeleteByPrefix(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	ids := []types.MetricID{{ID: "CPUutilization1", Type: "gauge"}}
+
+	tests := []struct {
+		name      string
+		mockSetup func(m *repositories.MockMetricDeleter)
+		wantIDs   []types.MetricID
+		wantErr   bool
+	}{
+		{
+			name: "success delete by prefix",
+			mockSetup: func(m *repositories.MockMetricDeleter) {
+				m.EXPECT().DeleteByPrefix(ctx, "CPU").Return(ids, nil)
+			},
+			wantIDs: ids,
+			wantErr: false,
+		},
+		{
+			name: "error on delete by prefix",
+			mockSetup: func(m *repositories.MockMetricDeleter) {
+				m.EXPECT().DeleteByPrefix(ctx, "CPU").Return(nil, errors.New("delete error"))
+			},
+			wantIDs: nil,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDeleter := repositories.NewMockMetricDeleter(ctrl)
+			repo := repositories.NewMetricContextDeleteRepository()
+			repo.SetContext(mockDeleter)
+
+			tt.mockSetup(mockDeleter)
+
+			got, err := repo.DeleteByPrefix(ctx, "CPU")
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.wantIDs, got)
+		})
+	}
+}
+
+func TestMetricContextResetRepository_Reset(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	id := types.MetricID{ID: "PollCount", Type: "counter"}
+	var zero int64
+	counter := &types.Metrics{ID: "PollCount", Type: "counter", Delta: &zero, Version: 2}
+
+	tests := []struct {
+		name      string
+		mockSetup func(m *repositories.MockMetricResetter)
+		wantReset *types.Metrics
+		wantErr   bool
+	}{
+		{
+			name: "success reset",
+			mockSetup: func(m *repositories.MockMetricResetter) {
+				m.EXPECT().Reset(ctx, id).Return(counter, nil)
+			},
+			wantReset: counter,
+			wantErr:   false,
+		},
+		{
+			name: "error on reset",
+			mockSetup: func(m *repositories.MockMetricResetter) {
+				m.EXPECT().Reset(ctx, id).Return(nil, errors.New("reset error"))
+			},
+			wantReset: nil,
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockResetter := repositories.NewMockMetricResetter(ctrl)
+			repo := repositories.NewMetricContextResetRepository()
+			repo.SetContext(mockResetter)
+
+			tt.mockSetup(mockResetter)
+
+			reset, err := repo.Reset(ctx, id)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.wantReset, reset)
+		})
+	}
+}
+
+func TestMetricContextApplyRepository_Apply(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	value := 1.5
+	metric := types.Metrics{ID: "id1", Type: "gauge", Value: &value, Op: types.OpMax}
+
+	tests := []struct {
+		name      string
+		mockSetup func(m *repositories.MockMetricApplier)
+		want      *types.Metrics
+		wantErr   bool
+	}{
+		{
+			name: "success apply",
+			mockSetup: func(m *repositories.MockMetricApplier) {
+				m.EXPECT().Apply(ctx, metric).Return(&types.Metrics{ID: "id1", Type: "gauge", Value: &value}, nil)
+			},
+			want:    &types.Metrics{ID: "id1", Type: "gauge", Value: &value},
+			wantErr: false,
+		},
+		{
+			name: "error on apply",
+			mockSetup: func(m *repositories.MockMetricApplier) {
+				m.EXPECT().Apply(ctx, metric).Return(nil, errors.New("apply error"))
+			},
+			want:    nil,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockApplier := repositories.NewMockMetricApplier(ctrl)
+			repo := repositories.NewMetricContextApplyRepository()
+			repo.SetContext(mockApplier)
+
+			tt.mockSetup(mockApplier)
+
+			got, err := repo.Apply(ctx, metric)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestMetricContextExpireRepository(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	id := types.MetricID{ID: "Alloc", Type: "gauge"}
+	cutoff := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	marked := &types.Metrics{ID: "Alloc", Type: "gauge", Stale: true}
+
+	mockExpirer := repositories.NewMockMetricExpirer(ctrl)
+	mockExpirer.EXPECT().Stamp(ctx, id, cutoff).Return(true, nil)
+	mockExpirer.EXPECT().MarkStale(ctx, id, cutoff).Return(marked, nil)
+	mockExpirer.EXPECT().Expire(ctx, id, cutoff).Return(false, errors.New("expire error"))
+
+	repo := repositories.NewMetricContextExpireRepository()
+	repo.SetContext(mockExpirer)
+
+	stamped, err := repo.Stamp(ctx, id, cutoff)
+	assert.NoError(t, err)
+	assert.True(t, stamped)
+
+	got, err := repo.MarkStale(ctx, id, cutoff)
+	assert.NoError(t, err)
+	assert.Equal(t, marked, got)
+
+	expired, err := repo.Expire(ctx, id, cutoff)
+	assert.Error(t, err)
+	assert.False(t, expired)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"time"
 
 	"github.com/jmoiron/sqlx"
 	"github.com/sbilibin2017/go-yandex-practicum/internal/types"
@@ -59,16 +60,22 @@ func (r *MetricDBSaveRepository) Save(ctx context.Context, metric types.Metrics)
 		metric.Type,
 		metric.Delta,
 		metric.Value,
+		metric.UpdatedAt,
+		metric.Stale,
+		metric.Version,
 	)
 	return err
 }
 
 const metricSaveQuery = `
-INSERT INTO content.metrics (id, type, delta, value)
-VALUES ($1, $2, $3, $4)
+INSERT INTO content.metrics (id, type, delta, value, updated_at, stale, version)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
 ON CONFLICT (id, type) DO UPDATE
     SET delta = EXCLUDED.delta,
-        value = EXCLUDED.value;
+        value = EXCLUDED.value,
+        updated_at = EXCLUDED.updated_at,
+        stale = EXCLUDED.stale,
+        version = EXCLUDED.version;
 `
 
 // --- MetricDBGetRepository ---
@@ -123,7 +130,7 @@ func (r *MetricDBGetRepository) Get(ctx context.Context, id types.MetricID) (*ty
 }
 
 const metricGetQuery = `
-SELECT id, type, delta, value
+SELECT id, type, delta, value, updated_at, stale, version
 FROM content.metrics
 WHERE id = $1 AND type = $2;
 `
@@ -177,7 +184,418 @@ func (r *MetricDBListRepository) List(ctx context.Context) ([]*types.Metrics, er
 }
 
 const metricListQuery = `
-SELECT id, type, delta, value
+SELECT id, type, delta, value, updated_at, stale, version
 FROM content.metrics
 ORDER BY id;
 `
+
+// --- MetricDBGetBatchRepository ---
+
+type MetricDBGetBatchRepository struct {
+	db       *sqlx.DB
+	TxGetter TxGetterFunc
+}
+
+type MetricDBGetBatchRepositoryOption func(*MetricDBGetBatchRepository)
+
+func WithMetricDBGetBatchRepositoryDB(db *sqlx.DB) MetricDBGetBatchRepositoryOption {
+	return func(repo *MetricDBGetBatchRepository) {
+		repo.db = db
+	}
+}
+
+func WithMetricDBGetBatchRepositoryTxGetter(getter TxGetterFunc) MetricDBGetBatchRepositoryOption {
+	return func(repo *MetricDBGetBatchRepository) {
+		repo.TxGetter = getter
+	}
+}
+
+func NewMetricDBGetBatchRepository(opts ...MetricDBGetBatchRepositoryOption) *MetricDBGetBatchRepository {
+	repo := &MetricDBGetBatchRepository{}
+	for _, opt := range opts {
+		opt(repo)
+	}
+	return repo
+}
+
+func (r *MetricDBGetBatchRepository) GetBatch(ctx context.Context, ids []types.MetricID) ([]*types.Metrics, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	var querier sqlx.ExtContext
+	if r.TxGetter != nil {
+		if tx, ok := r.TxGetter(ctx); ok && tx != nil {
+			querier = tx
+		}
+	}
+	if querier == nil {
+		querier = r.db
+	}
+
+	metricIDs := make([]string, 0, len(ids))
+	metricTypes := make([]string, 0, len(ids))
+	for _, id := range ids {
+		metricIDs = append(metricIDs, id.ID)
+		metricTypes = append(metricTypes, id.Type)
+	}
+
+	var metrics []*types.Metrics
+	err := sqlx.SelectContext(ctx, querier, &metrics, metricGetBatchQuery, metricIDs, metricTypes)
+	if err != nil {
+		return nil, err
+	}
+	return metrics, nil
+}
+
+const metricGetBatchQuery = `
+SELECT m.id, m.type, m.delta, m.value, m.updated_at, m.stale, m.version
+FROM content.metrics m
+JOIN unnest($1::varchar[], $2::varchar[]) AS ids(id, type)
+    ON m.id = ids.id AND m.type = ids.type
+ORDER BY m.id;
+`
+
+// --- MetricDBDeleteRepository ---
+
+type MetricDBDeleteRepository struct {
+	db       *sqlx.DB
+	TxGetter TxGetterFunc
+}
+
+type MetricDBDeleteRepositoryOption func(*MetricDBDeleteRepository)
+
+func WithMetricDBDeleteRepositoryDB(db *sqlx.DB) MetricDBDeleteRepositoryOption {
+	return func(repo *MetricDBDeleteRepository) {
+		repo.db = db
+	}
+}
+
+func WithMetricDBDeleteRepositoryTxGetter(getter TxGetterFunc) MetricDBDeleteRepositoryOption {
+	return func(repo *MetricDBDeleteRepository) {
+		repo.TxGetter = getter
+	}
+}
+
+func NewMetricDBDeleteRepository(opts ...MetricDBDeleteRepositoryOption) *MetricDBDeleteRepository {
+	repo := &MetricDBDeleteRepository{}
+	for _, opt := range opts {
+		opt(repo)
+	}
+	return repo
+}
+
+func (r *MetricDBDeleteRepository) Delete(ctx context.Context, id types.MetricID) (bool, error) {
+	var execer sqlx.ExtContext
+	if r.TxGetter != nil {
+		if tx, ok := r.TxGetter(ctx); ok && tx != nil {
+			execer = tx
+		}
+	}
+	if execer == nil {
+		execer = r.db
+	}
+
+	res, err := execer.ExecContext(ctx, metricDeleteQuery, id.ID, id.Type)
+	if err != nil {
+		return false, err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+func (r *MetricDBDeleteRepository) DeleteByPrefix(ctx context.Context, prefix string) ([]types.MetricID, error) {
+	var querier sqlx.ExtContext
+	if r.TxGetter != nil {
+		if tx, ok := r.TxGetter(ctx); ok && tx != nil {
+			querier = tx
+		}
+	}
+	if querier == nil {
+		querier = r.db
+	}
+
+	var deleted []types.MetricID
+	err := sqlx.SelectContext(ctx, querier, &deleted, metricDeleteByPrefixQuery, prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	sortMetricIDs(deleted)
+
+	return deleted, nil
+}
+
+const metricDeleteQuery = `
+DELETE FROM content.metrics
+WHERE id = $1 AND type = $2;
+`
+
+const metricDeleteByPrefixQuery = `
+DELETE FROM content.metrics
+WHERE starts_with(id, $1)
+RETURNING id, type;
+`
+
+// --- MetricDBResetRepository ---
+
+type MetricDBResetRepository struct {
+	db       *sqlx.DB
+	TxGetter TxGetterFunc
+}
+
+type MetricDBResetRepositoryOption func(*MetricDBResetRepository)
+
+func WithMetricDBResetRepositoryDB(db *sqlx.DB) MetricDBResetRepositoryOption {
+	return func(repo *MetricDBResetRepository) {
+		repo.db = db
+	}
+}
+
+func WithMetricDBResetRepositoryTxGetter(getter TxGetterFunc) MetricDBResetRepositoryOption {
+	return func(repo *MetricDBResetRepository) {
+		repo.TxGetter = getter
+	}
+}
+
+func NewMetricDBResetRepository(opts ...MetricDBResetRepositoryOption) *MetricDBResetRepository {
+	repo := &MetricDBResetRepository{}
+	for _, opt := range opts {
+		opt(repo)
+	}
+	return repo
+}
+
+func (r *MetricDBResetRepository) Reset(ctx context.Context, id types.MetricID) (*types.Metrics, error) {
+	if id.Type != types.Counter {
+		return nil, nil
+	}
+
+	var execer sqlx.ExtContext
+	if r.TxGetter != nil {
+		if tx, ok := r.TxGetter(ctx); ok && tx != nil {
+			execer = tx
+		}
+	}
+	if execer == nil {
+		execer = r.db
+	}
+
+	var metric types.Metrics
+	err := sqlx.GetContext(ctx, execer, &metric, metricResetQuery, id.ID, id.Type, nextMetricVersion(nil))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &metric, nil
+}
+
+const metricResetQuery = `
+UPDATE content.metrics
+SET delta = 0,
+    version = GREATEST(version + 1, $3)
+WHERE id = $1 AND type = $2
+RETURNING id, type, delta, value, updated_at, stale, version;
+`
+
+// --- MetricDBApplyRepository ---
+
+type MetricDBApplyRepository struct {
+	db       *sqlx.DB
+	TxGetter TxGetterFunc
+}
+
+type MetricDBApplyRepositoryOption func(*MetricDBApplyRepository)
+
+func WithMetricDBApplyRepositoryDB(db *sqlx.DB) MetricDBApplyRepositoryOption {
+	return func(repo *MetricDBApplyRepository) {
+		repo.db = db
+	}
+}
+
+func WithMetricDBApplyRepositoryTxGetter(getter TxGetterFunc) MetricDBApplyRepositoryOption {
+	return func(repo *MetricDBApplyRepository) {
+		repo.TxGetter = getter
+	}
+}
+
+func NewMetricDBApplyRepository(opts ...MetricDBApplyRepositoryOption) *MetricDBApplyRepository {
+	repo := &MetricDBApplyRepository{}
+	for _, opt := range opts {
+		opt(repo)
+	}
+	return repo
+}
+
+// Apply executes the update operation in a single upsert statement, so concurrent
+// updates of the same metric never lose writes. A conditional update whose expected
+// version does not match affects no row and yields types.ErrVersionConflict.
+func (r *MetricDBApplyRepository) Apply(ctx context.Context, metric types.Metrics) (*types.Metrics, error) {
+	var querier sqlx.QueryerContext
+	if r.TxGetter != nil {
+		if tx, ok := r.TxGetter(ctx); ok && tx != nil {
+			querier = tx
+		}
+	}
+	if querier == nil {
+		querier = r.db
+	}
+
+	// The inserted row is what the operation yields on a missing metric,
+	// which for "sub" is the negated value.
+	initial := applyMetricOp(nil, metric)
+
+	var result types.Metrics
+	err := sqlx.GetContext(ctx, querier, &result, metricApplyQuery,
+		metric.ID,
+		metric.Type,
+		initial.Delta,
+		initial.Value,
+		metric.UpdatedAt,
+		metric.Stale,
+		metric.Op,
+		metric.ExpectedVersion,
+		initial.Version,
+	)
+	if err != nil {
+		if metric.ExpectedVersion != nil && errors.Is(err, sql.ErrNoRows) {
+			return nil, types.ErrVersionConflict
+		}
+		return nil, err
+	}
+	return &result, nil
+}
+
+const metricApplyQuery = `
+WITH cur AS (
+    SELECT version FROM content.metrics
+    WHERE id = $1 AND type = $2
+    FOR UPDATE
+)
+INSERT INTO content.metrics AS m (id, type, delta, value, updated_at, stale, version)
+SELECT $1, $2, $3, $4, $5, $6, $9
+WHERE $8::bigint IS NULL
+   OR $8::bigint = COALESCE((SELECT version FROM cur), 0)
+ON CONFLICT (id, type) DO UPDATE
+    SET delta = CASE
+            WHEN $7::text = 'set' THEN EXCLUDED.delta
+            ELSE COALESCE(m.delta, 0) + EXCLUDED.delta
+        END,
+        value = CASE $7::text
+            WHEN 'add' THEN COALESCE(m.value, 0) + EXCLUDED.value
+            WHEN 'sub' THEN COALESCE(m.value, 0) + EXCLUDED.value
+            WHEN 'min' THEN LEAST(m.value, EXCLUDED.value)
+            WHEN 'max' THEN GREATEST(m.value, EXCLUDED.value)
+            ELSE EXCLUDED.value
+        END,
+        updated_at = EXCLUDED.updated_at,
+        stale = EXCLUDED.stale,
+        version = GREATEST(m.version + 1, $9)
+    WHERE $8::bigint IS NULL OR m.version = $8::bigint
+RETURNING id, type, delta, value, updated_at, stale, version;
+`
+
+// --- MetricDBExpireRepository ---
+
+type MetricDBExpireRepository struct {
+	db       *sqlx.DB
+	TxGetter TxGetterFunc
+}
+
+type MetricDBExpireRepositoryOption func(*MetricDBExpireRepository)
+
+func WithMetricDBExpireRepositoryDB(db *sqlx.DB) MetricDBExpireRepositoryOption {
+	return func(repo *MetricDBExpireRepository) {
+		repo.db = db
+	}
+}
+
+func WithMetricDBExpireRepositoryTxGetter(getter TxGetterFunc) MetricDBExpireRepositoryOption {
+	return func(repo *MetricDBExpireRepository) {
+		repo.TxGetter = getter
+	}
+}
+
+func NewMetricDBExpireRepository(opts ...MetricDBExpireRepositoryOption) *MetricDBExpireRepository {
+	repo := &MetricDBExpireRepository{}
+	for _, opt := range opts {
+		opt(repo)
+	}
+	return repo
+}
+
+func (r *MetricDBExpireRepository) execer(ctx context.Context) sqlx.ExtContext {
+	if r.TxGetter != nil {
+		if tx, ok := r.TxGetter(ctx); ok && tx != nil {
+			return tx
+		}
+	}
+	return r.db
+}
+
+// Stamp sets the update time of a metric that has none. It reports whether the metric was stamped.
+func (r *MetricDBExpireRepository) Stamp(ctx context.Context, id types.MetricID, at time.Time) (bool, error) {
+	res, err := r.execer(ctx).ExecContext(ctx, metricStampQuery, id.ID, id.Type, at, nextMetricVersion(nil))
+	if err != nil {
+		return false, err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+// MarkStale flags a metric last updated before cutoff as stale and returns it,
+// or nil when the metric is missing, already stale or updated since.
+// The check and the change are a single statement.
+func (r *MetricDBExpireRepository) MarkStale(ctx context.Context, id types.MetricID, cutoff time.Time) (*types.Metrics, error) {
+	var metric types.Metrics
+	err := sqlx.GetContext(ctx, r.execer(ctx), &metric, metricMarkStaleQuery, id.ID, id.Type, cutoff, nextMetricVersion(nil))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &metric, nil
+}
+
+// Expire deletes a metric last updated before cutoff. It reports whether the metric was deleted.
+// The check and the deletion are a single statement.
+func (r *MetricDBExpireRepository) Expire(ctx context.Context, id types.MetricID, cutoff time.Time) (bool, error) {
+	res, err := r.execer(ctx).ExecContext(ctx, metricExpireQuery, id.ID, id.Type, cutoff)
+	if err != nil {
+		return false, err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+const metricStampQuery = `
+UPDATE content.metrics
+SET updated_at = $3,
+    version = GREATEST(version + 1, $4)
+WHERE id = $1 AND type = $2 AND updated_at IS NULL;
+`
+
+const metricMarkStaleQuery = `
+UPDATE content.metrics
+SET stale = TRUE,
+    version = GREATEST(version + 1, $4)
+WHERE id = $1 AND type = $2 AND NOT stale AND updated_at < $3
+RETURNING id, type, delta, value, updated_at, stale, version;
+`
+
+const metricExpireQuery = `
+DELETE FROM content.metrics
+WHERE id = $1 AND type = $2 AND updated_at < $3;
+`
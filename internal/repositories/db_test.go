@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/jmoiron/sqlx"
 	"github.com/sbilibin2017/go-yandex-practicum/internal/types"
@@ -24,6 +25,9 @@ CREATE TABLE IF NOT EXISTS content.metrics (
     type VARCHAR(255),
     delta BIGINT,
     value DOUBLE PRECISION,
+    updated_at TIMESTAMPTZ,
+    stale BOOLEAN NOT NULL DEFAULT FALSE,
+    version BIGINT NOT NULL DEFAULT 0,
     PRIMARY KEY (id, type)  
 );
 `
@@ -200,3 +204,237 @@ func TestMetricDBListRepository_List(t *testing.T) {
 		require.Equal(t, m.Value, got[i].Value)
 	}
 }
+
+func TestMetricDBGetBatchRepository_GetBatch(t *testing.T) {
+	ctx := context.Background()
+
+	float64Ptr := func(f float64) *float64 { return &f }
+	int64Ptr := func(i int64) *int64 { return &i }
+
+	db, cleanup := setupPostgresContainer(ctx, t)
+	defer cleanup()
+
+	metricsToInsert := []types.Metrics{
+		{ID: "metric1", Type: "gauge", Value: float64Ptr(1.1)},
+		{ID: "metric2", Type: "counter", Delta: int64Ptr(20)},
+		{ID: "metric3", Type: "gauge", Value: float64Ptr(3.3)},
+	}
+
+	for _, m := range metricsToInsert {
+		_, err := db.ExecContext(ctx,
+			`INSERT INTO content.metrics (id, type, delta, value) VALUES ($1, $2, $3, $4)`,
+			m.ID, m.Type, m.Delta, m.Value)
+		require.NoError(t, err)
+	}
+
+	repo := NewMetricDBGetBatchRepository(
+		WithMetricDBGetBatchRepositoryDB(db),
+		WithMetricDBGetBatchRepositoryTxGetter(func(ctx context.Context) (*sqlx.Tx, bool) {
+			return nil, false
+		}),
+	)
+
+	got, err := repo.GetBatch(ctx, []types.MetricID{
+		{ID: "metric3", Type: "gauge"},
+		{ID: "metric2", Type: "counter"},
+		{ID: "metric1", Type: "counter"},
+		{ID: "missing", Type: "gauge"},
+	})
+	require.NoError(t, err)
+	require.Len(t, got, 2)
+	require.Equal(t, "metric2", got[0].ID)
+	require.Equal(t, metricsToInsert[1].Delta, got[0].Delta)
+	require.Equal(t, "metric3", got[1].ID)
+	require.Equal(t, metricsToInsert[2].Value, got[1].Value)
+
+	got, err = repo.GetBatch(ctx, nil)
+	require.NoError(t, err)
+	require.Nil(t, got)
+}
+
+func TestMetricDBDeleteRepository(t *testing.T) {
+	ctx := context.Background()
+
+	float64Ptr := func(f float64) *float64 { return &f }
+
+	db, cleanup := setupPostgresContainer(ctx, t)
+	defer cleanup()
+
+	for _, m := range []types.Metrics{
+		{ID: "CPUutilization1", Type: "gauge", Value: float64Ptr(1)},
+		{ID: "CPUutilization2", Type: "gauge", Value: float64Ptr(2)},
+		{ID: "Alloc", Type: "gauge", Value: float64Ptr(3)},
+	} {
+		_, err := db.ExecContext(ctx,
+			`INSERT INTO content.metrics (id, type, delta, value) VALUES ($1, $2, $3, $4)`,
+			m.ID, m.Type, m.Delta, m.Value)
+		require.NoError(t, err)
+	}
+
+	repo := NewMetricDBDeleteRepository(
+		WithMetricDBDeleteRepositoryDB(db),
+		WithMetricDBDeleteRepositoryTxGetter(func(ctx context.Context) (*sqlx.Tx, bool) {
+			return nil, false
+		}),
+	)
+
+	deleted, err := repo.Delete(ctx, types.MetricID{ID: "Alloc", Type: "gauge"})
+	require.NoError(t, err)
+	require.True(t, deleted)
+
+	deleted, err = repo.Delete(ctx, types.MetricID{ID: "Alloc", Type: "gauge"})
+	require.NoError(t, err)
+	require.False(t, deleted)
+
+	ids, err := repo.DeleteByPrefix(ctx, "CPU")
+	require.NoError(t, err)
+	require.Equal(t, []types.MetricID{
+		{ID: "CPUutilization1", Type: "gauge"},
+		{ID: "CPUutilization2", Type: "gauge"},
+	}, ids)
+
+	var count int
+	require.NoError(t, db.GetContext(ctx, &count, `SELECT COUNT(*) FROM content.metrics`))
+	require.Equal(t, 0, count)
+}
+
+func TestMetricDBResetRepository_Reset(t *testing.T) {
+	ctx := context.Background()
+
+	int64Ptr := func(i int64) *int64 { return &i }
+
+	db, cleanup := setupPostgresContainer(ctx, t)
+	defer cleanup()
+
+	_, err := db.ExecContext(ctx,
+		`INSERT INTO content.metrics (id, type, delta, value) VALUES ($1, $2, $3, $4)`,
+		"PollCount", "counter", int64Ptr(7), nil)
+	require.NoError(t, err)
+
+	repo := NewMetricDBResetRepository(
+		WithMetricDBResetRepositoryDB(db),
+		WithMetricDBResetRepositoryTxGetter(func(ctx context.Context) (*sqlx.Tx, bool) {
+			return nil, false
+		}),
+	)
+
+	reset, err := repo.Reset(ctx, types.MetricID{ID: "PollCount", Type: "counter"})
+	require.NoError(t, err)
+	require.NotNil(t, reset)
+	require.Equal(t, int64(0), *reset.Delta)
+
+	missing, err := repo.Reset(ctx, types.MetricID{ID: "missing", Type: "counter"})
+	require.NoError(t, err)
+	require.Nil(t, missing)
+
+	var delta int64
+	require.NoError(t, db.GetContext(ctx, &delta, `SELECT delta FROM content.metrics WHERE id = 'PollCount'`))
+	require.Equal(t, int64(0), delta)
+
+	var version int64
+	require.NoError(t, db.GetContext(ctx, &version, `SELECT version FROM content.metrics WHERE id = 'PollCount'`))
+	require.Greater(t, version, int64(1), "a reset gives the counter a new version")
+	require.Equal(t, version, reset.Version)
+}
+
+func TestMetricDBApplyRepository_Apply(t *testing.T) {
+	ctx := context.Background()
+
+	float64Ptr := func(f float64) *float64 { return &f }
+	int64Ptr := func(i int64) *int64 { return &i }
+
+	db, cleanup := setupPostgresContainer(ctx, t)
+	defer cleanup()
+
+	repo := NewMetricDBApplyRepository(
+		WithMetricDBApplyRepositoryDB(db),
+		WithMetricDBApplyRepositoryTxGetter(func(ctx context.Context) (*sqlx.Tx, bool) {
+			return nil, false
+		}),
+	)
+
+	got, err := repo.Apply(ctx, types.Metrics{ID: "Load", Type: "gauge", Value: float64Ptr(3), Op: types.OpSub})
+	require.NoError(t, err)
+	require.Equal(t, -3.0, *got.Value)
+
+	got, err = repo.Apply(ctx, types.Metrics{ID: "Load", Type: "gauge", Value: float64Ptr(5), Op: types.OpAdd})
+	require.NoError(t, err)
+	require.Equal(t, 2.0, *got.Value)
+
+	got, err = repo.Apply(ctx, types.Metrics{ID: "Load", Type: "gauge", Value: float64Ptr(1), Op: types.OpMin})
+	require.NoError(t, err)
+	require.Equal(t, 1.0, *got.Value)
+
+	got, err = repo.Apply(ctx, types.Metrics{ID: "Load", Type: "gauge", Value: float64Ptr(0), Op: types.OpMax})
+	require.NoError(t, err)
+	require.Equal(t, 1.0, *got.Value)
+
+	got, err = repo.Apply(ctx, types.Metrics{ID: "PollCount", Type: "counter", Delta: int64Ptr(4), Op: types.OpAdd})
+	require.NoError(t, err)
+	require.Equal(t, int64(4), *got.Delta)
+	first := got.Version
+
+	got, err = repo.Apply(ctx, types.Metrics{ID: "PollCount", Type: "counter", Delta: int64Ptr(1), Op: types.OpSet})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), *got.Delta)
+	require.Greater(t, got.Version, first)
+	current := got.Version
+
+	_, err = repo.Apply(ctx, types.Metrics{ID: "PollCount", Type: "counter", Delta: int64Ptr(1), ExpectedVersion: &first})
+	require.ErrorIs(t, err, types.ErrVersionConflict)
+
+	missing := int64(3)
+	_, err = repo.Apply(ctx, types.Metrics{ID: "Missing", Type: "counter", Delta: int64Ptr(1), ExpectedVersion: &missing})
+	require.ErrorIs(t, err, types.ErrVersionConflict)
+
+	got, err = repo.Apply(ctx, types.Metrics{ID: "PollCount", Type: "counter", Delta: int64Ptr(1), ExpectedVersion: &current})
+	require.NoError(t, err)
+	require.Greater(t, got.Version, current)
+}
+
+func TestMetricDBExpireRepository(t *testing.T) {
+	ctx := context.Background()
+
+	db, cleanup := setupPostgresContainer(ctx, t)
+	defer cleanup()
+
+	cutoff := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	_, err := db.ExecContext(ctx,
+		`INSERT INTO content.metrics (id, type, value, updated_at) VALUES
+			('Alloc', 'gauge', 1, $1), ('Frees', 'gauge', 2, $2), ('Mallocs', 'gauge', 3, NULL)`,
+		cutoff.Add(-time.Minute), cutoff.Add(time.Minute))
+	require.NoError(t, err)
+
+	repo := NewMetricDBExpireRepository(
+		WithMetricDBExpireRepositoryDB(db),
+		WithMetricDBExpireRepositoryTxGetter(func(ctx context.Context) (*sqlx.Tx, bool) {
+			return nil, false
+		}),
+	)
+
+	marked, err := repo.MarkStale(ctx, types.MetricID{ID: "Frees", Type: "gauge"}, cutoff)
+	require.NoError(t, err)
+	require.Nil(t, marked, "a metric updated since the cutoff is kept")
+
+	marked, err = repo.MarkStale(ctx, types.MetricID{ID: "Alloc", Type: "gauge"}, cutoff)
+	require.NoError(t, err)
+	require.NotNil(t, marked)
+	require.True(t, marked.Stale)
+	require.Greater(t, marked.Version, int64(1))
+
+	stamped, err := repo.Stamp(ctx, types.MetricID{ID: "Mallocs", Type: "gauge"}, cutoff)
+	require.NoError(t, err)
+	require.True(t, stamped)
+
+	expired, err := repo.Expire(ctx, types.MetricID{ID: "Frees", Type: "gauge"}, cutoff)
+	require.NoError(t, err)
+	require.False(t, expired)
+
+	expired, err = repo.Expire(ctx, types.MetricID{ID: "Alloc", Type: "gauge"}, cutoff)
+	require.NoError(t, err)
+	require.True(t, expired)
+
+	var count int
+	require.NoError(t, db.GetContext(ctx, &count, `SELECT COUNT(*) FROM content.metrics`))
+	require.Equal(t, 2, count)
+}
@@ -6,7 +6,9 @@ import (
 	"encoding/json"
 	"os"
 	"sort"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/sbilibin2017/go-yandex-practicum/internal/types"
 )
@@ -172,3 +174,404 @@ func (r *MetricFileListRepository) List(ctx context.Context) ([]*types.Metrics,
 
 	return metricsSlice, nil
 }
+
+//
+// MetricFileGetBatchRepository
+//
+
+type MetricFileGetBatchRepository struct {
+	metricFilePath string
+}
+
+type MetricFileGetBatchRepositoryOption func(*MetricFileGetBatchRepository)
+
+func WithMetricFileGetBatchRepositoryPath(path string) MetricFileGetBatchRepositoryOption {
+	return func(r *MetricFileGetBatchRepository) {
+		r.metricFilePath = path
+	}
+}
+
+func NewMetricFileGetBatchRepository(opts ...MetricFileGetBatchRepositoryOption) *MetricFileGetBatchRepository {
+	repo := &MetricFileGetBatchRepository{}
+	for _, opt := range opts {
+		opt(repo)
+	}
+	return repo
+}
+
+func (r *MetricFileGetBatchRepository) GetBatch(ctx context.Context, ids []types.MetricID) ([]*types.Metrics, error) {
+	muFile.RLock()
+	defer muFile.RUnlock()
+
+	file, err := os.Open(r.metricFilePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer file.Close()
+
+	wanted := make(map[types.MetricID]struct{}, len(ids))
+	for _, id := range ids {
+		wanted[id] = struct{}{}
+	}
+
+	metricsMap := make(map[types.MetricID]*types.Metrics)
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var m types.Metrics
+		if err := json.Unmarshal(scanner.Bytes(), &m); err != nil {
+			return nil, err
+		}
+		key := types.MetricID{ID: m.ID, Type: m.Type}
+		if _, ok := wanted[key]; !ok {
+			continue
+		}
+		mCopy := m
+		metricsMap[key] = &mCopy
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	metricsSlice := make([]*types.Metrics, 0, len(metricsMap))
+	for _, m := range metricsMap {
+		metricsSlice = append(metricsSlice, m)
+	}
+
+	sort.SliceStable(metricsSlice, func(i, j int) bool {
+		return metricsSlice[i].ID < metricsSlice[j].ID
+	})
+
+	return metricsSlice, nil
+}
+
+// readMetricsFile reads all metrics from the file keyed by MetricID.
+// Later lines override earlier ones. A missing file yields an empty map.
+func readMetricsFile(path string) (map[types.MetricID]types.Metrics, error) {
+	metricsMap := make(map[types.MetricID]types.Metrics)
+
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return metricsMap, nil
+		}
+		return nil, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var m types.Metrics
+		if err := json.Unmarshal(scanner.Bytes(), &m); err != nil {
+			return nil, err
+		}
+		metricsMap[types.MetricID{ID: m.ID, Type: m.Type}] = m
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return metricsMap, nil
+}
+
+// writeMetricsFile replaces the file content with the given metrics, one JSON object per line.
+func writeMetricsFile(path string, metricsMap map[types.MetricID]types.Metrics) error {
+	metricsSlice := make([]types.Metrics, 0, len(metricsMap))
+	for _, m := range metricsMap {
+		metricsSlice = append(metricsSlice, m)
+	}
+
+	sort.SliceStable(metricsSlice, func(i, j int) bool {
+		return metricsSlice[i].ID < metricsSlice[j].ID
+	})
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	enc := json.NewEncoder(file)
+	for _, m := range metricsSlice {
+		if err := enc.Encode(m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//
+// MetricFileDeleteRepository
+//
+
+type MetricFileDeleteRepository struct {
+	metricFilePath string
+}
+
+type MetricFileDeleteRepositoryOption func(*MetricFileDeleteRepository)
+
+func WithMetricFileDeleteRepositoryPath(path string) MetricFileDeleteRepositoryOption {
+	return func(r *MetricFileDeleteRepository) {
+		r.metricFilePath = path
+	}
+}
+
+func NewMetricFileDeleteRepository(opts ...MetricFileDeleteRepositoryOption) *MetricFileDeleteRepository {
+	repo := &MetricFileDeleteRepository{}
+	for _, opt := range opts {
+		opt(repo)
+	}
+	return repo
+}
+
+func (r *MetricFileDeleteRepository) Delete(ctx context.Context, id types.MetricID) (bool, error) {
+	muFile.Lock()
+	defer muFile.Unlock()
+
+	metricsMap, err := readMetricsFile(r.metricFilePath)
+	if err != nil {
+		return false, err
+	}
+
+	if _, exists := metricsMap[id]; !exists {
+		return false, nil
+	}
+
+	delete(metricsMap, id)
+	if err := writeMetricsFile(r.metricFilePath, metricsMap); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (r *MetricFileDeleteRepository) DeleteByPrefix(ctx context.Context, prefix string) ([]types.MetricID, error) {
+	muFile.Lock()
+	defer muFile.Unlock()
+
+	metricsMap, err := readMetricsFile(r.metricFilePath)
+	if err != nil {
+		return nil, err
+	}
+
+	var deleted []types.MetricID
+	for key := range metricsMap {
+		if strings.HasPrefix(key.ID, prefix) {
+			delete(metricsMap, key)
+			deleted = append(deleted, key)
+		}
+	}
+
+	if len(deleted) == 0 {
+		return nil, nil
+	}
+
+	if err := writeMetricsFile(r.metricFilePath, metricsMap); err != nil {
+		return nil, err
+	}
+
+	sortMetricIDs(deleted)
+
+	return deleted, nil
+}
+
+//
+// MetricFileResetRepository
+//
+
+type MetricFileResetRepository struct {
+	metricFilePath string
+}
+
+type MetricFileResetRepositoryOption func(*MetricFileResetRepository)
+
+func WithMetricFileResetRepositoryPath(path string) MetricFileResetRepositoryOption {
+	return func(r *MetricFileResetRepository) {
+		r.metricFilePath = path
+	}
+}
+
+func NewMetricFileResetRepository(opts ...MetricFileResetRepositoryOption) *MetricFileResetRepository {
+	repo := &MetricFileResetRepository{}
+	for _, opt := range opts {
+		opt(repo)
+	}
+	return repo
+}
+
+func (r *MetricFileResetRepository) Reset(ctx context.Context, id types.MetricID) (*types.Metrics, error) {
+	muFile.Lock()
+	defer muFile.Unlock()
+
+	metricsMap, err := readMetricsFile(r.metricFilePath)
+	if err != nil {
+		return nil, err
+	}
+
+	metric, exists := metricsMap[id]
+	if !exists || metric.Type != types.Counter {
+		return nil, nil
+	}
+
+	var zero int64
+	metric.Delta = &zero
+	metric.Version = nextMetricVersion(&metric)
+	metricsMap[id] = metric
+
+	if err := writeMetricsFile(r.metricFilePath, metricsMap); err != nil {
+		return nil, err
+	}
+	return &metric, nil
+}
+
+//
+// MetricFileApplyRepository
+//
+
+type MetricFileApplyRepository struct {
+	metricFilePath string
+}
+
+type MetricFileApplyRepositoryOption func(*MetricFileApplyRepository)
+
+func WithMetricFileApplyRepositoryPath(path string) MetricFileApplyRepositoryOption {
+	return func(r *MetricFileApplyRepository) {
+		r.metricFilePath = path
+	}
+}
+
+func NewMetricFileApplyRepository(opts ...MetricFileApplyRepositoryOption) *MetricFileApplyRepository {
+	repo := &MetricFileApplyRepository{}
+	for _, opt := range opts {
+		opt(repo)
+	}
+	return repo
+}
+
+func (r *MetricFileApplyRepository) Apply(ctx context.Context, metric types.Metrics) (*types.Metrics, error) {
+	muFile.Lock()
+	defer muFile.Unlock()
+
+	metricsMap, err := readMetricsFile(r.metricFilePath)
+	if err != nil {
+		return nil, err
+	}
+
+	key := types.MetricID{ID: metric.ID, Type: metric.Type}
+
+	var current *types.Metrics
+	if m, exists := metricsMap[key]; exists {
+		current = &m
+	}
+
+	if err := checkMetricVersion(current, metric); err != nil {
+		return nil, err
+	}
+
+	result := applyMetricOp(current, metric)
+	metricsMap[key] = result
+
+	if err := writeMetricsFile(r.metricFilePath, metricsMap); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+//
+// MetricFileExpireRepository
+//
+
+type MetricFileExpireRepository struct {
+	metricFilePath string
+}
+
+type MetricFileExpireRepositoryOption func(*MetricFileExpireRepository)
+
+func WithMetricFileExpireRepositoryPath(path string) MetricFileExpireRepositoryOption {
+	return func(r *MetricFileExpireRepository) {
+		r.metricFilePath = path
+	}
+}
+
+func NewMetricFileExpireRepository(opts ...MetricFileExpireRepositoryOption) *MetricFileExpireRepository {
+	repo := &MetricFileExpireRepository{}
+	for _, opt := range opts {
+		opt(repo)
+	}
+	return repo
+}
+
+// Stamp sets the update time of a metric that has none. It reports whether the metric was stamped.
+func (r *MetricFileExpireRepository) Stamp(ctx context.Context, id types.MetricID, at time.Time) (bool, error) {
+	muFile.Lock()
+	defer muFile.Unlock()
+
+	metricsMap, err := readMetricsFile(r.metricFilePath)
+	if err != nil {
+		return false, err
+	}
+
+	metric, exists := metricsMap[id]
+	if !exists || metric.UpdatedAt != nil {
+		return false, nil
+	}
+	metric.UpdatedAt = &at
+	metric.Version = nextMetricVersion(&metric)
+	metricsMap[id] = metric
+
+	if err := writeMetricsFile(r.metricFilePath, metricsMap); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// MarkStale flags a metric last updated before cutoff as stale and returns it,
+// or nil when the metric is missing, already stale or updated since.
+func (r *MetricFileExpireRepository) MarkStale(ctx context.Context, id types.MetricID, cutoff time.Time) (*types.Metrics, error) {
+	muFile.Lock()
+	defer muFile.Unlock()
+
+	metricsMap, err := readMetricsFile(r.metricFilePath)
+	if err != nil {
+		return nil, err
+	}
+
+	metric, exists := metricsMap[id]
+	if !exists || metric.Stale || !updatedBefore(&metric, cutoff) {
+		return nil, nil
+	}
+	metric.Stale = true
+	metric.Version = nextMetricVersion(&metric)
+	metricsMap[id] = metric
+
+	if err := writeMetricsFile(r.metricFilePath, metricsMap); err != nil {
+		return nil, err
+	}
+	return &metric, nil
+}
+
+// Expire deletes a metric last updated before cutoff. It reports whether the metric was deleted.
+func (r *MetricFileExpireRepository) Expire(ctx context.Context, id types.MetricID, cutoff time.Time) (bool, error) {
+	muFile.Lock()
+	defer muFile.Unlock()
+
+	metricsMap, err := readMetricsFile(r.metricFilePath)
+	if err != nil {
+		return false, err
+	}
+
+	metric, exists := metricsMap[id]
+	if !exists || !updatedBefore(&metric, cutoff) {
+		return false, nil
+	}
+	delete(metricsMap, id)
+
+	if err := writeMetricsFile(r.metricFilePath, metricsMap); err != nil {
+		return false, err
+	}
+	return true, nil
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -147,3 +148,239 @@ func TestMetricFileListRepository_List(t *testing.T) {
 		}
 	})
 }
+
+func TestMetricFileGetBatchRepository_GetBatch(t *testing.T) {
+	tmpFile, cleanup := createTempFile(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	metrics := []types.Metrics{
+		{ID: "a", Type: types.Counter, Value: nil, Delta: int64Ptr(1)},
+		{ID: "b", Type: types.Gauge, Value: float64Ptr(2.0), Delta: nil},
+		{ID: "c", Type: types.Counter, Value: nil, Delta: int64Ptr(3)},
+	}
+
+	f, err := os.OpenFile(tmpFile, os.O_WRONLY, 0644)
+	require.NoError(t, err)
+	for _, m := range metrics {
+		b, err := json.Marshal(m)
+		require.NoError(t, err)
+		_, err = f.Write(append(b, '\n'))
+		require.NoError(t, err)
+	}
+	f.Close()
+
+	repo := NewMetricFileGetBatchRepository(WithMetricFileGetBatchRepositoryPath(tmpFile))
+
+	t.Run("get requested metrics", func(t *testing.T) {
+		got, err := repo.GetBatch(ctx, []types.MetricID{
+			{ID: "c", Type: types.Counter},
+			{ID: "a", Type: types.Counter},
+			{ID: "b", Type: types.Counter},
+			{ID: "unknown", Type: types.Gauge},
+		})
+		require.NoError(t, err)
+		require.Len(t, got, 2)
+		assert.Equal(t, &metrics[0], got[0])
+		assert.Equal(t, &metrics[2], got[1])
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		repo := NewMetricFileGetBatchRepository(WithMetricFileGetBatchRepositoryPath(tmpFile + ".missing"))
+		got, err := repo.GetBatch(ctx, []types.MetricID{{ID: "a", Type: types.Counter}})
+		require.NoError(t, err)
+		assert.Nil(t, got)
+	})
+}
+
+func TestMetricFileDeleteRepository(t *testing.T) {
+	tmpFile, cleanup := createTempFile(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	metrics := []types.Metrics{
+		{ID: "CPUutilization1", Type: types.Gauge, Value: float64Ptr(1)},
+		{ID: "CPUutilization2", Type: types.Gauge, Value: float64Ptr(2)},
+		{ID: "PollCount", Type: types.Counter, Delta: int64Ptr(3)},
+		{ID: "RandomValue", Type: types.Gauge, Value: float64Ptr(4)},
+	}
+	require.NoError(t, writeMetricsFile(tmpFile, map[types.MetricID]types.Metrics{
+		{ID: metrics[0].ID, Type: metrics[0].Type}: metrics[0],
+		{ID: metrics[1].ID, Type: metrics[1].Type}: metrics[1],
+		{ID: metrics[2].ID, Type: metrics[2].Type}: metrics[2],
+		{ID: metrics[3].ID, Type: metrics[3].Type}: metrics[3],
+	}))
+
+	repo := NewMetricFileDeleteRepository(WithMetricFileDeleteRepositoryPath(tmpFile))
+
+	t.Run("delete single metric", func(t *testing.T) {
+		deleted, err := repo.Delete(ctx, types.MetricID{ID: "RandomValue", Type: types.Gauge})
+		require.NoError(t, err)
+		assert.True(t, deleted)
+
+		deleted, err = repo.Delete(ctx, types.MetricID{ID: "RandomValue", Type: types.Gauge})
+		require.NoError(t, err)
+		assert.False(t, deleted)
+	})
+
+	t.Run("delete by prefix", func(t *testing.T) {
+		deleted, err := repo.DeleteByPrefix(ctx, "CPU")
+		require.NoError(t, err)
+		assert.Equal(t, []types.MetricID{
+			{ID: "CPUutilization1", Type: types.Gauge},
+			{ID: "CPUutilization2", Type: types.Gauge},
+		}, deleted)
+	})
+
+	t.Run("remaining metrics persisted", func(t *testing.T) {
+		left, err := readMetricsFile(tmpFile)
+		require.NoError(t, err)
+		assert.Equal(t, map[types.MetricID]types.Metrics{
+			{ID: "PollCount", Type: types.Counter}: metrics[2],
+		}, left)
+	})
+}
+
+func TestMetricFileResetRepository_Reset(t *testing.T) {
+	tmpFile, cleanup := createTempFile(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	require.NoError(t, writeMetricsFile(tmpFile, map[types.MetricID]types.Metrics{
+		{ID: "PollCount", Type: types.Counter}: {ID: "PollCount", Type: types.Counter, Delta: int64Ptr(10)},
+		{ID: "Alloc", Type: types.Gauge}:       {ID: "Alloc", Type: types.Gauge, Value: float64Ptr(1.5)},
+	}))
+
+	repo := NewMetricFileResetRepository(WithMetricFileResetRepositoryPath(tmpFile))
+
+	reset, err := repo.Reset(ctx, types.MetricID{ID: "PollCount", Type: types.Counter})
+	require.NoError(t, err)
+	require.NotNil(t, reset)
+
+	gauge, err := repo.Reset(ctx, types.MetricID{ID: "Alloc", Type: types.Gauge})
+	require.NoError(t, err)
+	assert.Nil(t, gauge)
+
+	missing, err := repo.Reset(ctx, types.MetricID{ID: "missing", Type: types.Counter})
+	require.NoError(t, err)
+	assert.Nil(t, missing)
+
+	left, err := readMetricsFile(tmpFile)
+	require.NoError(t, err)
+	assert.Equal(t, left[types.MetricID{ID: "PollCount", Type: types.Counter}], *reset)
+	assert.Equal(t, int64(0), *left[types.MetricID{ID: "PollCount", Type: types.Counter}].Delta)
+	assert.Equal(t, 1.5, *left[types.MetricID{ID: "Alloc", Type: types.Gauge}].Value)
+}
+
+func TestMetricFileRepository_UpdateTimeRoundTrip(t *testing.T) {
+	tmpFile, cleanup := createTempFile(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	updatedAt := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	metric := types.Metrics{
+		ID:        "Alloc",
+		Type:      types.Gauge,
+		Value:     float64Ptr(1.5),
+		UpdatedAt: &updatedAt,
+		Stale:     true,
+	}
+
+	saveRepo := NewMetricFileSaveRepository(WithMetricFileSaveRepositoryPath(tmpFile))
+	require.NoError(t, saveRepo.Save(ctx, metric))
+
+	getRepo := NewMetricFileGetRepository(WithMetricFileGetRepositoryPath(tmpFile))
+	got, err := getRepo.Get(ctx, types.MetricID{ID: "Alloc", Type: types.Gauge})
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.True(t, updatedAt.Equal(*got.UpdatedAt))
+	assert.True(t, got.Stale)
+}
+
+func TestMetricFileApplyRepository_Apply(t *testing.T) {
+	tmpFile, cleanup := createTempFile(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	repo := NewMetricFileApplyRepository(WithMetricFileApplyRepositoryPath(tmpFile))
+
+	got, err := repo.Apply(ctx, types.Metrics{ID: "PollCount", Type: types.Counter, Delta: int64Ptr(5), Op: types.OpAdd})
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), *got.Delta)
+	first := got.Version
+
+	got, err = repo.Apply(ctx, types.Metrics{ID: "PollCount", Type: types.Counter, Delta: int64Ptr(100), Op: types.OpSet})
+	require.NoError(t, err)
+	assert.Equal(t, int64(100), *got.Delta)
+
+	left, err := readMetricsFile(tmpFile)
+	require.NoError(t, err)
+	stored := left[types.MetricID{ID: "PollCount", Type: types.Counter}]
+	assert.Equal(t, int64(100), *stored.Delta)
+	assert.Empty(t, stored.Op)
+	assert.Greater(t, stored.Version, first)
+
+	_, err = repo.Apply(ctx, types.Metrics{ID: "PollCount", Type: types.Counter, Delta: int64Ptr(1), ExpectedVersion: &first})
+	assert.ErrorIs(t, err, types.ErrVersionConflict)
+
+	// A reset gives the counter a new version
+	reset, err := NewMetricFileResetRepository(WithMetricFileResetRepositoryPath(tmpFile)).Reset(ctx, types.MetricID{ID: "PollCount", Type: types.Counter})
+	require.NoError(t, err)
+	require.NotNil(t, reset)
+	left, err = readMetricsFile(tmpFile)
+	require.NoError(t, err)
+	assert.Greater(t, left[types.MetricID{ID: "PollCount", Type: types.Counter}].Version, stored.Version)
+	assert.Equal(t, left[types.MetricID{ID: "PollCount", Type: types.Counter}].Version, reset.Version)
+}
+
+func TestMetricFileExpireRepository(t *testing.T) {
+	tmpFile, cleanup := createTempFile(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	repo := NewMetricFileExpireRepository(WithMetricFileExpireRepositoryPath(tmpFile))
+
+	cutoff := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	old := cutoff.Add(-time.Minute)
+	recent := cutoff.Add(time.Minute)
+	stale := types.MetricID{ID: "Alloc", Type: types.Gauge}
+	fresh := types.MetricID{ID: "Frees", Type: types.Gauge}
+	legacy := types.MetricID{ID: "PollCount", Type: types.Counter}
+
+	require.NoError(t, writeMetricsFile(tmpFile, map[types.MetricID]types.Metrics{
+		stale:  {ID: "Alloc", Type: types.Gauge, Value: float64Ptr(1), UpdatedAt: &old},
+		fresh:  {ID: "Frees", Type: types.Gauge, Value: float64Ptr(2), UpdatedAt: &recent},
+		legacy: {ID: "PollCount", Type: types.Counter, Delta: int64Ptr(3)},
+	}))
+
+	marked, err := repo.MarkStale(ctx, fresh, cutoff)
+	require.NoError(t, err)
+	assert.Nil(t, marked)
+
+	marked, err = repo.MarkStale(ctx, stale, cutoff)
+	require.NoError(t, err)
+	require.NotNil(t, marked)
+	assert.True(t, marked.Stale)
+
+	stamped, err := repo.Stamp(ctx, legacy, cutoff)
+	require.NoError(t, err)
+	assert.True(t, stamped)
+
+	expired, err := repo.Expire(ctx, fresh, cutoff)
+	require.NoError(t, err)
+	assert.False(t, expired)
+
+	expired, err = repo.Expire(ctx, stale, cutoff)
+	require.NoError(t, err)
+	assert.True(t, expired)
+
+	left, err := readMetricsFile(tmpFile)
+	require.NoError(t, err)
+	assert.NotContains(t, left, stale)
+	assert.Contains(t, left, fresh)
+	assert.Equal(t, cutoff, left[legacy].UpdatedAt.UTC())
+}
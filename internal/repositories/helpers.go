@@ -0,0 +1,110 @@
+package repositories
+
+import (
+	"sort"
+	"sync/atomic"
+	"time"
+
+	"github.com/sbilibin2017/go-yandex-practicum/internal/types"
+)
+
+// lastMetricVersion is the last version handed out by nextMetricVersion.
+var lastMetricVersion atomic.Int64
+
+// nextMetricVersion returns the version of a metric after a change. Versions
+// are the change time in nanoseconds, kept strictly increasing, so they never
+// repeat for an ID, even when the metric is deleted and created again, and an
+// old ETag cannot match new content. The result is always above the version
+// of current, which may be nil.
+func nextMetricVersion(current *types.Metrics) int64 {
+	for {
+		last := lastMetricVersion.Load()
+		v := max(time.Now().UnixNano(), last+1)
+		if current != nil {
+			v = max(v, current.Version+1)
+		}
+		if lastMetricVersion.CompareAndSwap(last, v) {
+			return v
+		}
+	}
+}
+
+// sortMetricIDs sorts metric IDs by ID and then by type.
+func sortMetricIDs(ids []types.MetricID) {
+	sort.SliceStable(ids, func(i, j int) bool {
+		if ids[i].ID != ids[j].ID {
+			return ids[i].ID < ids[j].ID
+		}
+		return ids[i].Type < ids[j].Type
+	})
+}
+
+// applyMetricOp returns the result of applying the update in m to the stored metric current.
+// A nil current is treated as a zero value. The returned metric carries no Op and
+// a new version from nextMetricVersion.
+func applyMetricOp(current *types.Metrics, m types.Metrics) types.Metrics {
+	result := m
+	result.Op = ""
+	result.ExpectedVersion = nil
+	result.Version = nextMetricVersion(current)
+
+	switch m.Type {
+	case types.Gauge:
+		if m.Value == nil {
+			break
+		}
+		var stored float64
+		if current != nil && current.Value != nil {
+			stored = *current.Value
+		}
+		v := *m.Value
+		switch m.Op {
+		case types.OpAdd:
+			v = stored + v
+		case types.OpSub:
+			v = stored - v
+		case types.OpMin:
+			if current != nil && current.Value != nil && stored < v {
+				v = stored
+			}
+		case types.OpMax:
+			if current != nil && current.Value != nil && stored > v {
+				v = stored
+			}
+		}
+		result.Value = &v
+
+	case types.Counter:
+		if m.Delta == nil {
+			break
+		}
+		d := *m.Delta
+		if m.Op != types.OpSet && current != nil && current.Delta != nil {
+			d = *current.Delta + d
+		}
+		result.Delta = &d
+	}
+
+	return result
+}
+
+// updatedBefore reports whether the metric was last updated before cutoff.
+// Metrics without an update time never are.
+func updatedBefore(m *types.Metrics, cutoff time.Time) bool {
+	return m.UpdatedAt != nil && m.UpdatedAt.Before(cutoff)
+}
+
+// checkMetricVersion verifies the expected version of a conditional update against the stored metric.
+func checkMetricVersion(current *types.Metrics, m types.Metrics) error {
+	if m.ExpectedVersion == nil {
+		return nil
+	}
+	var stored int64
+	if current != nil {
+		stored = current.Version
+	}
+	if stored != *m.ExpectedVersion {
+		return types.ErrVersionConflict
+	}
+	return nil
+}
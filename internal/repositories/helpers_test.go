@@ -0,0 +1,115 @@
+package repositories
+
+import (
+	"testing"
+
+	"github.com/sbilibin2017/go-yandex-practicum/internal/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSortMetricIDs(t *testing.T) {
+	ids := []types.MetricID{
+		{ID: "b", Type: types.Gauge},
+		{ID: "a", Type: types.Gauge},
+		{ID: "a", Type: types.Counter},
+	}
+
+	sortMetricIDs(ids)
+
+	assert.Equal(t, []types.MetricID{
+		{ID: "a", Type: types.Counter},
+		{ID: "a", Type: types.Gauge},
+		{ID: "b", Type: types.Gauge},
+	}, ids)
+}
+
+func TestApplyMetricOp(t *testing.T) {
+	gauge := func(v float64) *types.Metrics {
+		return &types.Metrics{ID: "g", Type: types.Gauge, Value: float64Ptr(v)}
+	}
+	counter := func(d int64) *types.Metrics {
+		return &types.Metrics{ID: "c", Type: types.Counter, Delta: int64Ptr(d)}
+	}
+
+	tests := []struct {
+		name      string
+		current   *types.Metrics
+		update    types.Metrics
+		wantValue *float64
+		wantDelta *int64
+	}{
+		{
+			name:      "gauge set",
+			current:   gauge(5),
+			update:    types.Metrics{ID: "g", Type: types.Gauge, Value: float64Ptr(2), Op: types.OpSet},
+			wantValue: float64Ptr(2),
+		},
+		{
+			name:      "gauge add",
+			current:   gauge(5),
+			update:    types.Metrics{ID: "g", Type: types.Gauge, Value: float64Ptr(2), Op: types.OpAdd},
+			wantValue: float64Ptr(7),
+		},
+		{
+			name:      "gauge sub",
+			current:   gauge(5),
+			update:    types.Metrics{ID: "g", Type: types.Gauge, Value: float64Ptr(2), Op: types.OpSub},
+			wantValue: float64Ptr(3),
+		},
+		{
+			name:      "gauge sub on missing metric",
+			current:   nil,
+			update:    types.Metrics{ID: "g", Type: types.Gauge, Value: float64Ptr(2), Op: types.OpSub},
+			wantValue: float64Ptr(-2),
+		},
+		{
+			name:      "gauge min keeps stored",
+			current:   gauge(1),
+			update:    types.Metrics{ID: "g", Type: types.Gauge, Value: float64Ptr(2), Op: types.OpMin},
+			wantValue: float64Ptr(1),
+		},
+		{
+			name:      "gauge max keeps new",
+			current:   gauge(1),
+			update:    types.Metrics{ID: "g", Type: types.Gauge, Value: float64Ptr(2), Op: types.OpMax},
+			wantValue: float64Ptr(2),
+		},
+		{
+			name:      "gauge max on missing metric",
+			current:   nil,
+			update:    types.Metrics{ID: "g", Type: types.Gauge, Value: float64Ptr(-3), Op: types.OpMax},
+			wantValue: float64Ptr(-3),
+		},
+		{
+			name:      "counter add",
+			current:   counter(5),
+			update:    types.Metrics{ID: "c", Type: types.Counter, Delta: int64Ptr(2), Op: types.OpAdd},
+			wantDelta: int64Ptr(7),
+		},
+		{
+			name:      "counter set",
+			current:   counter(5),
+			update:    types.Metrics{ID: "c", Type: types.Counter, Delta: int64Ptr(2), Op: types.OpSet},
+			wantDelta: int64Ptr(2),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := applyMetricOp(tt.current, tt.update)
+			assert.Empty(t, got.Op)
+			assert.Equal(t, tt.wantValue, got.Value)
+			assert.Equal(t, tt.wantDelta, got.Delta)
+		})
+	}
+}
+
+func TestNextMetricVersion(t *testing.T) {
+	first := nextMetricVersion(nil)
+	second := nextMetricVersion(nil)
+	assert.Greater(t, second, first, "versions never repeat")
+
+	ahead := &types.Metrics{Version: second + 1_000_000_000}
+	assert.Equal(t, ahead.Version+1, nextMetricVersion(ahead))
+	assert.Greater(t, nextMetricVersion(nil), ahead.Version+1)
+}
@@ -3,7 +3,9 @@ package repositories
 import (
 	"context"
 	"sort"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/sbilibin2017/go-yandex-practicum/internal/types"
 )
@@ -92,3 +94,183 @@ func (r *MetricMemoryListRepository) List(ctx context.Context) ([]*types.Metrics
 
 	return metrics, nil
 }
+
+// MetricMemoryGetBatchRepository provides methods to get several metrics from memory at once.
+type MetricMemoryGetBatchRepository struct{}
+
+// NewMetricMemoryGetBatchRepository creates a new MetricMemoryGetBatchRepository.
+func NewMetricMemoryGetBatchRepository() *MetricMemoryGetBatchRepository {
+	return &MetricMemoryGetBatchRepository{}
+}
+
+// GetBatch retrieves the metrics matching the given MetricIDs from the in-memory map.
+// Missing metrics are skipped. The whole lookup happens under a single read lock.
+func (r *MetricMemoryGetBatchRepository) GetBatch(ctx context.Context, ids []types.MetricID) ([]*types.Metrics, error) {
+	muMemory.RLock()
+	defer muMemory.RUnlock()
+
+	var metrics []*types.Metrics
+	for _, id := range ids {
+		if m, exists := data[id]; exists {
+			c := m
+			metrics = append(metrics, &c)
+		}
+	}
+
+	sort.SliceStable(metrics, func(i, j int) bool {
+		return metrics[i].ID < metrics[j].ID
+	})
+
+	return metrics, nil
+}
+
+// MetricMemoryDeleteRepository provides methods to delete metrics from memory.
+type MetricMemoryDeleteRepository struct{}
+
+// NewMetricMemoryDeleteRepository creates a new MetricMemoryDeleteRepository.
+func NewMetricMemoryDeleteRepository() *MetricMemoryDeleteRepository {
+	return &MetricMemoryDeleteRepository{}
+}
+
+// Delete removes the metric with the given MetricID from the in-memory map.
+// It reports whether the metric existed.
+func (r *MetricMemoryDeleteRepository) Delete(ctx context.Context, id types.MetricID) (bool, error) {
+	muMemory.Lock()
+	defer muMemory.Unlock()
+
+	if _, exists := data[id]; !exists {
+		return false, nil
+	}
+
+	delete(data, id)
+	return true, nil
+}
+
+// DeleteByPrefix removes all metrics whose ID starts with prefix
+// and returns the IDs of the removed metrics sorted by ID.
+func (r *MetricMemoryDeleteRepository) DeleteByPrefix(ctx context.Context, prefix string) ([]types.MetricID, error) {
+	muMemory.Lock()
+	defer muMemory.Unlock()
+
+	var deleted []types.MetricID
+	for key := range data {
+		if strings.HasPrefix(key.ID, prefix) {
+			delete(data, key)
+			deleted = append(deleted, key)
+		}
+	}
+
+	sortMetricIDs(deleted)
+
+	return deleted, nil
+}
+
+// MetricMemoryResetRepository provides methods to reset counters in memory.
+type MetricMemoryResetRepository struct{}
+
+// NewMetricMemoryResetRepository creates a new MetricMemoryResetRepository.
+func NewMetricMemoryResetRepository() *MetricMemoryResetRepository {
+	return &MetricMemoryResetRepository{}
+}
+
+// Reset sets the delta of the counter with the given MetricID to zero and
+// gives it a new version. It returns the reset counter, or nil when it does not exist.
+func (r *MetricMemoryResetRepository) Reset(ctx context.Context, id types.MetricID) (*types.Metrics, error) {
+	muMemory.Lock()
+	defer muMemory.Unlock()
+
+	metric, exists := data[id]
+	if !exists || metric.Type != types.Counter {
+		return nil, nil
+	}
+
+	var zero int64
+	metric.Delta = &zero
+	metric.Version = nextMetricVersion(&metric)
+	data[id] = metric
+	return &metric, nil
+}
+
+// MetricMemoryApplyRepository provides methods to apply update operations to metrics in memory.
+type MetricMemoryApplyRepository struct{}
+
+// NewMetricMemoryApplyRepository creates a new MetricMemoryApplyRepository.
+func NewMetricMemoryApplyRepository() *MetricMemoryApplyRepository {
+	return &MetricMemoryApplyRepository{}
+}
+
+// Apply applies the update operation carried by metric to the stored value and returns the result.
+// The read and the write happen under a single write lock.
+func (r *MetricMemoryApplyRepository) Apply(ctx context.Context, metric types.Metrics) (*types.Metrics, error) {
+	muMemory.Lock()
+	defer muMemory.Unlock()
+
+	key := types.MetricID{ID: metric.ID, Type: metric.Type}
+
+	var current *types.Metrics
+	if m, exists := data[key]; exists {
+		current = &m
+	}
+
+	if err := checkMetricVersion(current, metric); err != nil {
+		return nil, err
+	}
+
+	result := applyMetricOp(current, metric)
+	data[key] = result
+	return &result, nil
+}
+
+// MetricMemoryExpireRepository provides methods to expire metrics in memory.
+// Each check and change happens under a single write lock, so a metric
+// updated after the sweep read it is never expired.
+type MetricMemoryExpireRepository struct{}
+
+// NewMetricMemoryExpireRepository creates a new MetricMemoryExpireRepository.
+func NewMetricMemoryExpireRepository() *MetricMemoryExpireRepository {
+	return &MetricMemoryExpireRepository{}
+}
+
+// Stamp sets the update time of a metric that has none. It reports whether the metric was stamped.
+func (r *MetricMemoryExpireRepository) Stamp(ctx context.Context, id types.MetricID, at time.Time) (bool, error) {
+	muMemory.Lock()
+	defer muMemory.Unlock()
+
+	metric, exists := data[id]
+	if !exists || metric.UpdatedAt != nil {
+		return false, nil
+	}
+	metric.UpdatedAt = &at
+	metric.Version = nextMetricVersion(&metric)
+	data[id] = metric
+	return true, nil
+}
+
+// MarkStale flags a metric last updated before cutoff as stale and returns it,
+// or nil when the metric is missing, already stale or updated since.
+func (r *MetricMemoryExpireRepository) MarkStale(ctx context.Context, id types.MetricID, cutoff time.Time) (*types.Metrics, error) {
+	muMemory.Lock()
+	defer muMemory.Unlock()
+
+	metric, exists := data[id]
+	if !exists || metric.Stale || !updatedBefore(&metric, cutoff) {
+		return nil, nil
+	}
+	metric.Stale = true
+	metric.Version = nextMetricVersion(&metric)
+	data[id] = metric
+	return &metric, nil
+}
+
+// Expire deletes a metric last updated before cutoff. It reports whether the metric was deleted.
+func (r *MetricMemoryExpireRepository) Expire(ctx context.Context, id types.MetricID, cutoff time.Time) (bool, error) {
+	muMemory.Lock()
+	defer muMemory.Unlock()
+
+	metric, exists := data[id]
+	if !exists || !updatedBefore(&metric, cutoff) {
+		return false, nil
+	}
+	delete(data, id)
+	return true, nil
+}
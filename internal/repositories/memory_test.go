@@ -3,9 +3,11 @@ package repositories
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/sbilibin2017/go-yandex-practicum/internal/types"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // clearMemory resets the in-memory storage before tests.
@@ -168,3 +170,326 @@ func TestMetricMemoryListRepository_List(t *testing.T) {
 		}
 	})
 }
+
+func TestMetricMemoryGetBatchRepository_GetBatch(t *testing.T) {
+	clearMemory()
+	ctx := context.Background()
+	saveRepo := NewMetricMemorySaveRepository()
+	batchRepo := NewMetricMemoryGetBatchRepository()
+
+	metrics := []types.Metrics{
+		{ID: "b", Type: types.Gauge, Value: float64Ptr(2.2)},
+		{ID: "a", Type: types.Counter, Delta: int64Ptr(5)},
+		{ID: "c", Type: types.Gauge, Value: float64Ptr(3.3)},
+	}
+
+	for _, m := range metrics {
+		_ = saveRepo.Save(ctx, m)
+	}
+
+	tests := []struct {
+		name    string
+		ids     []types.MetricID
+		wantIDs []string
+	}{
+		{
+			name: "Get existing metrics sorted by ID",
+			ids: []types.MetricID{
+				{ID: "b", Type: types.Gauge},
+				{ID: "a", Type: types.Counter},
+			},
+			wantIDs: []string{"a", "b"},
+		},
+		{
+			name: "Skip missing and mismatched type",
+			ids: []types.MetricID{
+				{ID: "c", Type: types.Gauge},
+				{ID: "a", Type: types.Gauge},
+				{ID: "missing", Type: types.Counter},
+			},
+			wantIDs: []string{"c"},
+		},
+		{
+			name:    "Empty request",
+			ids:     nil,
+			wantIDs: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := batchRepo.GetBatch(ctx, tt.ids)
+			assert.NoError(t, err)
+
+			var gotIDs []string
+			for _, m := range got {
+				gotIDs = append(gotIDs, m.ID)
+			}
+			assert.Equal(t, tt.wantIDs, gotIDs)
+		})
+	}
+}
+
+func TestMetricMemoryDeleteRepository_Delete(t *testing.T) {
+	clearMemory()
+	ctx := context.Background()
+	saveRepo := NewMetricMemorySaveRepository()
+	getRepo := NewMetricMemoryGetRepository()
+	deleteRepo := NewMetricMemoryDeleteRepository()
+
+	_ = saveRepo.Save(ctx, types.Metrics{ID: "a", Type: types.Counter, Delta: int64Ptr(1)})
+
+	tests := []struct {
+		name        string
+		id          types.MetricID
+		wantDeleted bool
+	}{
+		{
+			name:        "Delete existing metric",
+			id:          types.MetricID{ID: "a", Type: types.Counter},
+			wantDeleted: true,
+		},
+		{
+			name:        "Delete already deleted metric",
+			id:          types.MetricID{ID: "a", Type: types.Counter},
+			wantDeleted: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deleted, err := deleteRepo.Delete(ctx, tt.id)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantDeleted, deleted)
+
+			got, err := getRepo.Get(ctx, tt.id)
+			assert.NoError(t, err)
+			assert.Nil(t, got)
+		})
+	}
+}
+
+func TestMetricMemoryDeleteRepository_DeleteByPrefix(t *testing.T) {
+	clearMemory()
+	ctx := context.Background()
+	saveRepo := NewMetricMemorySaveRepository()
+	listRepo := NewMetricMemoryListRepository()
+	deleteRepo := NewMetricMemoryDeleteRepository()
+
+	metrics := []types.Metrics{
+		{ID: "CPUutilization2", Type: types.Gauge, Value: float64Ptr(2)},
+		{ID: "CPUutilization1", Type: types.Gauge, Value: float64Ptr(1)},
+		{ID: "PollCount", Type: types.Counter, Delta: int64Ptr(5)},
+	}
+	for _, m := range metrics {
+		_ = saveRepo.Save(ctx, m)
+	}
+
+	deleted, err := deleteRepo.DeleteByPrefix(ctx, "CPU")
+	assert.NoError(t, err)
+	assert.Equal(t, []types.MetricID{
+		{ID: "CPUutilization1", Type: types.Gauge},
+		{ID: "CPUutilization2", Type: types.Gauge},
+	}, deleted)
+
+	left, err := listRepo.List(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, left, 1)
+	assert.Equal(t, "PollCount", left[0].ID)
+
+	deleted, err = deleteRepo.DeleteByPrefix(ctx, "CPU")
+	assert.NoError(t, err)
+	assert.Empty(t, deleted)
+}
+
+func TestMetricMemoryResetRepository_Reset(t *testing.T) {
+	clearMemory()
+	ctx := context.Background()
+	saveRepo := NewMetricMemorySaveRepository()
+	getRepo := NewMetricMemoryGetRepository()
+	resetRepo := NewMetricMemoryResetRepository()
+
+	_ = saveRepo.Save(ctx, types.Metrics{ID: "PollCount", Type: types.Counter, Delta: int64Ptr(42)})
+	_ = saveRepo.Save(ctx, types.Metrics{ID: "Alloc", Type: types.Gauge, Value: float64Ptr(1.5)})
+
+	tests := []struct {
+		name      string
+		id        types.MetricID
+		wantReset bool
+	}{
+		{
+			name:      "Reset existing counter",
+			id:        types.MetricID{ID: "PollCount", Type: types.Counter},
+			wantReset: true,
+		},
+		{
+			name:      "Gauge is not reset",
+			id:        types.MetricID{ID: "Alloc", Type: types.Gauge},
+			wantReset: false,
+		},
+		{
+			name:      "Missing counter",
+			id:        types.MetricID{ID: "missing", Type: types.Counter},
+			wantReset: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reset, err := resetRepo.Reset(ctx, tt.id)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantReset, reset != nil)
+		})
+	}
+
+	got, err := getRepo.Get(ctx, types.MetricID{ID: "PollCount", Type: types.Counter})
+	assert.NoError(t, err)
+	if assert.NotNil(t, got) {
+		assert.Equal(t, int64(0), *got.Delta)
+	}
+
+	got, err = getRepo.Get(ctx, types.MetricID{ID: "Alloc", Type: types.Gauge})
+	assert.NoError(t, err)
+	if assert.NotNil(t, got) {
+		assert.Equal(t, 1.5, *got.Value)
+	}
+}
+
+func TestMetricMemoryApplyRepository_Apply(t *testing.T) {
+	clearMemory()
+	ctx := context.Background()
+	applyRepo := NewMetricMemoryApplyRepository()
+	getRepo := NewMetricMemoryGetRepository()
+
+	got, err := applyRepo.Apply(ctx, types.Metrics{ID: "Peak", Type: types.Gauge, Value: float64Ptr(10), Op: types.OpMax})
+	assert.NoError(t, err)
+	assert.Equal(t, 10.0, *got.Value)
+
+	got, err = applyRepo.Apply(ctx, types.Metrics{ID: "Peak", Type: types.Gauge, Value: float64Ptr(4), Op: types.OpMax})
+	assert.NoError(t, err)
+	assert.Equal(t, 10.0, *got.Value)
+
+	stored, err := getRepo.Get(ctx, types.MetricID{ID: "Peak", Type: types.Gauge})
+	assert.NoError(t, err)
+	assert.Equal(t, 10.0, *stored.Value)
+	assert.Empty(t, stored.Op)
+}
+
+func TestMetricMemoryApplyRepository_Version(t *testing.T) {
+	clearMemory()
+	ctx := context.Background()
+	applyRepo := NewMetricMemoryApplyRepository()
+
+	var versions []int64
+	apply := func(expected *int64) (*types.Metrics, error) {
+		got, err := applyRepo.Apply(ctx, types.Metrics{
+			ID:              "Alloc",
+			Type:            types.Gauge,
+			Value:           float64Ptr(1),
+			ExpectedVersion: expected,
+		})
+		if err == nil {
+			assert.Nil(t, got.ExpectedVersion)
+			versions = append(versions, got.Version)
+		}
+		return got, err
+	}
+
+	// Creating requires version 0
+	zero := int64(0)
+	created, err := apply(&zero)
+	require.NoError(t, err)
+	assert.Positive(t, created.Version)
+
+	updated, err := apply(nil)
+	require.NoError(t, err)
+	assert.Greater(t, updated.Version, created.Version)
+
+	_, err = apply(&created.Version)
+	assert.ErrorIs(t, err, types.ErrVersionConflict, "stale version conflicts")
+
+	_, err = apply(&updated.Version)
+	require.NoError(t, err)
+
+	// A metric deleted and created again never reuses a version
+	_, err = NewMetricMemoryDeleteRepository().Delete(ctx, types.MetricID{ID: "Alloc", Type: types.Gauge})
+	require.NoError(t, err)
+	_, err = apply(nil)
+	require.NoError(t, err)
+
+	assert.IsIncreasing(t, versions)
+}
+
+func TestMetricMemoryResetRepository_Version(t *testing.T) {
+	clearMemory()
+	ctx := context.Background()
+
+	applied, err := NewMetricMemoryApplyRepository().Apply(ctx, types.Metrics{ID: "PollCount", Type: types.Counter, Delta: int64Ptr(5)})
+	require.NoError(t, err)
+
+	reset, err := NewMetricMemoryResetRepository().Reset(ctx, types.MetricID{ID: "PollCount", Type: types.Counter})
+	require.NoError(t, err)
+	require.NotNil(t, reset)
+
+	got, err := NewMetricMemoryGetRepository().Get(ctx, types.MetricID{ID: "PollCount", Type: types.Counter})
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), *got.Delta)
+	assert.Greater(t, got.Version, applied.Version, "a reset changes the ETag")
+	assert.Equal(t, got.Version, reset.Version)
+}
+
+func TestMetricMemoryExpireRepository(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMetricMemoryExpireRepository()
+
+	cutoff := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	old := cutoff.Add(-time.Minute)
+	recent := cutoff.Add(time.Minute)
+	id := types.MetricID{ID: "Alloc", Type: types.Gauge}
+
+	store := func(m types.Metrics) {
+		clearMemory()
+		m.ID, m.Type = id.ID, id.Type
+		data[id] = m
+	}
+
+	// A metric updated after the sweep read it is kept
+	store(types.Metrics{Value: float64Ptr(2), UpdatedAt: &recent, Version: 5})
+	marked, err := repo.MarkStale(ctx, id, cutoff)
+	require.NoError(t, err)
+	assert.Nil(t, marked)
+	expired, err := repo.Expire(ctx, id, cutoff)
+	require.NoError(t, err)
+	assert.False(t, expired)
+	assert.False(t, data[id].Stale)
+
+	// A stale metric keeps its value and gets a new version when marked
+	store(types.Metrics{Value: float64Ptr(1), UpdatedAt: &old, Version: 5})
+	marked, err = repo.MarkStale(ctx, id, cutoff)
+	require.NoError(t, err)
+	require.NotNil(t, marked)
+	assert.True(t, marked.Stale)
+	assert.Equal(t, 1.0, *marked.Value)
+	assert.Greater(t, marked.Version, int64(5))
+	assert.Equal(t, *marked, data[id])
+
+	marked, err = repo.MarkStale(ctx, id, cutoff)
+	require.NoError(t, err)
+	assert.Nil(t, marked, "already stale")
+
+	expired, err = repo.Expire(ctx, id, cutoff)
+	require.NoError(t, err)
+	assert.True(t, expired)
+	assert.NotContains(t, data, id)
+
+	// Only metrics without an update time are stamped
+	store(types.Metrics{Value: float64Ptr(1)})
+	stamped, err := repo.Stamp(ctx, id, cutoff)
+	require.NoError(t, err)
+	assert.True(t, stamped)
+	assert.Equal(t, cutoff, *data[id].UpdatedAt)
+	stamped, err = repo.Stamp(ctx, id, recent)
+	require.NoError(t, err)
+	assert.False(t, stamped)
+	assert.Equal(t, cutoff, *data[id].UpdatedAt)
+}
@@ -0,0 +1,88 @@
+package services
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	"github.com/sbilibin2017/go-yandex-practicum/internal/types"
+)
+
+// Pinger defines an interface to check database connectivity.
+type Pinger interface {
+	PingContext(ctx context.Context) error
+}
+
+// HealthService reports the health of the configured metric storage.
+type HealthService struct {
+	db       Pinger
+	filePath string
+}
+
+// HealthServiceOption defines a functional option for configuring HealthService.
+type HealthServiceOption func(*HealthService)
+
+// WithHealthDB sets the database whose connectivity is checked.
+func WithHealthDB(db Pinger) HealthServiceOption {
+	return func(s *HealthService) {
+		s.db = db
+	}
+}
+
+// WithHealthFilePath sets the storage file whose writability is checked.
+func WithHealthFilePath(path string) HealthServiceOption {
+	return func(s *HealthService) {
+		s.filePath = path
+	}
+}
+
+// NewHealthService creates a new HealthService with the given options.
+func NewHealthService(opts ...HealthServiceOption) *HealthService {
+	s := &HealthService{}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Check returns the health of every configured storage component.
+// In-memory storage, used when nothing else is configured, is always healthy.
+func (s *HealthService) Check(ctx context.Context) []types.ComponentHealth {
+	var components []types.ComponentHealth
+	if s.db != nil {
+		components = append(components, componentHealth(types.HealthComponentDatabase, s.db.PingContext(ctx)))
+	}
+	if s.filePath != "" {
+		components = append(components, componentHealth(types.HealthComponentFile, checkWritable(s.filePath)))
+	}
+	if len(components) == 0 {
+		components = append(components, types.ComponentHealth{Name: types.HealthComponentMemory, Healthy: true})
+	}
+	return components
+}
+
+func componentHealth(name string, err error) types.ComponentHealth {
+	if err != nil {
+		return types.ComponentHealth{Name: name, Error: err.Error()}
+	}
+	return types.ComponentHealth{Name: name, Healthy: true}
+}
+
+// checkWritable verifies that path can be written without modifying it:
+// an existing file is opened for writing, and its directory must accept new files.
+func checkWritable(path string) error {
+	if _, err := os.Stat(path); err == nil {
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+		if err != nil {
+			return err
+		}
+		return f.Close()
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(path), ".health-*")
+	if err != nil {
+		return err
+	}
+	f.Close()
+	return os.Remove(f.Name())
+}
@@ -0,0 +1,101 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sbilibin2017/go-yandex-practicum/internal/services"
+	"github.com/sbilibin2017/go-yandex-practicum/internal/types"
+)
+
+func TestHealthService_Check(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	dir := t.TempDir()
+	existing := filepath.Join(dir, "metrics.json")
+	require.NoError(t, os.WriteFile(existing, []byte("[]"), 0644))
+	readOnly := filepath.Join(dir, "readonly.json")
+	require.NoError(t, os.WriteFile(readOnly, []byte("[]"), 0444))
+
+	tests := []struct {
+		name  string
+		setup func() []services.HealthServiceOption
+		want  []types.ComponentHealth
+	}{
+		{
+			name:  "memory storage",
+			setup: func() []services.HealthServiceOption { return nil },
+			want:  []types.ComponentHealth{{Name: types.HealthComponentMemory, Healthy: true}},
+		},
+		{
+			name: "database reachable",
+			setup: func() []services.HealthServiceOption {
+				db := services.NewMockPinger(ctrl)
+				db.EXPECT().PingContext(gomock.Any()).Return(nil)
+				return []services.HealthServiceOption{services.WithHealthDB(db)}
+			},
+			want: []types.ComponentHealth{{Name: types.HealthComponentDatabase, Healthy: true}},
+		},
+		{
+			name: "database unreachable",
+			setup: func() []services.HealthServiceOption {
+				db := services.NewMockPinger(ctrl)
+				db.EXPECT().PingContext(gomock.Any()).Return(errors.New("connection refused"))
+				return []services.HealthServiceOption{services.WithHealthDB(db)}
+			},
+			want: []types.ComponentHealth{{Name: types.HealthComponentDatabase, Error: "connection refused"}},
+		},
+		{
+			name: "existing file",
+			setup: func() []services.HealthServiceOption {
+				return []services.HealthServiceOption{services.WithHealthFilePath(existing)}
+			},
+			want: []types.ComponentHealth{{Name: types.HealthComponentFile, Healthy: true}},
+		},
+		{
+			name: "file not created yet",
+			setup: func() []services.HealthServiceOption {
+				return []services.HealthServiceOption{services.WithHealthFilePath(filepath.Join(dir, "new.json"))}
+			},
+			want: []types.ComponentHealth{{Name: types.HealthComponentFile, Healthy: true}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := services.NewHealthService(tt.setup()...)
+			assert.Equal(t, tt.want, svc.Check(context.Background()))
+		})
+	}
+
+	t.Run("unwritable file", func(t *testing.T) {
+		if os.Geteuid() == 0 {
+			t.Skip("root can write read-only files")
+		}
+		svc := services.NewHealthService(services.WithHealthFilePath(readOnly))
+		got := svc.Check(context.Background())
+		require.Len(t, got, 1)
+		assert.False(t, got[0].Healthy)
+	})
+
+	t.Run("missing directory", func(t *testing.T) {
+		svc := services.NewHealthService(services.WithHealthFilePath(filepath.Join(dir, "missing", "metrics.json")))
+		got := svc.Check(context.Background())
+		require.Len(t, got, 1)
+		assert.False(t, got[0].Healthy)
+		assert.NotEmpty(t, got[0].Error)
+	})
+
+	// The probe leaves no files behind.
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 2)
+}
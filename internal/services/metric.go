@@ -2,11 +2,25 @@ package services
 
 import (
 	"context"
+	"errors"
 	"sort"
+	"sync"
+	"time"
 
+	"github.com/sbilibin2017/go-yandex-practicum/internal/logger"
 	"github.com/sbilibin2017/go-yandex-practicum/internal/types"
 )
 
+// ErrInvalidOp is returned when an update operation is not supported for the metric type.
+var ErrInvalidOp = errors.New("unsupported metric operation")
+
+// ErrConditionalUnsupported is returned when a conditional update is requested
+// but the service has no Applier to perform it atomically.
+var ErrConditionalUnsupported = errors.New("conditional metric updates are not supported")
+
+// ErrEmptyPrefix is returned when a bulk delete is requested with an empty prefix.
+var ErrEmptyPrefix = errors.New("metric prefix must not be empty")
+
 // Getter defines an interface to get a metric by its MetricID.
 type Getter interface {
 	// Get fetches a metric by its ID and type.
@@ -19,16 +33,51 @@ type Saver interface {
 	Save(ctx context.Context, metric types.Metrics) error
 }
 
+// Applier defines an interface to apply an update operation atomically.
+type Applier interface {
+	// Apply applies the operation carried by the metric and returns the stored result.
+	Apply(ctx context.Context, metric types.Metrics) (*types.Metrics, error)
+}
+
+// Publisher defines an interface to announce metric changes to watchers.
+type Publisher interface {
+	// Publish delivers change events; it must not block the caller.
+	Publish(events ...types.MetricEvent)
+}
+
 // Lister defines an interface to list all metrics.
 type Lister interface {
 	// List returns all stored metrics.
 	List(ctx context.Context) ([]*types.Metrics, error)
 }
 
+// BatchGetter defines an interface to get several metrics by their MetricIDs at once.
+type BatchGetter interface {
+	// GetBatch fetches all existing metrics matching the given IDs.
+	GetBatch(ctx context.Context, ids []types.MetricID) ([]*types.Metrics, error)
+}
+
+// Deleter defines an interface to delete metrics.
+type Deleter interface {
+	// Delete removes a metric and reports whether it existed.
+	Delete(ctx context.Context, id types.MetricID) (bool, error)
+	// DeleteByPrefix removes all metrics whose ID starts with prefix.
+	DeleteByPrefix(ctx context.Context, prefix string) ([]types.MetricID, error)
+}
+
+// Resetter defines an interface to reset counters.
+type Resetter interface {
+	// Reset sets a counter back to zero and returns it, or nil when it does not exist.
+	Reset(ctx context.Context, id types.MetricID) (*types.Metrics, error)
+}
+
 // MetricUpdatesService provides methods to update metrics.
 type MetricUpdatesService struct {
-	getter Getter
-	saver  Saver
+	getter    Getter
+	saver     Saver
+	applier   Applier
+	publisher Publisher
+	now       func() time.Time
 }
 
 // MetricUpdatesServiceOption defines a functional option for configuring MetricUpdatesService.
@@ -48,6 +97,29 @@ func WithMetricUpdatesSaver(saver Saver) MetricUpdatesServiceOption {
 	}
 }
 
+// WithMetricUpdatesApplier sets the Applier dependency. When set, every metric is applied
+// through it, so versions are incremented atomically with the value.
+func WithMetricUpdatesApplier(applier Applier) MetricUpdatesServiceOption {
+	return func(svc *MetricUpdatesService) {
+		svc.applier = applier
+	}
+}
+
+// WithMetricUpdatesPublisher sets the Publisher notified about every updated metric.
+func WithMetricUpdatesPublisher(publisher Publisher) MetricUpdatesServiceOption {
+	return func(svc *MetricUpdatesService) {
+		svc.publisher = publisher
+	}
+}
+
+// WithMetricUpdatesClock sets the clock used to stamp the last update time of saved metrics.
+// When no clock is set, update times are not recorded.
+func WithMetricUpdatesClock(now func() time.Time) MetricUpdatesServiceOption {
+	return func(svc *MetricUpdatesService) {
+		svc.now = now
+	}
+}
+
 // NewMetricUpdatesService creates a new MetricUpdatesService with the provided options.
 func NewMetricUpdatesService(opts ...MetricUpdatesServiceOption) *MetricUpdatesService {
 	svc := &MetricUpdatesService{}
@@ -58,6 +130,9 @@ func NewMetricUpdatesService(opts ...MetricUpdatesServiceOption) *MetricUpdatesS
 }
 
 // Updates updates or adds the provided metrics, returning the updated metrics.
+// When an Applier is set, metrics are applied atomically by it and may carry an explicit Op
+// or an ExpectedVersion; without one, metrics keep the default semantics (set for gauges,
+// add for counters) via Get and Save.
 func (svc *MetricUpdatesService) Updates(
 	ctx context.Context,
 	metrics []*types.Metrics,
@@ -65,6 +140,29 @@ func (svc *MetricUpdatesService) Updates(
 	metricsMap := make(map[types.MetricID]*types.Metrics)
 
 	for _, m := range metrics {
+		if svc.applier != nil || m.Op != "" || m.ExpectedVersion != nil {
+			if !types.IsValidOp(m.Type, m.Op) {
+				return nil, ErrInvalidOp
+			}
+			if svc.applier == nil {
+				return nil, ErrConditionalUnsupported
+			}
+
+			m.Stale = false
+			if svc.now != nil {
+				updatedAt := svc.now()
+				m.UpdatedAt = &updatedAt
+			}
+
+			result, err := svc.applier.Apply(ctx, *m)
+			if err != nil {
+				return nil, err
+			}
+
+			metricsMap[types.MetricID{ID: m.ID, Type: m.Type}] = result
+			continue
+		}
+
 		if m.Type == types.Counter {
 			current, err := svc.getter.Get(ctx, types.MetricID{ID: m.ID, Type: m.Type})
 			if err != nil {
@@ -85,6 +183,12 @@ func (svc *MetricUpdatesService) Updates(
 			m.Delta = &totalDelta
 		}
 
+		m.Stale = false
+		if svc.now != nil {
+			updatedAt := svc.now()
+			m.UpdatedAt = &updatedAt
+		}
+
 		err := svc.saver.Save(ctx, *m)
 		if err != nil {
 			return nil, err
@@ -102,6 +206,14 @@ func (svc *MetricUpdatesService) Updates(
 		return updatedMetrics[i].ID < updatedMetrics[j].ID
 	})
 
+	if svc.publisher != nil {
+		events := make([]types.MetricEvent, 0, len(updatedMetrics))
+		for _, m := range updatedMetrics {
+			events = append(events, types.MetricEvent{Kind: types.MetricEventUpdated, Metric: *m})
+		}
+		svc.publisher.Publish(events...)
+	}
+
 	return updatedMetrics, nil
 }
 
@@ -139,6 +251,8 @@ func (svc *MetricGetService) Get(
 // MetricListService provides method to list all metrics.
 type MetricListService struct {
 	lister Lister
+	policy types.TTLPolicy
+	now    func() time.Time
 }
 
 // MetricListServiceOption defines a functional option for configuring MetricListService.
@@ -151,6 +265,20 @@ func WithMetricListLister(lister Lister) MetricListServiceOption {
 	}
 }
 
+// WithMetricListTTLPolicy sets the TTL policy used to flag stale metrics in list results.
+func WithMetricListTTLPolicy(policy types.TTLPolicy) MetricListServiceOption {
+	return func(svc *MetricListService) {
+		svc.policy = policy
+	}
+}
+
+// WithMetricListClock sets the clock used to evaluate staleness.
+func WithMetricListClock(now func() time.Time) MetricListServiceOption {
+	return func(svc *MetricListService) {
+		svc.now = now
+	}
+}
+
 // NewMetricListService creates a new MetricListService with the provided options.
 func NewMetricListService(opts ...MetricListServiceOption) *MetricListService {
 	svc := &MetricListService{}
@@ -161,6 +289,331 @@ func NewMetricListService(opts ...MetricListServiceOption) *MetricListService {
 }
 
 // List returns all stored metrics.
+// Metrics not updated within their TTL are flagged as stale even before the expiry worker runs.
 func (svc *MetricListService) List(ctx context.Context) ([]*types.Metrics, error) {
-	return svc.lister.List(ctx)
+	metrics, err := svc.lister.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if svc.policy.Enabled() {
+		now := time.Now
+		if svc.now != nil {
+			now = svc.now
+		}
+		t := now()
+		for _, m := range metrics {
+			if svc.policy.IsStale(m, t) {
+				m.Stale = true
+			}
+		}
+	}
+
+	return metrics, nil
+}
+
+// MetricGetBatchService provides method to get several metrics in one call.
+type MetricGetBatchService struct {
+	batchGetter BatchGetter
+}
+
+// MetricGetBatchServiceOption defines a functional option for configuring MetricGetBatchService.
+type MetricGetBatchServiceOption func(*MetricGetBatchService)
+
+// WithMetricGetBatchGetter sets the BatchGetter dependency for MetricGetBatchService.
+func WithMetricGetBatchGetter(batchGetter BatchGetter) MetricGetBatchServiceOption {
+	return func(svc *MetricGetBatchService) {
+		svc.batchGetter = batchGetter
+	}
+}
+
+// NewMetricGetBatchService creates a new MetricGetBatchService with the provided options.
+func NewMetricGetBatchService(opts ...MetricGetBatchServiceOption) *MetricGetBatchService {
+	svc := &MetricGetBatchService{}
+	for _, opt := range opts {
+		opt(svc)
+	}
+	return svc
+}
+
+// GetBatch fetches the metrics matching the given MetricIDs with a single repository call.
+// It returns the found metrics and the IDs that were not found, in request order.
+// Duplicate IDs are looked up once.
+func (svc *MetricGetBatchService) GetBatch(
+	ctx context.Context, metricIDs []types.MetricID,
+) ([]*types.Metrics, []types.MetricID, error) {
+	uniqueIDs := make([]types.MetricID, 0, len(metricIDs))
+	seen := make(map[types.MetricID]struct{}, len(metricIDs))
+	for _, id := range metricIDs {
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		uniqueIDs = append(uniqueIDs, id)
+	}
+
+	metrics, err := svc.batchGetter.GetBatch(ctx, uniqueIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	found := make(map[types.MetricID]struct{}, len(metrics))
+	for _, m := range metrics {
+		found[types.MetricID{ID: m.ID, Type: m.Type}] = struct{}{}
+	}
+
+	notFound := make([]types.MetricID, 0)
+	for _, id := range uniqueIDs {
+		if _, ok := found[id]; !ok {
+			notFound = append(notFound, id)
+		}
+	}
+
+	return metrics, notFound, nil
+}
+
+// MetricDeleteService provides methods to delete metrics.
+type MetricDeleteService struct {
+	deleter   Deleter
+	publisher Publisher
+}
+
+// MetricDeleteServiceOption defines a functional option for configuring MetricDeleteService.
+type MetricDeleteServiceOption func(*MetricDeleteService)
+
+// WithMetricDeleteDeleter sets the Deleter dependency for MetricDeleteService.
+func WithMetricDeleteDeleter(deleter Deleter) MetricDeleteServiceOption {
+	return func(svc *MetricDeleteService) {
+		svc.deleter = deleter
+	}
+}
+
+// WithMetricDeletePublisher sets the Publisher notified about every deleted metric.
+func WithMetricDeletePublisher(publisher Publisher) MetricDeleteServiceOption {
+	return func(svc *MetricDeleteService) {
+		svc.publisher = publisher
+	}
+}
+
+// NewMetricDeleteService creates a new MetricDeleteService with the provided options.
+func NewMetricDeleteService(opts ...MetricDeleteServiceOption) *MetricDeleteService {
+	svc := &MetricDeleteService{}
+	for _, opt := range opts {
+		opt(svc)
+	}
+	return svc
+}
+
+// Delete removes a metric by its MetricID and reports whether it existed.
+func (svc *MetricDeleteService) Delete(
+	ctx context.Context, metricID types.MetricID,
+) (bool, error) {
+	deleted, err := svc.deleter.Delete(ctx, metricID)
+	if err != nil {
+		logger.Log.Errorw("MetricDeleteService: delete failed", "id", metricID.ID, "type", metricID.Type, "error", err)
+		return false, err
+	}
+	if deleted {
+		logger.Log.Infow("MetricDeleteService: metric deleted", "id", metricID.ID, "type", metricID.Type)
+		svc.publishDeleted(metricID)
+	}
+	return deleted, nil
+}
+
+// DeleteByPrefix removes all metrics whose ID starts with prefix and returns their IDs.
+// An empty prefix is rejected with ErrEmptyPrefix so that a single call cannot wipe the storage.
+func (svc *MetricDeleteService) DeleteByPrefix(
+	ctx context.Context, prefix string,
+) ([]types.MetricID, error) {
+	if prefix == "" {
+		return nil, ErrEmptyPrefix
+	}
+
+	deleted, err := svc.deleter.DeleteByPrefix(ctx, prefix)
+	if err != nil {
+		logger.Log.Errorw("MetricDeleteService: delete by prefix failed", "prefix", prefix, "error", err)
+		return nil, err
+	}
+	logger.Log.Infow("MetricDeleteService: metrics deleted by prefix", "prefix", prefix, "count", len(deleted))
+	svc.publishDeleted(deleted...)
+	return deleted, nil
+}
+
+func (svc *MetricDeleteService) publishDeleted(ids ...types.MetricID) {
+	if svc.publisher == nil || len(ids) == 0 {
+		return
+	}
+	events := make([]types.MetricEvent, 0, len(ids))
+	for _, id := range ids {
+		events = append(events, types.MetricEvent{
+			Kind:   types.MetricEventDeleted,
+			Metric: types.Metrics{ID: id.ID, Type: id.Type},
+		})
+	}
+	svc.publisher.Publish(events...)
+}
+
+// MetricResetService provides method to reset counters.
+type MetricResetService struct {
+	resetter  Resetter
+	publisher Publisher
+}
+
+// MetricResetServiceOption defines a functional option for configuring MetricResetService.
+type MetricResetServiceOption func(*MetricResetService)
+
+// WithMetricResetResetter sets the Resetter dependency for MetricResetService.
+func WithMetricResetResetter(resetter Resetter) MetricResetServiceOption {
+	return func(svc *MetricResetService) {
+		svc.resetter = resetter
+	}
+}
+
+// WithMetricResetPublisher sets the Publisher notified about every reset counter.
+func WithMetricResetPublisher(publisher Publisher) MetricResetServiceOption {
+	return func(svc *MetricResetService) {
+		svc.publisher = publisher
+	}
+}
+
+// NewMetricResetService creates a new MetricResetService with the provided options.
+func NewMetricResetService(opts ...MetricResetServiceOption) *MetricResetService {
+	svc := &MetricResetService{}
+	for _, opt := range opts {
+		opt(svc)
+	}
+	return svc
+}
+
+// Reset sets a counter back to zero and reports whether it existed.
+func (svc *MetricResetService) Reset(
+	ctx context.Context, metricID types.MetricID,
+) (bool, error) {
+	metric, err := svc.resetter.Reset(ctx, metricID)
+	if err != nil {
+		logger.Log.Errorw("MetricResetService: reset failed", "id", metricID.ID, "type", metricID.Type, "error", err)
+		return false, err
+	}
+	if metric == nil {
+		return false, nil
+	}
+	logger.Log.Infow("MetricResetService: counter reset", "id", metricID.ID, "type", metricID.Type)
+	if svc.publisher != nil {
+		svc.publisher.Publish(types.MetricEvent{Kind: types.MetricEventUpdated, Metric: *metric})
+	}
+	return true, nil
+}
+
+// MetricWatchService fans metric change events out to subscribers.
+// It implements Publisher and is shared by the services that modify metrics.
+type MetricWatchService struct {
+	mu     sync.RWMutex
+	subs   map[*metricSubscriber]struct{}
+	buffer int
+	closed bool
+}
+
+type metricSubscriber struct {
+	filter types.MetricFilter
+	events chan types.MetricEvent
+}
+
+// MetricWatchServiceOption defines a functional option for configuring MetricWatchService.
+type MetricWatchServiceOption func(*MetricWatchService)
+
+// WithMetricWatchBuffer sets how many events may be queued per subscriber.
+// Sizes below one are raised to one.
+func WithMetricWatchBuffer(size int) MetricWatchServiceOption {
+	return func(svc *MetricWatchService) {
+		svc.buffer = size
+	}
+}
+
+// NewMetricWatchService creates a new MetricWatchService with the provided options.
+// The per-subscriber buffer defaults to 64 events.
+func NewMetricWatchService(opts ...MetricWatchServiceOption) *MetricWatchService {
+	svc := &MetricWatchService{
+		subs:   make(map[*metricSubscriber]struct{}),
+		buffer: 64,
+	}
+	for _, opt := range opts {
+		opt(svc)
+	}
+	if svc.buffer < 1 {
+		svc.buffer = 1
+	}
+	return svc
+}
+
+// Watch subscribes to events for metrics matching filter.
+// The returned channel is closed once ctx is done or the service is closed.
+// A subscriber that falls more than the buffer size behind receives a final
+// MetricEventOverflow event before its channel is closed.
+func (svc *MetricWatchService) Watch(
+	ctx context.Context, filter types.MetricFilter,
+) <-chan types.MetricEvent {
+	sub := &metricSubscriber{
+		filter: filter,
+		// One extra slot keeps room for the overflow event.
+		events: make(chan types.MetricEvent, svc.buffer+1),
+	}
+
+	svc.mu.Lock()
+	defer svc.mu.Unlock()
+
+	if svc.closed {
+		close(sub.events)
+		return sub.events
+	}
+	svc.subs[sub] = struct{}{}
+
+	go func() {
+		<-ctx.Done()
+		svc.mu.Lock()
+		defer svc.mu.Unlock()
+		if _, ok := svc.subs[sub]; ok {
+			delete(svc.subs, sub)
+			close(sub.events)
+		}
+	}()
+
+	return sub.events
+}
+
+// Close ends all subscriptions, so long-lived watch streams finish on shutdown.
+func (svc *MetricWatchService) Close() {
+	svc.mu.Lock()
+	defer svc.mu.Unlock()
+
+	svc.closed = true
+	for sub := range svc.subs {
+		delete(svc.subs, sub)
+		close(sub.events)
+	}
+}
+
+// Publish delivers events to every matching subscriber without blocking.
+// A subscriber whose buffer is full would miss the event, so instead its
+// subscription ends with a MetricEventOverflow event and it has to resubscribe.
+// This way a slow watcher never delays metric updates nor silently loses changes.
+func (svc *MetricWatchService) Publish(events ...types.MetricEvent) {
+	svc.mu.Lock()
+	defer svc.mu.Unlock()
+
+	for sub := range svc.subs {
+		for _, event := range events {
+			if !sub.filter.Match(types.MetricID{ID: event.Metric.ID, Type: event.Metric.Type}) {
+				continue
+			}
+			if len(sub.events) >= svc.buffer {
+				logger.Log.Warnw("MetricWatchService: subscriber is lagging, subscription ended",
+					"id", event.Metric.ID, "type", event.Metric.Type)
+				sub.events <- types.MetricEvent{Kind: types.MetricEventOverflow}
+				delete(svc.subs, sub)
+				close(sub.events)
+				break
+			}
+			sub.events <- event
+		}
+	}
 }
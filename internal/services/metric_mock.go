@@ -87,6 +87,83 @@ func (mr *MockSaverMockRecorder) Save(ctx, metric interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Save", reflect.TypeOf((*MockSaver)(nil).Save), ctx, metric)
 }
 
+// MockApplier is a mock of Applier interface.
+type MockApplier struct {
+	ctrl     *gomock.Controller
+	recorder *MockApplierMockRecorder
+}
+
+// MockApplierMockRecorder is the mock recorder for MockApplier.
+type MockApplierMockRecorder struct {
+	mock *MockApplier
+}
+
+// NewMockApplier creates a new mock instance.
+func NewMockApplier(ctrl *gomock.Controller) *MockApplier {
+	mock := &MockApplier{ctrl: ctrl}
+	mock.recorder = &MockApplierMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockApplier) EXPECT() *MockApplierMockRecorder {
+	return m.recorder
+}
+
+// Apply mocks base method.
+func (m *MockApplier) Apply(ctx context.Context, metric types.Metrics) (*types.Metrics, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Apply", ctx, metric)
+	ret0, _ := ret[0].(*types.Metrics)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Apply indicates an expected call of Apply.
+func (mr *MockApplierMockRecorder) Apply(ctx, metric interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Apply", reflect.TypeOf((*MockApplier)(nil).Apply), ctx, metric)
+}
+
+// MockPublisher is a mock of Publisher interface.
+type MockPublisher struct {
+	ctrl     *gomock.Controller
+	recorder *MockPublisherMockRecorder
+}
+
+// MockPublisherMockRecorder is the mock recorder for MockPublisher.
+type MockPublisherMockRecorder struct {
+	mock *MockPublisher
+}
+
+// NewMockPublisher creates a new mock instance.
+func NewMockPublisher(ctrl *gomock.Controller) *MockPublisher {
+	mock := &MockPublisher{ctrl: ctrl}
+	mock.recorder = &MockPublisherMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPublisher) EXPECT() *MockPublisherMockRecorder {
+	return m.recorder
+}
+
+// Publish mocks base method.
+func (m *MockPublisher) Publish(events ...types.MetricEvent) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{}
+	for _, a := range events {
+		varargs = append(varargs, a)
+	}
+	m.ctrl.Call(m, "Publish", varargs...)
+}
+
+// Publish indicates an expected call of Publish.
+func (mr *MockPublisherMockRecorder) Publish(events ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Publish", reflect.TypeOf((*MockPublisher)(nil).Publish), events...)
+}
+
 // MockLister is a mock of Lister interface.
 type MockLister struct {
 	ctrl     *gomock.Controller
@@ -124,3 +201,132 @@ func (mr *MockListerMockRecorder) List(ctx interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockLister)(nil).List), ctx)
 }
+
+// MockBatchGetter is a mock of BatchGetter interface.
+type MockBatchGetter struct {
+	ctrl     *gomock.Controller
+	recorder *MockBatchGetterMockRecorder
+}
+
+// MockBatchGetterMockRecorder is the mock recorder for MockBatchGetter.
+type MockBatchGetterMockRecorder struct {
+	mock *MockBatchGetter
+}
+
+// NewMockBatchGetter creates a new mock instance.
+func NewMockBatchGetter(ctrl *gomock.Controller) *MockBatchGetter {
+	mock := &MockBatchGetter{ctrl: ctrl}
+	mock.recorder = &MockBatchGetterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockBatchGetter) EXPECT() *MockBatchGetterMockRecorder {
+	return m.recorder
+}
+
+// GetBatch mocks base method.
+func (m *MockBatchGetter) GetBatch(ctx context.Context, ids []types.MetricID) ([]*types.Metrics, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBatch", ctx, ids)
+	ret0, _ := ret[0].([]*types.Metrics)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBatch indicates an expected call of GetBatch.
+func (mr *MockBatchGetterMockRecorder) GetBatch(ctx, ids interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBatch", reflect.TypeOf((*MockBatchGetter)(nil).GetBatch), ctx, ids)
+}
+
+// MockDeleter is a mock of Deleter interface.
+type MockDeleter struct {
+	ctrl     *gomock.Controller
+	recorder *MockDeleterMockRecorder
+}
+
+// MockDeleterMockRecorder is the mock recorder for MockDeleter.
+type MockDeleterMockRecorder struct {
+	mock *MockDeleter
+}
+
+// NewMockDeleter creates a new mock instance.
+func NewMockDeleter(ctrl *gomock.Controller) *MockDeleter {
+	mock := &MockDeleter{ctrl: ctrl}
+	mock.recorder = &MockDeleterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDeleter) EXPECT() *MockDeleterMockRecorder {
+	return m.recorder
+}
+
+// Delete mocks base method.
+func (m *MockDeleter) Delete(ctx context.Context, id types.MetricID) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, id)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockDeleterMockRecorder) Delete(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockDeleter)(nil).Delete), ctx, id)
+}
+
+// DeleteByPrefix mocks base method.
+func (m *MockDeleter) DeleteByPrefix(ctx context.Context, prefix string) ([]types.MetricID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteByPrefix", ctx, prefix)
+	ret0, _ := ret[0].([]types.MetricID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteByPrefix indicates an expected call of DeleteByPrefix.
+func (mr *MockDeleterMockRecorder) DeleteByPrefix(ctx, prefix interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteByPrefix", reflect.TypeOf((*MockDeleter)(nil).DeleteByPrefix), ctx, prefix)
+}
+
+// MockResetter is a mock of Resetter interface.
+type MockResetter struct {
+	ctrl     *gomock.Controller
+	recorder *MockResetterMockRecorder
+}
+
+// MockResetterMockRecorder is the mock recorder for MockResetter.
+type MockResetterMockRecorder struct {
+	mock *MockResetter
+}
+
+// NewMockResetter creates a new mock instance.
+func NewMockResetter(ctrl *gomock.Controller) *MockResetter {
+	mock := &MockResetter{ctrl: ctrl}
+	mock.recorder = &MockResetterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockResetter) EXPECT() *MockResetterMockRecorder {
+	return m.recorder
+}
+
+// Reset mocks base method.
+func (m *MockResetter) Reset(ctx context.Context, id types.MetricID) (*types.Metrics, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reset", ctx, id)
+	ret0, _ := ret[0].(*types.Metrics)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Reset indicates an expected call of Reset.
+func (mr *MockResetterMockRecorder) Reset(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reset", reflect.TypeOf((*MockResetter)(nil).Reset), ctx, id)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/require"
@@ -298,7 +299,545 @@ func TestMetricListService_List(t *testing.T) {
 	}
 }
 
+func TestMetricGetBatchService_GetBatch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockBatchGetter := services.NewMockBatchGetter(ctrl)
+
+	svc := services.NewMetricGetBatchService(services.WithMetricGetBatchGetter(mockBatchGetter))
+
+	tests := []struct {
+		name         string
+		metricIDs    []types.MetricID
+		expectedIDs  []types.MetricID
+		mockReturn   []*types.Metrics
+		mockErr      error
+		wantMetrics  []*types.Metrics
+		wantNotFound []types.MetricID
+		wantErr      bool
+	}{
+		{
+			name: "all found",
+			metricIDs: []types.MetricID{
+				{ID: "m1", Type: types.Gauge},
+				{ID: "m2", Type: types.Counter},
+			},
+			expectedIDs: []types.MetricID{
+				{ID: "m1", Type: types.Gauge},
+				{ID: "m2", Type: types.Counter},
+			},
+			mockReturn: []*types.Metrics{
+				{ID: "m1", Type: types.Gauge, Value: ptrFloat64(1.0)},
+				{ID: "m2", Type: types.Counter, Delta: ptrInt64(2)},
+			},
+			wantMetrics: []*types.Metrics{
+				{ID: "m1", Type: types.Gauge, Value: ptrFloat64(1.0)},
+				{ID: "m2", Type: types.Counter, Delta: ptrInt64(2)},
+			},
+			wantNotFound: []types.MetricID{},
+		},
+		{
+			name: "duplicates collapsed and missing reported",
+			metricIDs: []types.MetricID{
+				{ID: "m1", Type: types.Gauge},
+				{ID: "m3", Type: types.Gauge},
+				{ID: "m1", Type: types.Gauge},
+				{ID: "m1", Type: types.Counter},
+			},
+			expectedIDs: []types.MetricID{
+				{ID: "m1", Type: types.Gauge},
+				{ID: "m3", Type: types.Gauge},
+				{ID: "m1", Type: types.Counter},
+			},
+			mockReturn: []*types.Metrics{
+				{ID: "m1", Type: types.Gauge, Value: ptrFloat64(1.0)},
+			},
+			wantMetrics: []*types.Metrics{
+				{ID: "m1", Type: types.Gauge, Value: ptrFloat64(1.0)},
+			},
+			wantNotFound: []types.MetricID{
+				{ID: "m3", Type: types.Gauge},
+				{ID: "m1", Type: types.Counter},
+			},
+		},
+		{
+			name:        "batch getter error",
+			metricIDs:   []types.MetricID{{ID: "m1", Type: types.Gauge}},
+			expectedIDs: []types.MetricID{{ID: "m1", Type: types.Gauge}},
+			mockErr:     errors.New("db failure"),
+			wantErr:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockBatchGetter.EXPECT().
+				GetBatch(gomock.Any(), tt.expectedIDs).
+				Return(tt.mockReturn, tt.mockErr)
+
+			metrics, notFound, err := svc.GetBatch(context.Background(), tt.metricIDs)
+			if tt.wantErr {
+				require.Error(t, err)
+				require.Nil(t, metrics)
+				require.Nil(t, notFound)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.wantMetrics, metrics)
+			require.Equal(t, tt.wantNotFound, notFound)
+		})
+	}
+}
+
 // Helpers
 
 func ptrInt64(i int64) *int64       { return &i }
 func ptrFloat64(f float64) *float64 { return &f }
+
+func TestMetricDeleteService_Delete(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDeleter := services.NewMockDeleter(ctrl)
+
+	svc := services.NewMetricDeleteService(services.WithMetricDeleteDeleter(mockDeleter))
+
+	id := types.MetricID{ID: "CPUutilization7", Type: types.Gauge}
+
+	tests := []struct {
+		name        string
+		mockDeleted bool
+		mockErr     error
+		wantDeleted bool
+		wantErr     bool
+	}{
+		{
+			name:        "deleted",
+			mockDeleted: true,
+			wantDeleted: true,
+		},
+		{
+			name:        "not found",
+			mockDeleted: false,
+			wantDeleted: false,
+		},
+		{
+			name:    "repository error",
+			mockErr: errors.New("delete failed"),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDeleter.EXPECT().Delete(gomock.Any(), id).Return(tt.mockDeleted, tt.mockErr)
+
+			deleted, err := svc.Delete(context.Background(), id)
+			if tt.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Equal(t, tt.wantDeleted, deleted)
+		})
+	}
+}
+
+func TestMetricDeleteService_DeleteByPrefix(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDeleter := services.NewMockDeleter(ctrl)
+
+	svc := services.NewMetricDeleteService(services.WithMetricDeleteDeleter(mockDeleter))
+
+	deletedIDs := []types.MetricID{{ID: "CPUutilization7", Type: types.Gauge}}
+
+	tests := []struct {
+		name      string
+		prefix    string
+		mockSetup func()
+		wantIDs   []types.MetricID
+		wantErr   error
+	}{
+		{
+			name:   "deleted by prefix",
+			prefix: "CPU",
+			mockSetup: func() {
+				mockDeleter.EXPECT().DeleteByPrefix(gomock.Any(), "CPU").Return(deletedIDs, nil)
+			},
+			wantIDs: deletedIDs,
+		},
+		{
+			name:      "empty prefix rejected",
+			prefix:    "",
+			mockSetup: func() {},
+			wantErr:   services.ErrEmptyPrefix,
+		},
+		{
+			name:   "repository error",
+			prefix: "CPU",
+			mockSetup: func() {
+				mockDeleter.EXPECT().DeleteByPrefix(gomock.Any(), "CPU").Return(nil, errors.New("delete failed"))
+			},
+			wantErr: errors.New("delete failed"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			got, err := svc.DeleteByPrefix(context.Background(), tt.prefix)
+			if tt.wantErr != nil {
+				require.EqualError(t, err, tt.wantErr.Error())
+				require.Nil(t, got)
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, tt.wantIDs, got)
+			}
+		})
+	}
+}
+
+func TestMetricResetService_Reset(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockResetter := services.NewMockResetter(ctrl)
+
+	svc := services.NewMetricResetService(services.WithMetricResetResetter(mockResetter))
+
+	id := types.MetricID{ID: "PollCount", Type: types.Counter}
+
+	tests := []struct {
+		name      string
+		mockReset *types.Metrics
+		mockErr   error
+		wantReset bool
+		wantErr   bool
+	}{
+		{
+			name:      "reset",
+			mockReset: &types.Metrics{ID: "PollCount", Type: types.Counter, Delta: ptrInt64(0), Version: 2},
+			wantReset: true,
+		},
+		{
+			name:      "not found",
+			wantReset: false,
+		},
+		{
+			name:    "repository error",
+			mockErr: errors.New("reset failed"),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockResetter.EXPECT().Reset(gomock.Any(), id).Return(tt.mockReset, tt.mockErr)
+
+			reset, err := svc.Reset(context.Background(), id)
+			if tt.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Equal(t, tt.wantReset, reset)
+		})
+	}
+}
+
+func TestMetricUpdatesService_Updates_StampsUpdateTime(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	getter := services.NewMockGetter(ctrl)
+	saver := services.NewMockSaver(ctrl)
+
+	svc := services.NewMetricUpdatesService(
+		services.WithMetricUpdatesGetter(getter),
+		services.WithMetricUpdatesSaver(saver),
+		services.WithMetricUpdatesClock(func() time.Time { return now }),
+	)
+
+	saver.EXPECT().
+		Save(gomock.Any(), types.Metrics{
+			ID:        "Alloc",
+			Type:      types.Gauge,
+			Value:     ptrFloat64(1.5),
+			UpdatedAt: &now,
+		}).
+		Return(nil)
+
+	got, err := svc.Updates(context.Background(), []*types.Metrics{
+		{ID: "Alloc", Type: types.Gauge, Value: ptrFloat64(1.5), Stale: true},
+	})
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	require.Equal(t, &now, got[0].UpdatedAt)
+	require.False(t, got[0].Stale)
+}
+
+func TestMetricListService_List_FlagsStale(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	old := now.Add(-time.Hour)
+	fresh := now.Add(-time.Second)
+
+	mockLister := services.NewMockLister(ctrl)
+
+	svc := services.NewMetricListService(
+		services.WithMetricListLister(mockLister),
+		services.WithMetricListTTLPolicy(types.TTLPolicy{Default: time.Minute}),
+		services.WithMetricListClock(func() time.Time { return now }),
+	)
+
+	mockLister.EXPECT().List(gomock.Any()).Return([]*types.Metrics{
+		{ID: "m1", Type: types.Gauge, Value: ptrFloat64(1.0), UpdatedAt: &old},
+		{ID: "m2", Type: types.Gauge, Value: ptrFloat64(2.0), UpdatedAt: &fresh},
+		{ID: "m3", Type: types.Gauge, Value: ptrFloat64(3.0)},
+	}, nil)
+
+	got, err := svc.List(context.Background())
+	require.NoError(t, err)
+	require.Len(t, got, 3)
+	require.True(t, got[0].Stale)
+	require.False(t, got[1].Stale)
+	require.False(t, got[2].Stale)
+}
+
+func TestMetricUpdatesService_Updates_WithOp(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	getter := services.NewMockGetter(ctrl)
+	saver := services.NewMockSaver(ctrl)
+	applier := services.NewMockApplier(ctrl)
+
+	svc := services.NewMetricUpdatesService(
+		services.WithMetricUpdatesGetter(getter),
+		services.WithMetricUpdatesSaver(saver),
+		services.WithMetricUpdatesApplier(applier),
+	)
+
+	tests := []struct {
+		name      string
+		metrics   []*types.Metrics
+		mockSetup func()
+		want      []*types.Metrics
+		wantErr   error
+	}{
+		{
+			name: "gauge max applied atomically",
+			metrics: []*types.Metrics{
+				{ID: "peak", Type: types.Gauge, Value: ptrFloat64(5), Op: types.OpMax},
+			},
+			mockSetup: func() {
+				applier.EXPECT().
+					Apply(gomock.Any(), types.Metrics{ID: "peak", Type: types.Gauge, Value: ptrFloat64(5), Op: types.OpMax}).
+					Return(&types.Metrics{ID: "peak", Type: types.Gauge, Value: ptrFloat64(9)}, nil)
+			},
+			want: []*types.Metrics{
+				{ID: "peak", Type: types.Gauge, Value: ptrFloat64(9)},
+			},
+		},
+		{
+			name: "counter set applied atomically",
+			metrics: []*types.Metrics{
+				{ID: "PollCount", Type: types.Counter, Delta: ptrInt64(0), Op: types.OpSet},
+			},
+			mockSetup: func() {
+				applier.EXPECT().
+					Apply(gomock.Any(), types.Metrics{ID: "PollCount", Type: types.Counter, Delta: ptrInt64(0), Op: types.OpSet}).
+					Return(&types.Metrics{ID: "PollCount", Type: types.Counter, Delta: ptrInt64(0)}, nil)
+			},
+			want: []*types.Metrics{
+				{ID: "PollCount", Type: types.Counter, Delta: ptrInt64(0)},
+			},
+		},
+		{
+			name: "default operation goes through applier",
+			metrics: []*types.Metrics{
+				{ID: "PollCount", Type: types.Counter, Delta: ptrInt64(2)},
+			},
+			mockSetup: func() {
+				applier.EXPECT().
+					Apply(gomock.Any(), types.Metrics{ID: "PollCount", Type: types.Counter, Delta: ptrInt64(2)}).
+					Return(&types.Metrics{ID: "PollCount", Type: types.Counter, Delta: ptrInt64(7), Version: 4}, nil)
+			},
+			want: []*types.Metrics{
+				{ID: "PollCount", Type: types.Counter, Delta: ptrInt64(7), Version: 4},
+			},
+		},
+		{
+			name: "version conflict",
+			metrics: []*types.Metrics{
+				{ID: "peak", Type: types.Gauge, Value: ptrFloat64(5), ExpectedVersion: ptrInt64(3)},
+			},
+			mockSetup: func() {
+				applier.EXPECT().
+					Apply(gomock.Any(), types.Metrics{ID: "peak", Type: types.Gauge, Value: ptrFloat64(5), ExpectedVersion: ptrInt64(3)}).
+					Return(nil, types.ErrVersionConflict)
+			},
+			wantErr: types.ErrVersionConflict,
+		},
+		{
+			name: "unsupported operation",
+			metrics: []*types.Metrics{
+				{ID: "PollCount", Type: types.Counter, Delta: ptrInt64(1), Op: types.OpMin},
+			},
+			mockSetup: func() {},
+			wantErr:   services.ErrInvalidOp,
+		},
+		{
+			name: "applier error",
+			metrics: []*types.Metrics{
+				{ID: "peak", Type: types.Gauge, Value: ptrFloat64(5), Op: types.OpMin},
+			},
+			mockSetup: func() {
+				applier.EXPECT().
+					Apply(gomock.Any(), gomock.Any()).
+					Return(nil, errors.New("apply failed"))
+			},
+			wantErr: errors.New("apply failed"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			got, err := svc.Updates(context.Background(), tt.metrics)
+			if tt.wantErr != nil {
+				require.EqualError(t, err, tt.wantErr.Error())
+				require.Nil(t, got)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestMetricUpdatesService_Updates_ConditionalWithoutApplier(t *testing.T) {
+	svc := services.NewMetricUpdatesService()
+
+	got, err := svc.Updates(context.Background(), []*types.Metrics{
+		{ID: "peak", Type: types.Gauge, Value: ptrFloat64(5), ExpectedVersion: ptrInt64(1)},
+	})
+	require.ErrorIs(t, err, services.ErrConditionalUnsupported)
+	require.Nil(t, got)
+}
+
+func TestMetricServices_PublishChanges(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	applier := services.NewMockApplier(ctrl)
+	deleter := services.NewMockDeleter(ctrl)
+	resetter := services.NewMockResetter(ctrl)
+	publisher := services.NewMockPublisher(ctrl)
+
+	updates := services.NewMetricUpdatesService(
+		services.WithMetricUpdatesApplier(applier),
+		services.WithMetricUpdatesPublisher(publisher),
+	)
+	deletes := services.NewMetricDeleteService(
+		services.WithMetricDeleteDeleter(deleter),
+		services.WithMetricDeletePublisher(publisher),
+	)
+	resets := services.NewMetricResetService(
+		services.WithMetricResetResetter(resetter),
+		services.WithMetricResetPublisher(publisher),
+	)
+
+	applier.EXPECT().
+		Apply(gomock.Any(), gomock.Any()).
+		Return(&types.Metrics{ID: "Alloc", Type: types.Gauge, Value: ptrFloat64(2), Version: 1}, nil)
+	publisher.EXPECT().Publish(types.MetricEvent{
+		Kind:   types.MetricEventUpdated,
+		Metric: types.Metrics{ID: "Alloc", Type: types.Gauge, Value: ptrFloat64(2), Version: 1},
+	})
+	_, err := updates.Updates(context.Background(), []*types.Metrics{{ID: "Alloc", Type: types.Gauge, Value: ptrFloat64(2)}})
+	require.NoError(t, err)
+
+	deleter.EXPECT().
+		DeleteByPrefix(gomock.Any(), "CPU").
+		Return([]types.MetricID{{ID: "CPU1", Type: types.Gauge}, {ID: "CPU2", Type: types.Gauge}}, nil)
+	publisher.EXPECT().Publish(
+		types.MetricEvent{Kind: types.MetricEventDeleted, Metric: types.Metrics{ID: "CPU1", Type: types.Gauge}},
+		types.MetricEvent{Kind: types.MetricEventDeleted, Metric: types.Metrics{ID: "CPU2", Type: types.Gauge}},
+	)
+	_, err = deletes.DeleteByPrefix(context.Background(), "CPU")
+	require.NoError(t, err)
+
+	deleter.EXPECT().
+		Delete(gomock.Any(), types.MetricID{ID: "missing", Type: types.Gauge}).
+		Return(false, nil)
+	_, err = deletes.Delete(context.Background(), types.MetricID{ID: "missing", Type: types.Gauge})
+	require.NoError(t, err)
+
+	resetter.EXPECT().
+		Reset(gomock.Any(), types.MetricID{ID: "PollCount", Type: types.Counter}).
+		Return(&types.Metrics{ID: "PollCount", Type: types.Counter, Delta: ptrInt64(0), Version: 7}, nil)
+	publisher.EXPECT().Publish(types.MetricEvent{
+		Kind:   types.MetricEventUpdated,
+		Metric: types.Metrics{ID: "PollCount", Type: types.Counter, Delta: ptrInt64(0), Version: 7},
+	})
+	_, err = resets.Reset(context.Background(), types.MetricID{ID: "PollCount", Type: types.Counter})
+	require.NoError(t, err)
+}
+
+func TestMetricWatchService(t *testing.T) {
+	svc := services.NewMetricWatchService(services.WithMetricWatchBuffer(1))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	all := svc.Watch(ctx, types.MetricFilter{})
+	cpu := svc.Watch(ctx, types.MetricFilter{Prefix: "CPU"})
+
+	alloc := types.MetricEvent{Kind: types.MetricEventUpdated, Metric: types.Metrics{ID: "Alloc", Type: types.Gauge}}
+	cpu1 := types.MetricEvent{Kind: types.MetricEventDeleted, Metric: types.Metrics{ID: "CPU1", Type: types.Gauge}}
+
+	svc.Publish(alloc)
+	require.Equal(t, alloc, <-all)
+
+	// The second event overflows the buffer of both subscribers,
+	// so their subscriptions end with an overflow event.
+	svc.Publish(cpu1, cpu1)
+	require.Equal(t, cpu1, <-all)
+	require.Equal(t, types.MetricEvent{Kind: types.MetricEventOverflow}, <-all)
+	_, ok := <-all
+	require.False(t, ok)
+	require.Equal(t, cpu1, <-cpu)
+	require.Equal(t, types.MetricEvent{Kind: types.MetricEventOverflow}, <-cpu)
+	_, ok = <-cpu
+	require.False(t, ok)
+
+	// A subscriber keeping up receives every event until ctx is done.
+	next := svc.Watch(ctx, types.MetricFilter{})
+	svc.Publish(alloc)
+	require.Equal(t, alloc, <-next)
+	svc.Publish(cpu1)
+	require.Equal(t, cpu1, <-next)
+
+	cancel()
+	_, ok = <-next
+	require.False(t, ok)
+
+	// Publishing without subscribers is a no-op.
+	svc.Publish(alloc)
+
+	// Close ends open subscriptions and rejects new ones.
+	open := svc.Watch(context.Background(), types.MetricFilter{})
+	svc.Close()
+	_, ok = <-open
+	require.False(t, ok)
+	_, ok = <-svc.Watch(context.Background(), types.MetricFilter{})
+	require.False(t, ok)
+}
@@ -0,0 +1,105 @@
+// Package sockets resolves the listen and dial addresses used by the servers
+// and the agent, which are either TCP "host:port" or "unix:///path/to.sock".
+package sockets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+)
+
+// UnixScheme prefixes Unix domain socket addresses.
+const UnixScheme = "unix://"
+
+// ParseAddress returns the network and address to listen on or dial.
+// "unix:///run/metrics.sock" gives ("unix", "/run/metrics.sock");
+// anything else is a TCP address.
+func ParseAddress(addr string) (network, address string) {
+	if path, ok := strings.CutPrefix(addr, UnixScheme); ok {
+		return "unix", path
+	}
+	return "tcp", addr
+}
+
+// IsUnix reports whether addr is a Unix domain socket address.
+func IsUnix(addr string) bool {
+	network, _ := ParseAddress(addr)
+	return network == "unix"
+}
+
+// ListenOpt defines a functional option for Listen.
+type ListenOpt func(*listenOptions)
+
+type listenOptions struct {
+	mode os.FileMode
+}
+
+// WithMode sets the permissions of a created socket file. Zero keeps the
+// permissions given by the process umask.
+func WithMode(mode os.FileMode) ListenOpt {
+	return func(o *listenOptions) {
+		o.mode = mode
+	}
+}
+
+// Listen listens on a TCP or Unix socket address.
+//
+// A socket file left behind by a process that did not shut down cleanly is
+// removed first; a socket some process still accepts connections on is not.
+// The socket file is removed when the listener is closed.
+func Listen(addr string, opts ...ListenOpt) (net.Listener, error) {
+	o := &listenOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	network, address := ParseAddress(addr)
+	if network != "unix" {
+		return net.Listen(network, address)
+	}
+
+	if err := removeStaleSocket(address); err != nil {
+		return nil, err
+	}
+	lis, err := net.Listen(network, address)
+	if err != nil {
+		return nil, err
+	}
+	if o.mode != 0 {
+		if err := os.Chmod(address, o.mode); err != nil {
+			lis.Close()
+			return nil, err
+		}
+	}
+	return lis, nil
+}
+
+func removeStaleSocket(path string) error {
+	info, err := os.Lstat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if info.Mode()&os.ModeSocket == 0 {
+		return fmt.Errorf("%s exists and is not a socket", path)
+	}
+
+	conn, err := net.Dial("unix", path)
+	if err == nil {
+		conn.Close()
+		return fmt.Errorf("socket %s is in use", path)
+	}
+	return os.Remove(path)
+}
+
+// DialContext dials a TCP or Unix socket address.
+func DialContext(ctx context.Context, addr string) (net.Conn, error) {
+	network, address := ParseAddress(addr)
+	var d net.Dialer
+	return d.DialContext(ctx, network, address)
+}
@@ -0,0 +1,103 @@
+package sockets
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAddress(t *testing.T) {
+	tests := []struct {
+		addr        string
+		wantNetwork string
+		wantAddress string
+	}{
+		{addr: "localhost:8080", wantNetwork: "tcp", wantAddress: "localhost:8080"},
+		{addr: ":8080", wantNetwork: "tcp", wantAddress: ":8080"},
+		{addr: "unix:///run/metrics.sock", wantNetwork: "unix", wantAddress: "/run/metrics.sock"},
+		{addr: "unix://metrics.sock", wantNetwork: "unix", wantAddress: "metrics.sock"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.addr, func(t *testing.T) {
+			network, address := ParseAddress(tt.addr)
+			assert.Equal(t, tt.wantNetwork, network)
+			assert.Equal(t, tt.wantAddress, address)
+			assert.Equal(t, tt.wantNetwork == "unix", IsUnix(tt.addr))
+		})
+	}
+}
+
+func TestListen_Unix(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "metrics.sock")
+	addr := UnixScheme + path
+
+	lis, err := Listen(addr, WithMode(0o660))
+	require.NoError(t, err)
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o660), info.Mode().Perm())
+
+	go func() {
+		conn, err := lis.Accept()
+		if err == nil {
+			conn.Write([]byte("ok"))
+			conn.Close()
+		}
+	}()
+	conn, err := DialContext(context.Background(), addr)
+	require.NoError(t, err)
+	buf := make([]byte, 2)
+	_, err = conn.Read(buf)
+	require.NoError(t, err)
+	assert.Equal(t, "ok", string(buf))
+	conn.Close()
+
+	// A socket someone is listening on is not taken over
+	_, err = Listen(addr)
+	assert.Error(t, err)
+
+	require.NoError(t, lis.Close())
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err), "socket is removed on close")
+}
+
+func TestListen_RemovesStaleSocket(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "metrics.sock")
+
+	// Leave a socket file behind, like a crashed process would
+	stale, err := net.ListenUnix("unix", &net.UnixAddr{Name: path, Net: "unix"})
+	require.NoError(t, err)
+	stale.SetUnlinkOnClose(false)
+	require.NoError(t, stale.Close())
+	_, err = os.Stat(path)
+	require.NoError(t, err)
+
+	lis, err := Listen(UnixScheme + path)
+	require.NoError(t, err)
+	lis.Close()
+}
+
+func TestListen_RefusesRegularFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "metrics.sock")
+	require.NoError(t, os.WriteFile(path, []byte("data"), 0o600))
+
+	_, err := Listen(UnixScheme + path)
+	assert.Error(t, err)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "data", string(data))
+}
+
+func TestListen_TCP(t *testing.T) {
+	lis, err := Listen("127.0.0.1:0")
+	require.NoError(t, err)
+	defer lis.Close()
+	assert.Equal(t, "tcp", lis.Addr().Network())
+}
@@ -0,0 +1,76 @@
+// Package systemd implements the parts of the systemd service protocol the
+// server uses, without cgo: socket activation (LISTEN_FDS), readiness and
+// stop notifications (NOTIFY_SOCKET) and watchdog keep-alives (WATCHDOG_USEC).
+package systemd
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// listenFdsStart is the first file descriptor passed by socket activation (SD_LISTEN_FDS_START).
+var listenFdsStart = 3
+
+// Listener is a socket passed by systemd socket activation.
+type Listener struct {
+	net.Listener
+
+	// Name is the FileDescriptorName= of the socket unit, or the unit name when unset.
+	Name string
+}
+
+// Listeners returns the listening sockets passed to this process via
+// LISTEN_PID, LISTEN_FDS and LISTEN_FDNAMES, in the order systemd passed them.
+//
+// It returns nil when the process was not socket activated. The variables are
+// unset so that child processes do not inherit them.
+func Listeners() ([]Listener, error) {
+	pid, fds, names := os.Getenv("LISTEN_PID"), os.Getenv("LISTEN_FDS"), os.Getenv("LISTEN_FDNAMES")
+	os.Unsetenv("LISTEN_PID")
+	os.Unsetenv("LISTEN_FDS")
+	os.Unsetenv("LISTEN_FDNAMES")
+
+	if pid == "" || fds == "" {
+		return nil, nil
+	}
+	if p, err := strconv.Atoi(pid); err != nil || p != os.Getpid() {
+		return nil, nil
+	}
+	n, err := strconv.Atoi(fds)
+	if err != nil || n < 0 {
+		return nil, fmt.Errorf("invalid LISTEN_FDS %q", fds)
+	}
+
+	var fdNames []string
+	if names != "" {
+		fdNames = strings.Split(names, ":")
+	}
+
+	listeners := make([]Listener, 0, n)
+	for i := 0; i < n; i++ {
+		fd := listenFdsStart + i
+		syscall.CloseOnExec(fd)
+
+		name := "LISTEN_FD_" + strconv.Itoa(fd)
+		if i < len(fdNames) {
+			name = fdNames[i]
+		}
+
+		f := os.NewFile(uintptr(fd), name)
+		lis, err := net.FileListener(f)
+		// FileListener dups the descriptor, so the original is closed either way
+		f.Close()
+		if err != nil {
+			for _, l := range listeners {
+				l.Close()
+			}
+			return nil, fmt.Errorf("socket activation fd %d (%s): %w", fd, name, err)
+		}
+		listeners = append(listeners, Listener{Listener: lis, Name: name})
+	}
+	return listeners, nil
+}
@@ -0,0 +1,95 @@
+package systemd
+
+import (
+	"net"
+	"os"
+	"strconv"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// passListener hands a duplicate of lis's descriptor to Listeners as if
+// systemd had passed it. Listeners takes ownership of the duplicate.
+func passListener(t *testing.T, lis net.Listener) {
+	t.Helper()
+	f, err := lis.(*net.TCPListener).File()
+	require.NoError(t, err)
+	defer f.Close()
+	fd, err := syscall.Dup(int(f.Fd()))
+	require.NoError(t, err)
+
+	start := listenFdsStart
+	listenFdsStart = fd
+	t.Cleanup(func() { listenFdsStart = start })
+}
+
+func TestListeners(t *testing.T) {
+	orig, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer orig.Close()
+	passListener(t, orig)
+
+	t.Setenv("LISTEN_PID", strconv.Itoa(os.Getpid()))
+	t.Setenv("LISTEN_FDS", "1")
+	t.Setenv("LISTEN_FDNAMES", "http")
+
+	listeners, err := Listeners()
+	require.NoError(t, err)
+	require.Len(t, listeners, 1)
+	defer listeners[0].Close()
+
+	assert.Equal(t, "http", listeners[0].Name)
+	assert.Equal(t, orig.Addr().String(), listeners[0].Addr().String())
+
+	for _, key := range []string{"LISTEN_PID", "LISTEN_FDS", "LISTEN_FDNAMES"} {
+		_, ok := os.LookupEnv(key)
+		assert.False(t, ok, "%s is unset", key)
+	}
+
+	// The inherited socket accepts connections made to the original address
+	go func() {
+		conn, err := listeners[0].Accept()
+		if err == nil {
+			conn.Write([]byte("ok"))
+			conn.Close()
+		}
+	}()
+	conn, err := net.Dial("tcp", orig.Addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+	buf := make([]byte, 2)
+	_, err = conn.Read(buf)
+	require.NoError(t, err)
+	assert.Equal(t, "ok", string(buf))
+}
+
+func TestListeners_NotActivated(t *testing.T) {
+	tests := []struct {
+		name    string
+		pid     string
+		fds     string
+		wantErr bool
+	}{
+		{name: "no environment"},
+		{name: "other process", pid: strconv.Itoa(os.Getpid() + 1), fds: "1"},
+		{name: "invalid pid", pid: "abc", fds: "1"},
+		{name: "invalid count", pid: strconv.Itoa(os.Getpid()), fds: "x", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("LISTEN_PID", tt.pid)
+			t.Setenv("LISTEN_FDS", tt.fds)
+
+			listeners, err := Listeners()
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Empty(t, listeners)
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package systemd
+
+import (
+	"net"
+	"os"
+	"strconv"
+	"time"
+)
+
+// Notification states understood by the service manager.
+const (
+	Ready    = "READY=1"
+	Stopping = "STOPPING=1"
+	Watchdog = "WATCHDOG=1"
+)
+
+// Notify sends state to the service manager over NOTIFY_SOCKET.
+//
+// It reports false without an error when the process is not supervised,
+// so callers can notify unconditionally. A leading "@" in the socket path
+// selects the Linux abstract namespace.
+func Notify(state string) (bool, error) {
+	path := os.Getenv("NOTIFY_SOCKET")
+	if path == "" {
+		return false, nil
+	}
+	if path[0] == '@' {
+		path = "\x00" + path[1:]
+	}
+
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: path, Net: "unixgram"})
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close()
+
+	if _, err := conn.Write([]byte(state)); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// WatchdogInterval returns the watchdog timeout requested with WATCHDOG_USEC,
+// or zero when the watchdog is disabled or meant for another process.
+// Keep-alives should be sent at about half this interval.
+func WatchdogInterval() time.Duration {
+	usec, err := strconv.ParseInt(os.Getenv("WATCHDOG_USEC"), 10, 64)
+	if err != nil || usec <= 0 {
+		return 0
+	}
+	if pid := os.Getenv("WATCHDOG_PID"); pid != "" {
+		if p, err := strconv.Atoi(pid); err != nil || p != os.Getpid() {
+			return 0
+		}
+	}
+	return time.Duration(usec) * time.Microsecond
+}
@@ -0,0 +1,89 @@
+package systemd
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotify(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notify.sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	require.NoError(t, err)
+	defer conn.Close()
+
+	t.Setenv("NOTIFY_SOCKET", path)
+
+	for _, state := range []string{Ready, Watchdog, Stopping} {
+		sent, err := Notify(state)
+		require.NoError(t, err)
+		assert.True(t, sent)
+
+		buf := make([]byte, 64)
+		conn.SetReadDeadline(time.Now().Add(time.Second))
+		n, err := conn.Read(buf)
+		require.NoError(t, err)
+		assert.Equal(t, state, string(buf[:n]))
+	}
+}
+
+func TestNotify_AbstractSocket(t *testing.T) {
+	name := "metrics-notify-test-" + strconv.Itoa(os.Getpid())
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: "\x00" + name, Net: "unixgram"})
+	require.NoError(t, err)
+	defer conn.Close()
+
+	t.Setenv("NOTIFY_SOCKET", "@"+name)
+
+	sent, err := Notify(Ready)
+	require.NoError(t, err)
+	assert.True(t, sent)
+
+	buf := make([]byte, 64)
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	n, err := conn.Read(buf)
+	require.NoError(t, err)
+	assert.Equal(t, Ready, string(buf[:n]))
+}
+
+func TestNotify_Unsupervised(t *testing.T) {
+	t.Setenv("NOTIFY_SOCKET", "")
+	sent, err := Notify(Ready)
+	assert.NoError(t, err)
+	assert.False(t, sent)
+
+	t.Setenv("NOTIFY_SOCKET", filepath.Join(t.TempDir(), "missing.sock"))
+	sent, err = Notify(Ready)
+	assert.Error(t, err)
+	assert.False(t, sent)
+}
+
+func TestWatchdogInterval(t *testing.T) {
+	pid := strconv.Itoa(os.Getpid())
+	tests := []struct {
+		name string
+		usec string
+		pid  string
+		want time.Duration
+	}{
+		{name: "disabled"},
+		{name: "enabled", usec: "2000000", want: 2 * time.Second},
+		{name: "enabled for this process", usec: "500000", pid: pid, want: 500 * time.Millisecond},
+		{name: "meant for another process", usec: "500000", pid: strconv.Itoa(os.Getpid() + 1)},
+		{name: "invalid", usec: "soon"},
+		{name: "zero", usec: "0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("WATCHDOG_USEC", tt.usec)
+			t.Setenv("WATCHDOG_PID", tt.pid)
+			assert.Equal(t, tt.want, WatchdogInterval())
+		})
+	}
+}
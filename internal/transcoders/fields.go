@@ -0,0 +1,108 @@
+package transcoders
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// setField sets the field at a dotted path, such as "id.type", from its string
+// form. Nested messages are created as needed and repeated fields take every value.
+// Field names may be given either as in the proto file or in their JSON form.
+func setField(msg protoreflect.Message, path string, values []string) error {
+	names := strings.Split(path, ".")
+	for i, name := range names {
+		fd := findField(msg.Descriptor(), name)
+		if fd == nil {
+			return fmt.Errorf("unknown field %q", path)
+		}
+
+		if i < len(names)-1 {
+			if fd.Kind() != protoreflect.MessageKind || fd.IsList() || fd.IsMap() {
+				return fmt.Errorf("field %q is not a message", strings.Join(names[:i+1], "."))
+			}
+			msg = msg.Mutable(fd).Message()
+			continue
+		}
+
+		if fd.IsMap() || fd.Kind() == protoreflect.MessageKind || fd.Kind() == protoreflect.GroupKind {
+			return fmt.Errorf("field %q cannot be set from a string", path)
+		}
+		if fd.IsList() {
+			list := msg.Mutable(fd).List()
+			for _, s := range values {
+				v, err := parseScalar(fd, s)
+				if err != nil {
+					return fmt.Errorf("field %q: %w", path, err)
+				}
+				list.Append(v)
+			}
+			return nil
+		}
+		if len(values) == 0 {
+			return nil
+		}
+		v, err := parseScalar(fd, values[len(values)-1])
+		if err != nil {
+			return fmt.Errorf("field %q: %w", path, err)
+		}
+		msg.Set(fd, v)
+	}
+	return nil
+}
+
+func findField(md protoreflect.MessageDescriptor, name string) protoreflect.FieldDescriptor {
+	if fd := md.Fields().ByName(protoreflect.Name(name)); fd != nil {
+		return fd
+	}
+	return md.Fields().ByJSONName(name)
+}
+
+// parseScalar converts s to a value of the scalar or enum field fd.
+// Enums accept either the value name or its number.
+func parseScalar(fd protoreflect.FieldDescriptor, s string) (protoreflect.Value, error) {
+	switch fd.Kind() {
+	case protoreflect.StringKind:
+		return protoreflect.ValueOfString(s), nil
+	case protoreflect.BoolKind:
+		v, err := strconv.ParseBool(s)
+		return protoreflect.ValueOfBool(v), err
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		v, err := strconv.ParseInt(s, 10, 32)
+		return protoreflect.ValueOfInt32(int32(v)), err
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		v, err := strconv.ParseInt(s, 10, 64)
+		return protoreflect.ValueOfInt64(v), err
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		v, err := strconv.ParseUint(s, 10, 32)
+		return protoreflect.ValueOfUint32(uint32(v)), err
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		v, err := strconv.ParseUint(s, 10, 64)
+		return protoreflect.ValueOfUint64(v), err
+	case protoreflect.FloatKind:
+		v, err := strconv.ParseFloat(s, 32)
+		return protoreflect.ValueOfFloat32(float32(v)), err
+	case protoreflect.DoubleKind:
+		v, err := strconv.ParseFloat(s, 64)
+		return protoreflect.ValueOfFloat64(v), err
+	case protoreflect.BytesKind:
+		v, err := base64.StdEncoding.DecodeString(s)
+		if err != nil {
+			v, err = base64.URLEncoding.DecodeString(s)
+		}
+		return protoreflect.ValueOfBytes(v), err
+	case protoreflect.EnumKind:
+		if ev := fd.Enum().Values().ByName(protoreflect.Name(s)); ev != nil {
+			return protoreflect.ValueOfEnum(ev.Number()), nil
+		}
+		n, err := strconv.ParseInt(s, 10, 32)
+		if err != nil || fd.Enum().Values().ByNumber(protoreflect.EnumNumber(n)) == nil {
+			return protoreflect.Value{}, fmt.Errorf("invalid value %q for enum %s", s, fd.Enum().FullName())
+		}
+		return protoreflect.ValueOfEnum(protoreflect.EnumNumber(n)), nil
+	}
+	return protoreflect.Value{}, fmt.Errorf("unsupported kind %s", fd.Kind())
+}
@@ -0,0 +1,121 @@
+package transcoders
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+)
+
+var errHeaderSent = errors.New("transcoder: headers already sent")
+
+// transportStream collects the headers set by handlers and interceptors
+// through grpc.SetHeader and copies them to the HTTP response.
+type transportStream struct {
+	method  string
+	w       http.ResponseWriter
+	header  metadata.MD
+	written bool
+}
+
+func (ts *transportStream) Method() string {
+	return ts.method
+}
+
+func (ts *transportStream) SetHeader(md metadata.MD) error {
+	if ts.written {
+		return errHeaderSent
+	}
+	ts.header = metadata.Join(ts.header, md)
+	return nil
+}
+
+func (ts *transportStream) SendHeader(md metadata.MD) error {
+	if err := ts.SetHeader(md); err != nil {
+		return err
+	}
+	ts.writeHeader()
+	return nil
+}
+
+// SetTrailer is a no-op: trailers have no counterpart in the REST responses.
+func (ts *transportStream) SetTrailer(metadata.MD) error {
+	return nil
+}
+
+// writeHeader copies the collected metadata to the response headers once.
+func (ts *transportStream) writeHeader() {
+	if ts.written {
+		return
+	}
+	ts.written = true
+	for k, v := range ts.header {
+		for _, s := range v {
+			ts.w.Header().Add(http.CanonicalHeaderKey(k), s)
+		}
+	}
+}
+
+// serverStream adapts a server-streaming call to an NDJSON response.
+// The single request message is decoded from the HTTP request on the first RecvMsg.
+type serverStream struct {
+	*transportStream
+
+	ctx context.Context
+	t   *Transcoder
+	r   *http.Request
+	b   *binding
+
+	received bool
+	sent     bool // whether the status line has been written
+}
+
+// SetTrailer is a no-op, see transportStream.SetTrailer.
+func (ss *serverStream) SetTrailer(metadata.MD) {}
+
+func (ss *serverStream) Context() context.Context {
+	return ss.ctx
+}
+
+func (ss *serverStream) SendHeader(md metadata.MD) error {
+	if err := ss.SetHeader(md); err != nil {
+		return err
+	}
+	ss.sendHeader()
+	return nil
+}
+
+func (ss *serverStream) sendHeader() {
+	if ss.sent {
+		return
+	}
+	ss.sent = true
+	ss.writeHeader()
+	ss.w.Header().Set("Content-Type", "application/x-ndjson")
+	ss.w.WriteHeader(http.StatusOK)
+	flush(ss.w)
+}
+
+func (ss *serverStream) SendMsg(m any) error {
+	body, err := ss.t.marshaler.Marshal(m.(proto.Message))
+	if err != nil {
+		return err
+	}
+	ss.sendHeader()
+	if _, err := ss.w.Write(append(body, '\n')); err != nil {
+		return err
+	}
+	flush(ss.w)
+	return nil
+}
+
+func (ss *serverStream) RecvMsg(m any) error {
+	if ss.received {
+		return io.EOF
+	}
+	ss.received = true
+	return ss.t.decodeRequest(ss.r, ss.b, m.(proto.Message))
+}
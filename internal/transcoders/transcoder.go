@@ -0,0 +1,409 @@
+package transcoders
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// TranscoderOpt defines a functional option for configuring Transcoder.
+type TranscoderOpt func(*Transcoder)
+
+// Transcoder serves gRPC services over HTTP/JSON using the google.api.http
+// annotations of their methods. It implements grpc.ServiceRegistrar, so the
+// generated Register*Server functions can register handlers on it directly.
+//
+// Unary methods answer with a JSON object; server-streaming methods answer with
+// newline-delimited JSON. Client-streaming methods and methods without an
+// annotation are not exposed.
+type Transcoder struct {
+	router chi.Router
+	unary  []grpc.UnaryServerInterceptor
+	stream []grpc.StreamServerInterceptor
+
+	marshaler   protojson.MarshalOptions
+	unmarshaler protojson.UnmarshalOptions
+}
+
+// WithRouter sets the router the REST routes are registered on.
+func WithRouter(r chi.Router) TranscoderOpt {
+	return func(t *Transcoder) {
+		t.router = r
+	}
+}
+
+// WithUnaryInterceptors sets the interceptors unary calls go through, outermost first.
+func WithUnaryInterceptors(interceptors ...grpc.UnaryServerInterceptor) TranscoderOpt {
+	return func(t *Transcoder) {
+		t.unary = append(t.unary, interceptors...)
+	}
+}
+
+// WithStreamInterceptors sets the interceptors streaming calls go through, outermost first.
+func WithStreamInterceptors(interceptors ...grpc.StreamServerInterceptor) TranscoderOpt {
+	return func(t *Transcoder) {
+		t.stream = append(t.stream, interceptors...)
+	}
+}
+
+// NewTranscoder creates a Transcoder. Without WithRouter it uses a new chi router.
+func NewTranscoder(opts ...TranscoderOpt) *Transcoder {
+	t := &Transcoder{
+		marshaler:   protojson.MarshalOptions{UseProtoNames: true, EmitUnpopulated: true},
+		unmarshaler: protojson.UnmarshalOptions{},
+	}
+	for _, opt := range opts {
+		opt(t)
+	}
+	if t.router == nil {
+		t.router = chi.NewRouter()
+	}
+	return t
+}
+
+// ServeHTTP lets the Transcoder be used as a standalone handler.
+func (t *Transcoder) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	t.router.ServeHTTP(w, r)
+}
+
+// RegisterService registers the annotated methods of a service as REST routes.
+// It panics like grpc.Server does when the service cannot be registered.
+func (t *Transcoder) RegisterService(desc *grpc.ServiceDesc, impl any) {
+	d, err := protoregistry.GlobalFiles.FindDescriptorByName(protoreflect.FullName(desc.ServiceName))
+	if err != nil {
+		panic(fmt.Sprintf("transcoder: service %s not found: %v", desc.ServiceName, err))
+	}
+	sd, ok := d.(protoreflect.ServiceDescriptor)
+	if !ok {
+		panic(fmt.Sprintf("transcoder: %s is not a service", desc.ServiceName))
+	}
+
+	for i := range desc.Methods {
+		m := &desc.Methods[i]
+		md := sd.Methods().ByName(protoreflect.Name(m.MethodName))
+		for _, b := range t.bindings(md) {
+			t.handle(b, t.unaryHandler(desc, m, impl, b))
+		}
+	}
+	for i := range desc.Streams {
+		s := &desc.Streams[i]
+		if s.ClientStreams || !s.ServerStreams {
+			continue
+		}
+		md := sd.Methods().ByName(protoreflect.Name(s.StreamName))
+		for _, b := range t.bindings(md) {
+			t.handle(b, t.streamHandler(desc, s, impl, b))
+		}
+	}
+}
+
+// binding is a single HTTP mapping of a method.
+type binding struct {
+	method  string
+	pattern string   // chi route pattern
+	params  []string // field paths bound by the pattern placeholders, in order
+	body    string   // "", "*" or the name of the field the body is decoded into
+}
+
+// bindings returns the HTTP mappings declared on md, including additional bindings.
+func (t *Transcoder) bindings(md protoreflect.MethodDescriptor) []*binding {
+	if md == nil {
+		return nil
+	}
+	opts, ok := md.Options().(*descriptorpb.MethodOptions)
+	if !ok || opts == nil {
+		return nil
+	}
+	rule, ok := proto.GetExtension(opts, annotations.E_Http).(*annotations.HttpRule)
+	if !ok || rule == nil {
+		return nil
+	}
+
+	var out []*binding
+	for _, r := range append([]*annotations.HttpRule{rule}, rule.GetAdditionalBindings()...) {
+		method, path := ruleMethodAndPath(r)
+		if path == "" {
+			continue
+		}
+		pattern, params := parseTemplate(path)
+		out = append(out, &binding{
+			method:  method,
+			pattern: pattern,
+			params:  params,
+			body:    r.GetBody(),
+		})
+	}
+	return out
+}
+
+func ruleMethodAndPath(r *annotations.HttpRule) (string, string) {
+	switch p := r.GetPattern().(type) {
+	case *annotations.HttpRule_Get:
+		return http.MethodGet, p.Get
+	case *annotations.HttpRule_Post:
+		return http.MethodPost, p.Post
+	case *annotations.HttpRule_Put:
+		return http.MethodPut, p.Put
+	case *annotations.HttpRule_Patch:
+		return http.MethodPatch, p.Patch
+	case *annotations.HttpRule_Delete:
+		return http.MethodDelete, p.Delete
+	case *annotations.HttpRule_Custom:
+		return strings.ToUpper(p.Custom.GetKind()), p.Custom.GetPath()
+	}
+	return "", ""
+}
+
+// parseTemplate turns a path template such as "/metrics/{id.type}/{id.id}" into
+// a chi pattern with positional placeholders and the field paths they bind.
+// Sub-patterns ("{name=*}") are treated as a single segment.
+func parseTemplate(path string) (string, []string) {
+	var (
+		sb     strings.Builder
+		params []string
+	)
+	for {
+		start := strings.IndexByte(path, '{')
+		if start < 0 {
+			sb.WriteString(path)
+			break
+		}
+		end := strings.IndexByte(path[start:], '}')
+		if end < 0 {
+			sb.WriteString(path)
+			break
+		}
+		end += start
+
+		field := path[start+1 : end]
+		if i := strings.IndexByte(field, '='); i >= 0 {
+			field = field[:i]
+		}
+		fmt.Fprintf(&sb, "%s{p%d}", path[:start], len(params))
+		params = append(params, field)
+		path = path[end+1:]
+	}
+	return sb.String(), params
+}
+
+func (t *Transcoder) handle(b *binding, h http.HandlerFunc) {
+	t.router.MethodFunc(b.method, b.pattern, h)
+}
+
+func (t *Transcoder) unaryHandler(desc *grpc.ServiceDesc, m *grpc.MethodDesc, impl any, b *binding) http.HandlerFunc {
+	info := &grpc.UnaryServerInfo{
+		Server:     impl,
+		FullMethod: "/" + desc.ServiceName + "/" + m.MethodName,
+	}
+	interceptor := chainUnary(t.unary)
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		ts := &transportStream{method: info.FullMethod, w: w}
+		ctx := t.newContext(r, ts)
+
+		dec := func(v any) error {
+			return t.decodeRequest(r, b, v.(proto.Message))
+		}
+
+		var resp any
+		var err error
+		if interceptor == nil {
+			resp, err = m.Handler(impl, ctx, dec, nil)
+		} else {
+			resp, err = m.Handler(impl, ctx, dec, func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+				return interceptor(ctx, req, info, handler)
+			})
+		}
+		if err != nil {
+			t.writeError(w, ts, err)
+			return
+		}
+
+		body, err := t.marshaler.Marshal(resp.(proto.Message))
+		if err != nil {
+			t.writeError(w, ts, status.Error(codes.Internal, err.Error()))
+			return
+		}
+		ts.writeHeader()
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write(body)
+	}
+}
+
+func (t *Transcoder) streamHandler(desc *grpc.ServiceDesc, s *grpc.StreamDesc, impl any, b *binding) http.HandlerFunc {
+	info := &grpc.StreamServerInfo{
+		FullMethod:     "/" + desc.ServiceName + "/" + s.StreamName,
+		IsClientStream: s.ClientStreams,
+		IsServerStream: s.ServerStreams,
+	}
+	interceptor := chainStream(t.stream)
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		ts := &transportStream{method: info.FullMethod, w: w}
+		ss := &serverStream{
+			transportStream: ts,
+			ctx:             t.newContext(r, ts),
+			t:               t,
+			r:               r,
+			b:               b,
+		}
+
+		var err error
+		if interceptor == nil {
+			err = s.Handler(impl, ss)
+		} else {
+			err = interceptor(impl, ss, info, s.Handler)
+		}
+		if err == nil {
+			ss.sendHeader()
+			return
+		}
+		if !ss.sent {
+			t.writeError(w, ts, err)
+			return
+		}
+		// The status line is gone, so report the error as the last line.
+		body, _ := t.marshaler.Marshal(status.Convert(err).Proto())
+		fmt.Fprintf(w, "{\"error\":%s}\n", body)
+		flush(w)
+	}
+}
+
+// newContext builds the incoming gRPC context of an HTTP request: headers
+// become metadata, the remote address and TLS state become the peer.
+func (t *Transcoder) newContext(r *http.Request, ts *transportStream) context.Context {
+	md := metadata.MD{}
+	for k, v := range r.Header {
+		md.Append(strings.ToLower(k), v...)
+	}
+	ctx := metadata.NewIncomingContext(r.Context(), md)
+
+	p := &peer.Peer{}
+	if addr, err := net.ResolveTCPAddr("tcp", r.RemoteAddr); err == nil {
+		p.Addr = addr
+	}
+	if r.TLS != nil {
+		p.AuthInfo = credentials.TLSInfo{
+			State:          *r.TLS,
+			CommonAuthInfo: credentials.CommonAuthInfo{SecurityLevel: credentials.PrivacyAndIntegrity},
+		}
+	}
+	if p.Addr != nil || p.AuthInfo != nil {
+		ctx = peer.NewContext(ctx, p)
+	}
+	return grpc.NewContextWithServerTransportStream(ctx, ts)
+}
+
+// decodeRequest fills msg from the body, the path parameters and the query string.
+func (t *Transcoder) decodeRequest(r *http.Request, b *binding, msg proto.Message) error {
+	if b.body != "" {
+		data, err := io.ReadAll(r.Body)
+		if err != nil {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+		if len(data) > 0 {
+			target := msg
+			if b.body != "*" {
+				fd := msg.ProtoReflect().Descriptor().Fields().ByName(protoreflect.Name(b.body))
+				if fd == nil || fd.Kind() != protoreflect.MessageKind || fd.IsList() || fd.IsMap() {
+					return status.Errorf(codes.Internal, "unsupported body field %q", b.body)
+				}
+				target = msg.ProtoReflect().Mutable(fd).Message().Interface()
+			}
+			if err := t.unmarshaler.Unmarshal(data, target); err != nil {
+				return status.Error(codes.InvalidArgument, err.Error())
+			}
+		}
+	}
+
+	for i, field := range b.params {
+		if err := setField(msg.ProtoReflect(), field, []string{chi.URLParam(r, fmt.Sprintf("p%d", i))}); err != nil {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+	}
+
+	// With a "*" body every field comes from the body.
+	if b.body == "*" {
+		return nil
+	}
+	for key, values := range r.URL.Query() {
+		if err := setField(msg.ProtoReflect(), key, values); err != nil {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+	}
+	return nil
+}
+
+func (t *Transcoder) writeError(w http.ResponseWriter, ts *transportStream, err error) {
+	st := status.Convert(err)
+	body, mErr := t.marshaler.Marshal(st.Proto())
+	if mErr != nil {
+		body = []byte(`{"code":13,"message":"failed to marshal error"}`)
+	}
+	ts.writeHeader()
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(HTTPStatusFromCode(st.Code()))
+	w.Write(body)
+}
+
+func flush(w http.ResponseWriter) {
+	if f, ok := w.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// chainUnary combines interceptors into one, the first being the outermost.
+func chainUnary(interceptors []grpc.UnaryServerInterceptor) grpc.UnaryServerInterceptor {
+	if len(interceptors) == 0 {
+		return nil
+	}
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		return interceptors[0](ctx, req, info, nextUnary(interceptors, 0, info, handler))
+	}
+}
+
+func nextUnary(interceptors []grpc.UnaryServerInterceptor, i int, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) grpc.UnaryHandler {
+	if i == len(interceptors)-1 {
+		return handler
+	}
+	return func(ctx context.Context, req any) (any, error) {
+		return interceptors[i+1](ctx, req, info, nextUnary(interceptors, i+1, info, handler))
+	}
+}
+
+// chainStream combines interceptors into one, the first being the outermost.
+func chainStream(interceptors []grpc.StreamServerInterceptor) grpc.StreamServerInterceptor {
+	if len(interceptors) == 0 {
+		return nil
+	}
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return interceptors[0](srv, ss, info, nextStream(interceptors, 0, info, handler))
+	}
+}
+
+func nextStream(interceptors []grpc.StreamServerInterceptor, i int, info *grpc.StreamServerInfo, handler grpc.StreamHandler) grpc.StreamHandler {
+	if i == len(interceptors)-1 {
+		return handler
+	}
+	return func(srv any, ss grpc.ServerStream) error {
+		return interceptors[i+1](srv, ss, info, nextStream(interceptors, i+1, info, handler))
+	}
+}
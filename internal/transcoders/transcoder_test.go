@@ -0,0 +1,339 @@
+package transcoders
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	pb "github.com/sbilibin2017/go-yandex-practicum/protos"
+	pbv2 "github.com/sbilibin2017/go-yandex-practicum/protos/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+type fakeMetricService struct {
+	pb.UnimplementedMetricServiceServer
+
+	gotGet      *pb.GetMetricRequest
+	gotGetBatch *pb.GetMetricsRequest
+	gotHeader   metadata.MD
+}
+
+func (s *fakeMetricService) Get(ctx context.Context, req *pb.GetMetricRequest) (*pb.GetMetricResponse, error) {
+	s.gotGet = req
+	s.gotHeader, _ = metadata.FromIncomingContext(ctx)
+	if req.GetId().GetId() == "Missing" {
+		return nil, status.Error(codes.NotFound, "metric not found")
+	}
+	grpc.SetHeader(ctx, metadata.Pairs("x-served-by", "fake"))
+	return &pb.GetMetricResponse{Metric: &pb.Metric{Id: req.GetId().GetId(), Type: req.GetId().GetType(), Value: 1.5}}, nil
+}
+
+func (s *fakeMetricService) GetBatch(_ context.Context, req *pb.GetMetricsRequest) (*pb.GetMetricsResponse, error) {
+	s.gotGetBatch = req
+	return &pb.GetMetricsResponse{NotFound: req.GetIds()}, nil
+}
+
+func (s *fakeMetricService) List(req *pb.ListMetricsRequest, stream grpc.ServerStreamingServer[pb.Metric]) error {
+	for _, id := range []string{"A", "B"} {
+		if err := stream.Send(&pb.Metric{Id: req.GetPrefix() + id, Type: "gauge"}); err != nil {
+			return err
+		}
+	}
+	if req.GetPrefix() == "Broken" {
+		return status.Error(codes.Internal, "storage failed")
+	}
+	return nil
+}
+
+func newTestServer(t *testing.T, svc pb.MetricServiceServer, opts ...TranscoderOpt) *httptest.Server {
+	t.Helper()
+	tr := NewTranscoder(opts...)
+	pb.RegisterMetricServiceServer(tr, svc)
+	srv := httptest.NewServer(tr)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestTranscoder_Unary(t *testing.T) {
+	svc := &fakeMetricService{}
+	srv := newTestServer(t, svc)
+
+	req, err := http.NewRequest(http.MethodGet, srv.URL+"/api/v1/metrics/gauge/Alloc", nil)
+	require.NoError(t, err)
+	req.Header.Set("X-Request-Id", "42")
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+	assert.Equal(t, "fake", resp.Header.Get("X-Served-By"))
+	assert.Equal(t, "Alloc", svc.gotGet.GetId().GetId())
+	assert.Equal(t, "gauge", svc.gotGet.GetId().GetType())
+	assert.Equal(t, []string{"42"}, svc.gotHeader.Get("x-request-id"))
+
+	var body struct {
+		Metric struct {
+			ID    string  `json:"id"`
+			Type  string  `json:"type"`
+			Value float64 `json:"value"`
+		} `json:"metric"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.Equal(t, "Alloc", body.Metric.ID)
+	assert.Equal(t, 1.5, body.Metric.Value)
+}
+
+func TestTranscoder_UnaryError(t *testing.T) {
+	srv := newTestServer(t, &fakeMetricService{})
+
+	resp, err := http.Get(srv.URL + "/api/v1/metrics/gauge/Missing")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	var body struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.Equal(t, int(codes.NotFound), body.Code)
+	assert.Equal(t, "metric not found", body.Message)
+}
+
+func TestTranscoder_Body(t *testing.T) {
+	svc := &fakeMetricService{}
+	srv := newTestServer(t, svc)
+
+	tests := []struct {
+		name     string
+		body     string
+		wantCode int
+	}{
+		{name: "empty body", body: ``, wantCode: http.StatusOK},
+		{name: "malformed body", body: `{"ids":`, wantCode: http.StatusBadRequest},
+		{name: "valid body", body: `{"ids":[{"id":"Alloc","type":"gauge"}]}`, wantCode: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := http.Post(srv.URL+"/api/v1/metrics/batch", "application/json", strings.NewReader(tt.body))
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			assert.Equal(t, tt.wantCode, resp.StatusCode)
+		})
+	}
+	require.Len(t, svc.gotGetBatch.GetIds(), 1)
+	assert.Equal(t, "Alloc", svc.gotGetBatch.GetIds()[0].GetId())
+}
+
+func TestTranscoder_ServerStream(t *testing.T) {
+	srv := newTestServer(t, &fakeMetricService{})
+
+	tests := []struct {
+		name      string
+		prefix    string
+		wantLines []string
+	}{
+		{
+			name:      "all messages",
+			prefix:    "Mem",
+			wantLines: []string{"MemA", "MemB"},
+		},
+		{
+			name:      "error after messages",
+			prefix:    "Broken",
+			wantLines: []string{"BrokenA", "BrokenB", "error"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := http.Get(srv.URL + "/api/v1/metrics?prefix=" + tt.prefix)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+			assert.Equal(t, "application/x-ndjson", resp.Header.Get("Content-Type"))
+
+			var got []string
+			sc := bufio.NewScanner(resp.Body)
+			for sc.Scan() {
+				var line map[string]any
+				require.NoError(t, json.Unmarshal(sc.Bytes(), &line))
+				if _, ok := line["error"]; ok {
+					got = append(got, "error")
+					continue
+				}
+				got = append(got, line["id"].(string))
+			}
+			assert.Equal(t, tt.wantLines, got)
+		})
+	}
+}
+
+func TestTranscoder_UnknownQueryParameter(t *testing.T) {
+	srv := newTestServer(t, &fakeMetricService{})
+
+	resp, err := http.Get(srv.URL + "/api/v1/metrics?bogus=1")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestTranscoder_Interceptors(t *testing.T) {
+	var unaryMethods, streamMethods []string
+	unary := func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		unaryMethods = append(unaryMethods, info.FullMethod)
+		return handler(ctx, req)
+	}
+	deny := func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		return nil, status.Error(codes.PermissionDenied, "denied")
+	}
+	stream := func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		streamMethods = append(streamMethods, info.FullMethod)
+		return handler(srv, ss)
+	}
+
+	srv := newTestServer(t, &fakeMetricService{},
+		WithUnaryInterceptors(unary, deny),
+		WithStreamInterceptors(stream),
+	)
+
+	resp, err := http.Get(srv.URL + "/api/v1/metrics/gauge/Alloc")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	resp, err = http.Get(srv.URL + "/api/v1/metrics")
+	require.NoError(t, err)
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	assert.Equal(t, []string{"/go_yandex_practicum.MetricService/Get"}, unaryMethods)
+	assert.Equal(t, []string{"/go_yandex_practicum.MetricService/List"}, streamMethods)
+}
+
+func TestTranscoder_ClientStreamingNotExposed(t *testing.T) {
+	tr := NewTranscoder()
+	pb.RegisterMetricServiceServer(tr, &fakeMetricService{})
+
+	rec := httptest.NewRecorder()
+	tr.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/v1/metrics:streamUpdates", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestParseTemplate(t *testing.T) {
+	tests := []struct {
+		path        string
+		wantPattern string
+		wantParams  []string
+	}{
+		{path: "/api/v1/metrics", wantPattern: "/api/v1/metrics"},
+		{path: "/api/v1/metrics/{id.type}/{id.id}", wantPattern: "/api/v1/metrics/{p0}/{p1}", wantParams: []string{"id.type", "id.id"}},
+		{path: "/api/v1/metrics/{id.id=*}/reset", wantPattern: "/api/v1/metrics/{p0}/reset", wantParams: []string{"id.id"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			pattern, params := parseTemplate(tt.path)
+			assert.Equal(t, tt.wantPattern, pattern)
+			assert.Equal(t, tt.wantParams, params)
+		})
+	}
+}
+
+func TestSetField(t *testing.T) {
+	tests := []struct {
+		name    string
+		msg     proto.Message
+		path    string
+		values  []string
+		want    proto.Message
+		wantErr bool
+	}{
+		{
+			name:   "nested enum by name",
+			msg:    &pbv2.GetMetricRequest{},
+			path:   "id.type",
+			values: []string{"METRIC_TYPE_GAUGE"},
+			want:   &pbv2.GetMetricRequest{Id: &pbv2.MetricID{Type: pbv2.MetricType_METRIC_TYPE_GAUGE}},
+		},
+		{
+			name:   "nested enum by number",
+			msg:    &pbv2.GetMetricRequest{},
+			path:   "id.type",
+			values: []string{"2"},
+			want:   &pbv2.GetMetricRequest{Id: &pbv2.MetricID{Type: pbv2.MetricType_METRIC_TYPE_COUNTER}},
+		},
+		{
+			name:    "invalid enum",
+			msg:     &pbv2.GetMetricRequest{},
+			path:    "id.type",
+			values:  []string{"gauge"},
+			wantErr: true,
+		},
+		{
+			name:   "string takes the last value",
+			msg:    &pb.WatchRequest{},
+			path:   "prefix",
+			values: []string{"Gc", "Mem"},
+			want:   &pb.WatchRequest{Prefix: "Mem"},
+		},
+		{
+			name:    "repeated message",
+			msg:     &pb.WatchRequest{},
+			path:    "ids",
+			values:  []string{"Alloc"},
+			wantErr: true,
+		},
+		{
+			name:    "unknown field",
+			msg:     &pb.WatchRequest{},
+			path:    "nope",
+			values:  []string{"x"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := setField(tt.msg.ProtoReflect(), tt.path, tt.values)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.True(t, proto.Equal(tt.want, tt.msg), "got %v", tt.msg)
+		})
+	}
+}
+
+func TestHTTPStatusFromCode(t *testing.T) {
+	tests := []struct {
+		code codes.Code
+		want int
+	}{
+		{codes.OK, http.StatusOK},
+		{codes.InvalidArgument, http.StatusBadRequest},
+		{codes.NotFound, http.StatusNotFound},
+		{codes.Unauthenticated, http.StatusUnauthorized},
+		{codes.PermissionDenied, http.StatusForbidden},
+		{codes.Unavailable, http.StatusServiceUnavailable},
+		{codes.Unknown, http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.code.String(), func(t *testing.T) {
+			assert.Equal(t, tt.want, HTTPStatusFromCode(tt.code))
+		})
+	}
+}
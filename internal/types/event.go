@@ -0,0 +1,39 @@
+package types
+
+import "strings"
+
+// Kinds of metric change events.
+const (
+	MetricEventUpdated = "updated"
+	MetricEventDeleted = "deleted"
+	// MetricEventOverflow ends a subscription that fell behind and missed changes.
+	MetricEventOverflow = "overflow"
+)
+
+// MetricEvent describes a change of a stored metric.
+// For deleted metrics only ID and Type of Metric are set;
+// overflow events carry no metric.
+type MetricEvent struct {
+	Kind   string  `json:"kind"`
+	Metric Metrics `json:"metric"`
+}
+
+// MetricFilter selects metrics by exact IDs and/or an ID prefix.
+// An empty filter matches every metric.
+type MetricFilter struct {
+	IDs    []MetricID
+	Prefix string
+}
+
+// Match reports whether the metric identified by id passes the filter.
+func (f MetricFilter) Match(id MetricID) bool {
+	if len(f.IDs) == 0 && f.Prefix == "" {
+		return true
+	}
+	for _, want := range f.IDs {
+		if want == id {
+			return true
+		}
+	}
+	return f.Prefix != "" && strings.HasPrefix(id.ID, f.Prefix)
+}
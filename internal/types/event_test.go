@@ -0,0 +1,29 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetricFilter_Match(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter MetricFilter
+		id     MetricID
+		want   bool
+	}{
+		{name: "empty filter", filter: MetricFilter{}, id: MetricID{ID: "Alloc", Type: Gauge}, want: true},
+		{name: "exact id", filter: MetricFilter{IDs: []MetricID{{ID: "Alloc", Type: Gauge}}}, id: MetricID{ID: "Alloc", Type: Gauge}, want: true},
+		{name: "id with other type", filter: MetricFilter{IDs: []MetricID{{ID: "Alloc", Type: Gauge}}}, id: MetricID{ID: "Alloc", Type: Counter}, want: false},
+		{name: "prefix", filter: MetricFilter{Prefix: "CPU"}, id: MetricID{ID: "CPUutilization1", Type: Gauge}, want: true},
+		{name: "prefix mismatch", filter: MetricFilter{Prefix: "CPU"}, id: MetricID{ID: "Alloc", Type: Gauge}, want: false},
+		{name: "ids or prefix", filter: MetricFilter{IDs: []MetricID{{ID: "PollCount", Type: Counter}}, Prefix: "CPU"}, id: MetricID{ID: "PollCount", Type: Counter}, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.filter.Match(tt.id))
+		})
+	}
+}
@@ -0,0 +1,25 @@
+package types
+
+// Storage components reported by health checks.
+const (
+	HealthComponentDatabase = "database"
+	HealthComponentFile     = "file"
+	HealthComponentMemory   = "memory"
+)
+
+// ComponentHealth is the health of a single storage component.
+type ComponentHealth struct {
+	Name    string `json:"name"`
+	Healthy bool   `json:"healthy"`
+	Error   string `json:"error,omitempty"`
+}
+
+// AllHealthy reports whether every component is healthy.
+func AllHealthy(components []ComponentHealth) bool {
+	for _, c := range components {
+		if !c.Healthy {
+			return false
+		}
+	}
+	return true
+}
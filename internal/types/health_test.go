@@ -0,0 +1,16 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAllHealthy(t *testing.T) {
+	assert.True(t, AllHealthy(nil))
+	assert.True(t, AllHealthy([]ComponentHealth{{Name: HealthComponentMemory, Healthy: true}}))
+	assert.False(t, AllHealthy([]ComponentHealth{
+		{Name: HealthComponentDatabase, Healthy: true},
+		{Name: HealthComponentFile, Error: "read-only file system"},
+	}))
+}
@@ -1,18 +1,62 @@
 package types
 
+import (
+	"errors"
+	"time"
+)
+
+// ErrVersionConflict is returned when a conditional update expects a metric version
+// that does not match the stored one.
+var ErrVersionConflict = errors.New("metric version conflict")
+
 const (
 	Counter = "counter" // Counter represents a metric that only increments (integer).
 	Gauge   = "gauge"   // Gauge represents a metric that can hold arbitrary float64 values.
 )
 
+// Update operations carried in the Op field of Metrics.
+const (
+	OpSet = "set" // OpSet replaces the stored value (default for gauges).
+	OpAdd = "add" // OpAdd adds to the stored value (default for counters).
+	OpSub = "sub" // OpSub subtracts from the stored gauge value.
+	OpMin = "min" // OpMin keeps the smaller of the stored and the new gauge value.
+	OpMax = "max" // OpMax keeps the larger of the stored and the new gauge value.
+)
+
+// IsValidOp reports whether op may be applied to a metric of the given type.
+// An empty op is always valid and means the default for the type.
+func IsValidOp(metricType, op string) bool {
+	switch metricType {
+	case Gauge:
+		switch op {
+		case "", OpSet, OpAdd, OpSub, OpMin, OpMax:
+			return true
+		}
+	case Counter:
+		switch op {
+		case "", OpSet, OpAdd:
+			return true
+		}
+	}
+	return false
+}
+
 type MetricID struct {
 	ID   string `json:"id" db:"id"`     // ID is the unique identifier/name of the metric.
 	Type string `json:"type" db:"type"` // Type specifies the metric type (e.g., "counter", "gauge").
 }
 
 type Metrics struct {
-	ID    string   `json:"id" db:"id"`                 // ID is the unique identifier/name of the metric.
-	Type  string   `json:"type" db:"type"`             // Type specifies the metric type (e.g., "counter", "gauge").
-	Value *float64 `json:"value,omitempty" db:"value"` // Value is used for gauge metrics (float64), nil for counters.
-	Delta *int64   `json:"delta,omitempty" db:"delta"` // Delta is used for counter metrics (int64), nil for gauges.
+	ID        string     `json:"id" db:"id"`                           // ID is the unique identifier/name of the metric.
+	Type      string     `json:"type" db:"type"`                       // Type specifies the metric type (e.g., "counter", "gauge").
+	Value     *float64   `json:"value,omitempty" db:"value"`           // Value is used for gauge metrics (float64), nil for counters.
+	Delta     *int64     `json:"delta,omitempty" db:"delta"`           // Delta is used for counter metrics (int64), nil for gauges.
+	UpdatedAt *time.Time `json:"updated_at,omitempty" db:"updated_at"` // UpdatedAt is the time of the last update, nil if unknown.
+	Stale     bool       `json:"stale,omitempty" db:"stale"`           // Stale reports that the metric was not updated within its TTL.
+	Op        string     `json:"op,omitempty" db:"-"`                  // Op is the update operation to apply, empty means the type default.
+	Version   int64      `json:"version,omitempty" db:"version"`       // Version is incremented on every update of the metric.
+
+	// ExpectedVersion makes an update conditional on the stored version; nil means unconditional.
+	// Version 0 stands for a metric that does not exist yet.
+	ExpectedVersion *int64 `json:"-" db:"-"`
 }
@@ -0,0 +1,35 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsValidOp(t *testing.T) {
+	tests := []struct {
+		metricType string
+		op         string
+		want       bool
+	}{
+		{Gauge, "", true},
+		{Gauge, OpSet, true},
+		{Gauge, OpAdd, true},
+		{Gauge, OpSub, true},
+		{Gauge, OpMin, true},
+		{Gauge, OpMax, true},
+		{Gauge, "mul", false},
+		{Counter, "", true},
+		{Counter, OpAdd, true},
+		{Counter, OpSet, true},
+		{Counter, OpSub, false},
+		{Counter, OpMax, false},
+		{"unknown", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.metricType+"/"+tt.op, func(t *testing.T) {
+			assert.Equal(t, tt.want, IsValidOp(tt.metricType, tt.op))
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package types
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TTLPolicy describes how long a metric may go without updates before it becomes stale.
+// Prefixes override Default for metrics whose ID starts with the given prefix;
+// the longest matching prefix wins. A non-positive TTL disables expiry.
+type TTLPolicy struct {
+	Default  time.Duration
+	Prefixes map[string]time.Duration
+}
+
+// TTL returns the time-to-live that applies to the metric with the given ID.
+func (p TTLPolicy) TTL(id string) time.Duration {
+	ttl := p.Default
+	matched := -1
+	for prefix, d := range p.Prefixes {
+		if strings.HasPrefix(id, prefix) && len(prefix) > matched {
+			ttl = d
+			matched = len(prefix)
+		}
+	}
+	return ttl
+}
+
+// Enabled reports whether any TTL is configured.
+func (p TTLPolicy) Enabled() bool {
+	if p.Default > 0 {
+		return true
+	}
+	for _, d := range p.Prefixes {
+		if d > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// IsStale reports whether the metric was last updated longer than its TTL ago.
+// Metrics without an update time are never considered stale.
+func (p TTLPolicy) IsStale(m *Metrics, now time.Time) bool {
+	if m == nil || m.UpdatedAt == nil {
+		return false
+	}
+	ttl := p.TTL(m.ID)
+	if ttl <= 0 {
+		return false
+	}
+	return now.Sub(*m.UpdatedAt) > ttl
+}
+
+// ParseTTLPrefixes parses per-prefix TTL overrides in the form "prefix=seconds,prefix=seconds".
+func ParseTTLPrefixes(s string) (map[string]time.Duration, error) {
+	prefixes := make(map[string]time.Duration)
+	if strings.TrimSpace(s) == "" {
+		return prefixes, nil
+	}
+
+	for _, pair := range strings.Split(s, ",") {
+		prefix, seconds, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok || prefix == "" {
+			return nil, fmt.Errorf("invalid ttl override %q, expected prefix=seconds", pair)
+		}
+		n, err := strconv.Atoi(seconds)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ttl override %q: %w", pair, err)
+		}
+		prefixes[prefix] = time.Duration(n) * time.Second
+	}
+
+	return prefixes, nil
+}
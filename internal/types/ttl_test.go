@@ -0,0 +1,74 @@
+package types
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTTLPolicy_TTL(t *testing.T) {
+	policy := TTLPolicy{
+		Default: time.Minute,
+		Prefixes: map[string]time.Duration{
+			"CPU":            time.Hour,
+			"CPUutilization": 2 * time.Hour,
+		},
+	}
+
+	assert.Equal(t, time.Minute, policy.TTL("Alloc"))
+	assert.Equal(t, time.Hour, policy.TTL("CPUcount"))
+	assert.Equal(t, 2*time.Hour, policy.TTL("CPUutilization7"))
+}
+
+func TestTTLPolicy_IsStale(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	old := now.Add(-2 * time.Minute)
+	fresh := now.Add(-30 * time.Second)
+
+	policy := TTLPolicy{
+		Default:  time.Minute,
+		Prefixes: map[string]time.Duration{"Keep": 0},
+	}
+
+	tests := []struct {
+		name   string
+		metric *Metrics
+		want   bool
+	}{
+		{name: "nil metric", metric: nil, want: false},
+		{name: "no update time", metric: &Metrics{ID: "Alloc"}, want: false},
+		{name: "fresh", metric: &Metrics{ID: "Alloc", UpdatedAt: &fresh}, want: false},
+		{name: "stale", metric: &Metrics{ID: "Alloc", UpdatedAt: &old}, want: true},
+		{name: "ttl disabled by override", metric: &Metrics{ID: "KeepMe", UpdatedAt: &old}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, policy.IsStale(tt.metric, now))
+		})
+	}
+
+	assert.True(t, policy.Enabled())
+	assert.False(t, TTLPolicy{}.Enabled())
+}
+
+func TestParseTTLPrefixes(t *testing.T) {
+	got, err := ParseTTLPrefixes("CPU=60, Disk=600")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]time.Duration{
+		"CPU":  time.Minute,
+		"Disk": 10 * time.Minute,
+	}, got)
+
+	got, err = ParseTTLPrefixes("")
+	require.NoError(t, err)
+	assert.Empty(t, got)
+
+	_, err = ParseTTLPrefixes("CPU")
+	assert.Error(t, err)
+
+	_, err = ParseTTLPrefixes("CPU=abc")
+	assert.Error(t, err)
+}
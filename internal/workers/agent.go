@@ -2,16 +2,13 @@ package workers
 
 import (
 	"context"
-	"math/rand/v2"
 	"runtime"
-	"strconv"
 	"sync"
 	"time"
 
+	"github.com/sbilibin2017/go-yandex-practicum/internal/collectors"
 	"github.com/sbilibin2017/go-yandex-practicum/internal/logger"
 	"github.com/sbilibin2017/go-yandex-practicum/internal/types"
-	"github.com/shirou/gopsutil/v4/cpu"
-	"github.com/shirou/gopsutil/v4/mem"
 )
 
 // Updater defines an interface for updating metrics.
@@ -34,6 +31,8 @@ type agentWorkerOptions struct {
 	batchSize      int
 	rateLimit      int
 	updater        Updater
+	aggregation    string
+	registry       *collectors.Registry
 }
 
 // AgentWorkerOption represents a functional option for configuring AgentWorkerConfig.
@@ -74,6 +73,23 @@ func WithUpdater(updater Updater) AgentWorkerOption {
 	}
 }
 
+// WithGaugeAggregation sets which value of a gauge polled several times between
+// reports is sent: GaugeAggregationLast, GaugeAggregationMin, GaugeAggregationMax
+// or GaugeAggregationAvg. Counters are always summed.
+func WithGaugeAggregation(mode string) AgentWorkerOption {
+	return func(cfg *agentWorkerOptions) {
+		cfg.aggregation = mode
+	}
+}
+
+// WithCollectors sets the registry of collectors to poll. Without it the built-in
+// collectors are polled every poll interval.
+func WithCollectors(registry *collectors.Registry) AgentWorkerOption {
+	return func(cfg *agentWorkerOptions) {
+		cfg.registry = registry
+	}
+}
+
 // NewAgentWorker creates and returns a worker function that collects and reports metrics
 // according to the provided configuration options.
 func NewAgentWorker(opts ...AgentWorkerOption) func(ctx context.Context) error {
@@ -88,209 +104,56 @@ func NewAgentWorker(opts ...AgentWorkerOption) func(ctx context.Context) error {
 	for _, opt := range opts {
 		opt(cfg)
 	}
+	if cfg.registry == nil {
+		// Without exec plugins the built-in collectors always register
+		cfg.registry, _ = collectors.NewDefaultRegistry(time.Duration(cfg.pollInterval) * time.Second)
+	}
 
 	return func(ctx context.Context) error {
 		return startAgent(
 			ctx,
 			cfg.updater,
-			cfg.pollInterval,
+			cfg.registry,
 			cfg.reportInterval,
 			cfg.batchSize,
 			cfg.rateLimit,
+			cfg.aggregation,
 		)
 	}
 }
 
-// startAgent coordinates the polling and reporting of metrics,
+// startAgent coordinates the polling, aggregation and reporting of metrics,
 // starting necessary goroutines and managing their lifecycle.
 func startAgent(
 	ctx context.Context,
 	updater Updater,
-	pollInterval int,
+	registry *collectors.Registry,
 	reportInterval int,
 	batchSize int,
 	rateLimit int,
+	aggregation string,
 ) error {
-	pollCh := startMetricsPolling(ctx, pollInterval)
-	reportCh := startMetricsReporting(ctx, reportInterval, updater, pollCh, batchSize, rateLimit)
+	pollCh := startMetricsPolling(ctx, registry)
+	agg := newAggregator(aggregation)
+	startMetricsAggregation(ctx, pollCh, agg)
+	reportCh := startMetricsReporting(ctx, reportInterval, updater, agg.Flush, batchSize, rateLimit)
 	return logResults(ctx, reportCh)
 }
 
-// startMetricsPolling begins periodic metrics collection.
+// startMetricsPolling polls the registered collectors.
 // Returns a channel that streams collected metrics.
-func startMetricsPolling(ctx context.Context, pollInterval int) <-chan *types.Metrics {
-	getRuntimeGaugeMetrics := func() []*types.Metrics {
-		var memStats runtime.MemStats
-		runtime.ReadMemStats(&memStats)
-
-		v1 := float64(memStats.Alloc)
-		v2 := float64(memStats.BuckHashSys)
-		v3 := float64(memStats.Frees)
-		v4 := memStats.GCCPUFraction
-		v5 := float64(memStats.GCSys)
-		v6 := float64(memStats.HeapAlloc)
-		v7 := float64(memStats.HeapIdle)
-		v8 := float64(memStats.HeapInuse)
-		v9 := float64(memStats.HeapObjects)
-		v10 := float64(memStats.HeapReleased)
-		v11 := float64(memStats.HeapSys)
-		v12 := float64(memStats.LastGC)
-		v13 := float64(memStats.Lookups)
-		v14 := float64(memStats.MCacheInuse)
-		v15 := float64(memStats.MCacheSys)
-		v16 := float64(memStats.MSpanInuse)
-		v17 := float64(memStats.MSpanSys)
-		v18 := float64(memStats.Mallocs)
-		v19 := float64(memStats.NextGC)
-		v20 := float64(memStats.NumForcedGC)
-		v21 := float64(memStats.NumGC)
-		v22 := float64(memStats.OtherSys)
-		v23 := float64(memStats.PauseTotalNs)
-		v24 := float64(memStats.StackInuse)
-		v25 := float64(memStats.StackSys)
-		v26 := float64(memStats.Sys)
-		v27 := float64(memStats.TotalAlloc)
-		v28 := rand.Float64()
-
-		return []*types.Metrics{
-			{ID: "Alloc", Type: types.Gauge, Value: &v1},
-			{ID: "BuckHashSys", Type: types.Gauge, Value: &v2},
-			{ID: "Frees", Type: types.Gauge, Value: &v3},
-			{ID: "GCCPUFraction", Type: types.Gauge, Value: &v4},
-			{ID: "GCSys", Type: types.Gauge, Value: &v5},
-			{ID: "HeapAlloc", Type: types.Gauge, Value: &v6},
-			{ID: "HeapIdle", Type: types.Gauge, Value: &v7},
-			{ID: "HeapInuse", Type: types.Gauge, Value: &v8},
-			{ID: "HeapObjects", Type: types.Gauge, Value: &v9},
-			{ID: "HeapReleased", Type: types.Gauge, Value: &v10},
-			{ID: "HeapSys", Type: types.Gauge, Value: &v11},
-			{ID: "LastGC", Type: types.Gauge, Value: &v12},
-			{ID: "Lookups", Type: types.Gauge, Value: &v13},
-			{ID: "MCacheInuse", Type: types.Gauge, Value: &v14},
-			{ID: "MCacheSys", Type: types.Gauge, Value: &v15},
-			{ID: "MSpanInuse", Type: types.Gauge, Value: &v16},
-			{ID: "MSpanSys", Type: types.Gauge, Value: &v17},
-			{ID: "Mallocs", Type: types.Gauge, Value: &v18},
-			{ID: "NextGC", Type: types.Gauge, Value: &v19},
-			{ID: "NumForcedGC", Type: types.Gauge, Value: &v20},
-			{ID: "NumGC", Type: types.Gauge, Value: &v21},
-			{ID: "OtherSys", Type: types.Gauge, Value: &v22},
-			{ID: "PauseTotalNs", Type: types.Gauge, Value: &v23},
-			{ID: "StackInuse", Type: types.Gauge, Value: &v24},
-			{ID: "StackSys", Type: types.Gauge, Value: &v25},
-			{ID: "Sys", Type: types.Gauge, Value: &v26},
-			{ID: "TotalAlloc", Type: types.Gauge, Value: &v27},
-			{ID: "RandomValue", Type: types.Gauge, Value: &v28},
-		}
-	}
-
-	getRuntimeCounterMetrics := func() []*types.Metrics {
-		pollCount := int64(1)
-		return []*types.Metrics{
-			{ID: "PollCount", Type: types.Counter, Delta: &pollCount},
-		}
-	}
-
-	getGoputilMetrics := func(ctx context.Context) []*types.Metrics {
-		var result []*types.Metrics
-
-		if vmStat, err := mem.VirtualMemory(); err == nil {
-			total := float64(vmStat.Total)
-			free := float64(vmStat.Free)
-
-			result = append(result, &types.Metrics{ID: "TotalMemory", Type: types.Gauge, Value: &total})
-			result = append(result, &types.Metrics{ID: "FreeMemory", Type: types.Gauge, Value: &free})
-		}
-
-		if cpuPercents, err := cpu.PercentWithContext(ctx, 0, true); err == nil {
-			for i, percent := range cpuPercents {
-				p := percent
-				id := "CPUutilization" + strconv.Itoa(i)
-				result = append(result, &types.Metrics{ID: id, Type: types.Gauge, Value: &p})
-			}
-		}
-
-		return result
-	}
-
-	generatorMetrics := func(input []*types.Metrics) chan *types.Metrics {
-		out := make(chan *types.Metrics, 100)
-
-		go func() {
-			defer close(out)
-			for _, item := range input {
-				out <- item
-			}
-		}()
-
-		return out
-	}
-
-	fanInMetrics := func(ctx context.Context, channels ...chan *types.Metrics) chan *types.Metrics {
-		finalCh := make(chan *types.Metrics)
-		var wg sync.WaitGroup
-
-		for _, ch := range channels {
-			chCopy := ch
-			wg.Add(1)
-			go func() {
-				defer wg.Done()
-				for {
-					select {
-					case <-ctx.Done():
-						return
-					case metric, ok := <-chCopy:
-						if !ok {
-							return
-						}
-						finalCh <- metric
-					}
-				}
-			}()
-		}
-
-		go func() {
-			wg.Wait()
-			close(finalCh)
-		}()
-
-		return finalCh
-	}
-
-	out := make(chan *types.Metrics, 100)
-
-	go func() {
-		defer close(out)
-
-		ticker := time.NewTicker(time.Duration(pollInterval) * time.Second)
-		defer ticker.Stop()
-
-		for {
-			select {
-			case <-ctx.Done():
-				return
-			case <-ticker.C:
-				runtimeCh := generatorMetrics(getRuntimeGaugeMetrics())
-				counterCh := generatorMetrics(getRuntimeCounterMetrics())
-				gopsutilCh := generatorMetrics(getGoputilMetrics(ctx))
-
-				for metric := range fanInMetrics(ctx, runtimeCh, counterCh, gopsutilCh) {
-					out <- metric
-				}
-			}
-		}
-	}()
-
-	return out
+func startMetricsPolling(ctx context.Context, registry *collectors.Registry) <-chan *types.Metrics {
+	return registry.Start(ctx)
 }
 
 // startMetricsReporting starts the reporting workers that batch and send metrics updates.
+// On every report tick it sends the metrics returned by flush, split into batches.
 // It returns a channel of Result indicating success or failure of update operations.
 func startMetricsReporting(
 	ctx context.Context,
 	reportInterval int,
 	updater Updater,
-	in <-chan *types.Metrics,
+	flush func() []*types.Metrics,
 	batchSize int,
 	rateLimit int,
 ) <-chan result {
@@ -345,29 +208,22 @@ func startMetricsReporting(
 			case <-ctx.Done():
 				return
 			case <-ticker.C:
-				wg.Add(rateLimit)
-				jobsCh := make(chan *types.Metrics, 100)
-
-				for i := 0; i < rateLimit; i++ {
-					go worker(i, wg, jobsCh)
+				metrics := flush()
+				if len(metrics) == 0 {
+					continue
 				}
 
-			feedLoop:
-				for {
-					select {
-					case <-ctx.Done():
-						break feedLoop
-					case m, ok := <-in:
-						if !ok {
-							break feedLoop
-						}
-						jobsCh <- m
-					default:
-						break feedLoop
-					}
+				// Queue the whole report before starting the workers so they take full batches
+				jobsCh := make(chan *types.Metrics, len(metrics))
+				for _, m := range metrics {
+					jobsCh <- m
 				}
-
 				close(jobsCh)
+
+				wg.Add(rateLimit)
+				for i := 0; i < rateLimit; i++ {
+					go worker(i, wg, jobsCh)
+				}
 				wg.Wait()
 			}
 		}
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/golang/mock/gomock"
+	"github.com/sbilibin2017/go-yandex-practicum/internal/collectors"
 	"github.com/sbilibin2017/go-yandex-practicum/internal/types"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -43,7 +44,9 @@ func TestStartMetricsPolling_CancelImmediately(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	cancel() // cancel immediately
 
-	ch := startMetricsPolling(ctx, 1)
+	registry, err := collectors.NewDefaultRegistry(time.Second)
+	require.NoError(t, err)
+	ch := startMetricsPolling(ctx, registry)
 	assert.NotNil(t, ch)
 
 	// The channel should close quickly due to canceled context
@@ -107,7 +110,9 @@ func TestStartMetricsPolling(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	metricsCh := startMetricsPolling(ctx, 1)
+	registry, err := collectors.NewDefaultRegistry(time.Second)
+	require.NoError(t, err)
+	metricsCh := startMetricsPolling(ctx, registry)
 
 	var collected []*types.Metrics
 	timeout := time.After(3 * time.Second)
@@ -160,11 +165,14 @@ func TestStartMetricsReporting(t *testing.T) {
 		{ID: "m5", Type: types.Gauge, Value: float64Ptr(5)},
 	}
 
-	inCh := make(chan *types.Metrics, len(metrics))
-	for _, m := range metrics {
-		inCh <- m
+	flushed := false
+	flush := func() []*types.Metrics {
+		if flushed {
+			return nil
+		}
+		flushed = true
+		return metrics
 	}
-	close(inCh)
 
 	batchSize := 2
 	rateLimit := 2
@@ -182,7 +190,7 @@ func TestStartMetricsReporting(t *testing.T) {
 		}).
 		Times(3) // Expect 3 calls: (2 + 2 + 1 metrics)
 
-	resultsCh := startMetricsReporting(ctx, reportInterval, mockUpdater, inCh, batchSize, rateLimit)
+	resultsCh := startMetricsReporting(ctx, reportInterval, mockUpdater, flush, batchSize, rateLimit)
 
 	var results []result
 	timeout := time.After(3 * time.Second)
@@ -0,0 +1,142 @@
+package workers
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+
+	"github.com/sbilibin2017/go-yandex-practicum/internal/types"
+)
+
+// Gauge aggregation modes: which value of a gauge polled several times
+// between two reports is sent.
+const (
+	GaugeAggregationLast = "last" // GaugeAggregationLast sends the latest sample.
+	GaugeAggregationMin  = "min"  // GaugeAggregationMin sends the smallest sample.
+	GaugeAggregationMax  = "max"  // GaugeAggregationMax sends the largest sample.
+	GaugeAggregationAvg  = "avg"  // GaugeAggregationAvg sends the mean of the samples.
+)
+
+// ValidateGaugeAggregation returns an error for an unknown gauge aggregation mode.
+// An empty mode means GaugeAggregationLast.
+func ValidateGaugeAggregation(mode string) error {
+	switch mode {
+	case "", GaugeAggregationLast, GaugeAggregationMin, GaugeAggregationMax, GaugeAggregationAvg:
+		return nil
+	default:
+		return fmt.Errorf("unknown gauge aggregation %q", mode)
+	}
+}
+
+// aggregate holds the samples of one metric since the last flush.
+type aggregate struct {
+	last, min, max, sum float64
+	count               int
+	delta               int64
+}
+
+// aggregator consolidates polled samples between reports: counters are
+// summed and gauges reduced to one value according to the mode.
+type aggregator struct {
+	mode string
+
+	mu      sync.Mutex
+	order   []types.MetricID // first-seen order, so reports are stable
+	entries map[types.MetricID]*aggregate
+}
+
+func newAggregator(mode string) *aggregator {
+	if mode == "" {
+		mode = GaugeAggregationLast
+	}
+	return &aggregator{
+		mode:    mode,
+		entries: make(map[types.MetricID]*aggregate),
+	}
+}
+
+// Add records one sample.
+func (a *aggregator) Add(m *types.Metrics) {
+	if m == nil {
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	id := types.MetricID{ID: m.ID, Type: m.Type}
+	e, ok := a.entries[id]
+	if !ok {
+		e = &aggregate{min: math.Inf(1), max: math.Inf(-1)}
+		a.entries[id] = e
+		a.order = append(a.order, id)
+	}
+
+	if m.Delta != nil {
+		e.delta += *m.Delta
+	}
+	if m.Value != nil {
+		v := *m.Value
+		e.last = v
+		e.min = math.Min(e.min, v)
+		e.max = math.Max(e.max, v)
+		e.sum += v
+		e.count++
+	}
+}
+
+// Flush returns one consolidated metric per metric seen since the previous flush
+// and starts over.
+func (a *aggregator) Flush() []*types.Metrics {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	out := make([]*types.Metrics, 0, len(a.order))
+	for _, id := range a.order {
+		e := a.entries[id]
+		m := &types.Metrics{ID: id.ID, Type: id.Type}
+		if id.Type == types.Counter {
+			delta := e.delta
+			m.Delta = &delta
+		} else if e.count > 0 {
+			value := e.value(a.mode)
+			m.Value = &value
+		}
+		out = append(out, m)
+	}
+
+	a.order = nil
+	a.entries = make(map[types.MetricID]*aggregate)
+	return out
+}
+
+func (e *aggregate) value(mode string) float64 {
+	switch mode {
+	case GaugeAggregationMin:
+		return e.min
+	case GaugeAggregationMax:
+		return e.max
+	case GaugeAggregationAvg:
+		return e.sum / float64(e.count)
+	default:
+		return e.last
+	}
+}
+
+// startMetricsAggregation feeds polled metrics into agg until the channel
+// closes or the context is done.
+func startMetricsAggregation(ctx context.Context, in <-chan *types.Metrics, agg *aggregator) {
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case m, ok := <-in:
+				if !ok {
+					return
+				}
+				agg.Add(m)
+			}
+		}
+	}()
+}
@@ -0,0 +1,104 @@
+package workers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sbilibin2017/go-yandex-practicum/internal/types"
+)
+
+func gaugeSample(id string, v float64) *types.Metrics {
+	return &types.Metrics{ID: id, Type: types.Gauge, Value: &v}
+}
+
+func counterSample(id string, d int64) *types.Metrics {
+	return &types.Metrics{ID: id, Type: types.Counter, Delta: &d}
+}
+
+func TestAggregator_Flush(t *testing.T) {
+	samples := []*types.Metrics{
+		gaugeSample("Alloc", 4),
+		counterSample("PollCount", 1),
+		gaugeSample("Alloc", 1),
+		counterSample("PollCount", 1),
+		gaugeSample("Alloc", 7),
+		counterSample("PollCount", 1),
+		gaugeSample("RandomValue", 0.5),
+	}
+
+	tests := []struct {
+		mode      string
+		wantAlloc float64
+	}{
+		{mode: "", wantAlloc: 7},
+		{mode: GaugeAggregationLast, wantAlloc: 7},
+		{mode: GaugeAggregationMin, wantAlloc: 1},
+		{mode: GaugeAggregationMax, wantAlloc: 7},
+		{mode: GaugeAggregationAvg, wantAlloc: 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			agg := newAggregator(tt.mode)
+			for _, m := range samples {
+				agg.Add(m)
+			}
+			agg.Add(nil)
+
+			got := agg.Flush()
+			require.Len(t, got, 3, "one metric per ID")
+
+			assert.Equal(t, "Alloc", got[0].ID)
+			require.NotNil(t, got[0].Value)
+			assert.Equal(t, tt.wantAlloc, *got[0].Value)
+
+			assert.Equal(t, "PollCount", got[1].ID)
+			require.NotNil(t, got[1].Delta)
+			assert.Equal(t, int64(3), *got[1].Delta)
+
+			assert.Equal(t, "RandomValue", got[2].ID)
+			assert.Equal(t, 0.5, *got[2].Value)
+
+			assert.Empty(t, agg.Flush(), "flush starts over")
+		})
+	}
+}
+
+func TestStartMetricsAggregation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	in := make(chan *types.Metrics)
+	agg := newAggregator(GaugeAggregationLast)
+	startMetricsAggregation(ctx, in, agg)
+
+	in <- counterSample("PollCount", 1)
+	in <- counterSample("PollCount", 1)
+	in <- gaugeSample("HeapAlloc", 10)
+	close(in)
+
+	// The last sample may still be on its way into the aggregator, so total up the flushes
+	var polls int64
+	var heapAlloc float64
+	assert.Eventually(t, func() bool {
+		for _, m := range agg.Flush() {
+			switch m.ID {
+			case "PollCount":
+				polls += *m.Delta
+			case "HeapAlloc":
+				heapAlloc = *m.Value
+			}
+		}
+		return polls == 2 && heapAlloc == 10
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestValidateGaugeAggregation(t *testing.T) {
+	for _, mode := range []string{"", "last", "min", "max", "avg"} {
+		assert.NoError(t, ValidateGaugeAggregation(mode), mode)
+	}
+	assert.Error(t, ValidateGaugeAggregation("median"))
+}
@@ -0,0 +1,193 @@
+package workers
+
+import (
+	"context"
+	"time"
+
+	"github.com/sbilibin2017/go-yandex-practicum/internal/logger"
+	"github.com/sbilibin2017/go-yandex-practicum/internal/types"
+)
+
+// Expiry modes supported by the expiry worker.
+const (
+	ExpiryModeMark   = "mark"   // ExpiryModeMark flags stale metrics but keeps them in storage.
+	ExpiryModeExpire = "expire" // ExpiryModeExpire deletes stale metrics from storage.
+)
+
+// Expirer defines an interface for changing a metric only while it is still
+// stale, so an update landing during a sweep is never lost. Each method checks
+// and changes the metric atomically.
+type Expirer interface {
+	// Stamp sets the update time of a metric that has none.
+	Stamp(ctx context.Context, id types.MetricID, at time.Time) (bool, error)
+	// MarkStale flags a metric last updated before cutoff as stale and returns it,
+	// or nil when it is missing, already stale or was updated since.
+	MarkStale(ctx context.Context, id types.MetricID, cutoff time.Time) (*types.Metrics, error)
+	// Expire deletes a metric last updated before cutoff.
+	Expire(ctx context.Context, id types.MetricID, cutoff time.Time) (bool, error)
+}
+
+// Publisher defines an interface for notifying watchers about metric changes.
+type Publisher interface {
+	Publish(events ...types.MetricEvent)
+}
+
+// ExpiryWorkerOption configures the expiry worker.
+type ExpiryWorkerOption func(*expiryWorkerOptions)
+
+type expiryWorkerOptions struct {
+	interval  int
+	mode      string
+	policy    types.TTLPolicy
+	lister    Lister
+	expirer   Expirer
+	publisher Publisher
+	now       func() time.Time
+}
+
+// WithExpiryInterval sets the interval (in seconds) between staleness checks.
+func WithExpiryInterval(interval int) ExpiryWorkerOption {
+	return func(o *expiryWorkerOptions) {
+		o.interval = interval
+	}
+}
+
+// WithExpiryMode sets whether stale metrics are marked (ExpiryModeMark) or deleted (ExpiryModeExpire).
+func WithExpiryMode(mode string) ExpiryWorkerOption {
+	return func(o *expiryWorkerOptions) {
+		o.mode = mode
+	}
+}
+
+// WithExpiryPolicy sets the TTL policy used to detect stale metrics.
+func WithExpiryPolicy(policy types.TTLPolicy) ExpiryWorkerOption {
+	return func(o *expiryWorkerOptions) {
+		o.policy = policy
+	}
+}
+
+// WithExpiryLister sets the Lister used to scan stored metrics.
+func WithExpiryLister(lister Lister) ExpiryWorkerOption {
+	return func(o *expiryWorkerOptions) {
+		o.lister = lister
+	}
+}
+
+// WithExpiryExpirer sets the Expirer used to stamp, mark and delete metrics.
+func WithExpiryExpirer(expirer Expirer) ExpiryWorkerOption {
+	return func(o *expiryWorkerOptions) {
+		o.expirer = expirer
+	}
+}
+
+// WithExpiryPublisher sets the Publisher notified about marked and expired metrics.
+func WithExpiryPublisher(publisher Publisher) ExpiryWorkerOption {
+	return func(o *expiryWorkerOptions) {
+		o.publisher = publisher
+	}
+}
+
+// WithExpiryClock sets the clock used to evaluate staleness.
+func WithExpiryClock(now func() time.Time) ExpiryWorkerOption {
+	return func(o *expiryWorkerOptions) {
+		o.now = now
+	}
+}
+
+// NewExpiryWorker creates a worker that periodically marks or deletes metrics
+// that were not updated within their TTL.
+func NewExpiryWorker(opts ...ExpiryWorkerOption) func(ctx context.Context) error {
+	wo := expiryWorkerOptions{
+		interval: 60,
+		mode:     ExpiryModeMark,
+		now:      time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(&wo)
+	}
+	if wo.interval <= 0 {
+		wo.interval = 60
+	}
+
+	return func(ctx context.Context) error {
+		logger.Log.Debugf("ExpiryWorker: starting mode=%s interval=%d", wo.mode, wo.interval)
+
+		ticker := time.NewTicker(time.Duration(wo.interval) * time.Second)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				logger.Log.Debug("ExpiryWorker: context done, stopping")
+				return nil
+			case <-ticker.C:
+				if err := expireMetrics(ctx, &wo); err != nil {
+					logger.Log.Errorw("ExpiryWorker: sweep failed", "error", err)
+				}
+			}
+		}
+	}
+}
+
+// expireMetrics runs a single staleness sweep over all stored metrics.
+// Metrics without an update time are stamped with the current time so that
+// data written before update times were tracked eventually expires too.
+// The listed metrics only select candidates: the Expirer checks again that a
+// metric is stale when changing it.
+func expireMetrics(ctx context.Context, wo *expiryWorkerOptions) error {
+	metrics, err := wo.lister.List(ctx)
+	if err != nil {
+		return err
+	}
+
+	now := wo.now()
+	for _, m := range metrics {
+		id := types.MetricID{ID: m.ID, Type: m.Type}
+
+		if m.UpdatedAt == nil {
+			if _, err := wo.expirer.Stamp(ctx, id, now); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if !wo.policy.IsStale(m, now) {
+			continue
+		}
+		cutoff := now.Add(-wo.policy.TTL(m.ID))
+
+		switch wo.mode {
+		case ExpiryModeExpire:
+			expired, err := wo.expirer.Expire(ctx, id, cutoff)
+			if err != nil {
+				return err
+			}
+			if expired {
+				logger.Log.Infow("ExpiryWorker: metric expired", "id", id.ID, "type", id.Type)
+				if wo.publisher != nil {
+					wo.publisher.Publish(types.MetricEvent{
+						Kind:   types.MetricEventDeleted,
+						Metric: types.Metrics{ID: id.ID, Type: id.Type},
+					})
+				}
+			}
+		default:
+			if m.Stale {
+				continue
+			}
+			marked, err := wo.expirer.MarkStale(ctx, id, cutoff)
+			if err != nil {
+				return err
+			}
+			if marked != nil {
+				logger.Log.Infow("ExpiryWorker: metric marked stale", "id", id.ID, "type", id.Type)
+				if wo.publisher != nil {
+					wo.publisher.Publish(types.MetricEvent{Kind: types.MetricEventUpdated, Metric: *marked})
+				}
+			}
+		}
+	}
+
+	return nil
+}
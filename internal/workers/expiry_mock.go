@@ -0,0 +1,121 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: /home/sergey/Go/go-yandex-practicum/internal/workers/expiry.go
+
+// Package workers is a generated GoMock package.
+package workers
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+	types "github.com/sbilibin2017/go-yandex-practicum/internal/types"
+)
+
+// MockExpirer is a mock of Expirer interface.
+type MockExpirer struct {
+	ctrl     *gomock.Controller
+	recorder *MockExpirerMockRecorder
+}
+
+// MockExpirerMockRecorder is the mock recorder for MockExpirer.
+type MockExpirerMockRecorder struct {
+	mock *MockExpirer
+}
+
+// NewMockExpirer creates a new mock instance.
+func NewMockExpirer(ctrl *gomock.Controller) *MockExpirer {
+	mock := &MockExpirer{ctrl: ctrl}
+	mock.recorder = &MockExpirerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockExpirer) EXPECT() *MockExpirerMockRecorder {
+	return m.recorder
+}
+
+// Expire mocks base method.
+func (m *MockExpirer) Expire(ctx context.Context, id types.MetricID, cutoff time.Time) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Expire", ctx, id, cutoff)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Expire indicates an expected call of Expire.
+func (mr *MockExpirerMockRecorder) Expire(ctx, id, cutoff interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Expire", reflect.TypeOf((*MockExpirer)(nil).Expire), ctx, id, cutoff)
+}
+
+// MarkStale mocks base method.
+func (m *MockExpirer) MarkStale(ctx context.Context, id types.MetricID, cutoff time.Time) (*types.Metrics, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkStale", ctx, id, cutoff)
+	ret0, _ := ret[0].(*types.Metrics)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MarkStale indicates an expected call of MarkStale.
+func (mr *MockExpirerMockRecorder) MarkStale(ctx, id, cutoff interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkStale", reflect.TypeOf((*MockExpirer)(nil).MarkStale), ctx, id, cutoff)
+}
+
+// Stamp mocks base method.
+func (m *MockExpirer) Stamp(ctx context.Context, id types.MetricID, at time.Time) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Stamp", ctx, id, at)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Stamp indicates an expected call of Stamp.
+func (mr *MockExpirerMockRecorder) Stamp(ctx, id, at interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stamp", reflect.TypeOf((*MockExpirer)(nil).Stamp), ctx, id, at)
+}
+
+// MockPublisher is a mock of Publisher interface.
+type MockPublisher struct {
+	ctrl     *gomock.Controller
+	recorder *MockPublisherMockRecorder
+}
+
+// MockPublisherMockRecorder is the mock recorder for MockPublisher.
+type MockPublisherMockRecorder struct {
+	mock *MockPublisher
+}
+
+// NewMockPublisher creates a new mock instance.
+func NewMockPublisher(ctrl *gomock.Controller) *MockPublisher {
+	mock := &MockPublisher{ctrl: ctrl}
+	mock.recorder = &MockPublisherMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPublisher) EXPECT() *MockPublisherMockRecorder {
+	return m.recorder
+}
+
+// Publish mocks base method.
+func (m *MockPublisher) Publish(events ...types.MetricEvent) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{}
+	for _, a := range events {
+		varargs = append(varargs, a)
+	}
+	m.ctrl.Call(m, "Publish", varargs...)
+}
+
+// Publish indicates an expected call of Publish.
+func (mr *MockPublisherMockRecorder) Publish(events ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Publish", reflect.TypeOf((*MockPublisher)(nil).Publish), events...)
+}
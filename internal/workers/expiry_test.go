@@ -0,0 +1,159 @@
+package workers
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sbilibin2017/go-yandex-practicum/internal/types"
+)
+
+func TestExpireMetrics(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	fresh := now.Add(-10 * time.Second)
+	old := now.Add(-10 * time.Minute)
+	value := 1.5
+
+	policy := types.TTLPolicy{
+		Default:  5 * time.Minute,
+		Prefixes: map[string]time.Duration{"CPU": 30 * time.Minute},
+	}
+
+	allocID := types.MetricID{ID: "Alloc", Type: types.Gauge}
+	allocCutoff := now.Add(-5 * time.Minute)
+
+	tests := []struct {
+		name    string
+		mode    string
+		setup   func(l *MockLister, e *MockExpirer, p *MockPublisher)
+		wantErr string
+	}{
+		{
+			name: "mark stale metric",
+			mode: ExpiryModeMark,
+			setup: func(l *MockLister, e *MockExpirer, p *MockPublisher) {
+				l.EXPECT().List(gomock.Any()).Return([]*types.Metrics{
+					{ID: "Alloc", Type: types.Gauge, Value: &value, UpdatedAt: &old},
+					{ID: "CPUutilization1", Type: types.Gauge, Value: &value, UpdatedAt: &old},
+					{ID: "Frees", Type: types.Gauge, Value: &value, UpdatedAt: &fresh},
+				}, nil)
+				marked := types.Metrics{ID: "Alloc", Type: types.Gauge, Value: &value, UpdatedAt: &old, Stale: true, Version: 2}
+				e.EXPECT().MarkStale(gomock.Any(), allocID, allocCutoff).Return(&marked, nil)
+				p.EXPECT().Publish(types.MetricEvent{Kind: types.MetricEventUpdated, Metric: marked})
+			},
+		},
+		{
+			name: "already marked metric is left alone",
+			mode: ExpiryModeMark,
+			setup: func(l *MockLister, e *MockExpirer, p *MockPublisher) {
+				l.EXPECT().List(gomock.Any()).Return([]*types.Metrics{
+					{ID: "Alloc", Type: types.Gauge, Value: &value, UpdatedAt: &old, Stale: true},
+				}, nil)
+			},
+		},
+		{
+			name: "expire stale metric",
+			mode: ExpiryModeExpire,
+			setup: func(l *MockLister, e *MockExpirer, p *MockPublisher) {
+				l.EXPECT().List(gomock.Any()).Return([]*types.Metrics{
+					{ID: "Alloc", Type: types.Gauge, Value: &value, UpdatedAt: &old},
+				}, nil)
+				e.EXPECT().Expire(gomock.Any(), allocID, allocCutoff).Return(true, nil)
+				p.EXPECT().Publish(types.MetricEvent{
+					Kind:   types.MetricEventDeleted,
+					Metric: types.Metrics{ID: "Alloc", Type: types.Gauge},
+				})
+			},
+		},
+		{
+			name: "metric updated during sweep is kept",
+			mode: ExpiryModeExpire,
+			setup: func(l *MockLister, e *MockExpirer, p *MockPublisher) {
+				l.EXPECT().List(gomock.Any()).Return([]*types.Metrics{
+					{ID: "Alloc", Type: types.Gauge, Value: &value, UpdatedAt: &old},
+				}, nil)
+				e.EXPECT().Expire(gomock.Any(), allocID, allocCutoff).Return(false, nil)
+			},
+		},
+		{
+			name: "metric updated during sweep is not marked",
+			mode: ExpiryModeMark,
+			setup: func(l *MockLister, e *MockExpirer, p *MockPublisher) {
+				l.EXPECT().List(gomock.Any()).Return([]*types.Metrics{
+					{ID: "Alloc", Type: types.Gauge, Value: &value, UpdatedAt: &old},
+				}, nil)
+				e.EXPECT().MarkStale(gomock.Any(), allocID, allocCutoff).Return(nil, nil)
+			},
+		},
+		{
+			name: "metric without update time is stamped",
+			mode: ExpiryModeExpire,
+			setup: func(l *MockLister, e *MockExpirer, p *MockPublisher) {
+				l.EXPECT().List(gomock.Any()).Return([]*types.Metrics{
+					{ID: "Alloc", Type: types.Gauge, Value: &value},
+				}, nil)
+				e.EXPECT().Stamp(gomock.Any(), allocID, now).Return(true, nil)
+			},
+		},
+		{
+			name: "list error",
+			mode: ExpiryModeMark,
+			setup: func(l *MockLister, e *MockExpirer, p *MockPublisher) {
+				l.EXPECT().List(gomock.Any()).Return(nil, errors.New("list error"))
+			},
+			wantErr: "list error",
+		},
+		{
+			name: "expire error",
+			mode: ExpiryModeExpire,
+			setup: func(l *MockLister, e *MockExpirer, p *MockPublisher) {
+				l.EXPECT().List(gomock.Any()).Return([]*types.Metrics{
+					{ID: "Alloc", Type: types.Gauge, Value: &value, UpdatedAt: &old},
+				}, nil)
+				e.EXPECT().Expire(gomock.Any(), gomock.Any(), gomock.Any()).Return(false, errors.New("expire error"))
+			},
+			wantErr: "expire error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lister := NewMockLister(ctrl)
+			expirer := NewMockExpirer(ctrl)
+			publisher := NewMockPublisher(ctrl)
+			tt.setup(lister, expirer, publisher)
+
+			wo := &expiryWorkerOptions{
+				mode:      tt.mode,
+				policy:    policy,
+				lister:    lister,
+				expirer:   expirer,
+				publisher: publisher,
+				now:       func() time.Time { return now },
+			}
+
+			err := expireMetrics(context.Background(), wo)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestNewExpiryWorker_StopsOnContextCancel(t *testing.T) {
+	worker := NewExpiryWorker(WithExpiryInterval(1))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	assert.NoError(t, worker(ctx))
+}
@@ -0,0 +1,78 @@
+package workers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/sbilibin2017/go-yandex-practicum/internal/logger"
+)
+
+// PushWorkerOption configures the push endpoint worker.
+type PushWorkerOption func(*pushWorkerOptions)
+
+type pushWorkerOptions struct {
+	address string
+	handler http.Handler
+}
+
+// WithPushAddress sets the address the push endpoint listens on.
+func WithPushAddress(addr string) PushWorkerOption {
+	return func(o *pushWorkerOptions) {
+		o.address = addr
+	}
+}
+
+// WithPushHandler sets the handler serving the push endpoint.
+func WithPushHandler(handler http.Handler) PushWorkerOption {
+	return func(o *pushWorkerOptions) {
+		o.handler = handler
+	}
+}
+
+// NewPushWorker creates a worker serving the agent's local push endpoint
+// until ctx is done.
+func NewPushWorker(opts ...PushWorkerOption) func(ctx context.Context) error {
+	var o pushWorkerOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return func(ctx context.Context) error {
+		ln, err := net.Listen("tcp", o.address)
+		if err != nil {
+			return fmt.Errorf("failed to listen for pushed metrics on %s: %w", o.address, err)
+		}
+		logger.Log.Infof("Push endpoint started on %s", ln.Addr())
+		return servePush(ctx, ln, o.handler)
+	}
+}
+
+// servePush serves HTTP on ln and shuts the server down once ctx is done.
+func servePush(ctx context.Context, ln net.Listener, handler http.Handler) error {
+	srv := &http.Server{
+		Handler:           handler,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- srv.Serve(ln)
+	}()
+
+	select {
+	case err := <-errCh:
+		return fmt.Errorf("push endpoint stopped: %w", err)
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("failed to shut down push endpoint: %w", err)
+	}
+	return nil
+}
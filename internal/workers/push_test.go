@@ -0,0 +1,48 @@
+package workers
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServePush(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- servePush(ctx, ln, handler)
+	}()
+
+	resp, err := http.Post("http://"+ln.Addr().String()+"/updates/", "application/json", nil)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+
+	cancel()
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("push endpoint did not stop")
+	}
+
+	_, err = http.Post("http://"+ln.Addr().String()+"/updates/", "application/json", nil)
+	assert.Error(t, err, "the listener is closed on shutdown")
+}
+
+func TestNewPushWorker_InvalidAddress(t *testing.T) {
+	worker := NewPushWorker(WithPushAddress("invalid-address"))
+	assert.Error(t, worker(context.Background()))
+}
@@ -0,0 +1,167 @@
+package workers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/sbilibin2017/go-yandex-practicum/internal/logger"
+	"github.com/sbilibin2017/go-yandex-practicum/internal/types"
+)
+
+// Pusher accepts metrics pushed to the agent by local applications.
+type Pusher interface {
+	Updates(ctx context.Context, metrics []*types.Metrics) ([]*types.Metrics, error)
+}
+
+// StatsDWorkerOption configures the StatsD worker.
+type StatsDWorkerOption func(*statsDWorkerOptions)
+
+type statsDWorkerOptions struct {
+	address string
+	pusher  Pusher
+}
+
+// WithStatsDAddress sets the UDP address the StatsD listener binds to.
+func WithStatsDAddress(addr string) StatsDWorkerOption {
+	return func(o *statsDWorkerOptions) {
+		o.address = addr
+	}
+}
+
+// WithStatsDPusher sets where the received metrics are pushed.
+func WithStatsDPusher(pusher Pusher) StatsDWorkerOption {
+	return func(o *statsDWorkerOptions) {
+		o.pusher = pusher
+	}
+}
+
+// NewStatsDWorker creates a worker that listens for StatsD packets and pushes
+// the counters and gauges they carry until ctx is done.
+func NewStatsDWorker(opts ...StatsDWorkerOption) func(ctx context.Context) error {
+	var o statsDWorkerOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return func(ctx context.Context) error {
+		conn, err := net.ListenPacket("udp", o.address)
+		if err != nil {
+			return fmt.Errorf("failed to listen for StatsD on %s: %w", o.address, err)
+		}
+		logger.Log.Infof("StatsD listener started on %s", conn.LocalAddr())
+		return serveStatsD(ctx, conn, o.pusher)
+	}
+}
+
+// serveStatsD reads packets from conn until ctx is done.
+func serveStatsD(ctx context.Context, conn net.PacketConn, pusher Pusher) error {
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	parser := newStatsDParser()
+	buf := make([]byte, 64*1024)
+	for {
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			conn.Close()
+			return fmt.Errorf("failed to read StatsD packet: %w", err)
+		}
+
+		metrics, err := parser.Parse(string(buf[:n]))
+		if err != nil {
+			logger.Log.Warnw("Invalid StatsD lines", "error", err)
+		}
+		if len(metrics) == 0 {
+			continue
+		}
+		if _, err := pusher.Updates(ctx, metrics); err != nil {
+			logger.Log.Errorw("Failed to push StatsD metrics", "error", err)
+		}
+	}
+}
+
+// statsDParser reads StatsD lines of the form "name:value|type[|@rate][|#tags]".
+// Counters ("c") become counters, scaled up by their sample rate; gauges ("g"),
+// timings ("ms") and histogram samples ("h") become gauges. A gauge value
+// starting with a sign changes the last value received for that gauge. Tags
+// are ignored and sets ("s") are not supported.
+type statsDParser struct {
+	gauges map[string]float64
+}
+
+func newStatsDParser() *statsDParser {
+	return &statsDParser{gauges: make(map[string]float64)}
+}
+
+// Parse returns the metrics of the valid lines of a packet together with an
+// error describing the invalid ones.
+func (p *statsDParser) Parse(packet string) ([]*types.Metrics, error) {
+	var metrics []*types.Metrics
+	var errs []error
+	for _, line := range strings.Split(packet, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		m, err := p.parseLine(line)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%q: %w", line, err))
+			continue
+		}
+		metrics = append(metrics, m)
+	}
+	return metrics, errors.Join(errs...)
+}
+
+func (p *statsDParser) parseLine(line string) (*types.Metrics, error) {
+	name, rest, ok := strings.Cut(line, ":")
+	if !ok || name == "" {
+		return nil, errors.New("expected name:value|type")
+	}
+	fields := strings.Split(rest, "|")
+	if len(fields) < 2 {
+		return nil, errors.New("expected name:value|type")
+	}
+	value, kind := fields[0], fields[1]
+
+	rate := 1.0
+	for _, field := range fields[2:] {
+		if s, ok := strings.CutPrefix(field, "@"); ok {
+			r, err := strconv.ParseFloat(s, 64)
+			if err != nil || r <= 0 || r > 1 {
+				return nil, fmt.Errorf("invalid sample rate %q", s)
+			}
+			rate = r
+		}
+	}
+
+	switch kind {
+	case "c":
+		v, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid counter value %q", value)
+		}
+		delta := int64(math.Round(v / rate))
+		return &types.Metrics{ID: name, Type: types.Counter, Delta: &delta}, nil
+	case "g", "ms", "h":
+		v, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid gauge value %q", value)
+		}
+		if kind == "g" && (value[0] == '+' || value[0] == '-') {
+			v += p.gauges[name]
+		}
+		p.gauges[name] = v
+		return &types.Metrics{ID: name, Type: types.Gauge, Value: &v}, nil
+	default:
+		return nil, fmt.Errorf("unsupported metric type %q", kind)
+	}
+}
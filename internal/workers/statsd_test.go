@@ -0,0 +1,121 @@
+package workers
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/sbilibin2017/go-yandex-practicum/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatsDParser_Parse(t *testing.T) {
+	delta := func(v int64) *int64 { return &v }
+	value := func(v float64) *float64 { return &v }
+
+	tests := []struct {
+		name    string
+		packet  string
+		want    []*types.Metrics
+		wantErr bool
+	}{
+		{
+			name:   "counter and gauge",
+			packet: "app.requests:3|c\napp.queue:12.5|g\n",
+			want: []*types.Metrics{
+				{ID: "app.requests", Type: types.Counter, Delta: delta(3)},
+				{ID: "app.queue", Type: types.Gauge, Value: value(12.5)},
+			},
+		},
+		{
+			name:   "sampled counter with tags",
+			packet: "app.requests:2|c|@0.1|#env:prod",
+			want:   []*types.Metrics{{ID: "app.requests", Type: types.Counter, Delta: delta(20)}},
+		},
+		{
+			name:   "gauge changes",
+			packet: "app.queue:10|g\napp.queue:+5|g\napp.queue:-3|g",
+			want: []*types.Metrics{
+				{ID: "app.queue", Type: types.Gauge, Value: value(10)},
+				{ID: "app.queue", Type: types.Gauge, Value: value(15)},
+				{ID: "app.queue", Type: types.Gauge, Value: value(12)},
+			},
+		},
+		{
+			name:   "timing",
+			packet: "app.latency:320|ms",
+			want:   []*types.Metrics{{ID: "app.latency", Type: types.Gauge, Value: value(320)}},
+		},
+		{
+			name:    "invalid lines are skipped",
+			packet:  "app.users:42|s\nbroken\napp.requests:x|c\napp.requests:1|c|@2\napp.requests:1|c",
+			want:    []*types.Metrics{{ID: "app.requests", Type: types.Counter, Delta: delta(1)}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := newStatsDParser().Parse(tt.packet)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestServeStatsD(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	pushed := make(chan []*types.Metrics, 1)
+	pusher := NewMockPusher(ctrl)
+	pusher.EXPECT().Updates(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, metrics []*types.Metrics) ([]*types.Metrics, error) {
+			pushed <- metrics
+			return metrics, nil
+		},
+	)
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- serveStatsD(ctx, conn, pusher)
+	}()
+
+	client, err := net.Dial("udp", conn.LocalAddr().String())
+	require.NoError(t, err)
+	defer client.Close()
+	_, err = client.Write([]byte("app.requests:4|c"))
+	require.NoError(t, err)
+
+	select {
+	case metrics := <-pushed:
+		require.Len(t, metrics, 1)
+		assert.Equal(t, "app.requests", metrics[0].ID)
+		assert.Equal(t, int64(4), *metrics[0].Delta)
+	case <-time.After(time.Second):
+		t.Fatal("no metrics pushed")
+	}
+
+	cancel()
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("listener did not stop")
+	}
+}
+
+func TestNewStatsDWorker_InvalidAddress(t *testing.T) {
+	worker := NewStatsDWorker(WithStatsDAddress("invalid-address"))
+	assert.Error(t, worker(context.Background()))
+}
@@ -0,0 +1,66 @@
+package workers
+
+import (
+	"context"
+	"time"
+
+	"github.com/sbilibin2017/go-yandex-practicum/internal/logger"
+)
+
+// WatchdogWorkerOption configures the watchdog worker.
+type WatchdogWorkerOption func(*watchdogWorkerOptions)
+
+type watchdogWorkerOptions struct {
+	interval time.Duration
+	notify   func(state string) (bool, error)
+	state    string
+}
+
+// WithWatchdogInterval sets the watchdog timeout; keep-alives are sent at half of it.
+func WithWatchdogInterval(interval time.Duration) WatchdogWorkerOption {
+	return func(o *watchdogWorkerOptions) {
+		o.interval = interval
+	}
+}
+
+// WithWatchdogNotify sets the function that sends a keep-alive state to the service manager.
+func WithWatchdogNotify(notify func(state string) (bool, error), state string) WatchdogWorkerOption {
+	return func(o *watchdogWorkerOptions) {
+		o.notify = notify
+		o.state = state
+	}
+}
+
+// NewWatchdogWorker creates a worker that pings the service manager's watchdog
+// until the context is done. A failed ping is logged and retried on the next tick,
+// leaving it to the service manager to restart a process that stays silent.
+func NewWatchdogWorker(opts ...WatchdogWorkerOption) func(ctx context.Context) error {
+	var wo watchdogWorkerOptions
+	for _, opt := range opts {
+		opt(&wo)
+	}
+
+	return func(ctx context.Context) error {
+		if wo.interval <= 0 || wo.notify == nil {
+			// Returning early would look like a finished worker and stop the server
+			<-ctx.Done()
+			return nil
+		}
+		logger.Log.Debugf("WatchdogWorker: starting interval=%s", wo.interval)
+
+		ticker := time.NewTicker(wo.interval / 2)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				logger.Log.Debug("WatchdogWorker: context done, stopping")
+				return nil
+			case <-ticker.C:
+				if _, err := wo.notify(wo.state); err != nil {
+					logger.Log.Errorw("WatchdogWorker: keep-alive failed", "error", err)
+				}
+			}
+		}
+	}
+}
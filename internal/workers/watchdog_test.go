@@ -0,0 +1,52 @@
+package workers
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewWatchdogWorker(t *testing.T) {
+	tests := []struct {
+		name      string
+		interval  time.Duration
+		notifyErr error
+		wantPings bool
+	}{
+		{name: "pings", interval: 20 * time.Millisecond, wantPings: true},
+		{name: "keeps pinging after a failure", interval: 20 * time.Millisecond, notifyErr: errors.New("refused"), wantPings: true},
+		{name: "disabled", interval: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var pings atomic.Int32
+			notify := func(state string) (bool, error) {
+				assert.Equal(t, "WATCHDOG=1", state)
+				pings.Add(1)
+				return tt.notifyErr == nil, tt.notifyErr
+			}
+
+			worker := NewWatchdogWorker(
+				WithWatchdogInterval(tt.interval),
+				WithWatchdogNotify(notify, "WATCHDOG=1"),
+			)
+
+			ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+			defer cancel()
+
+			start := time.Now()
+			assert.NoError(t, worker(ctx))
+			assert.GreaterOrEqual(t, time.Since(start), 100*time.Millisecond, "worker runs until the context is done")
+
+			if tt.wantPings {
+				assert.GreaterOrEqual(t, pings.Load(), int32(2))
+			} else {
+				assert.Zero(t, pings.Load())
+			}
+		})
+	}
+}
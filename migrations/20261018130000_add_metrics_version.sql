@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE content.metrics
+    ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 0;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE content.metrics
+    DROP COLUMN IF EXISTS version;
+-- +goose StatementEnd
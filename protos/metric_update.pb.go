@@ -7,6 +7,7 @@
 package protos
 
 import (
+	_ "google.golang.org/genproto/googleapis/api/annotations"
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
 	reflect "reflect"
@@ -21,12 +22,62 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+type MetricEventType int32
+
+const (
+	MetricEventType_METRIC_EVENT_TYPE_UNSPECIFIED MetricEventType = 0
+	MetricEventType_METRIC_EVENT_TYPE_UPDATED     MetricEventType = 1
+	MetricEventType_METRIC_EVENT_TYPE_DELETED     MetricEventType = 2
+)
+
+// Enum value maps for MetricEventType.
+var (
+	MetricEventType_name = map[int32]string{
+		0: "METRIC_EVENT_TYPE_UNSPECIFIED",
+		1: "METRIC_EVENT_TYPE_UPDATED",
+		2: "METRIC_EVENT_TYPE_DELETED",
+	}
+	MetricEventType_value = map[string]int32{
+		"METRIC_EVENT_TYPE_UNSPECIFIED": 0,
+		"METRIC_EVENT_TYPE_UPDATED":     1,
+		"METRIC_EVENT_TYPE_DELETED":     2,
+	}
+)
+
+func (x MetricEventType) Enum() *MetricEventType {
+	p := new(MetricEventType)
+	*p = x
+	return p
+}
+
+func (x MetricEventType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (MetricEventType) Descriptor() protoreflect.EnumDescriptor {
+	return file_metric_update_proto_enumTypes[0].Descriptor()
+}
+
+func (MetricEventType) Type() protoreflect.EnumType {
+	return &file_metric_update_proto_enumTypes[0]
+}
+
+func (x MetricEventType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use MetricEventType.Descriptor instead.
+func (MetricEventType) EnumDescriptor() ([]byte, []int) {
+	return file_metric_update_proto_rawDescGZIP(), []int{0}
+}
+
 type Metric struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
 	Type          string                 `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
 	Value         float64                `protobuf:"fixed64,3,opt,name=value,proto3" json:"value,omitempty"`
 	Delta         int64                  `protobuf:"varint,4,opt,name=delta,proto3" json:"delta,omitempty"`
+	Op            string                 `protobuf:"bytes,5,opt,name=op,proto3" json:"op,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -89,9 +140,19 @@ func (x *Metric) GetDelta() int64 {
 	return 0
 }
 
+func (x *Metric) GetOp() string {
+	if x != nil {
+		return x.Op
+	}
+	return ""
+}
+
 type UpdateMetricsRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Metrics       []*Metric              `protobuf:"bytes,1,rep,name=metrics,proto3" json:"metrics,omitempty"`
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Metrics []*Metric              `protobuf:"bytes,1,rep,name=metrics,proto3" json:"metrics,omitempty"`
+	// HMAC SHA256 of the message with hash unset. Set on every message of
+	// a StreamUpdates stream when requests are signed.
+	Hash          string `protobuf:"bytes,2,opt,name=hash,proto3" json:"hash,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -133,6 +194,13 @@ func (x *UpdateMetricsRequest) GetMetrics() []*Metric {
 	return nil
 }
 
+func (x *UpdateMetricsRequest) GetHash() string {
+	if x != nil {
+		return x.Hash
+	}
+	return ""
+}
+
 type UpdateMetricsResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Metrics       []*Metric              `protobuf:"bytes,1,rep,name=metrics,proto3" json:"metrics,omitempty"`
@@ -185,23 +253,755 @@ func (x *UpdateMetricsResponse) GetError() string {
 	return ""
 }
 
+type MetricID struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Type          string                 `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MetricID) Reset() {
+	*x = MetricID{}
+	mi := &file_metric_update_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MetricID) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MetricID) ProtoMessage() {}
+
+func (x *MetricID) ProtoReflect() protoreflect.Message {
+	mi := &file_metric_update_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MetricID.ProtoReflect.Descriptor instead.
+func (*MetricID) Descriptor() ([]byte, []int) {
+	return file_metric_update_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *MetricID) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *MetricID) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+type GetMetricsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Ids           []*MetricID            `protobuf:"bytes,1,rep,name=ids,proto3" json:"ids,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetMetricsRequest) Reset() {
+	*x = GetMetricsRequest{}
+	mi := &file_metric_update_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetMetricsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetMetricsRequest) ProtoMessage() {}
+
+func (x *GetMetricsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_metric_update_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetMetricsRequest.ProtoReflect.Descriptor instead.
+func (*GetMetricsRequest) Descriptor() ([]byte, []int) {
+	return file_metric_update_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *GetMetricsRequest) GetIds() []*MetricID {
+	if x != nil {
+		return x.Ids
+	}
+	return nil
+}
+
+type GetMetricsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Metrics       []*Metric              `protobuf:"bytes,1,rep,name=metrics,proto3" json:"metrics,omitempty"`
+	NotFound      []*MetricID            `protobuf:"bytes,2,rep,name=not_found,json=notFound,proto3" json:"not_found,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetMetricsResponse) Reset() {
+	*x = GetMetricsResponse{}
+	mi := &file_metric_update_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetMetricsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetMetricsResponse) ProtoMessage() {}
+
+func (x *GetMetricsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_metric_update_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetMetricsResponse.ProtoReflect.Descriptor instead.
+func (*GetMetricsResponse) Descriptor() ([]byte, []int) {
+	return file_metric_update_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *GetMetricsResponse) GetMetrics() []*Metric {
+	if x != nil {
+		return x.Metrics
+	}
+	return nil
+}
+
+func (x *GetMetricsResponse) GetNotFound() []*MetricID {
+	if x != nil {
+		return x.NotFound
+	}
+	return nil
+}
+
+type DeleteMetricRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            *MetricID              `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteMetricRequest) Reset() {
+	*x = DeleteMetricRequest{}
+	mi := &file_metric_update_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteMetricRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteMetricRequest) ProtoMessage() {}
+
+func (x *DeleteMetricRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_metric_update_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteMetricRequest.ProtoReflect.Descriptor instead.
+func (*DeleteMetricRequest) Descriptor() ([]byte, []int) {
+	return file_metric_update_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *DeleteMetricRequest) GetId() *MetricID {
+	if x != nil {
+		return x.Id
+	}
+	return nil
+}
+
+type DeleteMetricResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteMetricResponse) Reset() {
+	*x = DeleteMetricResponse{}
+	mi := &file_metric_update_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteMetricResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteMetricResponse) ProtoMessage() {}
+
+func (x *DeleteMetricResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_metric_update_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteMetricResponse.ProtoReflect.Descriptor instead.
+func (*DeleteMetricResponse) Descriptor() ([]byte, []int) {
+	return file_metric_update_proto_rawDescGZIP(), []int{7}
+}
+
+type DeleteMetricsByPrefixRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Prefix        string                 `protobuf:"bytes,1,opt,name=prefix,proto3" json:"prefix,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteMetricsByPrefixRequest) Reset() {
+	*x = DeleteMetricsByPrefixRequest{}
+	mi := &file_metric_update_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteMetricsByPrefixRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteMetricsByPrefixRequest) ProtoMessage() {}
+
+func (x *DeleteMetricsByPrefixRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_metric_update_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteMetricsByPrefixRequest.ProtoReflect.Descriptor instead.
+func (*DeleteMetricsByPrefixRequest) Descriptor() ([]byte, []int) {
+	return file_metric_update_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *DeleteMetricsByPrefixRequest) GetPrefix() string {
+	if x != nil {
+		return x.Prefix
+	}
+	return ""
+}
+
+type DeleteMetricsByPrefixResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Deleted       []*MetricID            `protobuf:"bytes,1,rep,name=deleted,proto3" json:"deleted,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteMetricsByPrefixResponse) Reset() {
+	*x = DeleteMetricsByPrefixResponse{}
+	mi := &file_metric_update_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteMetricsByPrefixResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteMetricsByPrefixResponse) ProtoMessage() {}
+
+func (x *DeleteMetricsByPrefixResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_metric_update_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteMetricsByPrefixResponse.ProtoReflect.Descriptor instead.
+func (*DeleteMetricsByPrefixResponse) Descriptor() ([]byte, []int) {
+	return file_metric_update_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *DeleteMetricsByPrefixResponse) GetDeleted() []*MetricID {
+	if x != nil {
+		return x.Deleted
+	}
+	return nil
+}
+
+type ResetMetricRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            *MetricID              `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ResetMetricRequest) Reset() {
+	*x = ResetMetricRequest{}
+	mi := &file_metric_update_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResetMetricRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResetMetricRequest) ProtoMessage() {}
+
+func (x *ResetMetricRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_metric_update_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResetMetricRequest.ProtoReflect.Descriptor instead.
+func (*ResetMetricRequest) Descriptor() ([]byte, []int) {
+	return file_metric_update_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *ResetMetricRequest) GetId() *MetricID {
+	if x != nil {
+		return x.Id
+	}
+	return nil
+}
+
+type ResetMetricResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ResetMetricResponse) Reset() {
+	*x = ResetMetricResponse{}
+	mi := &file_metric_update_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResetMetricResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResetMetricResponse) ProtoMessage() {}
+
+func (x *ResetMetricResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_metric_update_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResetMetricResponse.ProtoReflect.Descriptor instead.
+func (*ResetMetricResponse) Descriptor() ([]byte, []int) {
+	return file_metric_update_proto_rawDescGZIP(), []int{11}
+}
+
+type GetMetricRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            *MetricID              `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetMetricRequest) Reset() {
+	*x = GetMetricRequest{}
+	mi := &file_metric_update_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetMetricRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetMetricRequest) ProtoMessage() {}
+
+func (x *GetMetricRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_metric_update_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetMetricRequest.ProtoReflect.Descriptor instead.
+func (*GetMetricRequest) Descriptor() ([]byte, []int) {
+	return file_metric_update_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *GetMetricRequest) GetId() *MetricID {
+	if x != nil {
+		return x.Id
+	}
+	return nil
+}
+
+type GetMetricResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Metric        *Metric                `protobuf:"bytes,1,opt,name=metric,proto3" json:"metric,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetMetricResponse) Reset() {
+	*x = GetMetricResponse{}
+	mi := &file_metric_update_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetMetricResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetMetricResponse) ProtoMessage() {}
+
+func (x *GetMetricResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_metric_update_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetMetricResponse.ProtoReflect.Descriptor instead.
+func (*GetMetricResponse) Descriptor() ([]byte, []int) {
+	return file_metric_update_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *GetMetricResponse) GetMetric() *Metric {
+	if x != nil {
+		return x.Metric
+	}
+	return nil
+}
+
+type ListMetricsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Prefix        string                 `protobuf:"bytes,1,opt,name=prefix,proto3" json:"prefix,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListMetricsRequest) Reset() {
+	*x = ListMetricsRequest{}
+	mi := &file_metric_update_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListMetricsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListMetricsRequest) ProtoMessage() {}
+
+func (x *ListMetricsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_metric_update_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListMetricsRequest.ProtoReflect.Descriptor instead.
+func (*ListMetricsRequest) Descriptor() ([]byte, []int) {
+	return file_metric_update_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *ListMetricsRequest) GetPrefix() string {
+	if x != nil {
+		return x.Prefix
+	}
+	return ""
+}
+
+type StreamUpdatesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Batches       int64                  `protobuf:"varint,1,opt,name=batches,proto3" json:"batches,omitempty"`
+	Metrics       int64                  `protobuf:"varint,2,opt,name=metrics,proto3" json:"metrics,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamUpdatesResponse) Reset() {
+	*x = StreamUpdatesResponse{}
+	mi := &file_metric_update_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamUpdatesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamUpdatesResponse) ProtoMessage() {}
+
+func (x *StreamUpdatesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_metric_update_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamUpdatesResponse.ProtoReflect.Descriptor instead.
+func (*StreamUpdatesResponse) Descriptor() ([]byte, []int) {
+	return file_metric_update_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *StreamUpdatesResponse) GetBatches() int64 {
+	if x != nil {
+		return x.Batches
+	}
+	return 0
+}
+
+func (x *StreamUpdatesResponse) GetMetrics() int64 {
+	if x != nil {
+		return x.Metrics
+	}
+	return 0
+}
+
+type WatchRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Ids           []*MetricID            `protobuf:"bytes,1,rep,name=ids,proto3" json:"ids,omitempty"`
+	Prefix        string                 `protobuf:"bytes,2,opt,name=prefix,proto3" json:"prefix,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchRequest) Reset() {
+	*x = WatchRequest{}
+	mi := &file_metric_update_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchRequest) ProtoMessage() {}
+
+func (x *WatchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_metric_update_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchRequest.ProtoReflect.Descriptor instead.
+func (*WatchRequest) Descriptor() ([]byte, []int) {
+	return file_metric_update_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *WatchRequest) GetIds() []*MetricID {
+	if x != nil {
+		return x.Ids
+	}
+	return nil
+}
+
+func (x *WatchRequest) GetPrefix() string {
+	if x != nil {
+		return x.Prefix
+	}
+	return ""
+}
+
+type MetricEvent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Type          MetricEventType        `protobuf:"varint,1,opt,name=type,proto3,enum=go_yandex_practicum.MetricEventType" json:"type,omitempty"`
+	Metric        *Metric                `protobuf:"bytes,2,opt,name=metric,proto3" json:"metric,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MetricEvent) Reset() {
+	*x = MetricEvent{}
+	mi := &file_metric_update_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MetricEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MetricEvent) ProtoMessage() {}
+
+func (x *MetricEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_metric_update_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MetricEvent.ProtoReflect.Descriptor instead.
+func (*MetricEvent) Descriptor() ([]byte, []int) {
+	return file_metric_update_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *MetricEvent) GetType() MetricEventType {
+	if x != nil {
+		return x.Type
+	}
+	return MetricEventType_METRIC_EVENT_TYPE_UNSPECIFIED
+}
+
+func (x *MetricEvent) GetMetric() *Metric {
+	if x != nil {
+		return x.Metric
+	}
+	return nil
+}
+
 var File_metric_update_proto protoreflect.FileDescriptor
 
 const file_metric_update_proto_rawDesc = "" +
 	"\n" +
-	"\x13metric_update.proto\x12\x13go_yandex_practicum\"X\n" +
+	"\x13metric_update.proto\x12\x13go_yandex_practicum\x1a\x1cgoogle/api/annotations.proto\"h\n" +
 	"\x06Metric\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
 	"\x04type\x18\x02 \x01(\tR\x04type\x12\x14\n" +
 	"\x05value\x18\x03 \x01(\x01R\x05value\x12\x14\n" +
-	"\x05delta\x18\x04 \x01(\x03R\x05delta\"M\n" +
+	"\x05delta\x18\x04 \x01(\x03R\x05delta\x12\x0e\n" +
+	"\x02op\x18\x05 \x01(\tR\x02op\"a\n" +
 	"\x14UpdateMetricsRequest\x125\n" +
-	"\ametrics\x18\x01 \x03(\v2\x1b.go_yandex_practicum.MetricR\ametrics\"d\n" +
+	"\ametrics\x18\x01 \x03(\v2\x1b.go_yandex_practicum.MetricR\ametrics\x12\x12\n" +
+	"\x04hash\x18\x02 \x01(\tR\x04hash\"d\n" +
 	"\x15UpdateMetricsResponse\x125\n" +
 	"\ametrics\x18\x01 \x03(\v2\x1b.go_yandex_practicum.MetricR\ametrics\x12\x14\n" +
-	"\x05error\x18\x02 \x01(\tR\x05error2q\n" +
-	"\rMetricUpdater\x12`\n" +
-	"\aUpdates\x12).go_yandex_practicum.UpdateMetricsRequest\x1a*.go_yandex_practicum.UpdateMetricsResponseB4Z2github.com/sbilibin2017/go-yandex-practicum/protosb\x06proto3"
+	"\x05error\x18\x02 \x01(\tR\x05error\".\n" +
+	"\bMetricID\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04type\x18\x02 \x01(\tR\x04type\"D\n" +
+	"\x11GetMetricsRequest\x12/\n" +
+	"\x03ids\x18\x01 \x03(\v2\x1d.go_yandex_practicum.MetricIDR\x03ids\"\x87\x01\n" +
+	"\x12GetMetricsResponse\x125\n" +
+	"\ametrics\x18\x01 \x03(\v2\x1b.go_yandex_practicum.MetricR\ametrics\x12:\n" +
+	"\tnot_found\x18\x02 \x03(\v2\x1d.go_yandex_practicum.MetricIDR\bnotFound\"D\n" +
+	"\x13DeleteMetricRequest\x12-\n" +
+	"\x02id\x18\x01 \x01(\v2\x1d.go_yandex_practicum.MetricIDR\x02id\"\x16\n" +
+	"\x14DeleteMetricResponse\"6\n" +
+	"\x1cDeleteMetricsByPrefixRequest\x12\x16\n" +
+	"\x06prefix\x18\x01 \x01(\tR\x06prefix\"X\n" +
+	"\x1dDeleteMetricsByPrefixResponse\x127\n" +
+	"\adeleted\x18\x01 \x03(\v2\x1d.go_yandex_practicum.MetricIDR\adeleted\"C\n" +
+	"\x12ResetMetricRequest\x12-\n" +
+	"\x02id\x18\x01 \x01(\v2\x1d.go_yandex_practicum.MetricIDR\x02id\"\x15\n" +
+	"\x13ResetMetricResponse\"A\n" +
+	"\x10GetMetricRequest\x12-\n" +
+	"\x02id\x18\x01 \x01(\v2\x1d.go_yandex_practicum.MetricIDR\x02id\"H\n" +
+	"\x11GetMetricResponse\x123\n" +
+	"\x06metric\x18\x01 \x01(\v2\x1b.go_yandex_practicum.MetricR\x06metric\",\n" +
+	"\x12ListMetricsRequest\x12\x16\n" +
+	"\x06prefix\x18\x01 \x01(\tR\x06prefix\"K\n" +
+	"\x15StreamUpdatesResponse\x12\x18\n" +
+	"\abatches\x18\x01 \x01(\x03R\abatches\x12\x18\n" +
+	"\ametrics\x18\x02 \x01(\x03R\ametrics\"W\n" +
+	"\fWatchRequest\x12/\n" +
+	"\x03ids\x18\x01 \x03(\v2\x1d.go_yandex_practicum.MetricIDR\x03ids\x12\x16\n" +
+	"\x06prefix\x18\x02 \x01(\tR\x06prefix\"|\n" +
+	"\vMetricEvent\x128\n" +
+	"\x04type\x18\x01 \x01(\x0e2$.go_yandex_practicum.MetricEventTypeR\x04type\x123\n" +
+	"\x06metric\x18\x02 \x01(\v2\x1b.go_yandex_practicum.MetricR\x06metric*r\n" +
+	"\x0fMetricEventType\x12!\n" +
+	"\x1dMETRIC_EVENT_TYPE_UNSPECIFIED\x10\x00\x12\x1d\n" +
+	"\x19METRIC_EVENT_TYPE_UPDATED\x10\x01\x12\x1d\n" +
+	"\x19METRIC_EVENT_TYPE_DELETED\x10\x022\x8d\x01\n" +
+	"\rMetricUpdater\x12|\n" +
+	"\aUpdates\x12).go_yandex_practicum.UpdateMetricsRequest\x1a*.go_yandex_practicum.UpdateMetricsResponse\"\x1a\x82\xd3\xe4\x93\x02\x14\"\x0f/api/v1/updates:\x01*2\xf5\a\n" +
+	"\rMetricService\x12\x7f\n" +
+	"\x03Get\x12%.go_yandex_practicum.GetMetricRequest\x1a&.go_yandex_practicum.GetMetricResponse\")\x82\xd3\xe4\x93\x02#\x12!/api/v1/metrics/{id.type}/{id.id}\x12}\n" +
+	"\bGetBatch\x12&.go_yandex_practicum.GetMetricsRequest\x1a'.go_yandex_practicum.GetMetricsResponse\" \x82\xd3\xe4\x93\x02\x1a\"\x15/api/v1/metrics/batch:\x01*\x12g\n" +
+	"\x04List\x12'.go_yandex_practicum.ListMetricsRequest\x1a\x1b.go_yandex_practicum.Metric\"\x17\x82\xd3\xe4\x93\x02\x11\x12\x0f/api/v1/metrics0\x01\x12\x88\x01\n" +
+	"\x06Delete\x12(.go_yandex_practicum.DeleteMetricRequest\x1a).go_yandex_practicum.DeleteMetricResponse\")\x82\xd3\xe4\x93\x02#*!/api/v1/metrics/{id.type}/{id.id}\x12\x90\x01\n" +
+	"\x0eDeleteByPrefix\x121.go_yandex_practicum.DeleteMetricsByPrefixRequest\x1a2.go_yandex_practicum.DeleteMetricsByPrefixResponse\"\x17\x82\xd3\xe4\x93\x02\x11*\x0f/api/v1/metrics\x12\x8b\x01\n" +
+	"\x05Reset\x12'.go_yandex_practicum.ResetMetricRequest\x1a(.go_yandex_practicum.ResetMetricResponse\"/\x82\xd3\xe4\x93\x02)\"'/api/v1/metrics/{id.type}/{id.id}/reset\x12h\n" +
+	"\rStreamUpdates\x12).go_yandex_practicum.UpdateMetricsRequest\x1a*.go_yandex_practicum.StreamUpdatesResponse(\x01\x12e\n" +
+	"\x05Watch\x12!.go_yandex_practicum.WatchRequest\x1a .go_yandex_practicum.MetricEvent\"\x15\x82\xd3\xe4\x93\x02\x0f\x12\r/api/v1/watch0\x01B4Z2github.com/sbilibin2017/go-yandex-practicum/protosb\x06proto3"
 
 var (
 	file_metric_update_proto_rawDescOnce sync.Once
@@ -215,22 +1015,66 @@ func file_metric_update_proto_rawDescGZIP() []byte {
 	return file_metric_update_proto_rawDescData
 }
 
-var file_metric_update_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_metric_update_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_metric_update_proto_msgTypes = make([]protoimpl.MessageInfo, 18)
 var file_metric_update_proto_goTypes = []any{
-	(*Metric)(nil),                // 0: go_yandex_practicum.Metric
-	(*UpdateMetricsRequest)(nil),  // 1: go_yandex_practicum.UpdateMetricsRequest
-	(*UpdateMetricsResponse)(nil), // 2: go_yandex_practicum.UpdateMetricsResponse
+	(MetricEventType)(0),                  // 0: go_yandex_practicum.MetricEventType
+	(*Metric)(nil),                        // 1: go_yandex_practicum.Metric
+	(*UpdateMetricsRequest)(nil),          // 2: go_yandex_practicum.UpdateMetricsRequest
+	(*UpdateMetricsResponse)(nil),         // 3: go_yandex_practicum.UpdateMetricsResponse
+	(*MetricID)(nil),                      // 4: go_yandex_practicum.MetricID
+	(*GetMetricsRequest)(nil),             // 5: go_yandex_practicum.GetMetricsRequest
+	(*GetMetricsResponse)(nil),            // 6: go_yandex_practicum.GetMetricsResponse
+	(*DeleteMetricRequest)(nil),           // 7: go_yandex_practicum.DeleteMetricRequest
+	(*DeleteMetricResponse)(nil),          // 8: go_yandex_practicum.DeleteMetricResponse
+	(*DeleteMetricsByPrefixRequest)(nil),  // 9: go_yandex_practicum.DeleteMetricsByPrefixRequest
+	(*DeleteMetricsByPrefixResponse)(nil), // 10: go_yandex_practicum.DeleteMetricsByPrefixResponse
+	(*ResetMetricRequest)(nil),            // 11: go_yandex_practicum.ResetMetricRequest
+	(*ResetMetricResponse)(nil),           // 12: go_yandex_practicum.ResetMetricResponse
+	(*GetMetricRequest)(nil),              // 13: go_yandex_practicum.GetMetricRequest
+	(*GetMetricResponse)(nil),             // 14: go_yandex_practicum.GetMetricResponse
+	(*ListMetricsRequest)(nil),            // 15: go_yandex_practicum.ListMetricsRequest
+	(*StreamUpdatesResponse)(nil),         // 16: go_yandex_practicum.StreamUpdatesResponse
+	(*WatchRequest)(nil),                  // 17: go_yandex_practicum.WatchRequest
+	(*MetricEvent)(nil),                   // 18: go_yandex_practicum.MetricEvent
 }
 var file_metric_update_proto_depIdxs = []int32{
-	0, // 0: go_yandex_practicum.UpdateMetricsRequest.metrics:type_name -> go_yandex_practicum.Metric
-	0, // 1: go_yandex_practicum.UpdateMetricsResponse.metrics:type_name -> go_yandex_practicum.Metric
-	1, // 2: go_yandex_practicum.MetricUpdater.Updates:input_type -> go_yandex_practicum.UpdateMetricsRequest
-	2, // 3: go_yandex_practicum.MetricUpdater.Updates:output_type -> go_yandex_practicum.UpdateMetricsResponse
-	3, // [3:4] is the sub-list for method output_type
-	2, // [2:3] is the sub-list for method input_type
-	2, // [2:2] is the sub-list for extension type_name
-	2, // [2:2] is the sub-list for extension extendee
-	0, // [0:2] is the sub-list for field type_name
+	1,  // 0: go_yandex_practicum.UpdateMetricsRequest.metrics:type_name -> go_yandex_practicum.Metric
+	1,  // 1: go_yandex_practicum.UpdateMetricsResponse.metrics:type_name -> go_yandex_practicum.Metric
+	4,  // 2: go_yandex_practicum.GetMetricsRequest.ids:type_name -> go_yandex_practicum.MetricID
+	1,  // 3: go_yandex_practicum.GetMetricsResponse.metrics:type_name -> go_yandex_practicum.Metric
+	4,  // 4: go_yandex_practicum.GetMetricsResponse.not_found:type_name -> go_yandex_practicum.MetricID
+	4,  // 5: go_yandex_practicum.DeleteMetricRequest.id:type_name -> go_yandex_practicum.MetricID
+	4,  // 6: go_yandex_practicum.DeleteMetricsByPrefixResponse.deleted:type_name -> go_yandex_practicum.MetricID
+	4,  // 7: go_yandex_practicum.ResetMetricRequest.id:type_name -> go_yandex_practicum.MetricID
+	4,  // 8: go_yandex_practicum.GetMetricRequest.id:type_name -> go_yandex_practicum.MetricID
+	1,  // 9: go_yandex_practicum.GetMetricResponse.metric:type_name -> go_yandex_practicum.Metric
+	4,  // 10: go_yandex_practicum.WatchRequest.ids:type_name -> go_yandex_practicum.MetricID
+	0,  // 11: go_yandex_practicum.MetricEvent.type:type_name -> go_yandex_practicum.MetricEventType
+	1,  // 12: go_yandex_practicum.MetricEvent.metric:type_name -> go_yandex_practicum.Metric
+	2,  // 13: go_yandex_practicum.MetricUpdater.Updates:input_type -> go_yandex_practicum.UpdateMetricsRequest
+	13, // 14: go_yandex_practicum.MetricService.Get:input_type -> go_yandex_practicum.GetMetricRequest
+	5,  // 15: go_yandex_practicum.MetricService.GetBatch:input_type -> go_yandex_practicum.GetMetricsRequest
+	15, // 16: go_yandex_practicum.MetricService.List:input_type -> go_yandex_practicum.ListMetricsRequest
+	7,  // 17: go_yandex_practicum.MetricService.Delete:input_type -> go_yandex_practicum.DeleteMetricRequest
+	9,  // 18: go_yandex_practicum.MetricService.DeleteByPrefix:input_type -> go_yandex_practicum.DeleteMetricsByPrefixRequest
+	11, // 19: go_yandex_practicum.MetricService.Reset:input_type -> go_yandex_practicum.ResetMetricRequest
+	2,  // 20: go_yandex_practicum.MetricService.StreamUpdates:input_type -> go_yandex_practicum.UpdateMetricsRequest
+	17, // 21: go_yandex_practicum.MetricService.Watch:input_type -> go_yandex_practicum.WatchRequest
+	3,  // 22: go_yandex_practicum.MetricUpdater.Updates:output_type -> go_yandex_practicum.UpdateMetricsResponse
+	14, // 23: go_yandex_practicum.MetricService.Get:output_type -> go_yandex_practicum.GetMetricResponse
+	6,  // 24: go_yandex_practicum.MetricService.GetBatch:output_type -> go_yandex_practicum.GetMetricsResponse
+	1,  // 25: go_yandex_practicum.MetricService.List:output_type -> go_yandex_practicum.Metric
+	8,  // 26: go_yandex_practicum.MetricService.Delete:output_type -> go_yandex_practicum.DeleteMetricResponse
+	10, // 27: go_yandex_practicum.MetricService.DeleteByPrefix:output_type -> go_yandex_practicum.DeleteMetricsByPrefixResponse
+	12, // 28: go_yandex_practicum.MetricService.Reset:output_type -> go_yandex_practicum.ResetMetricResponse
+	16, // 29: go_yandex_practicum.MetricService.StreamUpdates:output_type -> go_yandex_practicum.StreamUpdatesResponse
+	18, // 30: go_yandex_practicum.MetricService.Watch:output_type -> go_yandex_practicum.MetricEvent
+	22, // [22:31] is the sub-list for method output_type
+	13, // [13:22] is the sub-list for method input_type
+	13, // [13:13] is the sub-list for extension type_name
+	13, // [13:13] is the sub-list for extension extendee
+	0,  // [0:13] is the sub-list for field type_name
 }
 
 func init() { file_metric_update_proto_init() }
@@ -243,13 +1087,14 @@ func file_metric_update_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_metric_update_proto_rawDesc), len(file_metric_update_proto_rawDesc)),
-			NumEnums:      0,
-			NumMessages:   3,
+			NumEnums:      1,
+			NumMessages:   18,
 			NumExtensions: 0,
-			NumServices:   1,
+			NumServices:   2,
 		},
 		GoTypes:           file_metric_update_proto_goTypes,
 		DependencyIndexes: file_metric_update_proto_depIdxs,
+		EnumInfos:         file_metric_update_proto_enumTypes,
 		MessageInfos:      file_metric_update_proto_msgTypes,
 	}.Build()
 	File_metric_update_proto = out.File
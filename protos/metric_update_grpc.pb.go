@@ -119,3 +119,371 @@ var MetricUpdater_ServiceDesc = grpc.ServiceDesc{
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "metric_update.proto",
 }
+
+const (
+	MetricService_Get_FullMethodName            = "/go_yandex_practicum.MetricService/Get"
+	MetricService_GetBatch_FullMethodName       = "/go_yandex_practicum.MetricService/GetBatch"
+	MetricService_List_FullMethodName           = "/go_yandex_practicum.MetricService/List"
+	MetricService_Delete_FullMethodName         = "/go_yandex_practicum.MetricService/Delete"
+	MetricService_DeleteByPrefix_FullMethodName = "/go_yandex_practicum.MetricService/DeleteByPrefix"
+	MetricService_Reset_FullMethodName          = "/go_yandex_practicum.MetricService/Reset"
+	MetricService_StreamUpdates_FullMethodName  = "/go_yandex_practicum.MetricService/StreamUpdates"
+	MetricService_Watch_FullMethodName          = "/go_yandex_practicum.MetricService/Watch"
+)
+
+// MetricServiceClient is the client API for MetricService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type MetricServiceClient interface {
+	Get(ctx context.Context, in *GetMetricRequest, opts ...grpc.CallOption) (*GetMetricResponse, error)
+	GetBatch(ctx context.Context, in *GetMetricsRequest, opts ...grpc.CallOption) (*GetMetricsResponse, error)
+	List(ctx context.Context, in *ListMetricsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Metric], error)
+	Delete(ctx context.Context, in *DeleteMetricRequest, opts ...grpc.CallOption) (*DeleteMetricResponse, error)
+	DeleteByPrefix(ctx context.Context, in *DeleteMetricsByPrefixRequest, opts ...grpc.CallOption) (*DeleteMetricsByPrefixResponse, error)
+	Reset(ctx context.Context, in *ResetMetricRequest, opts ...grpc.CallOption) (*ResetMetricResponse, error)
+	StreamUpdates(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[UpdateMetricsRequest, StreamUpdatesResponse], error)
+	Watch(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[MetricEvent], error)
+}
+
+type metricServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewMetricServiceClient(cc grpc.ClientConnInterface) MetricServiceClient {
+	return &metricServiceClient{cc}
+}
+
+func (c *metricServiceClient) Get(ctx context.Context, in *GetMetricRequest, opts ...grpc.CallOption) (*GetMetricResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetMetricResponse)
+	err := c.cc.Invoke(ctx, MetricService_Get_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *metricServiceClient) GetBatch(ctx context.Context, in *GetMetricsRequest, opts ...grpc.CallOption) (*GetMetricsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetMetricsResponse)
+	err := c.cc.Invoke(ctx, MetricService_GetBatch_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *metricServiceClient) List(ctx context.Context, in *ListMetricsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Metric], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &MetricService_ServiceDesc.Streams[0], MetricService_List_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ListMetricsRequest, Metric]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type MetricService_ListClient = grpc.ServerStreamingClient[Metric]
+
+func (c *metricServiceClient) Delete(ctx context.Context, in *DeleteMetricRequest, opts ...grpc.CallOption) (*DeleteMetricResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteMetricResponse)
+	err := c.cc.Invoke(ctx, MetricService_Delete_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *metricServiceClient) DeleteByPrefix(ctx context.Context, in *DeleteMetricsByPrefixRequest, opts ...grpc.CallOption) (*DeleteMetricsByPrefixResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteMetricsByPrefixResponse)
+	err := c.cc.Invoke(ctx, MetricService_DeleteByPrefix_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *metricServiceClient) Reset(ctx context.Context, in *ResetMetricRequest, opts ...grpc.CallOption) (*ResetMetricResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ResetMetricResponse)
+	err := c.cc.Invoke(ctx, MetricService_Reset_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *metricServiceClient) StreamUpdates(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[UpdateMetricsRequest, StreamUpdatesResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &MetricService_ServiceDesc.Streams[1], MetricService_StreamUpdates_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[UpdateMetricsRequest, StreamUpdatesResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type MetricService_StreamUpdatesClient = grpc.ClientStreamingClient[UpdateMetricsRequest, StreamUpdatesResponse]
+
+func (c *metricServiceClient) Watch(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[MetricEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &MetricService_ServiceDesc.Streams[2], MetricService_Watch_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchRequest, MetricEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type MetricService_WatchClient = grpc.ServerStreamingClient[MetricEvent]
+
+// MetricServiceServer is the server API for MetricService service.
+// All implementations must embed UnimplementedMetricServiceServer
+// for forward compatibility.
+type MetricServiceServer interface {
+	Get(context.Context, *GetMetricRequest) (*GetMetricResponse, error)
+	GetBatch(context.Context, *GetMetricsRequest) (*GetMetricsResponse, error)
+	List(*ListMetricsRequest, grpc.ServerStreamingServer[Metric]) error
+	Delete(context.Context, *DeleteMetricRequest) (*DeleteMetricResponse, error)
+	DeleteByPrefix(context.Context, *DeleteMetricsByPrefixRequest) (*DeleteMetricsByPrefixResponse, error)
+	Reset(context.Context, *ResetMetricRequest) (*ResetMetricResponse, error)
+	StreamUpdates(grpc.ClientStreamingServer[UpdateMetricsRequest, StreamUpdatesResponse]) error
+	Watch(*WatchRequest, grpc.ServerStreamingServer[MetricEvent]) error
+	mustEmbedUnimplementedMetricServiceServer()
+}
+
+// UnimplementedMetricServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedMetricServiceServer struct{}
+
+func (UnimplementedMetricServiceServer) Get(context.Context, *GetMetricRequest) (*GetMetricResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Get not implemented")
+}
+func (UnimplementedMetricServiceServer) GetBatch(context.Context, *GetMetricsRequest) (*GetMetricsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBatch not implemented")
+}
+func (UnimplementedMetricServiceServer) List(*ListMetricsRequest, grpc.ServerStreamingServer[Metric]) error {
+	return status.Errorf(codes.Unimplemented, "method List not implemented")
+}
+func (UnimplementedMetricServiceServer) Delete(context.Context, *DeleteMetricRequest) (*DeleteMetricResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Delete not implemented")
+}
+func (UnimplementedMetricServiceServer) DeleteByPrefix(context.Context, *DeleteMetricsByPrefixRequest) (*DeleteMetricsByPrefixResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteByPrefix not implemented")
+}
+func (UnimplementedMetricServiceServer) Reset(context.Context, *ResetMetricRequest) (*ResetMetricResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Reset not implemented")
+}
+func (UnimplementedMetricServiceServer) StreamUpdates(grpc.ClientStreamingServer[UpdateMetricsRequest, StreamUpdatesResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method StreamUpdates not implemented")
+}
+func (UnimplementedMetricServiceServer) Watch(*WatchRequest, grpc.ServerStreamingServer[MetricEvent]) error {
+	return status.Errorf(codes.Unimplemented, "method Watch not implemented")
+}
+func (UnimplementedMetricServiceServer) mustEmbedUnimplementedMetricServiceServer() {}
+func (UnimplementedMetricServiceServer) testEmbeddedByValue()                       {}
+
+// UnsafeMetricServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to MetricServiceServer will
+// result in compilation errors.
+type UnsafeMetricServiceServer interface {
+	mustEmbedUnimplementedMetricServiceServer()
+}
+
+func RegisterMetricServiceServer(s grpc.ServiceRegistrar, srv MetricServiceServer) {
+	// If the following call pancis, it indicates UnimplementedMetricServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&MetricService_ServiceDesc, srv)
+}
+
+func _MetricService_Get_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetMetricRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MetricServiceServer).Get(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MetricService_Get_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MetricServiceServer).Get(ctx, req.(*GetMetricRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MetricService_GetBatch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetMetricsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MetricServiceServer).GetBatch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MetricService_GetBatch_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MetricServiceServer).GetBatch(ctx, req.(*GetMetricsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MetricService_List_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ListMetricsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(MetricServiceServer).List(m, &grpc.GenericServerStream[ListMetricsRequest, Metric]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type MetricService_ListServer = grpc.ServerStreamingServer[Metric]
+
+func _MetricService_Delete_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteMetricRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MetricServiceServer).Delete(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MetricService_Delete_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MetricServiceServer).Delete(ctx, req.(*DeleteMetricRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MetricService_DeleteByPrefix_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteMetricsByPrefixRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MetricServiceServer).DeleteByPrefix(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MetricService_DeleteByPrefix_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MetricServiceServer).DeleteByPrefix(ctx, req.(*DeleteMetricsByPrefixRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MetricService_Reset_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ResetMetricRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MetricServiceServer).Reset(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MetricService_Reset_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MetricServiceServer).Reset(ctx, req.(*ResetMetricRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MetricService_StreamUpdates_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(MetricServiceServer).StreamUpdates(&grpc.GenericServerStream[UpdateMetricsRequest, StreamUpdatesResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type MetricService_StreamUpdatesServer = grpc.ClientStreamingServer[UpdateMetricsRequest, StreamUpdatesResponse]
+
+func _MetricService_Watch_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(MetricServiceServer).Watch(m, &grpc.GenericServerStream[WatchRequest, MetricEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type MetricService_WatchServer = grpc.ServerStreamingServer[MetricEvent]
+
+// MetricService_ServiceDesc is the grpc.ServiceDesc for MetricService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var MetricService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "go_yandex_practicum.MetricService",
+	HandlerType: (*MetricServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Get",
+			Handler:    _MetricService_Get_Handler,
+		},
+		{
+			MethodName: "GetBatch",
+			Handler:    _MetricService_GetBatch_Handler,
+		},
+		{
+			MethodName: "Delete",
+			Handler:    _MetricService_Delete_Handler,
+		},
+		{
+			MethodName: "DeleteByPrefix",
+			Handler:    _MetricService_DeleteByPrefix_Handler,
+		},
+		{
+			MethodName: "Reset",
+			Handler:    _MetricService_Reset_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "List",
+			Handler:       _MetricService_List_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "StreamUpdates",
+			Handler:       _MetricService_StreamUpdates_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "Watch",
+			Handler:       _MetricService_Watch_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "metric_update.proto",
+}